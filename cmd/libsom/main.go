@@ -0,0 +1,179 @@
+// Command libsom is the C library of map inference, which embeds trained
+// models in C, C++ or Python applications without a service hop. Build it by
+//
+//	go build -buildmode=c-shared -o libsom.so ./cmd/libsom
+//
+// which also writes libsom.h declaring:
+//
+//	uintptr_t som_load(char* path);
+//	int som_dims(uintptr_t handle, int* x, int* y);
+//	int som_map(uintptr_t handle, double* vector, int length, int* x, int* y);
+//	int som_anomaly_score(uintptr_t handle, double* vector, int length, double* score);
+//	int som_free(uintptr_t handle);
+//	char* som_last_error(void);
+//	void som_free_error(char* message);
+//
+// som_load loads the model saved by SaveJSON or SaveBinary and returns its
+// handle, 0 on failure, the handle is released by som_free. som_map finds
+// the BMU of the vector and som_anomaly_score computes its distance to the
+// BMU, the quantization error of the vector. The functions return 0 on
+// success and -1 on failure, som_last_error returns a copy of the message of
+// the last failure in any thread, NULL if nothing failed yet, which the caller
+// owns and releases by som_free_error. The functions are safe to call
+// concurrently.
+package main
+
+/*
+#include <stdint.h>
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"runtime/cgo"
+	"sync"
+	"unsafe"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+var (
+	mu        sync.Mutex
+	lastError string
+)
+
+func main() {}
+
+//export som_load
+func som_load(path *C.char) C.uintptr_t {
+	data, err := os.ReadFile(C.GoString(path))
+	if err != nil {
+		fail(err)
+		return 0
+	}
+	var sm *som.SOM
+	if bytes.HasPrefix(data, []byte("SOMB")) {
+		sm, err = som.LoadBinary(bytes.NewReader(data))
+	} else {
+		sm, err = som.LoadJSON(bytes.NewReader(data))
+	}
+	if err != nil {
+		fail(err)
+		return 0
+	}
+	model, err := sm.Model()
+	if err != nil {
+		fail(err)
+		return 0
+	}
+	return C.uintptr_t(cgo.NewHandle(model))
+}
+
+//export som_dims
+func som_dims(handle C.uintptr_t, x, y *C.int) C.int {
+	model, err := lookup(handle)
+	if err != nil {
+		return C.int(fail(err))
+	}
+	xLen, yLen := model.Dims()
+	*x, *y = C.int(xLen), C.int(yLen)
+	return 0
+}
+
+//export som_map
+func som_map(handle C.uintptr_t, vector *C.double, length C.int, x, y *C.int) C.int {
+	model, err := lookup(handle)
+	if err != nil {
+		return C.int(fail(err))
+	}
+	bmu, err := model.BMU(goVector(vector, length))
+	if err != nil {
+		return C.int(fail(err))
+	}
+	*x, *y = C.int(bmu.X), C.int(bmu.Y)
+	return 0
+}
+
+//export som_anomaly_score
+func som_anomaly_score(handle C.uintptr_t, vector *C.double, length C.int, score *C.double) C.int {
+	model, err := lookup(handle)
+	if err != nil {
+		return C.int(fail(err))
+	}
+	v := goVector(vector, length)
+	if width := model.Width(); len(v) != width {
+		return C.int(fail(fmt.Errorf("%w: vector length is %d, weights length is %d", som.ErrWidthMismatch, len(v), width)))
+	}
+	min := math.Inf(1)
+	for _, column := range model.Distances(v, nil) {
+		for _, d := range column {
+			min = math.Min(min, d)
+		}
+	}
+	*score = C.double(min)
+	return 0
+}
+
+//export som_free
+func som_free(handle C.uintptr_t) (code C.int) {
+	if handle == 0 {
+		return 0
+	}
+	defer func() {
+		if recover() != nil {
+			code = C.int(fail(errors.New("invalid model handle")))
+		}
+	}()
+	cgo.Handle(handle).Delete()
+	return 0
+}
+
+//export som_last_error
+func som_last_error() *C.char {
+	mu.Lock()
+	defer mu.Unlock()
+	if lastError == "" {
+		return nil
+	}
+	return C.CString(lastError)
+}
+
+//export som_free_error
+func som_free_error(message *C.char) {
+	C.free(unsafe.Pointer(message))
+}
+
+// lookup returns the model of the handle.
+func lookup(handle C.uintptr_t) (model *som.Model, err error) {
+	if handle == 0 {
+		return nil, errors.New("invalid model handle 0")
+	}
+	defer func() {
+		if recover() != nil {
+			err = errors.New("invalid model handle")
+		}
+	}()
+	return cgo.Handle(handle).Value().(*som.Model), nil
+}
+
+// goVector copies the C vector.
+func goVector(vector *C.double, length C.int) som.DataVector {
+	if length <= 0 {
+		return som.DataVector{}
+	}
+	c := unsafe.Slice((*float64)(unsafe.Pointer(vector)), int(length))
+	return append(som.DataVector(nil), c...)
+}
+
+// fail records the error as the last one and returns -1.
+func fail(err error) int {
+	mu.Lock()
+	defer mu.Unlock()
+	lastError = err.Error()
+	return -1
+}
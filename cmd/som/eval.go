@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/voievodin/self-organizing-map/som"
+	"github.com/voievodin/self-organizing-map/som/quality"
+)
+
+// evalResult is the outcome of som eval, Purity and Accuracy
+// are set only for labeled data.
+type evalResult struct {
+	Vectors           int      `json:"vectors"`
+	QuantizationError float64  `json:"quantization_error"`
+	TopographicError  float64  `json:"topographic_error"`
+	Entropy           float64  `json:"entropy"`
+	NormalizedEntropy float64  `json:"normalized_entropy"`
+	Dead              int      `json:"dead"`
+	Neurons           int      `json:"neurons"`
+	Purity            *float64 `json:"purity,omitempty"`
+	Accuracy          *float64 `json:"accuracy,omitempty"`
+}
+
+// eval prints the quality metrics of the model on the data, see usage.
+func eval(args []string) error {
+	flags := flag.NewFlagSet("eval", flag.ExitOnError)
+	modelPath := flags.String("model", "", "the model saved by SaveJSON or SaveBinary")
+	dataPath := flags.String("data", "", "the CSV data in the input space of the model")
+	header := flags.Bool("header", false, "the first CSV record is the column names")
+	labelsPath := flags.String("labels", "", "the labels of the data vectors, one per line")
+	asJSON := flags.Bool("json", false, "print the metrics as JSON")
+	flags.Parse(args)
+	if *modelPath == "" || *dataPath == "" {
+		usage()
+	}
+
+	sm, err := loadModel(*modelPath)
+	if err != nil {
+		return err
+	}
+	ds, err := loadCSV(*dataPath, *header)
+	if err != nil {
+		return err
+	}
+	activation := quality.Activation(quality.HitMap(sm, ds))
+	result := &evalResult{
+		Vectors:           ds.Len(),
+		QuantizationError: sm.QuantizationError(ds),
+		TopographicError:  sm.TopographicError(ds),
+		Entropy:           activation.Entropy,
+		NormalizedEntropy: activation.NormalizedEntropy,
+		Dead:              activation.Dead,
+		Neurons:           activation.Neurons,
+	}
+	if *labelsPath != "" {
+		if err := evalLabeled(sm, ds, *labelsPath, result); err != nil {
+			return err
+		}
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(result)
+	}
+	fmt.Printf("vectors: %d\n", result.Vectors)
+	fmt.Printf("quantization error: %g\n", result.QuantizationError)
+	fmt.Printf("topographic error: %g\n", result.TopographicError)
+	fmt.Printf("entropy: %g bits (normalized %g)\n", result.Entropy, result.NormalizedEntropy)
+	fmt.Printf("dead units: %d of %d\n", result.Dead, result.Neurons)
+	if result.Purity != nil {
+		fmt.Printf("purity: %g\n", *result.Purity)
+		fmt.Printf("accuracy: %g\n", *result.Accuracy)
+	}
+	return nil
+}
+
+// evalLabeled calibrates the model by the labeled data and records
+// the purity of the map and the accuracy of the calibrated model on the data.
+func evalLabeled(sm *som.SOM, ds *som.DataSet, labelsPath string, result *evalResult) error {
+	labels, err := loadLabels(labelsPath, ds.Len())
+	if err != nil {
+		return err
+	}
+	model, err := sm.Model()
+	if err != nil {
+		return err
+	}
+	if model, err = model.Calibrate(ds, labels); err != nil {
+		return err
+	}
+	_, purity := quality.Purity(model.Calibration())
+	confusion, err := quality.Confusion(model, ds, labels)
+	if err != nil {
+		return err
+	}
+	accuracy := confusion.Accuracy()
+	result.Purity, result.Accuracy = &purity, &accuracy
+	return nil
+}
+
+// loadModel reads the model saved by SaveJSON or SaveBinary,
+// the format is detected by the content.
+func loadModel(path string) (*som.SOM, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.HasPrefix(data, []byte("SOMB")) {
+		return som.LoadBinary(bytes.NewReader(data))
+	}
+	return som.LoadJSON(bytes.NewReader(data))
+}
+
+// loadCSV reads the data set, malformed rows fail the command,
+// so the vectors stay aligned with their labels.
+func loadCSV(path string, header bool) (*som.DataSet, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	ds, _, err := som.ReadCSV(f, header)
+	if err != nil {
+		return nil, err
+	}
+	if ds.Len() == 0 {
+		return nil, fmt.Errorf("data set %s is empty", path)
+	}
+	return ds, nil
+}
+
+// loadLabels reads one label per line, so the label on line i is the label
+// of the data row i, empty lines are empty labels. Fails if the number of
+// the labels differs from the number of the data rows.
+func loadLabels(path string, rows int) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var labels []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		labels = append(labels, strings.TrimSpace(scanner.Text()))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(labels) != rows {
+		return nil, fmt.Errorf("%d labels in %s for %d data rows", len(labels), path, rows)
+	}
+	return labels, nil
+}
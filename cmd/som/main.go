@@ -0,0 +1,103 @@
+// Command som runs self-organizing map experiments.
+//
+// Usage:
+//
+//	som run spec.json
+//
+// runs the experiment declared by the spec file, see som.ExperimentSpec,
+// and prints its quantization and topographic errors.
+//
+//	som eval -model model.json -data test.csv [-header] [-labels labels.txt] [-json]
+//
+// prints the quantization and topographic errors, the map entropy and the
+// number of dead units of the model on the data. Given the labels of the
+// data, one per line, it also prints the purity of the map and the accuracy
+// of the model calibrated by the data. -json prints the metrics as JSON.
+//
+//	som tune -data data.csv -grid sizes=20x20,30x30 -radius 2,4,8 [-iters 10000] [-metric qe]
+//
+// trains a map for each combination of the sizes and the radii, see
+// tune.GridSearch, writes the runs ranked by the metric, qe (quantization
+// error) or te (topographic error), to -results (tune.csv) and the best
+// map to -model (best.json).
+//
+//	som serve-train -listen :8080 [-x 10 -y 10 | -model model.json] [-checkpoint model.somb] [-every 1m]
+//
+// teaches a map online from the vectors posted to /vectors, see
+// server.TrainingServer, serves the live dashboard at / and saves the map
+// to the checkpoint file periodically and on interrupt. A new map is
+// initialized from the first vectors, -model continues learning a saved one.
+// -buffer N queues up to N posted vectors for learning, so bursts don't block
+// the producers, -overflow decides what the full queue does with new vectors:
+// block (default), drop-oldest or sample, see som.IngestBuffer.
+// -snapshots dir keeps the snapshots taken by POST /snapshots in the directory,
+// so POST /rollback can return to them after a restart. -journal appends the
+// updates of the map to the file for audit, see som.Journal.
+//
+//	som replay -journal journal.jsonl [-until 2024-11-29T00:00:00Z] [-out model.json] [-check model.somb]
+//
+// replays the journal and restores the map as it was at the time, RFC 3339,
+// or at the end of the journal, saves it to -out and prints the maximal
+// difference of its weights from the -check model, see som.ReplayJournal.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	var err error
+	switch os.Args[1] {
+	case "run":
+		err = run(os.Args[2:])
+	case "eval":
+		err = eval(os.Args[2:])
+	case "tune":
+		err = tuneMaps(os.Args[2:])
+	case "serve-train":
+		err = serveTrain(os.Args[2:])
+	case "replay":
+		err = replay(os.Args[2:])
+	default:
+		usage()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "som:", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: som run spec.json")
+	fmt.Fprintln(os.Stderr, "       som eval -model model.json -data test.csv [-header] [-labels labels.txt] [-json]")
+	fmt.Fprintln(os.Stderr, "       som tune -data data.csv -grid sizes=20x20,30x30 -radius 2,4,8 [-iters 10000] [-metric qe]")
+	fmt.Fprintln(os.Stderr, "       som serve-train -listen :8080 [-x 10 -y 10 | -model model.json] [-checkpoint model.somb] [-every 1m]")
+	fmt.Fprintln(os.Stderr, "       som replay -journal journal.jsonl [-until 2024-11-29T00:00:00Z] [-out model.json] [-check model.somb]")
+	os.Exit(2)
+}
+
+func run(args []string) error {
+	if len(args) != 1 {
+		usage()
+	}
+	spec, err := som.LoadExperimentSpecFile(args[0])
+	if err != nil {
+		return err
+	}
+	result, err := som.RunExperiment(spec)
+	if err != nil {
+		return err
+	}
+	if result.LoadReport != nil {
+		fmt.Fprintln(os.Stderr, "som:", result.LoadReport)
+	}
+	fmt.Printf("%s: quantization error %g, topographic error %g\n",
+		spec.Name, result.QuantizationError, result.TopographicError)
+	return nil
+}
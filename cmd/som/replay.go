@@ -0,0 +1,88 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+// replay replays the learning journal, see usage.
+func replay(args []string) error {
+	flags := flag.NewFlagSet("replay", flag.ExitOnError)
+	journalPath := flags.String("journal", "", "the journal written by som.Journal")
+	until := flags.String("until", "", "the time to restore the map at, RFC 3339, the end of the journal if empty")
+	outPath := flags.String("out", "", "the file the restored map is saved to, as JSON if the path has .json extension and as binary otherwise")
+	checkPath := flags.String("check", "", "the model to compare the restored map with, e.g. the checkpoint")
+	flags.Parse(args)
+	if *journalPath == "" {
+		usage()
+	}
+	var untilTime time.Time
+	if *until != "" {
+		var err error
+		if untilTime, err = time.Parse(time.RFC3339, *until); err != nil {
+			return err
+		}
+	}
+
+	f, err := os.Open(*journalPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	sm, err := som.ReplayJournal(f, untilTime)
+	if err != nil {
+		return err
+	}
+	xLen, yLen := sm.Dims()
+	fmt.Printf("restored %dx%d map\n", xLen, yLen)
+
+	if *checkPath != "" {
+		expected, err := loadModel(*checkPath)
+		if err != nil {
+			return err
+		}
+		diff, err := maxWeightsDiff(sm, expected)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("max weights difference from %s: %g\n", *checkPath, diff)
+	}
+	if *outPath != "" {
+		return writeFile(*outPath, func(f *os.File) error {
+			if filepath.Ext(*outPath) == ".json" {
+				return sm.SaveJSON(f, som.Precision{})
+			}
+			return sm.SaveBinary(f, som.Precision{})
+		})
+	}
+	return nil
+}
+
+// maxWeightsDiff returns the maximal absolute difference
+// between the weights of the maps of the same size.
+func maxWeightsDiff(a, b *som.SOM) (float64, error) {
+	ax, ay := a.Dims()
+	bx, by := b.Dims()
+	if ax != bx || ay != by {
+		return 0, fmt.Errorf("map sizes differ: %dx%d and %dx%d", ax, ay, bx, by)
+	}
+	diff := 0.0
+	for x := 0; x < ax; x++ {
+		for y := 0; y < ay; y++ {
+			aw, bw := a.Neurons[x][y].Weights, b.Neurons[x][y].Weights
+			if len(aw) != len(bw) {
+				return 0, fmt.Errorf("%w: neuron (%d, %d) has %d and %d weights", som.ErrWidthMismatch, x, y, len(aw), len(bw))
+			}
+			for k := range aw {
+				diff = math.Max(diff, math.Abs(aw[k]-bw[k]))
+			}
+		}
+	}
+	return diff, nil
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/voievodin/self-organizing-map/som"
+	"github.com/voievodin/self-organizing-map/som/server"
+)
+
+var overflowPolicies = map[string]som.OverflowPolicy{
+	"block":       som.OverflowBlock,
+	"drop-oldest": som.OverflowDropOldest,
+	"sample":      som.OverflowSample,
+}
+
+// serveTrain teaches a map from the vectors posted over HTTP, see usage.
+func serveTrain(args []string) error {
+	flags := flag.NewFlagSet("serve-train", flag.ExitOnError)
+	listen := flags.String("listen", ":8080", "the address to listen on")
+	x := flags.Int("x", 10, "the map width")
+	y := flags.Int("y", 10, "the map height")
+	modelPath := flags.String("model", "", "the model to continue learning, a new map is initialized from the first vectors if empty")
+	checkpointPath := flags.String("checkpoint", "model.somb", "the file the map is saved to, as JSON if the path has .json extension and as binary otherwise")
+	every := flags.Duration("every", time.Minute, "the interval between checkpoints")
+	horizon := flags.Int("horizon", 10000, "the number of iterations the learning rate and the radius decay over")
+	rate := flags.Float64("rate", 0.5, "the initial learning rate")
+	radius := flags.Float64("radius", 0, "the initial neighbourhood radius, half of the larger map side if 0")
+	warmup := flags.Int("warmup", 0, "the number of vectors a new map is initialized from, the number of neurons if 0")
+	buffer := flags.Int("buffer", 0, "the capacity of the ingestion buffer, vectors are learned by the requests if 0")
+	forget := flags.Float64("forget", 0, "the rate stale neurons relax toward the data mean at, forgetting is disabled if 0")
+	drift := flags.Float64("drift", 0, "the divergence of the recent BMUs from the first ones which raises the drift alarm, the alarm is disabled if 0")
+	snapshots := flags.String("snapshots", "", "the directory named snapshots are saved to, snapshots are kept in memory only if empty")
+	journalPath := flags.String("journal", "", "the file the updates of the map are appended to, see som.Journal, journaling is disabled if empty")
+	overflow := flags.String("overflow", "block", "what a full buffer does with new vectors: block, drop-oldest or sample")
+	maxBody := flags.Int64("max-body", server.DefaultMaxBodyBytes, "the maximum size of request bodies in bytes, larger requests are answered with 413")
+	flags.Parse(args)
+
+	var sm *som.SOM
+	var err error
+	if *modelPath != "" {
+		if sm, err = loadModel(*modelPath); err != nil {
+			return err
+		}
+	} else {
+		if *x <= 0 || *y <= 0 {
+			return fmt.Errorf("map size must be positive, got %dx%d", *x, *y)
+		}
+		sm = som.New(*x, *y)
+		if sm.Initializer, err = som.NewInitializer("rand-vectors", nil, nil); err != nil {
+			return err
+		}
+	}
+	if *radius == 0 {
+		xLen, yLen := sm.Dims()
+		*radius = float64(xLen) / 2
+		if yLen > xLen {
+			*radius = float64(yLen) / 2
+		}
+	}
+	if sm.Restraint, err = som.NewRestraint("exp", som.Params{"initial_rate": *rate}); err != nil {
+		return err
+	}
+	if sm.Influence, err = som.NewInfluence("gaussian-exp-decay", som.Params{"initial_width": *radius, "min_width": 0.5}); err != nil {
+		return err
+	}
+
+	policy, ok := overflowPolicies[*overflow]
+	if !ok {
+		return fmt.Errorf("unknown overflow policy %q, expected block, drop-oldest or sample", *overflow)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	ts := &server.TrainingServer{Trainer: &som.OnlineTrainer{SOM: sm, Horizon: *horizon, Warmup: *warmup, SnapshotDir: *snapshots}, MaxBodyBytes: *maxBody}
+	if *forget > 0 {
+		ts.Trainer.Forgetting = &som.Forgetting{Rate: *forget}
+	}
+	if *drift > 0 {
+		ts.Trainer.Drift = &som.DriftDetector{Threshold: *drift}
+	}
+	if *journalPath != "" {
+		journal, err := os.OpenFile(*journalPath, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+		if err != nil {
+			return err
+		}
+		defer journal.Close()
+		sm.Journal = &som.Journal{W: journal}
+	}
+	sm.Logger = &som.StdLogger{Logger: log.New(os.Stderr, "som: ", log.LstdFlags), MinLevel: som.LogWarn}
+	consumed := make(chan struct{})
+	if *buffer > 0 {
+		ts.Buffer = som.NewIngestBuffer(*buffer, policy, nil)
+		go func() {
+			defer close(consumed)
+			ts.Trainer.Consume(ts.Buffer, nil)
+		}()
+	} else {
+		close(consumed)
+	}
+	// the final checkpoint waits for the buffered vectors to be learned
+	learned, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	checkpointed := make(chan struct{})
+	go func() {
+		defer close(checkpointed)
+		ts.CheckpointEvery(learned, *checkpointPath, *every, func(err error) {
+			fmt.Fprintln(os.Stderr, "som: checkpoint:", err)
+		})
+	}()
+
+	httpServer := &http.Server{Addr: *listen, Handler: ts}
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		httpServer.Shutdown(shutdown)
+	}()
+	fmt.Fprintf(os.Stderr, "som: learning from http://%s/vectors, dashboard at http://%s/\n", *listen, *listen)
+	err = httpServer.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		err = nil
+	}
+	if ts.Buffer != nil {
+		ts.Buffer.Close()
+	}
+	<-consumed
+	cancel()
+	<-checkpointed
+	return err
+}
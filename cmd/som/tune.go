@@ -0,0 +1,116 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/voievodin/self-organizing-map/som"
+	"github.com/voievodin/self-organizing-map/som/tune"
+)
+
+// metricNames are the short names of the tuning metrics accepted by som tune.
+var metricNames = map[string]string{
+	"qe": tune.MetricQuantizationError,
+	"te": tune.MetricTopographicError,
+}
+
+// tuneMaps runs the grid search on the data, writes the ranked results
+// and the best model, see usage.
+func tuneMaps(args []string) error {
+	flags := flag.NewFlagSet("tune", flag.ExitOnError)
+	dataPath := flags.String("data", "", "the CSV data")
+	header := flags.Bool("header", false, "the first CSV record is the column names")
+	grid := flags.String("grid", "", "the map sizes, e.g. sizes=20x20,30x30")
+	radii := flags.String("radius", "", "the initial neighbourhood radii, e.g. 2,4,8")
+	iterations := flags.Int("iters", 10000, "the number of learning iterations of each map")
+	metric := flags.String("metric", "qe", "the metric the maps are ranked by, qe or te")
+	seed := flags.Int64("seed", 1, "the seed of the random components of the maps")
+	resultsPath := flags.String("results", "tune.csv", "the ranked results CSV")
+	modelPath := flags.String("model", "best.json", "the best model, saved as JSON if the path has .json extension and as binary otherwise")
+	flags.Parse(args)
+	if *dataPath == "" || *grid == "" || *radii == "" {
+		usage()
+	}
+
+	search := &tune.GridSearch{Iterations: *iterations, Seed: *seed}
+	var err error
+	if search.Sizes, err = parseSizes(*grid); err != nil {
+		return err
+	}
+	if search.Radii, err = parseFloats(*radii); err != nil {
+		return err
+	}
+	metricName, ok := metricNames[*metric]
+	if !ok {
+		return fmt.Errorf("unknown metric %q, expected qe or te", *metric)
+	}
+	ds, err := loadCSV(*dataPath, *header)
+	if err != nil {
+		return err
+	}
+
+	comparison, maps, err := search.Run(ds)
+	if err != nil {
+		return err
+	}
+	if err := writeFile(*resultsPath, func(f *os.File) error { return comparison.WriteCSV(f, metricName) }); err != nil {
+		return err
+	}
+	best := comparison.Ranked(metricName)[0]
+	err = writeFile(*modelPath, func(f *os.File) error {
+		if filepath.Ext(*modelPath) == ".json" {
+			return maps[best].SaveJSON(f, som.Precision{})
+		}
+		return maps[best].SaveBinary(f, som.Precision{})
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("best run %s: %s %g\n", comparison.Runs[best].Name, metricName, comparison.Runs[best].Metrics[metricName])
+	return nil
+}
+
+// parseSizes parses sizes=AxB,CxD.
+func parseSizes(grid string) ([]tune.Size, error) {
+	list, ok := strings.CutPrefix(grid, "sizes=")
+	if !ok {
+		return nil, fmt.Errorf("grid %q must be sizes=AxB,...", grid)
+	}
+	var sizes []tune.Size
+	for _, s := range strings.Split(list, ",") {
+		var size tune.Size
+		if _, err := fmt.Sscanf(s, "%dx%d", &size.X, &size.Y); err != nil || size.X <= 0 || size.Y <= 0 {
+			return nil, fmt.Errorf("bad map size %q", s)
+		}
+		sizes = append(sizes, size)
+	}
+	return sizes, nil
+}
+
+func parseFloats(list string) ([]float64, error) {
+	var values []float64
+	for _, s := range strings.Split(list, ",") {
+		v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+func writeFile(path string, write func(f *os.File) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,109 @@
+package som
+
+import (
+	"fmt"
+	"math"
+)
+
+// QuantizationObserver is implemented by components adapting to the learning
+// progress. Learn calls ObserveQuantization of its influence function after
+// BMU search with the distance between the input vector and the BMU,
+// before the weights are fixed.
+// currentIt => [0, iterationsNumber), 0 means that a new learning started.
+type QuantizationObserver interface {
+	ObserveQuantization(currentIt int, bmuDistance float64)
+}
+
+// AdaptiveGaussianInfluenceFunc is a gaussian influence function, like
+// GaussianExpDecayInfluenceFunc, but its width shrinks depending on the map quality
+// instead of time: the average BMU distance (quantization error) is measured
+// over windows of Window iterations and when it improves relatively to
+// the previous window by less than Threshold, the width is multiplied by Shrink,
+// so the map is organized by a wide neighbourhood as long as it helps.
+type AdaptiveGaussianInfluenceFunc struct {
+	// InitialWidth is the initial width of the neighbourhood.
+	InitialWidth float64
+
+	// MinWidth is the floor of the neighbourhood width, 0 means no floor.
+	MinWidth float64
+
+	// Shrink is the width multiplier within (0, 1), e.g. 0.8.
+	Shrink float64
+
+	// Threshold is the relative improvement of the quantization error,
+	// e.g. 0.01 means that the width shrinks when the error is improved by less than 1%.
+	Threshold float64
+
+	// Window is the number of iterations the error is averaged over.
+	Window int
+
+	started    bool
+	width      float64
+	windowSum  float64
+	windowLen  int
+	previousQE float64
+}
+
+func (f *AdaptiveGaussianInfluenceFunc) Validate() error {
+	if err := validateWidths("width", f.InitialWidth, f.MinWidth); err != nil {
+		return err
+	}
+	if !(f.Shrink > 0 && f.Shrink < 1) {
+		return fmt.Errorf("%w: shrink must be within (0, 1), got %v", ErrInvalidConfig, f.Shrink)
+	}
+	if math.IsNaN(f.Threshold) || f.Threshold < 0 {
+		return fmt.Errorf("%w: threshold must be a non-negative number, got %v", ErrInvalidConfig, f.Threshold)
+	}
+	if f.Window <= 0 {
+		return fmt.Errorf("%w: window must be positive, got %d", ErrInvalidConfig, f.Window)
+	}
+	return nil
+}
+
+func (f *AdaptiveGaussianInfluenceFunc) ObserveQuantization(currentIt int, bmuDistance float64) {
+	if currentIt == 0 || !f.started {
+		f.reset()
+	}
+	f.windowSum += bmuDistance
+	f.windowLen++
+	if f.windowLen < f.Window {
+		return
+	}
+
+	qe := f.windowSum / float64(f.windowLen)
+	f.windowSum, f.windowLen = 0, 0
+	if !math.IsNaN(f.previousQE) {
+		improvement := 0.0
+		if f.previousQE > 0 {
+			improvement = (f.previousQE - qe) / f.previousQE
+		}
+		if improvement < f.Threshold {
+			f.width = math.Max(f.width*f.Shrink, f.MinWidth)
+		}
+	}
+	f.previousQE = qe
+}
+
+// EffectiveRadius returns the current neighbourhood width,
+// which doesn't depend on the iteration.
+func (f *AdaptiveGaussianInfluenceFunc) EffectiveRadius(currentIt, iterationsNumber int) float64 {
+	if !f.started {
+		return f.InitialWidth
+	}
+	return f.width
+}
+
+func (f *AdaptiveGaussianInfluenceFunc) Apply(bmu *Neuron, currentIt, iterationsNumber, x, y int) float64 {
+	return f.ApplyDistance(gridDistance(bmu, x, y), currentIt, iterationsNumber)
+}
+
+func (f *AdaptiveGaussianInfluenceFunc) ApplyDistance(d float64, currentIt, iterationsNumber int) float64 {
+	return gaussian(d, f.EffectiveRadius(currentIt, iterationsNumber))
+}
+
+func (f *AdaptiveGaussianInfluenceFunc) reset() {
+	f.started = true
+	f.width = f.InitialWidth
+	f.windowSum, f.windowLen = 0, 0
+	f.previousQE = math.NaN()
+}
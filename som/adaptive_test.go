@@ -0,0 +1,67 @@
+package som_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+func TestAdaptiveGaussianInfluenceFuncShrinksOnStagnation(t *testing.T) {
+	f := &som.AdaptiveGaussianInfluenceFunc{InitialWidth: 4, MinWidth: 1.5, Shrink: 0.5, Threshold: 0.1, Window: 2}
+	if err := f.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the error halves each window, so the width is kept
+	distances := []float64{8, 8, 4, 4, 2, 2}
+	for it, d := range distances {
+		f.ObserveQuantization(it, d)
+	}
+	assertEq(t, f.EffectiveRadius(6, 100), 4.0)
+
+	// the error stagnates, so the width shrinks down to the floor
+	for it := 6; it < 12; it++ {
+		f.ObserveQuantization(it, 2)
+	}
+	assertEq(t, f.EffectiveRadius(12, 100), 1.5)
+
+	// a new learning starts with the initial width
+	f.ObserveQuantization(0, 2)
+	assertEq(t, f.EffectiveRadius(0, 100), 4.0)
+}
+
+func TestAdaptiveGaussianInfluenceFuncInLearning(t *testing.T) {
+	f := &som.AdaptiveGaussianInfluenceFunc{InitialWidth: 3, Shrink: 0.5, Threshold: 0.05, Window: 5}
+	sm := som.New(5, 5)
+	sm.Initializer = &som.RandWeightsInitializer{}
+	sm.Restraint = &som.SimpleRestraintFunc{A: 1, B: 2}
+	sm.Influence = f
+
+	var radiuses []float64
+	sm.Events = som.EventListenerFunc(func(event som.Event) {
+		radiuses = append(radiuses, event.(*som.IterationEvent).Radius)
+	})
+	if err := sm.Learn(epochsDataSet(), 200); err != nil {
+		t.Fatal(err)
+	}
+
+	assertEq(t, radiuses[0], 3.0)
+	if last := radiuses[len(radiuses)-1]; last >= 3 {
+		t.Fatalf("Expected the width to shrink, got %f", last)
+	}
+}
+
+func TestAdaptiveGaussianInfluenceFuncValidate(t *testing.T) {
+	cases := []*som.AdaptiveGaussianInfluenceFunc{
+		{InitialWidth: 1, Shrink: 1, Window: 1},
+		{InitialWidth: 1, Shrink: 0.5, Window: 0},
+		{InitialWidth: -1, Shrink: 0.5, Window: 1},
+		{InitialWidth: 1, Shrink: 0.5, Threshold: -1, Window: 1},
+	}
+	for _, f := range cases {
+		if err := f.Validate(); !errors.Is(err, som.ErrInvalidConfig) {
+			t.Fatalf("Expected ErrInvalidConfig for %+v, got %v", f, err)
+		}
+	}
+}
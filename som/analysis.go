@@ -0,0 +1,116 @@
+package som
+
+import "math"
+
+// CodebookPCA is the principal component analysis of the codebook,
+// the weights of the unmasked neurons, see Model.CodebookPCA.
+type CodebookPCA struct {
+	// Mean is the mean weights vector.
+	Mean []float64
+
+	// Components[i] is the unit vector of the i-th principal axis, its values
+	// are the loadings of the features, components are ordered by Variances.
+	Components [][]float64
+
+	// Variances[i] is the variance of the codebook along Components[i],
+	// in descending order.
+	Variances []float64
+
+	// Explained[i] is the share of the total variance explained by Components[i].
+	Explained []float64
+}
+
+// CodebookPCA computes the principal components of the codebook, which show
+// the directions the map is organized along. E.g. if the first component
+// explains most of the variance, the map mostly orders the data along it,
+// and its loadings tell which features drive the organization.
+func (m *Model) CodebookPCA() *CodebookPCA {
+	codebook := m.unmaskedWeights()
+	mean, cov := covariance(codebook, m.Width())
+	values, vectors := symmetricEigen(cov)
+
+	pca := &CodebookPCA{
+		Mean:       mean,
+		Components: transpose(vectors),
+		Variances:  make([]float64, len(values)),
+		Explained:  make([]float64, len(values)),
+	}
+	total := 0.0
+	for i, v := range values {
+		pca.Variances[i] = math.Max(v, 0)
+		total += pca.Variances[i]
+	}
+	for i, v := range pca.Variances {
+		if total > 0 {
+			pca.Explained[i] = v / total
+		}
+	}
+	return pca
+}
+
+// FeatureVariances returns the variance of each feature, i.e. of its component
+// plane, across the unmasked neurons and the share of the variance in the total
+// variance of the codebook. Features with tiny shares are almost constant across
+// the map, so they don't affect the organization and are candidates for removal.
+func (m *Model) FeatureVariances() (variances, shares []float64) {
+	_, cov := covariance(m.unmaskedWeights(), m.Width())
+	variances = make([]float64, len(cov))
+	shares = make([]float64, len(cov))
+	total := 0.0
+	for i := range cov {
+		variances[i] = cov[i][i]
+		total += cov[i][i]
+	}
+	for i, v := range variances {
+		if total > 0 {
+			shares[i] = v / total
+		}
+	}
+	return variances, shares
+}
+
+// PlaneCorrelations computes the Pearson correlations between the component
+// planes, correlations[i][j] is the correlation of the weights of the features
+// i and j across the unmasked neurons, NaN if either plane is constant.
+// Near-identical planes (|correlation| close to 1) mean the map treats
+// the features as one, see RedundantFeatures.
+func (m *Model) PlaneCorrelations() [][]float64 {
+	_, cov := covariance(m.unmaskedWeights(), m.Width())
+	correlations := newMatrix(len(cov), len(cov))
+	for i := range cov {
+		for j := range cov {
+			if cov[i][i] == 0 || cov[j][j] == 0 {
+				correlations[i][j] = math.NaN()
+			} else {
+				correlations[i][j] = cov[i][j] / math.Sqrt(cov[i][i]*cov[j][j])
+			}
+		}
+	}
+	return correlations
+}
+
+// RedundantFeatures returns the pairs of features {i, j}, i < j, whose
+// planes correlate with the absolute value of at least the threshold,
+// e.g. 0.95, given the correlations computed by PlaneCorrelations.
+func RedundantFeatures(correlations [][]float64, threshold float64) [][2]int {
+	var pairs [][2]int
+	for i := range correlations {
+		for j := i + 1; j < len(correlations[i]); j++ {
+			if math.Abs(correlations[i][j]) >= threshold {
+				pairs = append(pairs, [2]int{i, j})
+			}
+		}
+	}
+	return pairs
+}
+
+// unmaskedWeights returns the weights of the unmasked neurons, which must not be modified.
+func (m *Model) unmaskedWeights() []DataVector {
+	var weights []DataVector
+	for i := 0; i < m.som.Len(); i++ {
+		if x, y := m.som.Position(i); !m.som.IsMasked(x, y) {
+			weights = append(weights, m.som.Neurons[x][y].Weights)
+		}
+	}
+	return weights
+}
@@ -0,0 +1,66 @@
+package som_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+// diagonalModel has weights along (t, t, 0) with a small noise in the second feature.
+func diagonalModel(t *testing.T) *som.Model {
+	sm := som.New(1, 4)
+	codebook := [][][]float64{{{0, 0, 5}, {1, 1.1, 5}, {2, 1.9, 5}, {3, 3, 5}}}
+	if err := sm.LoadCodebook(codebook); err != nil {
+		t.Fatal(err)
+	}
+	model, err := sm.Model()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return model
+}
+
+func TestCodebookPCA(t *testing.T) {
+	pca := diagonalModel(t).CodebookPCA()
+	checkSlicesEqual(t, pca.Mean, []float64{1.5, 1.5, 5})
+	if pca.Explained[0] < 0.99 {
+		t.Fatalf("Expected the first component to explain the variance, got %v", pca.Explained)
+	}
+	first := pca.Components[0]
+	if math.Abs(math.Abs(first[0])-math.Sqrt(0.5)) > 0.05 || math.Abs(first[2]) > 1e-9 {
+		t.Fatalf("Expected the first component along the diagonal, got %v", first)
+	}
+	sum := 0.0
+	for _, e := range pca.Explained {
+		sum += e
+	}
+	if math.Abs(sum-1) > 1e-12 {
+		t.Fatalf("Expected explained shares to sum up to 1, got %f", sum)
+	}
+}
+
+func TestFeatureVariances(t *testing.T) {
+	variances, shares := diagonalModel(t).FeatureVariances()
+	assertEq(t, variances[0], 1.25)
+	assertEq(t, variances[2], 0.0)
+	assertEq(t, shares[2], 0.0)
+	if math.Abs(shares[0]+shares[1]-1) > 1e-12 {
+		t.Fatalf("Expected shares to sum up to 1, got %v", shares)
+	}
+}
+
+func TestPlaneCorrelationsFlagRedundantFeatures(t *testing.T) {
+	correlations := diagonalModel(t).PlaneCorrelations()
+	assertEq(t, correlations[0][0], 1.0)
+	if correlations[0][1] < 0.99 {
+		t.Fatalf("Expected near-identical planes, got %f", correlations[0][1])
+	}
+	if !math.IsNaN(correlations[0][2]) {
+		t.Fatalf("Expected NaN correlation with the constant plane, got %f", correlations[0][2])
+	}
+
+	pairs := som.RedundantFeatures(correlations, 0.95)
+	assertEq(t, len(pairs), 1)
+	assertEq(t, pairs[0], [2]int{0, 1})
+}
@@ -0,0 +1,98 @@
+package som
+
+import "fmt"
+
+// Anchor is a known prototype vector placed at the given map position.
+type Anchor struct {
+	X, Y    int
+	Label   string
+	Weights DataVector
+}
+
+// AnchorInitializer initializes neurons by Fallback and then places anchors
+// at their positions, so the rest of the map organizes around known prototypes.
+// If Freeze is true, the anchored neurons are frozen and keep
+// the anchor weights while learning, see Neuron.Frozen.
+// Fallback is RandDataSetVectorsWeightsInitializer if nil.
+type AnchorInitializer struct {
+	Anchors  []Anchor
+	Freeze   bool
+	Fallback NeuronsInitializer
+}
+
+// Validate checks that anchors have non-empty weights of the same length
+// and distinct positions, returned errors wrap ErrInvalidConfig.
+// Anchors outside the map and weights not matching the data set
+// width are rejected with ErrInvalidConfig by learning before Init.
+func (initializer *AnchorInitializer) Validate() error {
+	positions := make(map[GridPoint]bool)
+	for i, anchor := range initializer.Anchors {
+		if len(anchor.Weights) == 0 || len(anchor.Weights) != len(initializer.Anchors[0].Weights) {
+			return fmt.Errorf("%w: anchor %d has %d weights", ErrInvalidConfig, i, len(anchor.Weights))
+		}
+		p := GridPoint{anchor.X, anchor.Y}
+		if positions[p] {
+			return fmt.Errorf("%w: several anchors at (%d, %d)", ErrInvalidConfig, p.X, p.Y)
+		}
+		positions[p] = true
+	}
+	return nil
+}
+
+// initValidator is implemented by initializers which fit some maps
+// and data sets only, it is checked before the neurons are initialized.
+type initValidator interface {
+	validateInit(set *DataSet, xLen, yLen int) error
+}
+
+func (initializer *AnchorInitializer) validateInit(set *DataSet, xLen, yLen int) error {
+	for _, anchor := range initializer.Anchors {
+		if anchor.X < 0 || anchor.X >= xLen || anchor.Y < 0 || anchor.Y >= yLen {
+			return fmt.Errorf("%w: anchor %q at (%d, %d) is out of %dx%d map", ErrInvalidConfig, anchor.Label, anchor.X, anchor.Y, xLen, yLen)
+		}
+		if set.Len() > 0 && len(anchor.Weights) != set.Width() {
+			return fmt.Errorf("%w: anchor %q has %d weights, data set width is %d", ErrInvalidConfig, anchor.Label, len(anchor.Weights), set.Width())
+		}
+	}
+	return nil
+}
+
+func (initializer *AnchorInitializer) Init(set *DataSet, neurons [][]*Neuron) {
+	fallback := initializer.Fallback
+	if fallback == nil {
+		fallback = &RandDataSetVectorsWeightsInitializer{}
+	}
+	fallback.Init(set, neurons)
+
+	for i := range neurons {
+		for _, neuron := range neurons[i] {
+			neuron.Frozen = false
+		}
+	}
+	for _, anchor := range initializer.Anchors {
+		if anchor.X < 0 || anchor.X >= len(neurons) || anchor.Y < 0 || anchor.Y >= len(neurons[anchor.X]) {
+			panic(fmt.Sprintf("anchor %q at (%d, %d) is out of the map", anchor.Label, anchor.X, anchor.Y))
+		}
+		if len(anchor.Weights) != set.Width() {
+			panic(fmt.Sprintf("anchor %q has %d weights, data set width is %d", anchor.Label, len(anchor.Weights), set.Width()))
+		}
+		neuron := neurons[anchor.X][anchor.Y]
+		neuron.Weights = append(make([]float64, 0, len(anchor.Weights)), anchor.Weights...)
+		neuron.Frozen = initializer.Freeze
+	}
+}
+
+// Labels returns the labels of anchors placed on the map of the given size,
+// labels[x][y] is the label of the anchor at (x, y), or "" if there is no anchor.
+func (initializer *AnchorInitializer) Labels(xLen, yLen int) [][]string {
+	labels := make([][]string, xLen)
+	for x := range labels {
+		labels[x] = make([]string, yLen)
+	}
+	for _, anchor := range initializer.Anchors {
+		if anchor.X >= 0 && anchor.X < xLen && anchor.Y >= 0 && anchor.Y < yLen {
+			labels[anchor.X][anchor.Y] = anchor.Label
+		}
+	}
+	return labels
+}
@@ -0,0 +1,74 @@
+package som_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+func TestAnchorInitializerFreezesAnchors(t *testing.T) {
+	initializer := &som.AnchorInitializer{
+		Anchors: []som.Anchor{
+			{X: 0, Y: 0, Label: "low", Weights: som.DataVector{0, 0}},
+			{X: 2, Y: 2, Label: "high", Weights: som.DataVector{1, 1}},
+		},
+		Freeze:   true,
+		Fallback: &som.ZeroValueWeightsInitializer{},
+	}
+	if err := initializer.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	sm := som.New(3, 3)
+	sm.Initializer = initializer
+	sm.Restraint = &som.SimpleRestraintFunc{A: 0.5, B: 1}
+	sm.Influence = &som.RadiusReducingConstantInfluenceFunc{Radius: 3}
+	if err := sm.Learn(&som.DataSet{Vectors: []som.DataVector{{0.5, 0.5}}}, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	checkSlicesEqual(t, sm.Neurons[0][0].Weights, []float64{0, 0})
+	checkSlicesEqual(t, sm.Neurons[2][2].Weights, []float64{1, 1})
+	if sm.Neurons[1][1].Weights[0] == 0 {
+		t.Fatal("Expected not anchored neurons to learn")
+	}
+	assertEq(t, initializer.Labels(3, 3)[2][2], "high")
+}
+
+func TestAnchorInitializerValidate(t *testing.T) {
+	cases := [][]som.Anchor{
+		{{X: 0, Y: 0, Weights: som.DataVector{1}}, {X: 0, Y: 0, Weights: som.DataVector{2}}},
+		{{X: 0, Y: 0, Weights: som.DataVector{1}}, {X: 1, Y: 0, Weights: som.DataVector{1, 2}}},
+		{{X: 0, Y: 0}},
+	}
+	for _, anchors := range cases {
+		err := (&som.AnchorInitializer{Anchors: anchors}).Validate()
+		if !errors.Is(err, som.ErrInvalidConfig) {
+			t.Fatalf("Expected ErrInvalidConfig for %v, got %v", anchors, err)
+		}
+	}
+}
+
+func TestLearnRejectsAnchorsNotFittingMapOrData(t *testing.T) {
+	set := &som.DataSet{Vectors: []som.DataVector{{0.5, 0.5}}}
+	cases := []som.Anchor{
+		{X: 3, Y: 0, Label: "outside", Weights: som.DataVector{0, 0}},
+		{X: 0, Y: -1, Label: "negative", Weights: som.DataVector{0, 0}},
+		{X: 0, Y: 0, Label: "narrow", Weights: som.DataVector{0}},
+	}
+	for _, anchor := range cases {
+		learners := map[string]func(sm *som.SOM) error{
+			"Learn":      func(sm *som.SOM) error { return sm.Learn(set, 1) },
+			"LearnBatch": func(sm *som.SOM) error { return sm.LearnBatch(set, 1) },
+		}
+		for name, learn := range learners {
+			sm := som.New(3, 3)
+			sm.Initializer = &som.AnchorInitializer{Anchors: []som.Anchor{anchor}}
+			err := learn(sm)
+			if !errors.Is(err, som.ErrInvalidConfig) || errors.Is(err, som.ErrTrainingPanic) {
+				t.Fatalf("%s: expected ErrInvalidConfig for anchor %q, got %v", name, anchor.Label, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,572 @@
+package som
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Arrow IPC message header types, see Message.fbs of the Arrow format.
+const (
+	arrowHeaderSchema          = 1
+	arrowHeaderDictionaryBatch = 2
+	arrowHeaderRecordBatch     = 3
+)
+
+// Arrow column types, see Schema.fbs of the Arrow format.
+const (
+	arrowTypeNull            = 1
+	arrowTypeInt             = 2
+	arrowTypeFloatingPoint   = 3
+	arrowTypeBinary          = 4
+	arrowTypeUtf8            = 5
+	arrowTypeBool            = 6
+	arrowTypeDecimal         = 7
+	arrowTypeDate            = 8
+	arrowTypeTime            = 9
+	arrowTypeTimestamp       = 10
+	arrowTypeInterval        = 11
+	arrowTypeFixedSizeBinary = 15
+	arrowTypeDuration        = 18
+	arrowTypeLargeBinary     = 19
+	arrowTypeLargeUtf8       = 20
+)
+
+var (
+	// ErrUnsupportedArrow is returned by ArrowReader when the stream
+	// uses features which are not supported, e.g. compression,
+	// dictionaries or nested columns.
+	ErrUnsupportedArrow = errors.New("unsupported arrow feature")
+
+	arrowFileMagic = []byte("ARROW1")
+)
+
+// ArrowReader reads data vectors from Apache Arrow IPC stream or file
+// (as written by pandas, polars, pyarrow, etc.), one vector per row.
+// Integer, floating point and boolean columns become vector elements,
+// other flat columns (e.g. strings or timestamps) are skipped,
+// null values are read as NaN. Streams without numeric columns
+// are rejected with ErrEmptyVector.
+//
+// Compressed bodies, dictionary encoded and nested columns
+// are not supported, ErrUnsupportedArrow is returned for them.
+type ArrowReader struct {
+	r       *bufio.Reader
+	fields  []arrowField
+	names   []string
+	columns [][]float64
+	rows    int
+	row     int
+	done    bool
+}
+
+type arrowField struct {
+	name     string
+	typ      byte
+	bitWidth int
+	signed   bool
+	// numeric is true for the fields which become vector elements
+	numeric bool
+}
+
+// NewArrowReader reads the schema from the given reader
+// and returns ArrowReader ready to stream rows.
+func NewArrowReader(r io.Reader) (*ArrowReader, error) {
+	br := bufio.NewReader(r)
+	if magic, err := br.Peek(len(arrowFileMagic)); err == nil && string(magic) == string(arrowFileMagic) {
+		// file format, magic is padded to 8 bytes and followed by a stream
+		if _, err := br.Discard(8); err != nil {
+			return nil, err
+		}
+	}
+
+	reader := &ArrowReader{r: br}
+	headerType, header, _, err := reader.readMessage()
+	if err != nil {
+		return nil, err
+	}
+	if headerType != arrowHeaderSchema {
+		return nil, fmt.Errorf("arrow: expected schema message, got header type %d", headerType)
+	}
+	if err := reader.parseSchema(header); err != nil {
+		return nil, err
+	}
+	return reader, nil
+}
+
+// Names returns the names of the columns which are read as vector elements,
+// in the order of the elements.
+func (reader *ArrowReader) Names() []string {
+	return append([]string(nil), reader.names...)
+}
+
+// Next returns the next row as a data vector,
+// ErrNoDataLeft is returned when the stream is exhausted.
+func (reader *ArrowReader) Next() (DataVector, error) {
+	for reader.row >= reader.rows {
+		if reader.done {
+			return nil, ErrNoDataLeft
+		}
+		if err := reader.nextBatch(); err != nil {
+			return nil, err
+		}
+	}
+
+	vector := make(DataVector, len(reader.columns))
+	for i, column := range reader.columns {
+		vector[i] = column[reader.row]
+	}
+	reader.row++
+	return vector, nil
+}
+
+func (reader *ArrowReader) nextBatch() error {
+	headerType, header, body, err := reader.readMessage()
+	if err == io.EOF {
+		reader.done = true
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	switch headerType {
+	case arrowHeaderRecordBatch:
+		return reader.parseRecordBatch(header, body)
+	case arrowHeaderDictionaryBatch:
+		return fmt.Errorf("%w: dictionary batches", ErrUnsupportedArrow)
+	default:
+		return fmt.Errorf("arrow: unexpected header type %d", headerType)
+	}
+}
+
+// readMessage reads an encapsulated message, returns io.EOF at the end of stream.
+func (reader *ArrowReader) readMessage() (byte, fbTable, []byte, error) {
+	var size uint32
+	if err := binary.Read(reader.r, binary.LittleEndian, &size); err != nil {
+		return 0, fbTable{}, nil, err
+	}
+	if size == 0xFFFFFFFF {
+		// continuation marker, followed by the actual size
+		if err := binary.Read(reader.r, binary.LittleEndian, &size); err != nil {
+			return 0, fbTable{}, nil, io.ErrUnexpectedEOF
+		}
+	}
+	if size == 0 {
+		return 0, fbTable{}, nil, io.EOF
+	}
+
+	meta, err := readArrowBytes(reader.r, int64(size))
+	if err != nil {
+		return 0, fbTable{}, nil, err
+	}
+
+	var (
+		headerType byte
+		header     fbTable
+		bodyLen    int64
+	)
+	err = fbCatch(func() {
+		message := fbRoot(meta)
+		headerType = byte(message.uint(1, 1))
+		header = message.table(2)
+		bodyLen = int64(message.uint(3, 8))
+	})
+	if err != nil {
+		return 0, fbTable{}, nil, err
+	}
+	if bodyLen < 0 || bodyLen > math.MaxInt32 {
+		return 0, fbTable{}, nil, fmt.Errorf("arrow: bad body length %d", bodyLen)
+	}
+
+	body, err := readArrowBytes(reader.r, bodyLen)
+	if err != nil {
+		return 0, fbTable{}, nil, err
+	}
+	return headerType, header, body, nil
+}
+
+// readArrowBytes reads n bytes, the buffer grows as they are read,
+// so a forged size of a truncated stream doesn't cause a huge allocation.
+func readArrowBytes(r io.Reader, n int64) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if _, err := io.CopyN(buf, r, n); err != nil {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return buf.Bytes(), nil
+}
+
+func (reader *ArrowReader) parseSchema(schema fbTable) error {
+	var unsupported error
+	err := fbCatch(func() {
+		if schema.uint(0, 2) != 0 {
+			unsupported = fmt.Errorf("%w: big endian data", ErrUnsupportedArrow)
+			return
+		}
+		fields := schema.vector(1)
+		for i := 0; i < fields.len(); i++ {
+			table := fields.table(i)
+			field := arrowField{name: table.string(0), typ: byte(table.uint(2, 1))}
+			if !table.absent(4) || table.vector(5).len() != 0 {
+				unsupported = fmt.Errorf("%w: dictionary or nested column %q", ErrUnsupportedArrow, field.name)
+				return
+			}
+
+			switch field.typ {
+			case arrowTypeInt:
+				typ := table.table(3)
+				field.bitWidth = int(typ.uint(0, 4))
+				field.signed = typ.uint(1, 1) != 0
+				field.numeric = true
+			case arrowTypeFloatingPoint:
+				field.bitWidth = 16 << table.table(3).uint(0, 2)
+				field.numeric = true
+			case arrowTypeBool:
+				field.bitWidth = 1
+				field.numeric = true
+			case arrowTypeNull, arrowTypeBinary, arrowTypeUtf8, arrowTypeDecimal, arrowTypeDate,
+				arrowTypeTime, arrowTypeTimestamp, arrowTypeInterval, arrowTypeFixedSizeBinary,
+				arrowTypeDuration, arrowTypeLargeBinary, arrowTypeLargeUtf8:
+			default:
+				unsupported = fmt.Errorf("%w: column %q of type %d", ErrUnsupportedArrow, field.name, field.typ)
+				return
+			}
+			if !field.supportedWidth() {
+				unsupported = fmt.Errorf("%w: column %q of %d bits", ErrUnsupportedArrow, field.name, field.bitWidth)
+				return
+			}
+
+			reader.fields = append(reader.fields, field)
+			if field.numeric {
+				reader.names = append(reader.names, field.name)
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if unsupported != nil {
+		return unsupported
+	}
+	if len(reader.names) == 0 {
+		return fmt.Errorf("arrow: schema has no numeric columns: %w", ErrEmptyVector)
+	}
+	return nil
+}
+
+func (reader *ArrowReader) parseRecordBatch(batch fbTable, body []byte) error {
+	var (
+		rows       int
+		compressed bool
+		nodes      fbVector
+		buffers    fbVector
+	)
+	err := fbCatch(func() {
+		rows = int(batch.uint(0, 8))
+		compressed = !batch.absent(3)
+		nodes = batch.vector(1)
+		buffers = batch.vector(2)
+	})
+	if err != nil {
+		return err
+	}
+	if compressed {
+		return fmt.Errorf("%w: compressed record batch", ErrUnsupportedArrow)
+	}
+	if rows < 0 || nodes.len() != len(reader.fields) {
+		return fmt.Errorf("arrow: record batch of %d rows with %d nodes for %d fields", rows, nodes.len(), len(reader.fields))
+	}
+
+	columns := make([][]float64, 0, len(reader.names))
+	bufIdx := 0
+	for _, field := range reader.fields {
+		n := arrowBuffersNum(field.typ)
+		if bufIdx+n > buffers.len() {
+			return fmt.Errorf("arrow: record batch has %d buffers, more expected", buffers.len())
+		}
+		if field.numeric {
+			validity, err := arrowBuffer(buffers, bufIdx, body)
+			if err != nil {
+				return err
+			}
+			values, err := arrowBuffer(buffers, bufIdx+1, body)
+			if err != nil {
+				return err
+			}
+			column, err := field.decode(rows, validity, values)
+			if err != nil {
+				return err
+			}
+			columns = append(columns, column)
+		}
+		bufIdx += n
+	}
+
+	reader.columns = columns
+	reader.rows = rows
+	reader.row = 0
+	return nil
+}
+
+// supportedWidth returns true if the bit width is valid for the field type,
+// the widths of non numeric fields don't matter as they are skipped.
+func (field *arrowField) supportedWidth() bool {
+	switch field.typ {
+	case arrowTypeInt:
+		return field.bitWidth == 8 || field.bitWidth == 16 || field.bitWidth == 32 || field.bitWidth == 64
+	case arrowTypeFloatingPoint:
+		return field.bitWidth == 16 || field.bitWidth == 32 || field.bitWidth == 64
+	case arrowTypeBool:
+		return field.bitWidth == 1
+	}
+	return true
+}
+
+func (field *arrowField) decode(rows int, validity, values []byte) ([]float64, error) {
+	// rows are bounded by the buffer bits first, so the product doesn't overflow
+	if rows > len(values)*8 || rows*field.bitWidth > len(values)*8 {
+		return nil, fmt.Errorf("arrow: column %q buffer is too short for %d rows", field.name, rows)
+	}
+	if len(validity) != 0 && len(validity)*8 < rows {
+		return nil, fmt.Errorf("arrow: column %q validity bitmap is too short for %d rows", field.name, rows)
+	}
+
+	column := make([]float64, rows)
+	for i := range column {
+		if len(validity) != 0 && validity[i>>3]&(1<<(i&7)) == 0 {
+			column[i] = math.NaN()
+			continue
+		}
+		column[i] = field.value(values, i)
+	}
+	return column, nil
+}
+
+func (field *arrowField) value(values []byte, i int) float64 {
+	switch field.typ {
+	case arrowTypeBool:
+		if values[i>>3]&(1<<(i&7)) != 0 {
+			return 1
+		}
+		return 0
+	case arrowTypeFloatingPoint:
+		switch field.bitWidth {
+		case 16:
+			return float16ToFloat64(binary.LittleEndian.Uint16(values[i*2:]))
+		case 32:
+			return float64(math.Float32frombits(binary.LittleEndian.Uint32(values[i*4:])))
+		default:
+			return math.Float64frombits(binary.LittleEndian.Uint64(values[i*8:]))
+		}
+	}
+
+	switch field.bitWidth {
+	case 8:
+		if field.signed {
+			return float64(int8(values[i]))
+		}
+		return float64(values[i])
+	case 16:
+		v := binary.LittleEndian.Uint16(values[i*2:])
+		if field.signed {
+			return float64(int16(v))
+		}
+		return float64(v)
+	case 32:
+		v := binary.LittleEndian.Uint32(values[i*4:])
+		if field.signed {
+			return float64(int32(v))
+		}
+		return float64(v)
+	default:
+		v := binary.LittleEndian.Uint64(values[i*8:])
+		if field.signed {
+			return float64(int64(v))
+		}
+		return float64(v)
+	}
+}
+
+// arrowBuffersNum returns the number of body buffers used by a flat column of the given type.
+func arrowBuffersNum(typ byte) int {
+	switch typ {
+	case arrowTypeNull:
+		return 0
+	case arrowTypeBinary, arrowTypeUtf8, arrowTypeLargeBinary, arrowTypeLargeUtf8:
+		return 3
+	default:
+		return 2
+	}
+}
+
+func arrowBuffer(buffers fbVector, i int, body []byte) ([]byte, error) {
+	var offset, length int64
+	err := fbCatch(func() {
+		offset = int64(buffers.structField(i, 16, 0, 8))
+		length = int64(buffers.structField(i, 16, 8, 8))
+	})
+	if err != nil {
+		return nil, err
+	}
+	if offset < 0 || length < 0 || offset+length > int64(len(body)) || offset+length < 0 {
+		return nil, fmt.Errorf("arrow: buffer [%d, +%d) is out of body bounds", offset, length)
+	}
+	return body[offset : offset+length], nil
+}
+
+// float16ToFloat64 converts IEEE 754 half precision value.
+func float16ToFloat64(h uint16) float64 {
+	sign := 1.0
+	if h&0x8000 != 0 {
+		sign = -1
+	}
+	exp := int(h>>10) & 0x1F
+	frac := float64(h & 0x3FF)
+	switch exp {
+	case 0:
+		return sign * math.Ldexp(frac, -24)
+	case 0x1F:
+		if frac == 0 {
+			return math.Inf(int(sign))
+		}
+		return math.NaN()
+	default:
+		return sign * math.Ldexp(1+frac/1024, exp-15)
+	}
+}
+
+// A minimal reader of flatbuffers, enough to read Arrow IPC metadata.
+// Malformed input causes fbError panics, which are turned
+// into errors by fbCatch.
+
+type fbError struct{ msg string }
+
+func fbCatch(f func()) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			fbErr, ok := r.(fbError)
+			if !ok {
+				panic(r)
+			}
+			err = errors.New("arrow: malformed metadata: " + fbErr.msg)
+		}
+	}()
+	f()
+	return nil
+}
+
+type fbTable struct {
+	buf []byte
+	pos int
+}
+
+type fbVector struct {
+	buf []byte
+	pos int
+	n   int
+}
+
+func fbRoot(buf []byte) fbTable {
+	return fbTable{buf: buf, pos: fbOffset(buf, 0)}
+}
+
+// fbOffset reads uoffset at pos and returns absolute position it points to.
+func fbOffset(buf []byte, pos int) int {
+	return pos + int(fbUint(buf, pos, 4))
+}
+
+func fbUint(buf []byte, pos, size int) uint64 {
+	if pos < 0 || pos+size > len(buf) {
+		panic(fbError{fmt.Sprintf("read of %d bytes at %d is out of %d bytes", size, pos, len(buf))})
+	}
+	switch size {
+	case 1:
+		return uint64(buf[pos])
+	case 2:
+		return uint64(binary.LittleEndian.Uint16(buf[pos:]))
+	case 4:
+		return uint64(binary.LittleEndian.Uint32(buf[pos:]))
+	default:
+		return binary.LittleEndian.Uint64(buf[pos:])
+	}
+}
+
+// fieldPos returns the absolute position of the field or 0 if the field is absent.
+func (t fbTable) fieldPos(field int) int {
+	vtable := t.pos - int(int32(fbUint(t.buf, t.pos, 4)))
+	vtableSize := int(fbUint(t.buf, vtable, 2))
+	entry := 4 + 2*field
+	if entry+2 > vtableSize {
+		return 0
+	}
+	off := int(fbUint(t.buf, vtable+entry, 2))
+	if off == 0 {
+		return 0
+	}
+	return t.pos + off
+}
+
+func (t fbTable) absent(field int) bool {
+	return t.fieldPos(field) == 0
+}
+
+// uint reads unsigned scalar field of the given size, absent fields are zeros.
+func (t fbTable) uint(field, size int) uint64 {
+	pos := t.fieldPos(field)
+	if pos == 0 {
+		return 0
+	}
+	return fbUint(t.buf, pos, size)
+}
+
+func (t fbTable) table(field int) fbTable {
+	pos := t.fieldPos(field)
+	if pos == 0 {
+		panic(fbError{fmt.Sprintf("required table field %d is absent", field)})
+	}
+	return fbTable{buf: t.buf, pos: fbOffset(t.buf, pos)}
+}
+
+func (t fbTable) string(field int) string {
+	pos := t.fieldPos(field)
+	if pos == 0 {
+		return ""
+	}
+	start := fbOffset(t.buf, pos)
+	n := int(fbUint(t.buf, start, 4))
+	if n > len(t.buf)-start-4 {
+		panic(fbError{fmt.Sprintf("string of %d bytes at %d is out of bounds", n, start)})
+	}
+	return string(t.buf[start+4 : start+4+n])
+}
+
+func (t fbTable) vector(field int) fbVector {
+	pos := t.fieldPos(field)
+	if pos == 0 {
+		return fbVector{}
+	}
+	start := fbOffset(t.buf, pos)
+	n := int(fbUint(t.buf, start, 4))
+	if n > len(t.buf)/4 {
+		panic(fbError{fmt.Sprintf("vector of %d elements at %d is out of bounds", n, start)})
+	}
+	return fbVector{buf: t.buf, pos: start + 4, n: n}
+}
+
+func (v fbVector) len() int {
+	return v.n
+}
+
+func (v fbVector) table(i int) fbTable {
+	return fbTable{buf: v.buf, pos: fbOffset(v.buf, v.pos+4*i)}
+}
+
+// structField reads unsigned scalar of the given size
+// at offset inside i-th struct of structSize bytes.
+func (v fbVector) structField(i, structSize, offset, size int) uint64 {
+	return fbUint(v.buf, v.pos+i*structSize+offset, size)
+}
@@ -0,0 +1,269 @@
+package som_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+func TestArrowReaderReadsNumericColumns(t *testing.T) {
+	stream := &bytes.Buffer{}
+	writeArrowMessage(stream, 1, arrowSchema(
+		arrowField("a", 3, fbTab{u16(2)}),         // double
+		arrowField("label", 5, fbTab{}),           // utf8, skipped
+		arrowField("b", 2, fbTab{u32(32), u8(1)}), // int32
+		arrowField("c", 2, fbTab{u32(8), u8(0)}),  // uint8
+		arrowField("d", 3, fbTab{u16(1)}),         // float
+	), nil)
+
+	body := &bytes.Buffer{}
+	buffers := []arrowBuf{}
+	addBuf := func(data []byte) {
+		buffers = append(buffers, arrowBuf{offset: body.Len(), length: len(data)})
+		body.Write(data)
+	}
+	// a: double, second value is null
+	addBuf([]byte{0b01})
+	addBuf(concat(f64(1.5), f64(0)))
+	// label: validity, offsets, data
+	addBuf(nil)
+	addBuf(concat(u32(0), u32(1), u32(2)))
+	addBuf([]byte("xy"))
+	// b: int32
+	addBuf(nil)
+	addBuf(concat(u32(uint32(0xFFFFFFFF)), u32(7)))
+	// c: uint8
+	addBuf(nil)
+	addBuf([]byte{200, 3})
+	// d: float
+	addBuf(nil)
+	addBuf(concat(u32(math.Float32bits(0.25)), u32(math.Float32bits(-2))))
+	writeArrowMessage(stream, 3, arrowRecordBatch(2, 5, buffers), body.Bytes())
+	stream.Write(concat(u32(0xFFFFFFFF), u32(0)))
+
+	reader, err := som.NewArrowReader(stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEq(t, len(reader.Names()), 4)
+	assertEq(t, reader.Names()[1], "b")
+
+	ds, err := som.ReadDataSet(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEq(t, ds.Len(), 2)
+	checkSlicesEqual(t, ds.Vectors[0], []float64{1.5, -1, 200, 0.25})
+	if !math.IsNaN(ds.Vectors[1][0]) {
+		t.Fatalf("Expected null to be read as NaN, got %f", ds.Vectors[1][0])
+	}
+	checkSlicesEqual(t, ds.Vectors[1][1:], []float64{7, 3, -2})
+}
+
+func TestArrowReaderRejectsCompressedBatches(t *testing.T) {
+	stream := &bytes.Buffer{}
+	writeArrowMessage(stream, 1, arrowSchema(arrowField("a", 3, fbTab{u16(2)})), nil)
+	batch := arrowRecordBatch(0, 1, []arrowBuf{{}, {}})
+	batch = append(batch, fbTab{u8(0)})
+	writeArrowMessage(stream, 3, batch, nil)
+
+	reader, err := som.NewArrowReader(stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reader.Next(); !errors.Is(err, som.ErrUnsupportedArrow) {
+		t.Fatalf("Expected ErrUnsupportedArrow, got %v", err)
+	}
+}
+
+func TestArrowReaderRejectsMalformedMetadata(t *testing.T) {
+	stream := bytes.NewBuffer(concat(u32(0xFFFFFFFF), u32(8), u32(100), u32(0)))
+	if _, err := som.NewArrowReader(stream); err == nil {
+		t.Fatal("Expected malformed metadata error")
+	}
+}
+
+func TestArrowReaderRejectsBadIntWidths(t *testing.T) {
+	for _, width := range []uint32{0, 1, 7, 128} {
+		stream := &bytes.Buffer{}
+		writeArrowMessage(stream, 1, arrowSchema(arrowField("a", 2, fbTab{u32(width), u8(1)})), nil)
+		if _, err := som.NewArrowReader(stream); !errors.Is(err, som.ErrUnsupportedArrow) {
+			t.Fatalf("Expected ErrUnsupportedArrow for %d bits, got %v", width, err)
+		}
+	}
+}
+
+func TestArrowReaderRejectsSchemaWithoutNumericColumns(t *testing.T) {
+	stream := &bytes.Buffer{}
+	writeArrowMessage(stream, 1, arrowSchema(arrowField("label", 5, fbTab{})), nil)
+	if _, err := som.NewArrowReader(stream); !errors.Is(err, som.ErrEmptyVector) {
+		t.Fatalf("Expected ErrEmptyVector, got %v", err)
+	}
+}
+
+func FuzzArrowReader(f *testing.F) {
+	stream := &bytes.Buffer{}
+	writeArrowMessage(stream, 1, arrowSchema(
+		arrowField("a", 3, fbTab{u16(2)}),
+		arrowField("b", 2, fbTab{u32(16), u8(1)}),
+		arrowField("c", 6, fbTab{}),
+	), nil)
+	body := concat([]byte{0b10}, f64(1.5), f64(2), nil, u16(3), u16(0xFFFF), nil, []byte{0b01})
+	writeArrowMessage(stream, 3, arrowRecordBatch(2, 3, []arrowBuf{
+		{0, 1}, {1, 16}, {17, 0}, {17, 4}, {21, 0}, {21, 1},
+	}), body)
+	stream.Write(concat(u32(0xFFFFFFFF), u32(0)))
+	f.Add(stream.Bytes())
+	f.Add(append([]byte("ARROW1\x00\x00"), stream.Bytes()...))
+	f.Add(concat(u32(0xFFFFFFFF), u32(8), u32(100), u32(0)))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		reader, err := som.NewArrowReader(bytes.NewReader(data))
+		if err != nil {
+			return
+		}
+		width := len(reader.Names())
+		for {
+			vector, err := reader.Next()
+			if err != nil {
+				return
+			}
+			if len(vector) != width {
+				t.Fatalf("Vector has %d values for %d columns", len(vector), width)
+			}
+		}
+	})
+}
+
+type arrowBuf struct {
+	offset, length int
+}
+
+func arrowSchema(fields ...fbTab) fbTab {
+	return fbTab{u16(0), fields}
+}
+
+func arrowField(name string, typ byte, typTable fbTab) fbTab {
+	return fbTab{name, u8(1), u8(typ), typTable}
+}
+
+func arrowRecordBatch(rows, nodes int, buffers []arrowBuf) fbTab {
+	nodesData := &bytes.Buffer{}
+	for i := 0; i < nodes; i++ {
+		nodesData.Write(concat(u64(uint64(rows)), u64(0)))
+	}
+	buffersData := &bytes.Buffer{}
+	for _, buf := range buffers {
+		buffersData.Write(concat(u64(uint64(buf.offset)), u64(uint64(buf.length))))
+	}
+	return fbTab{
+		u64(uint64(rows)),
+		fbStructs{n: nodes, data: nodesData.Bytes()},
+		fbStructs{n: len(buffers), data: buffersData.Bytes()},
+	}
+}
+
+func writeArrowMessage(w *bytes.Buffer, headerType byte, header fbTab, body []byte) {
+	meta := fbBuild(fbTab{u16(4), u8(headerType), header, u64(uint64(len(body)))})
+	w.Write(concat(u32(0xFFFFFFFF), u32(uint32(len(meta)))))
+	w.Write(meta)
+	w.Write(body)
+}
+
+// fbTab is a flatbuffer table used to build test metadata,
+// its elements are the fields: nil (absent), []byte (inline scalar),
+// string, fbTab, []fbTab (vector of tables) or fbStructs.
+type fbTab []interface{}
+
+type fbStructs struct {
+	n    int
+	data []byte
+}
+
+func fbBuild(root fbTab) []byte {
+	buf := make([]byte, 4)
+	var pos int
+	buf, pos = fbWrite(buf, root)
+	binary.LittleEndian.PutUint32(buf, uint32(pos))
+	return buf
+}
+
+// fbWrite appends the object to the buffer and returns position the references should point to.
+func fbWrite(buf []byte, obj interface{}) ([]byte, int) {
+	switch obj := obj.(type) {
+	case string:
+		pos := len(buf)
+		buf = append(buf, u32(uint32(len(obj)))...)
+		buf = append(buf, obj...)
+		return append(buf, 0), pos
+	case fbStructs:
+		pos := len(buf)
+		buf = append(buf, u32(uint32(obj.n))...)
+		return append(buf, obj.data...), pos
+	case []fbTab:
+		pos := len(buf)
+		buf = append(buf, u32(uint32(len(obj)))...)
+		refs := make([]int, len(obj))
+		for i := range obj {
+			refs[i] = len(buf)
+			buf = append(buf, 0, 0, 0, 0)
+		}
+		for i, table := range obj {
+			var tablePos int
+			buf, tablePos = fbWrite(buf, table)
+			binary.LittleEndian.PutUint32(buf[refs[i]:], uint32(tablePos-refs[i]))
+		}
+		return buf, pos
+	case fbTab:
+		// vtable, then table with inline fields, then referenced objects
+		vtablePos := len(buf)
+		buf = append(buf, make([]byte, 4+2*len(obj))...)
+		tablePos := len(buf)
+		buf = append(buf, u32(uint32(tablePos-vtablePos))...)
+		type ref struct {
+			pos   int
+			field interface{}
+		}
+		var refs []ref
+		for i, field := range obj {
+			if field == nil {
+				continue
+			}
+			binary.LittleEndian.PutUint16(buf[vtablePos+4+2*i:], uint16(len(buf)-tablePos))
+			if scalar, ok := field.([]byte); ok {
+				buf = append(buf, scalar...)
+			} else {
+				refs = append(refs, ref{pos: len(buf), field: field})
+				buf = append(buf, 0, 0, 0, 0)
+			}
+		}
+		binary.LittleEndian.PutUint16(buf[vtablePos:], uint16(4+2*len(obj)))
+		binary.LittleEndian.PutUint16(buf[vtablePos+2:], uint16(len(buf)-tablePos))
+		for _, r := range refs {
+			var childPos int
+			buf, childPos = fbWrite(buf, r.field)
+			binary.LittleEndian.PutUint32(buf[r.pos:], uint32(childPos-r.pos))
+		}
+		return buf, tablePos
+	default:
+		panic("unsupported flatbuffer object")
+	}
+}
+
+func u8(v byte) []byte { return []byte{v} }
+
+func u16(v uint16) []byte { return binary.LittleEndian.AppendUint16(nil, v) }
+
+func u32(v uint32) []byte { return binary.LittleEndian.AppendUint32(nil, v) }
+
+func u64(v uint64) []byte { return binary.LittleEndian.AppendUint64(nil, v) }
+
+func f64(v float64) []byte { return u64(math.Float64bits(v)) }
+
+func concat(parts ...[]byte) []byte {
+	return bytes.Join(parts, nil)
+}
@@ -0,0 +1,497 @@
+package som
+
+import (
+	"container/list"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/voievodin/self-organizing-map/som/vec"
+)
+
+// LearnBatch does batch learning of this SOM from the given data set.
+// Each epoch finds BMUs of all the data vectors first and then sets
+// weights of each neuron to the average of the vectors weighted by
+// the neighbourhood kernel h(bmu, neuron):
+//
+//	w(j) = Σ h(bmu(i), j) * x(i) / Σ h(bmu(i), j)
+//
+// The kernel is gaussian of the grid distance in the map topology,
+// its width is the radius the influence function reports at the epoch,
+// so Influence must implement RadiusReporter. Restraint is not used.
+// Kernels are cached by KernelCache, Monitor is notified after each epoch.
+// BMUs of the vectors are searched by BatchShards parallel workers,
+// each worker accumulates the sums of its shard of the data set,
+// and the sums of the shards are combined before the update.
+// Returns ErrInvalidConfig if the data set is empty or a component implementing
+// ParamsValidator fails validation, panics of the components
+// are reported as *TrainingError wrapping ErrTrainingPanic, like Learn does.
+func (som *SOM) LearnBatch(set *DataSet, epochs int) (err error) {
+	reporter, ok := som.Influence.(RadiusReporter)
+	if !ok {
+		return fmt.Errorf("%w: batch learning needs an influence function reporting the radius, got %T", ErrInvalidConfig, som.Influence)
+	}
+	if set.Len() == 0 {
+		return fmt.Errorf("%w: data set is empty", ErrInvalidConfig)
+	}
+	if err := som.validateComponents(); err != nil {
+		return err
+	}
+	if err := som.validateInit(set); err != nil {
+		return err
+	}
+	som.log(LogInfo, "batch learning started", "epochs", epochs, "vectors", set.Len())
+	started := time.Now()
+	cache := som.KernelCache
+	if cache == nil {
+		cache = &KernelCache{}
+	}
+
+	it := 0
+	som.Profile.start()
+	defer func() {
+		som.Profile.stop(it)
+		if r := recover(); r != nil {
+			err = som.learned(it, started, som.panicError(it, nil, r))
+		}
+	}()
+
+	som.Initializer.Init(set, som.Neurons)
+	xLen, yLen := som.Dims()
+	width := len(som.Neurons[0][0].Weights)
+	sums := make([][]float64, som.Len())
+	for i := range sums {
+		sums[i] = make([]float64, width)
+	}
+	counts := make([]float64, som.Len())
+	shards := som.batchShards(set.Len())
+	for epoch := 0; epoch < epochs; epoch++ {
+		for i := range sums {
+			counts[i] = 0
+			for k := range sums[i] {
+				sums[i][k] = 0
+			}
+		}
+		var idx int
+		var err error
+		if shards == nil {
+			idx, err = som.accumulate(set, 0, set.Len(), sums, counts, nil, nil)
+		} else {
+			// the phases of parallel workers overlap, so they are recorded as a whole
+			mark := som.Profile.enter(PhaseDistance)
+			idx, err = som.accumulateShards(set, shards, sums, counts)
+			som.Profile.leave(PhaseDistance, mark)
+		}
+		if err != nil {
+			return som.learned(it, started, &TrainingError{It: it + idx + 1, VectorIndex: idx, X: -1, Y: -1, Err: err})
+		}
+		it += set.Len()
+
+		mark := som.Profile.enter(PhaseUpdate)
+		kernel := cache.Kernel(som.Topology, reporter.EffectiveRadius(epoch, epochs), xLen, yLen)
+		som.fixBatchWeights(kernel, sums, counts)
+		som.Profile.leave(PhaseUpdate, mark)
+
+		mark = som.Profile.enter(PhaseMonitor)
+		som.Monitor.ItCompleted(epoch+1, epochs, som)
+		if som.Snapshots != nil && som.Snapshots.due(epoch+1) {
+			som.Snapshots.publish(som)
+		}
+		som.Profile.leave(PhaseMonitor, mark)
+	}
+	return som.learned(it, started, nil)
+}
+
+// BatchSums are the sums of the vectors mapped to each neuron, which is
+// all a batch update needs from the data, so the sums can be computed
+// over partitions of the data set and merged, see AccumulateBatch.
+type BatchSums struct {
+	// Sums[i] is the sum of the adapted vectors whose BMU
+	// is the neuron with the row-major index i, see SOM.Index.
+	Sums [][]float64
+
+	// Counts[i] is the number of such vectors.
+	Counts []float64
+}
+
+// Check returns ErrInvalidConfig if the sums are not of the given number
+// of neurons and ErrWidthMismatch if they are not of the given width, e.g.
+// the sums received from another process, which may be of another map.
+func (b *BatchSums) Check(neurons, width int) error {
+	if len(b.Counts) != neurons || len(b.Sums) != neurons {
+		return fmt.Errorf("%w: %d sums and %d counts for the map of %d neurons", ErrInvalidConfig, len(b.Sums), len(b.Counts), neurons)
+	}
+	for _, sum := range b.Sums {
+		if len(sum) != width {
+			return fmt.Errorf("%w: sums length is %d, weights length is %d", ErrWidthMismatch, len(sum), width)
+		}
+	}
+	return nil
+}
+
+// Add adds the other sums of the same map to these ones,
+// see Check for the errors of the sums of another map.
+func (b *BatchSums) Add(other *BatchSums) error {
+	if len(b.Sums) == 0 {
+		return fmt.Errorf("%w: no sums to add to", ErrInvalidConfig)
+	}
+	if err := other.Check(len(b.Sums), len(b.Sums[0])); err != nil {
+		return err
+	}
+	for cell, count := range other.Counts {
+		if count == 0 {
+			continue
+		}
+		b.Counts[cell] += count
+		vec.Add(b.Sums[cell], other.Sums[cell])
+	}
+	return nil
+}
+
+// AccumulateBatch maps the vectors of the set to their BMUs and returns
+// their sums, which is the first half of a LearnBatch epoch, the vectors
+// are mapped by BatchShards workers. Returns *TrainingError wrapping
+// ErrWidthMismatch if a vector doesn't fit the weights.
+func (som *SOM) AccumulateBatch(set *DataSet) (*BatchSums, error) {
+	if err := som.checkTrained(); err != nil {
+		return nil, err
+	}
+	width := len(som.Neurons[0][0].Weights)
+	sums := &BatchSums{Sums: make([][]float64, som.Len()), Counts: make([]float64, som.Len())}
+	for i := range sums.Sums {
+		sums.Sums[i] = make([]float64, width)
+	}
+	var idx int
+	var err error
+	if shards := som.batchShards(set.Len()); shards == nil {
+		idx, err = som.accumulate(set, 0, set.Len(), sums.Sums, sums.Counts, nil, nil)
+	} else {
+		idx, err = som.accumulateShards(set, shards, sums.Sums, sums.Counts)
+	}
+	if err != nil {
+		return nil, &TrainingError{It: idx + 1, VectorIndex: idx, X: -1, Y: -1, Err: err}
+	}
+	return sums, nil
+}
+
+// UpdateBatch sets neurons weights to the kernel weighted averages of
+// the summed vectors, which is the second half of the given LearnBatch epoch
+// within [0, epochs). The kernel is computed like by LearnBatch.
+func (som *SOM) UpdateBatch(sums *BatchSums, epoch, epochs int) error {
+	reporter, ok := som.Influence.(RadiusReporter)
+	if !ok {
+		return fmt.Errorf("%w: batch learning needs an influence function reporting the radius, got %T", ErrInvalidConfig, som.Influence)
+	}
+	if err := sums.Check(som.Len(), len(som.Neurons[0][0].Weights)); err != nil {
+		return err
+	}
+	cache := som.KernelCache
+	if cache == nil {
+		cache = &KernelCache{}
+	}
+	xLen, yLen := som.Dims()
+	som.fixBatchWeights(cache.Kernel(som.Topology, reporter.EffectiveRadius(epoch, epochs), xLen, yLen), sums.Sums, sums.Counts)
+	return nil
+}
+
+// accumulate maps the vectors [from, to) of the set to their BMUs and adds
+// them to the sums and the counts of the BMUs. The vectors are adapted by
+// InDataAdapter, unless adapted is not nil, then adapted[idx] is the adapted
+// vector idx of the set. The mutex, if not nil, guards TieBreaker, which is
+// shared by concurrent shards. Returns the index of the vector which doesn't
+// fit the weights along with the error.
+func (som *SOM) accumulate(set *DataSet, from, to int, sums [][]float64, counts []float64, adapted []DataVector, mu *sync.Mutex) (int, error) {
+	width := len(som.Neurons[0][0].Weights)
+	var field DistanceField
+	var buf DataVector
+	for idx := from; idx < to; idx++ {
+		var vector DataVector
+		if adapted != nil {
+			vector = adapted[idx]
+		} else {
+			// adapt a copy, so the data set is not modified when it is passed many times
+			buf = append(buf[:0], set.At(idx)...)
+			vector = som.InDataAdapter.Adapt(buf)
+		}
+		if len(vector) != width {
+			return idx, fmt.Errorf("%w: vector length is %d, weights length is %d", ErrWidthMismatch, len(vector), width)
+		}
+		var bmu *Neuron
+		if mu == nil {
+			mark := som.Profile.enter(PhaseDistance)
+			som.distances = som.computeDistances(vector, som.distances)
+			som.Profile.leave(PhaseDistance, mark)
+
+			mark = som.Profile.enter(PhaseBMU)
+			bmu = som.bmu(som.distances)
+			som.Profile.leave(PhaseBMU, mark)
+		} else {
+			field = som.computeDistances(vector, field)
+			if _, x, y, count := field.minimum(); count <= 1 {
+				bmu = som.Neurons[x][y]
+			} else {
+				mu.Lock()
+				bmu = som.bmu(field)
+				mu.Unlock()
+			}
+		}
+		cell := som.Index(bmu.X, bmu.Y)
+		counts[cell]++
+		vec.Add(sums[cell], vector)
+	}
+	return -1, nil
+}
+
+// batchShards returns the bounds of the shards of the data set
+// of the given length, nil if the data set is not sharded.
+func (som *SOM) batchShards(n int) [][2]int {
+	shards := som.BatchShards
+	if shards > n {
+		shards = n
+	}
+	if shards <= 1 {
+		return nil
+	}
+	bounds := make([][2]int, shards)
+	for i := range bounds {
+		bounds[i] = [2]int{i * n / shards, (i + 1) * n / shards}
+	}
+	return bounds
+}
+
+// accumulateShards accumulates the shards of the data set in parallel,
+// each shard into its own sums and counts, which are then added
+// to the given ones. Returns the first error like accumulate does.
+// The vectors are adapted by InDataAdapter before the shards start, one
+// by one in the order of the set like accumulate does, since the adapter
+// may be stateful, e.g. RunningScalingDataAdapter, so it's neither called
+// concurrently nor sees the vectors in an order depending on the shards.
+func (som *SOM) accumulateShards(set *DataSet, shards [][2]int, sums [][]float64, counts []float64) (int, error) {
+	type partial struct {
+		sums   [][]float64
+		counts []float64
+		idx    int
+		err    error
+	}
+	adapted := make([]DataVector, set.Len())
+	for idx := range adapted {
+		// adapt a copy, so the data set is not modified when it is passed many times
+		adapted[idx] = som.InDataAdapter.Adapt(append(DataVector(nil), set.At(idx)...))
+	}
+	partials := make([]partial, len(shards))
+	mu := &sync.Mutex{}
+	wg := sync.WaitGroup{}
+	for i, shard := range shards {
+		p := &partials[i]
+		p.sums = make([][]float64, len(sums))
+		for cell := range p.sums {
+			p.sums[cell] = make([]float64, len(sums[cell]))
+		}
+		p.counts = make([]float64, len(counts))
+		wg.Add(1)
+		go func(from, to int) {
+			defer wg.Done()
+			p.idx, p.err = som.accumulate(set, from, to, p.sums, p.counts, adapted, mu)
+		}(shard[0], shard[1])
+	}
+	wg.Wait()
+
+	for _, p := range partials {
+		if p.err != nil {
+			return p.idx, p.err
+		}
+		for cell, count := range p.counts {
+			if count == 0 {
+				continue
+			}
+			counts[cell] += count
+			vec.Add(sums[cell], p.sums[cell])
+		}
+	}
+	return -1, nil
+}
+
+// fixBatchWeights sets neurons weights to the kernel weighted
+// averages of the vectors, given their sums and counts per BMU.
+func (som *SOM) fixBatchWeights(kernel *Kernel, sums [][]float64, counts []float64) {
+	xLen, yLen := som.Dims()
+	numerator := make([]float64, len(sums[0]))
+	for i := 0; i < xLen; i++ {
+		for j := 0; j < yLen; j++ {
+			neuron := som.Neurons[i][j]
+			if neuron.Frozen || som.IsMasked(i, j) {
+				continue
+			}
+			vec.Scale(numerator, 0)
+			denominator := 0.0
+			for cell, count := range counts {
+				if count == 0 {
+					continue
+				}
+				bmuX, bmuY := som.Position(cell)
+				h := kernel.At(bmuX, bmuY, i, j)
+				if h == 0 {
+					continue
+				}
+				denominator += h * count
+				vec.AddScaled(numerator, h, sums[cell])
+			}
+			if denominator == 0 {
+				continue
+			}
+			for k := range neuron.Weights {
+				neuron.Weights[k] = numerator[k] / denominator
+			}
+			if som.Renormalize {
+				normalize(neuron.Weights)
+			}
+		}
+	}
+}
+
+// Kernel is a neighbourhood kernel of a map: gaussian of the grid distance
+// between neurons with the fixed width. Values are precomputed for each offset
+// between the neurons, which takes (2*xLen-1)*(2*yLen-1) values. The values of
+// MetricTopology depend on the neurons rather than on the offset, so they are
+// computed from the distances of the topology by each At call instead.
+type Kernel struct {
+	Topology   Topology
+	Radius     float64
+	xLen, yLen int
+	values     []float64
+
+	// metric is the topology if it's MetricTopology
+	metric MetricTopology
+}
+
+// NewKernel computes the kernel of the given radius for the map of xLen*yLen size.
+func NewKernel(topology Topology, radius float64, xLen, yLen int) *Kernel {
+	kernel := &Kernel{Topology: topology, Radius: radius, xLen: xLen, yLen: yLen}
+	if metric, ok := topology.(MetricTopology); ok {
+		kernel.metric = metric
+		return kernel
+	}
+	kernel.values = make([]float64, (2*xLen-1)*(2*yLen-1))
+	for dx := -(xLen - 1); dx < xLen; dx++ {
+		for dy := -(yLen - 1); dy < yLen; dy++ {
+			d := math.Sqrt(float64(dx*dx + dy*dy))
+			kernel.values[kernel.offset(dx, dy)] = gaussian(d, radius)
+		}
+	}
+	return kernel
+}
+
+// At returns the kernel value between the neurons (x1, y1) and (x2, y2).
+func (kernel *Kernel) At(x1, y1, x2, y2 int) float64 {
+	if kernel.metric != nil {
+		return gaussian(kernel.metric.Distance(x1, y1, x2, y2, kernel.xLen, kernel.yLen), kernel.Radius)
+	}
+	x1, y1 = kernel.Topology.Closest(x1, y1, x2, y2, kernel.xLen, kernel.yLen)
+	return kernel.values[kernel.offset(x1-x2, y1-y2)]
+}
+
+func (kernel *Kernel) offset(dx, dy int) int {
+	return (dx+kernel.xLen-1)*(2*kernel.yLen-1) + dy + kernel.yLen - 1
+}
+
+// KernelCache is an LRU cache of kernels keyed by the topology, the map size
+// and the radius discretized with Resolution step. The topologies are told apart
+// by their descriptions if they implement Describer, otherwise by their values,
+// and the kernels of the topologies which are neither are not cached, since
+// their values can't be compared. So the kernels computed
+// for the radius of one epoch are reused by the following epochs having
+// about the same radius (e.g. once the radius reaches its floor), as well as
+// by the following LearnBatch calls if the cache is set to SOM.KernelCache.
+// KernelCache is not safe for concurrent use.
+type KernelCache struct {
+	// Capacity is the max number of cached kernels, <= 0 means 16.
+	Capacity int
+
+	// Resolution is the step radii are discretized with, <= 0 means 0.01.
+	Resolution float64
+
+	// Hits and Misses count kernel lookups.
+	Hits, Misses int
+
+	entries map[kernelKey]*list.Element
+	order   *list.List
+}
+
+type kernelEntry struct {
+	key    kernelKey
+	kernel *Kernel
+}
+
+type kernelKey struct {
+	// topology is the description of the topology or the topology itself
+	topology   interface{}
+	radius     int64
+	xLen, yLen int
+}
+
+// topologyKey returns the key of the topology in KernelCache: its description
+// if it's a Describer describing it with the type, the topology itself if it's
+// comparable, false otherwise.
+func topologyKey(topology Topology) (interface{}, bool) {
+	if describer, ok := topology.(Describer); ok {
+		if spec := describer.Describe(); spec.Type != "" {
+			if description, err := json.Marshal(spec); err == nil {
+				return string(description), true
+			}
+		}
+	}
+	if t := reflect.TypeOf(topology); t != nil && t.Comparable() {
+		return topology, true
+	}
+	return nil, false
+}
+
+// Kernel returns the cached kernel for the radius rounded to Resolution,
+// computing it if it's not cached.
+func (cache *KernelCache) Kernel(topology Topology, radius float64, xLen, yLen int) *Kernel {
+	resolution := cache.Resolution
+	if resolution <= 0 {
+		resolution = 0.01
+	}
+	capacity := cache.Capacity
+	if capacity <= 0 {
+		capacity = 16
+	}
+	if cache.entries == nil {
+		cache.entries = make(map[kernelKey]*list.Element)
+		cache.order = list.New()
+	}
+
+	steps := math.Round(radius / resolution)
+	topologyKey, ok := topologyKey(topology)
+	if !ok {
+		cache.Misses++
+		return NewKernel(topology, steps*resolution, xLen, yLen)
+	}
+	key := kernelKey{topology: topologyKey, radius: int64(steps), xLen: xLen, yLen: yLen}
+	if elem, ok := cache.entries[key]; ok {
+		cache.Hits++
+		cache.order.MoveToFront(elem)
+		return elem.Value.(*kernelEntry).kernel
+	}
+
+	cache.Misses++
+	kernel := NewKernel(topology, steps*resolution, xLen, yLen)
+	cache.entries[key] = cache.order.PushFront(&kernelEntry{key: key, kernel: kernel})
+	for cache.order.Len() > capacity {
+		oldest := cache.order.Remove(cache.order.Back()).(*kernelEntry)
+		delete(cache.entries, oldest.key)
+	}
+	return kernel
+}
+
+// Len returns the number of cached kernels.
+func (cache *KernelCache) Len() int {
+	if cache.order == nil {
+		return 0
+	}
+	return cache.order.Len()
+}
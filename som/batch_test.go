@@ -0,0 +1,273 @@
+package som_test
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+	"testing/quick"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+func TestLearnBatchFindsClusterCentres(t *testing.T) {
+	sm := som.New(1, 2)
+	sm.Initializer = &som.ProvidedWeightsInitializer{Weights: [][][]float64{{{0, 0}, {1, 1}}}}
+	sm.Influence = &som.BMUOnlyInfluencedFunc{}
+
+	ds := &som.DataSet{Vectors: []som.DataVector{{0, 0.1}, {0.2, 0.1}, {0.9, 1}, {1, 0.9}, {1.1, 1.1}}}
+	if err := sm.LearnBatch(ds, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	checkSlicesEqual(t, sm.Neurons[0][0].Weights, []float64{0.1, 0.1})
+	checkSlicesEqual(t, sm.Neurons[0][1].Weights, []float64{1, 1})
+	assertEq(t, sm.TrainingState().Iterations, 15)
+}
+
+func TestLearnBatchReusesKernels(t *testing.T) {
+	sm := som.New(5, 5)
+	sm.Initializer = &som.RandWeightsInitializer{}
+	// the width is floored from the very first epoch, so it never changes
+	sm.Influence = &som.GaussianExpDecayInfluenceFunc{InitialWidth: 0.1, MinWidth: 1}
+	sm.KernelCache = &som.KernelCache{}
+
+	ds := &som.DataSet{Vectors: []som.DataVector{{0}, {0.5}, {1}}}
+	if err := sm.LearnBatch(ds, 10); err != nil {
+		t.Fatal(err)
+	}
+	assertEq(t, sm.KernelCache.Misses, 1)
+	assertEq(t, sm.KernelCache.Hits, 9)
+}
+
+func TestKernelCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := &som.KernelCache{Capacity: 2, Resolution: 0.5}
+	topology := &som.PlanarTopology{}
+
+	first := cache.Kernel(topology, 1, 3, 3)
+	cache.Kernel(topology, 2, 3, 3)
+	if cache.Kernel(topology, 1.1, 3, 3) != first {
+		t.Fatal("Expected radius 1.1 to be discretized to 1")
+	}
+	cache.Kernel(topology, 3, 3, 3)
+
+	assertEq(t, cache.Len(), 2)
+	cache.Kernel(topology, 2, 3, 3)
+	assertEq(t, cache.Misses, 4)
+	assertEq(t, cache.Hits, 1)
+}
+
+// slicedTopology is a topology which isn't comparable.
+type slicedTopology struct {
+	planar []som.PlanarTopology
+}
+
+func (t slicedTopology) Closest(x, y, toX, toY, xLen, yLen int) (int, int) { return x, y }
+
+func TestKernelCacheKeysTopologies(t *testing.T) {
+	cache := &som.KernelCache{}
+
+	// equally described topologies share the kernels
+	first := cache.Kernel(&som.CylinderTopology{Wrapped: som.AxisY}, 1, 3, 3)
+	if cache.Kernel(&som.CylinderTopology{Wrapped: som.AxisY}, 1, 3, 3) != first {
+		t.Fatal("Expected the kernel of the equal topology to be reused")
+	}
+	if cache.Kernel(&som.CylinderTopology{Wrapped: som.AxisX}, 1, 3, 3) == first {
+		t.Fatal("Expected the kernel of the other topology to be computed")
+	}
+
+	// the kernels of the topologies which can't be compared are not cached
+	sliced := slicedTopology{}
+	cache.Kernel(sliced, 1, 3, 3)
+	kernel := cache.Kernel(sliced, 1, 3, 3)
+	assertEq(t, kernel.At(0, 0, 1, 0), math.Exp(-0.5))
+	assertEq(t, cache.Len(), 2)
+	assertEq(t, cache.Hits, 1)
+	assertEq(t, cache.Misses, 4)
+}
+
+func TestKernelFollowsTopology(t *testing.T) {
+	kernel := som.NewKernel(&som.TorusTopology{}, 2, 10, 10)
+
+	expected := math.Exp(-2.0 / 8)
+	if math.Abs(kernel.At(0, 0, 9, 9)-expected) > 1e-12 {
+		t.Fatalf("Expected %f got %f", expected, kernel.At(0, 0, 9, 9))
+	}
+	assertEq(t, kernel.At(4, 4, 4, 4), 1.0)
+}
+
+type constantInfluenceFunc struct{}
+
+func (f *constantInfluenceFunc) Apply(bmu *som.Neuron, currentIt, iterationsNumber, x, y int) float64 {
+	return 1
+}
+
+func TestLearnBatchNeedsRadius(t *testing.T) {
+	sm := som.New(2, 2)
+	sm.Influence = &constantInfluenceFunc{}
+	err := sm.LearnBatch(&som.DataSet{Vectors: []som.DataVector{{1}}}, 1)
+	if !errors.Is(err, som.ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig, got %v", err)
+	}
+}
+
+func TestLearnBatchRejectsEmptySet(t *testing.T) {
+	sm := som.New(2, 2)
+	sm.Initializer = &som.RandDataSetVectorsWeightsInitializer{}
+	sm.Influence = &som.GaussianExpDecayInfluenceFunc{InitialWidth: 1}
+	if err := sm.LearnBatch(&som.DataSet{}, 2); !errors.Is(err, som.ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig, got %v", err)
+	}
+}
+
+func TestLearnBatchRecoversPanics(t *testing.T) {
+	sm := som.New(2, 2)
+	sm.Initializer = &brokenInitializer{}
+	sm.Influence = &som.GaussianExpDecayInfluenceFunc{InitialWidth: 1}
+
+	err := sm.LearnBatch(&som.DataSet{Vectors: []som.DataVector{{1, 1}}}, 1)
+	var trainingErr *som.TrainingError
+	if !errors.As(err, &trainingErr) || !errors.Is(err, som.ErrTrainingPanic) {
+		t.Fatalf("Expected TrainingError wrapping ErrTrainingPanic, got %v", err)
+	}
+}
+
+func TestLearnBatchDoesNotModifyDataSet(t *testing.T) {
+	ds := &som.DataSet{Vectors: []som.DataVector{{0}, {5}, {10}}}
+	sm := som.New(2, 1)
+	sm.Influence = &som.GaussianExpDecayInfluenceFunc{InitialWidth: 1}
+	sm.InDataAdapter = som.NewScalingDataAdapter([]float64{0}, []float64{10})
+
+	if err := sm.LearnBatch(ds, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, expected := range []float64{0, 5, 10} {
+		assertEq(t, ds.At(i)[0], expected)
+	}
+}
+
+func TestShardedLearnBatchMatchesSequential(t *testing.T) {
+	ds := &som.DataSet{}
+	for i := 0; i < 500; i++ {
+		ds.AddRaw(float64(i%17)/16, float64(i%13)/12)
+	}
+	testShardedLearnBatchMatchesSequential(t, ds, nil)
+}
+
+func TestShardedLearnBatchMatchesSequentialWithAdaptedDataSet(t *testing.T) {
+	ds := &som.DataSet{}
+	for i := 0; i < 500; i++ {
+		ds.AddRaw(float64(i%17), float64(i%13))
+	}
+	ds.SetAdapter(som.NewScalingDataAdapter([]float64{0, 0}, []float64{16, 12}))
+	testShardedLearnBatchMatchesSequential(t, ds, nil)
+}
+
+// countingAdapter is a stateful adapter which isn't safe for concurrent use,
+// it shifts each vector by the number of the vectors adapted before.
+type countingAdapter struct {
+	n int
+}
+
+func (a *countingAdapter) Adapt(vector []float64) []float64 {
+	for k := range vector {
+		vector[k] += float64(a.n) * 1e-4
+	}
+	a.n++
+	return vector
+}
+
+func TestShardedLearnBatchMatchesSequentialWithStatefulAdapters(t *testing.T) {
+	ds := &som.DataSet{}
+	for i := 0; i < 500; i++ {
+		ds.AddRaw(float64(i%17), float64(i%13))
+	}
+	testShardedLearnBatchMatchesSequential(t, ds, func() som.DataAdapter { return &som.RunningScalingDataAdapter{} })
+	testShardedLearnBatchMatchesSequential(t, ds, func() som.DataAdapter { return &countingAdapter{} })
+}
+
+// testShardedLearnBatchMatchesSequential learns the data set sequentially
+// and by shards, which run concurrently, so run it with the race detector.
+// The adapter, if not nil, creates the input adapter of each map.
+func testShardedLearnBatchMatchesSequential(t *testing.T, ds *som.DataSet, adapter func() som.DataAdapter) {
+	learn := func(shards int) *som.SOM {
+		sm := som.New(4, 4)
+		sm.Initializer = &som.ProvidedWeightsInitializer{Weights: gridWeights(4, 4)}
+		sm.Influence = &som.GaussianExpDecayInfluenceFunc{InitialWidth: 2, MinWidth: 0.5}
+		sm.TieBreaker = &som.LowestIndexTieBreaker{}
+		sm.BatchShards = shards
+		if adapter != nil {
+			sm.InDataAdapter = adapter()
+		}
+		if err := sm.LearnBatch(ds, 5); err != nil {
+			t.Fatal(err)
+		}
+		return sm
+	}
+	// sharded first, so the shards fill the adapted vectors cache
+	sharded, sequential := learn(4), learn(0)
+	for x := range sequential.Neurons {
+		for y := range sequential.Neurons[x] {
+			for k, w := range sequential.Neurons[x][y].Weights {
+				if math.Abs(w-sharded.Neurons[x][y].Weights[k]) > 1e-9 {
+					t.Fatalf("Weights of (%d, %d) differ: %v != %v", x, y, sequential.Neurons[x][y].Weights, sharded.Neurons[x][y].Weights)
+				}
+			}
+		}
+	}
+	assertEq(t, sharded.TrainingState().Iterations, 2500)
+}
+
+func TestShardedLearnBatchReportsMismatchedVector(t *testing.T) {
+	sm := som.New(2, 2)
+	sm.Initializer = &som.ProvidedWeightsInitializer{Weights: gridWeights(2, 2)}
+	sm.Influence = &som.BMUOnlyInfluencedFunc{}
+	sm.BatchShards = 3
+	ds := &som.DataSet{Vectors: []som.DataVector{{0, 0}, {1, 1}, {0, 1}, {1}, {1, 0}}}
+
+	err := sm.LearnBatch(ds, 2)
+	var trainingErr *som.TrainingError
+	if !errors.As(err, &trainingErr) || !errors.Is(err, som.ErrWidthMismatch) {
+		t.Fatalf("Expected TrainingError wrapping ErrWidthMismatch, got %v", err)
+	}
+	assertEq(t, trainingErr.VectorIndex, 3)
+	assertEq(t, trainingErr.It, 4)
+}
+
+// squaredEuclidean makes QuantizationError the mean squared error,
+// which batch learning with BMU only kernel (k-means) never increases.
+type squaredEuclidean struct{}
+
+func (squaredEuclidean) Apply(x, y []float64) float64 {
+	d := 0.0
+	for k := range x {
+		d += (x[k] - y[k]) * (x[k] - y[k])
+	}
+	return d
+}
+
+func TestLearnBatchNeverIncreasesQuantizationError(t *testing.T) {
+	property := func(seed int64) bool {
+		rng := rand.New(rand.NewSource(seed))
+		ds := &som.DataSet{}
+		for i := 0; i < 40; i++ {
+			ds.Vectors = append(ds.Vectors, som.DataVector{rng.NormFloat64(), rng.NormFloat64(), rng.Float64()})
+		}
+		sm := som.New(3, 3)
+		sm.Initializer = &som.RandDataSetVectorsWeightsInitializer{Rand: rng}
+		sm.TieBreaker = &som.LowestIndexTieBreaker{}
+		sm.Influence = &som.BMUOnlyInfluencedFunc{}
+		sm.Distance = squaredEuclidean{}
+		ok, prev := true, math.Inf(1)
+		sm.Monitor = progressMonitorFunc(func(it, itNum int) {
+			qe := sm.QuantizationError(ds)
+			ok = ok && qe <= prev*(1+1e-12)
+			prev = qe
+		})
+		return sm.LearnBatch(ds, 10) == nil && ok
+	}
+	if err := quick.Check(property, nil); err != nil {
+		t.Fatal(err)
+	}
+}
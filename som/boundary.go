@@ -0,0 +1,147 @@
+package som
+
+import "sort"
+
+// GridPoint is a point in cell coordinates,
+// the cell (x, y) spans from the point (x, y) to the point (x+1, y+1).
+type GridPoint struct {
+	X, Y int
+}
+
+// Boundary is a polyline separating two clusters.
+type Boundary struct {
+	// Clusters are the labels of the clusters on both
+	// sides of the boundary, Clusters[0] < Clusters[1].
+	Clusters [2]int
+
+	// Points of the polyline, closed polylines end with their first point.
+	Points []GridPoint
+}
+
+type boundarySegment struct {
+	from, to GridPoint
+}
+
+// ClusterBoundaries computes boundaries between clusters of neurons,
+// where clusters[x][y] is the label of the cluster the neuron at (x, y) belongs to.
+// The result contains polylines going along cell edges which separate
+// neighbouring cells of different clusters, so they can be drawn over
+// the map images, e.g. scaled by the cell size. The boundaries of the map
+// itself are not included. Collinear points are merged, so straight
+// boundaries consist of two points.
+func ClusterBoundaries(clusters [][]int) []Boundary {
+	segments := make(map[[2]int][]boundarySegment)
+	var keys [][2]int
+	addSegment := func(a, b int, segment boundarySegment) {
+		if a > b {
+			a, b = b, a
+		}
+		key := [2]int{a, b}
+		if _, ok := segments[key]; !ok {
+			keys = append(keys, key)
+		}
+		segments[key] = append(segments[key], segment)
+	}
+
+	for x := 0; x < len(clusters); x++ {
+		for y := 0; y < len(clusters[x]); y++ {
+			label := clusters[x][y]
+			if x+1 < len(clusters) && y < len(clusters[x+1]) && clusters[x+1][y] != label {
+				addSegment(label, clusters[x+1][y], boundarySegment{GridPoint{x + 1, y}, GridPoint{x + 1, y + 1}})
+			}
+			if y+1 < len(clusters[x]) && clusters[x][y+1] != label {
+				addSegment(label, clusters[x][y+1], boundarySegment{GridPoint{x, y + 1}, GridPoint{x + 1, y + 1}})
+			}
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i][0] != keys[j][0] {
+			return keys[i][0] < keys[j][0]
+		}
+		return keys[i][1] < keys[j][1]
+	})
+
+	var boundaries []Boundary
+	for _, key := range keys {
+		for _, polyline := range chainSegments(segments[key]) {
+			boundaries = append(boundaries, Boundary{Clusters: key, Points: simplifyPolyline(polyline)})
+		}
+	}
+	return boundaries
+}
+
+// chainSegments joins segments sharing end points into polylines,
+// open polylines are started from their ends, then closed ones are collected.
+func chainSegments(segments []boundarySegment) [][]GridPoint {
+	adjacent := make(map[GridPoint][]int)
+	for i, s := range segments {
+		adjacent[s.from] = append(adjacent[s.from], i)
+		adjacent[s.to] = append(adjacent[s.to], i)
+	}
+
+	used := make([]bool, len(segments))
+	walk := func(start GridPoint) []GridPoint {
+		polyline := []GridPoint{start}
+		current := start
+		for {
+			next := -1
+			for _, i := range adjacent[current] {
+				if !used[i] {
+					next = i
+					break
+				}
+			}
+			if next == -1 {
+				return polyline
+			}
+			used[next] = true
+			if segments[next].from == current {
+				current = segments[next].to
+			} else {
+				current = segments[next].from
+			}
+			polyline = append(polyline, current)
+		}
+	}
+
+	var polylines [][]GridPoint
+	// odd degree points are the ends of open polylines
+	for _, s := range segments {
+		for _, p := range []GridPoint{s.from, s.to} {
+			if len(adjacent[p])%2 == 1 && hasUnused(adjacent[p], used) {
+				polylines = append(polylines, walk(p))
+			}
+		}
+	}
+	for i, s := range segments {
+		if !used[i] {
+			polylines = append(polylines, walk(s.from))
+		}
+	}
+	return polylines
+}
+
+func hasUnused(indices []int, used []bool) bool {
+	for _, i := range indices {
+		if !used[i] {
+			return true
+		}
+	}
+	return false
+}
+
+// simplifyPolyline removes points lying on a straight line between their neighbours.
+func simplifyPolyline(points []GridPoint) []GridPoint {
+	if len(points) < 3 {
+		return points
+	}
+	result := []GridPoint{points[0]}
+	for i := 1; i < len(points)-1; i++ {
+		prev, p, next := result[len(result)-1], points[i], points[i+1]
+		if (p.X-prev.X)*(next.Y-p.Y) != (p.Y-prev.Y)*(next.X-p.X) {
+			result = append(result, p)
+		}
+	}
+	return append(result, points[len(points)-1])
+}
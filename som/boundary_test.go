@@ -0,0 +1,47 @@
+package som_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+func TestClusterBoundariesOfTwoHalves(t *testing.T) {
+	// x is the first index, so clusters split the map by x = 2 line
+	clusters := [][]int{
+		{0, 0, 0},
+		{0, 0, 0},
+		{1, 1, 1},
+	}
+
+	boundaries := som.ClusterBoundaries(clusters)
+
+	expected := []som.Boundary{
+		{Clusters: [2]int{0, 1}, Points: []som.GridPoint{{2, 0}, {2, 3}}},
+	}
+	if !reflect.DeepEqual(boundaries, expected) {
+		t.Fatalf("Expected %v, got %v", expected, boundaries)
+	}
+}
+
+func TestClusterBoundariesOfEnclosedCluster(t *testing.T) {
+	clusters := [][]int{
+		{0, 0, 0},
+		{0, 2, 0},
+		{0, 0, 0},
+	}
+
+	boundaries := som.ClusterBoundaries(clusters)
+
+	assertEq(t, len(boundaries), 1)
+	assertEq(t, boundaries[0].Clusters, [2]int{0, 2})
+	points := boundaries[0].Points
+	assertEq(t, len(points), 5)
+	assertEq(t, points[0], points[len(points)-1])
+	for _, p := range points {
+		if (p.X != 1 && p.X != 2) || (p.Y != 1 && p.Y != 2) {
+			t.Fatalf("Unexpected boundary point %v", p)
+		}
+	}
+}
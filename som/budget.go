@@ -0,0 +1,61 @@
+package som
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// budgetCheckEvery is the number of iterations between re-estimations of LearnFor.
+const budgetCheckEvery = 64
+
+// LearnFor does learning of this SOM from the given data set until the time
+// budget is exhausted, which is convenient for CI jobs and interactive sessions.
+// The total number of iterations, which the learning schedules (Restraint and
+// Influence) depend on, is unknown upfront, so it's estimated on the fly from
+// the measured speed of learning, and the schedules are adapted accordingly:
+// the first iterations run with the initial rate and radius, and by the end
+// of the budget the schedules reach their final values. The selector
+// is initialized before each pass over the data set, like by LearnEpochs,
+// so the data set is cycled, and the vectors are adapted by copies. Returns
+// the number of completed iterations, errors are reported like by Learn,
+// and ErrInvalidConfig if the data set is empty.
+func (som *SOM) LearnFor(set *DataSet, duration time.Duration) (int, error) {
+	if set.Len() == 0 {
+		return 0, fmt.Errorf("%w: data set is empty", ErrInvalidConfig)
+	}
+	som.log(LogInfo, "learning started", "duration", duration, "vectors", set.Len())
+	started := time.Now()
+	budget := &timeBudget{started: started, duration: duration}
+	it, err := som.learn(set, math.MaxInt32, set.Len(), nil, budget)
+	return it, som.learned(it, started, err)
+}
+
+// timeBudget estimates the number of iterations which fit the duration.
+type timeBudget struct {
+	started  time.Time
+	duration time.Duration
+}
+
+// estimate returns the number of iterations estimated after it iterations,
+// the current estimate is itNum. The estimate equals it once the time is up.
+func (b *timeBudget) estimate(it, itNum int) int {
+	if it%budgetCheckEvery != 0 {
+		return itNum
+	}
+	elapsed := time.Since(b.started)
+	if elapsed >= b.duration {
+		return it
+	}
+	if it == 0 || elapsed <= 0 {
+		return itNum
+	}
+	estimate := float64(it) * float64(b.duration) / float64(elapsed)
+	if estimate > math.MaxInt32 {
+		return math.MaxInt32
+	}
+	if int(estimate) <= it {
+		return it + 1
+	}
+	return int(estimate)
+}
@@ -0,0 +1,64 @@
+package som_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+func TestLearnForStopsWhenBudgetIsExhausted(t *testing.T) {
+	sm := som.New(4, 4)
+	sm.Initializer = &som.RandWeightsInitializer{}
+	sm.Restraint = &som.ExpRestraintFunc{InitialRate: 1}
+	sm.Influence = &som.GaussianExpDecayInfluenceFunc{InitialWidth: 2}
+
+	var rates []float64
+	sm.Events = som.EventListenerFunc(func(event som.Event) {
+		if e, ok := event.(*som.IterationEvent); ok {
+			rates = append(rates, e.LearningRate)
+		}
+	})
+	ds := &som.DataSet{Vectors: []som.DataVector{{0, 0}, {1, 1}, {0, 1}}}
+
+	budget := 50 * time.Millisecond
+	started := time.Now()
+	it, err := sm.LearnFor(ds, budget)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(started); elapsed < budget*9/10 || elapsed > budget+time.Second {
+		t.Fatalf("Expected learning to take about %v, took %v", budget, elapsed)
+	}
+	if it <= ds.Len() || it != len(rates) {
+		t.Fatalf("Expected the data set to be cycled, %d iterations, %d events", it, len(rates))
+	}
+	assertEq(t, sm.TrainingState().Iterations, it)
+
+	// the schedule spans the budget, so the rate decays by the end
+	if last := rates[len(rates)-1]; last >= rates[0]/2 {
+		t.Fatalf("Expected the rate to decay from %f, got %f", rates[0], last)
+	}
+}
+
+func TestLearnForDoesNotModifyDataSet(t *testing.T) {
+	sm := som.New(2, 2)
+	sm.InDataAdapter = som.NewScalingDataAdapter([]float64{0, 0}, []float64{4, 4})
+	ds := &som.DataSet{Vectors: []som.DataVector{{0, 2}, {4, 1}}}
+
+	if _, err := sm.LearnFor(ds, 10*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+
+	assertEq(t, ds.At(0)[1], 2.0)
+	assertEq(t, ds.At(1)[0], 4.0)
+}
+
+func TestLearnForRejectsEmptyDataSet(t *testing.T) {
+	sm := som.New(2, 2)
+
+	if _, err := sm.LearnFor(&som.DataSet{}, time.Millisecond); !errors.Is(err, som.ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig, got %v", err)
+	}
+}
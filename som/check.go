@@ -0,0 +1,79 @@
+package som
+
+import (
+	"fmt"
+	"math"
+)
+
+// Warning codes, see Warning.
+const (
+	// WarnRadiusExceedsGrid: the initial neighbourhood radius is larger
+	// than the grid, so the whole map moves together for a long time.
+	WarnRadiusExceedsGrid = "radius-exceeds-grid"
+
+	// WarnRateVanishes: the learning rate is close to zero halfway
+	// through learning, so the second half does almost nothing.
+	WarnRateVanishes = "rate-vanishes"
+
+	// WarnMapTooSmall and WarnMapTooLarge: the number of neurons is
+	// far from the usual heuristic of 5*sqrt(N) for N vectors.
+	WarnMapTooSmall = "map-too-small"
+	WarnMapTooLarge = "map-too-large"
+)
+
+// Warning describes a suspicious, though not invalid, configuration or
+// learning state, e.g. found by CheckConfig.
+type Warning struct {
+	// Code identifies the kind of the warning, e.g. WarnRateVanishes.
+	Code string
+
+	// Message describes the warning with its details.
+	Message string
+}
+
+func (w Warning) String() string {
+	return w.Code + ": " + w.Message
+}
+
+// vanishingRate is the share of the initial learning rate
+// which is considered to be close to zero.
+const vanishingRate = 0.01
+
+// CheckConfig does a dry run of the configuration of this map for learning
+// from the data set in itNum iterations and returns warnings about common
+// mistakes: the initial radius larger than the grid, the learning rate
+// vanishing halfway, the map too small or too large for the data set.
+// Nothing is learned and the map is not modified, nil means no warnings.
+func (som *SOM) CheckConfig(set *DataSet, itNum int) []Warning {
+	var warnings []Warning
+	warn := func(code, format string, args ...interface{}) {
+		warnings = append(warnings, Warning{Code: code, Message: fmt.Sprintf(format, args...)})
+	}
+
+	xLen, yLen := som.Dims()
+	if reporter, ok := som.Influence.(RadiusReporter); ok {
+		diagonal := math.Hypot(float64(xLen-1), float64(yLen-1))
+		if radius := reporter.EffectiveRadius(0, itNum); radius > diagonal {
+			warn(WarnRadiusExceedsGrid, "initial radius %g is larger than the grid diagonal %g of %dx%d map", radius, diagonal, xLen, yLen)
+		}
+	}
+
+	if itNum > 1 {
+		initial, half := som.Restraint.Apply(0, itNum), som.Restraint.Apply(itNum/2, itNum)
+		if initial > 0 && half < initial*vanishingRate {
+			warn(WarnRateVanishes, "learning rate falls from %g to %g by iteration %d of %d", initial, half, itNum/2, itNum)
+		}
+	}
+
+	if n := set.Len(); n > 0 {
+		neurons := som.Len()
+		recommended := 5 * math.Sqrt(float64(n))
+		switch {
+		case neurons > n || float64(neurons) > 4*recommended:
+			warn(WarnMapTooLarge, "%d neurons for %d vectors, about %.0f are recommended", neurons, n, recommended)
+		case float64(neurons) < recommended/4:
+			warn(WarnMapTooSmall, "%d neurons for %d vectors, about %.0f are recommended", neurons, n, recommended)
+		}
+	}
+	return warnings
+}
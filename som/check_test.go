@@ -0,0 +1,48 @@
+package som_test
+
+import (
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+func vectorsDataSet(n int) *som.DataSet {
+	ds := &som.DataSet{}
+	for i := 0; i < n; i++ {
+		ds.Vectors = append(ds.Vectors, som.DataVector{float64(i)})
+	}
+	return ds
+}
+
+func warningCodes(warnings []som.Warning) []string {
+	codes := make([]string, len(warnings))
+	for i, w := range warnings {
+		codes[i] = w.Code
+	}
+	return codes
+}
+
+func TestCheckConfigFindsCommonMistakes(t *testing.T) {
+	sm := som.New(3, 3)
+	sm.Influence = &som.GaussianExpDecayInfluenceFunc{InitialWidth: 10}
+	sm.Restraint = &som.ExpRestraintFunc{InitialRate: 1, N: 10}
+
+	warnings := som.New(3, 3).CheckConfig(vectorsDataSet(9), 100)
+	if len(warnings) != 0 {
+		t.Fatalf("Expected no warnings, got %v", warnings)
+	}
+
+	warnings = sm.CheckConfig(vectorsDataSet(9), 1000)
+	codes := warningCodes(warnings)
+	if len(codes) != 2 || codes[0] != som.WarnRadiusExceedsGrid || codes[1] != som.WarnRateVanishes {
+		t.Fatalf("Unexpected warnings %v", warnings)
+	}
+
+	warnings = som.New(2, 2).CheckConfig(vectorsDataSet(10000), 100)
+	assertEq(t, len(warnings), 1)
+	assertEq(t, warnings[0].Code, som.WarnMapTooSmall)
+
+	warnings = som.New(10, 10).CheckConfig(vectorsDataSet(50), 100)
+	assertEq(t, len(warnings), 1)
+	assertEq(t, warnings[0].String(), "map-too-large: 100 neurons for 50 vectors, about 35 are recommended")
+}
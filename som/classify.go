@@ -0,0 +1,361 @@
+package som
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Calibration records the classes of the labeled vectors mapped to each neuron,
+// which makes a model a classifier, see Model.Calibrate.
+type Calibration struct {
+	// Classes are the distinct labels in ascending order.
+	Classes []string
+
+	// Counts[x][y][c] is the number of vectors of the class Classes[c]
+	// which have the neuron at (x, y) as their BMU.
+	Counts [][][]int
+
+	// Mapped[x][y] is the number of vectors which have the neuron at (x, y)
+	// as their BMU, it's less than the sum of Counts[x][y] if the vectors
+	// have multiple labels, see Model.CalibrateMulti.
+	Mapped [][]int
+}
+
+// Hits returns the number of calibration vectors mapped to the neuron at (x, y).
+func (c *Calibration) Hits(x, y int) int {
+	return c.Mapped[x][y]
+}
+
+// LabelScore is a label with its score, e.g. the frequency of the label
+// among the vectors mapped to a neuron.
+type LabelScore struct {
+	Label string
+	Score float64
+}
+
+// Labels returns the labels of the vectors mapped to the neuron at (x, y)
+// scored by their frequencies (the share of the vectors having the label),
+// ranked from the most frequent one, ties are resolved like by Label.
+// Returns nil if no calibration vectors are mapped to the neuron.
+func (c *Calibration) Labels(x, y int) []LabelScore {
+	hits := c.Hits(x, y)
+	var labels []LabelScore
+	for class, n := range c.Counts[x][y] {
+		if n > 0 {
+			labels = append(labels, LabelScore{Label: c.Classes[class], Score: float64(n) / float64(hits)})
+		}
+	}
+	sort.SliceStable(labels, func(i, j int) bool { return labels[i].Score > labels[j].Score })
+	return labels
+}
+
+// Label returns the majority class of the neuron at (x, y), ties are resolved
+// in favour of the class which is the first in Classes. Returns false
+// if no calibration vectors are mapped to the neuron.
+func (c *Calibration) Label(x, y int) (string, bool) {
+	best := -1
+	for class, n := range c.Counts[x][y] {
+		if n > 0 && (best == -1 || n > c.Counts[x][y][best]) {
+			best = class
+		}
+	}
+	if best == -1 {
+		return "", false
+	}
+	return c.Classes[best], true
+}
+
+// Calibrate maps the labeled vectors, labels[i] is the class of ds.At(i),
+// and returns a copy of this model calibrated by them, which can Classify vectors.
+// The copy shares the codebook with this model.
+func (m *Model) Calibrate(ds *DataSet, labels []string) (*Model, error) {
+	if len(labels) != ds.Len() {
+		return nil, fmt.Errorf("%d labels for %d vectors", len(labels), ds.Len())
+	}
+	sets := make([][]string, len(labels))
+	for i := range labels {
+		sets[i] = labels[i : i+1]
+	}
+	return m.CalibrateMulti(ds, sets)
+}
+
+// CalibrateMulti is like Calibrate, but each vector may have multiple labels,
+// labels[i] are the labels of ds.At(i), so the neurons accumulate label sets
+// with their frequencies, see Calibration.Labels and Model.ClassifyMulti.
+// Repeated labels of a vector are counted once.
+func (m *Model) CalibrateMulti(ds *DataSet, labels [][]string) (*Model, error) {
+	if len(labels) != ds.Len() {
+		return nil, fmt.Errorf("%d labels for %d vectors", len(labels), ds.Len())
+	}
+	vectors := make([]DataVector, ds.Len())
+	for i := range vectors {
+		vectors[i] = ds.At(i)
+	}
+	points, err := m.MapBatch(vectors)
+	if err != nil {
+		return nil, err
+	}
+
+	cal := &Calibration{}
+	classes := make(map[string]int)
+	for _, set := range labels {
+		for _, label := range set {
+			if _, ok := classes[label]; !ok {
+				classes[label] = 0
+				cal.Classes = append(cal.Classes, label)
+			}
+		}
+	}
+	sort.Strings(cal.Classes)
+	for i, class := range cal.Classes {
+		classes[class] = i
+	}
+
+	xLen, yLen := m.Dims()
+	cal.Counts = make([][][]int, xLen)
+	cal.Mapped = make([][]int, xLen)
+	for x := range cal.Counts {
+		cal.Counts[x] = make([][]int, yLen)
+		cal.Mapped[x] = make([]int, yLen)
+		for y := range cal.Counts[x] {
+			cal.Counts[x][y] = make([]int, len(cal.Classes))
+		}
+	}
+	seen := make([]int, len(cal.Classes))
+	for i, p := range points {
+		cal.Mapped[p.X][p.Y]++
+		for _, label := range labels[i] {
+			if class := classes[label]; seen[class] != i+1 {
+				seen[class] = i + 1
+				cal.Counts[p.X][p.Y][class]++
+			}
+		}
+	}
+
+	calibrated := *m
+	calibrated.calibration = cal
+	return &calibrated, nil
+}
+
+// Calibration returns the calibration of this model, nil if it's not calibrated.
+// The returned calibration must not be modified.
+func (m *Model) Calibration() *Calibration {
+	return m.calibration
+}
+
+// Unknown is the label Classify and ClassifyKNN return
+// for the vectors rejected by the model, see Rejection.
+const Unknown = "unknown"
+
+// Rejection configures when a calibrated model refuses to classify a vector
+// and reports it as Unknown instead, so unfamiliar inputs are not assigned
+// to the closest class however far it is. Zero values disable the checks.
+type Rejection struct {
+	// MaxDistance rejects the vectors whose distance
+	// to the closest calibrated neuron exceeds it.
+	MaxDistance float64
+
+	// MinMargin rejects the vectors whose most probable class
+	// is less than MinMargin more probable than the second one,
+	// see Model.ClassProbabilities.
+	MinMargin float64
+}
+
+// WithRejection returns a copy of this model which
+// rejects vectors as configured, see Rejection.
+func (m *Model) WithRejection(rejection Rejection) *Model {
+	rejecting := *m
+	rejecting.rejection = rejection
+	return &rejecting
+}
+
+// Rejection returns the rejection configuration of this model.
+func (m *Model) Rejection() Rejection {
+	return m.rejection
+}
+
+// rejects returns true if the vector whose closest
+// calibrated neuron is at the distance d must be rejected.
+func (m *Model) rejects(vector DataVector, d float64) (bool, error) {
+	if m.rejection.MaxDistance > 0 && d > m.rejection.MaxDistance {
+		return true, nil
+	}
+	if m.rejection.MinMargin <= 0 {
+		return false, nil
+	}
+	probs, err := m.ClassProbabilities(vector)
+	if err != nil {
+		return false, err
+	}
+	first, second := 0.0, 0.0
+	for _, p := range probs {
+		if p > first {
+			first, second = p, first
+		} else if p > second {
+			second = p
+		}
+	}
+	return first-second < m.rejection.MinMargin, nil
+}
+
+// Classify returns the label of the calibrated neuron closest to the vector,
+// i.e. of the BMU among the neurons having calibration vectors mapped to them,
+// or Unknown if the vector is rejected, see WithRejection.
+// Returns ErrNotCalibrated if the model is not calibrated.
+func (m *Model) Classify(vector DataVector) (string, error) {
+	if m.calibration == nil {
+		return "", ErrNotCalibrated
+	}
+	field := m.som.borrowField()
+	defer releaseField(field)
+	distances, err := m.distances(vector, *field)
+	if err != nil {
+		return "", err
+	}
+
+	label, min := "", math.Inf(1)
+	for x := range distances {
+		for y, d := range distances[x] {
+			if d >= min {
+				continue
+			}
+			if l, ok := m.calibration.Label(x, y); ok {
+				label, min = l, d
+			}
+		}
+	}
+	if math.IsInf(min, 1) {
+		return "", ErrNotCalibrated
+	}
+	if rejected, err := m.rejects(vector, min); err != nil || rejected {
+		return Unknown, err
+	}
+	return label, nil
+}
+
+// ClassifyKNN returns the label voted by the k calibrated neurons closest to
+// the vector, each neuron votes for its label (see Calibration.Label) with
+// the weight 1/d, where d is its distance to the vector, so the decision
+// near cluster boundaries depends on several neurons instead of the BMU only.
+// A neuron at zero distance decides alone, ties are resolved in favour of
+// the class which is the first in Calibration.Classes.
+// ClassifyKNN with k == 1 is the same as Classify, the rejection is the same too.
+func (m *Model) ClassifyKNN(vector DataVector, k int) (string, error) {
+	if k <= 0 {
+		return "", fmt.Errorf("%w: k must be positive, got %d", ErrInvalidConfig, k)
+	}
+	neighbours, err := m.calibratedNeighbours(vector)
+	if err != nil {
+		return "", err
+	}
+	if rejected, err := m.rejects(vector, neighbours[0].d); err != nil || rejected {
+		return Unknown, err
+	}
+	if len(neighbours) > k {
+		neighbours = neighbours[:k]
+	}
+	if neighbours[0].d == 0 {
+		return neighbours[0].label, nil
+	}
+
+	votes := make(map[string]float64)
+	for _, n := range neighbours {
+		votes[n.label] += 1 / n.d
+	}
+	best, bestVotes := "", -1.0
+	for _, class := range m.calibration.Classes {
+		if v, ok := votes[class]; ok && v > bestVotes {
+			best, bestVotes = class, v
+		}
+	}
+	return best, nil
+}
+
+// ClassifyMulti returns the ranked labels of the calibrated neuron
+// closest to the vector (see Calibration.Labels), which is useful for
+// the models calibrated by multi-labeled vectors, see CalibrateMulti.
+// Returns nil if the vector is rejected, see WithRejection.
+func (m *Model) ClassifyMulti(vector DataVector) ([]LabelScore, error) {
+	neighbours, err := m.calibratedNeighbours(vector)
+	if err != nil {
+		return nil, err
+	}
+	if rejected, err := m.rejects(vector, neighbours[0].d); err != nil || rejected {
+		return nil, err
+	}
+	return m.calibration.Labels(neighbours[0].x, neighbours[0].y), nil
+}
+
+// ClassProbabilities returns the probabilities of the vector belonging to
+// each class, result[c] is the probability of Calibration.Classes[c].
+// The distribution is the average of the class histograms of the calibrated
+// neurons (the shares of the classes among the labels of the vectors mapped
+// to a neuron) weighted by 1/d², where d is the distance from the neuron
+// to the vector, so it is dominated by the neurons around the BMU.
+// The histogram of a neuron at zero distance is returned as is.
+func (m *Model) ClassProbabilities(vector DataVector) ([]float64, error) {
+	neighbours, err := m.calibratedNeighbours(vector)
+	if err != nil {
+		return nil, err
+	}
+	if neighbours[0].d == 0 {
+		neighbours = neighbours[:1]
+	}
+
+	probs := make([]float64, len(m.calibration.Classes))
+	total := 0.0
+	for _, n := range neighbours {
+		w := 1.0
+		if n.d != 0 {
+			w = 1 / (n.d * n.d)
+		}
+		counts := m.calibration.Counts[n.x][n.y]
+		labeled := 0
+		for _, count := range counts {
+			labeled += count
+		}
+		for c, count := range counts {
+			probs[c] += w * float64(count) / float64(labeled)
+		}
+		total += w
+	}
+	for c := range probs {
+		probs[c] /= total
+	}
+	return probs, nil
+}
+
+type calibratedNeighbour struct {
+	x, y  int
+	d     float64
+	label string
+}
+
+// calibratedNeighbours returns the calibrated neurons ordered by
+// their distances to the vector, the list is never empty.
+func (m *Model) calibratedNeighbours(vector DataVector) ([]calibratedNeighbour, error) {
+	if m.calibration == nil {
+		return nil, ErrNotCalibrated
+	}
+	field := m.som.borrowField()
+	defer releaseField(field)
+	distances, err := m.distances(vector, *field)
+	if err != nil {
+		return nil, err
+	}
+
+	var neighbours []calibratedNeighbour
+	for x := range distances {
+		for y, d := range distances[x] {
+			if label, ok := m.calibration.Label(x, y); ok && !math.IsInf(d, 1) {
+				neighbours = append(neighbours, calibratedNeighbour{x: x, y: y, d: d, label: label})
+			}
+		}
+	}
+	if len(neighbours) == 0 {
+		return nil, ErrNotCalibrated
+	}
+	sort.SliceStable(neighbours, func(i, j int) bool { return neighbours[i].d < neighbours[j].d })
+	return neighbours, nil
+}
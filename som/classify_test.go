@@ -0,0 +1,145 @@
+package som_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+	"github.com/voievodin/self-organizing-map/som/internal/somtest"
+)
+
+func TestCalibratedModelClassifiesByClosestLabeledNeuron(t *testing.T) {
+	model := somtest.LineModel(t, 0, 10, 20)
+	if _, err := model.Classify(som.DataVector{1}); !errors.Is(err, som.ErrNotCalibrated) {
+		t.Fatalf("Expected ErrNotCalibrated, got %v", err)
+	}
+
+	ds := &som.DataSet{Vectors: []som.DataVector{{1}, {2}, {-1}, {19}}}
+	calibrated, err := model.Calibrate(ds, []string{"a", "b", "b", "c"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	label, ok := calibrated.Calibration().Label(0, 0)
+	assertEq(t, label, "b")
+	assertEq(t, ok, true)
+	assertEq(t, calibrated.Calibration().Hits(0, 0), 3)
+	if _, ok := calibrated.Calibration().Label(0, 1); ok {
+		t.Fatal("Expected neuron without vectors to have no label")
+	}
+
+	// the BMU (0, 1) has no label, the closest labeled neuron is (0, 2)
+	label, err = calibrated.Classify(som.DataVector{12})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEq(t, label, "c")
+}
+
+func TestClassifyKNNVotesByDistance(t *testing.T) {
+	ds := &som.DataSet{Vectors: []som.DataVector{{0}, {10}, {12}, {30}}}
+	model, err := somtest.LineModel(t, 0, 10, 12, 30).Calibrate(ds, []string{"a", "b", "b", "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the BMU is labeled a, but both b neurons are nearly as close
+	vector := som.DataVector{4.5}
+	label, _ := model.Classify(vector)
+	assertEq(t, label, "a")
+	label, err = model.ClassifyKNN(vector, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEq(t, label, "b")
+
+	label, _ = model.ClassifyKNN(som.DataVector{10}, 3)
+	assertEq(t, label, "b")
+	label, _ = model.ClassifyKNN(vector, 1)
+	assertEq(t, label, "a")
+
+	if _, err := model.ClassifyKNN(vector, 0); !errors.Is(err, som.ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig, got %v", err)
+	}
+}
+
+func TestClassProbabilities(t *testing.T) {
+	ds := &som.DataSet{Vectors: []som.DataVector{{0}, {0}, {0}, {0}, {10}}}
+	model, err := somtest.LineModel(t, 0, 10).Calibrate(ds, []string{"a", "a", "a", "b", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	probs, err := model.ClassProbabilities(som.DataVector{0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkSlicesEqual(t, probs, []float64{0.75, 0.25})
+
+	// weights 1/25 of both neurons are equal
+	probs, _ = model.ClassProbabilities(som.DataVector{5})
+	checkSlicesEqual(t, probs, []float64{0.375, 0.625})
+
+	probs, _ = model.ClassProbabilities(som.DataVector{9})
+	if probs[1] <= 0.9 || math.Abs(probs[0]+probs[1]-1) > 1e-12 {
+		t.Fatalf("Expected b to dominate a normalized distribution, got %v", probs)
+	}
+
+	if _, err := somtest.LineModel(t, 0, 10).ClassProbabilities(som.DataVector{0}); !errors.Is(err, som.ErrNotCalibrated) {
+		t.Fatalf("Expected ErrNotCalibrated, got %v", err)
+	}
+}
+
+func TestClassifyRejectsUnfamiliarVectors(t *testing.T) {
+	ds := &som.DataSet{Vectors: []som.DataVector{{0}, {10}}}
+	calibrated, err := somtest.LineModel(t, 0, 10).Calibrate(ds, []string{"a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	model := calibrated.WithRejection(som.Rejection{MaxDistance: 3})
+	label, _ := model.Classify(som.DataVector{2})
+	assertEq(t, label, "a")
+	label, _ = model.Classify(som.DataVector{-4})
+	assertEq(t, label, som.Unknown)
+	label, _ = model.ClassifyKNN(som.DataVector{14}, 2)
+	assertEq(t, label, som.Unknown)
+
+	// equally distant neurons give equally probable classes
+	model = calibrated.WithRejection(som.Rejection{MinMargin: 0.2})
+	label, _ = model.Classify(som.DataVector{5})
+	assertEq(t, label, som.Unknown)
+	label, _ = model.Classify(som.DataVector{1})
+	assertEq(t, label, "a")
+
+	label, _ = calibrated.Classify(som.DataVector{-4})
+	assertEq(t, label, "a")
+}
+
+func TestClassifyMultiRanksLabels(t *testing.T) {
+	ds := &som.DataSet{Vectors: []som.DataVector{{0}, {1}, {0}, {10}}}
+	labels := [][]string{{"news", "sport"}, {"sport"}, {"sport", "sport"}, {"weather"}}
+	model, err := somtest.LineModel(t, 0, 10).CalibrateMulti(ds, labels)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cal := model.Calibration()
+	assertEq(t, cal.Hits(0, 0), 3)
+	checkIntsEqual(t, cal.Counts[0][0], []int{1, 3, 0})
+
+	ranked, err := model.ClassifyMulti(som.DataVector{2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ranked) != 2 {
+		t.Fatalf("Expected 2 labels, got %v", ranked)
+	}
+	assertEq(t, ranked[0], som.LabelScore{Label: "sport", Score: 1})
+	assertEq(t, ranked[1].Label, "news")
+
+	label, _ := model.Classify(som.DataVector{2})
+	assertEq(t, label, "sport")
+	ranked, _ = model.ClassifyMulti(som.DataVector{9})
+	assertEq(t, len(ranked), 1)
+	assertEq(t, ranked[0].Label, "weather")
+}
@@ -0,0 +1,110 @@
+package som
+
+import "fmt"
+
+// SetWeights replaces weights of the neuron at (x, y) with a copy of the vector,
+// e.g. to pin the neuron to a known prototype before fine-tuning.
+// The vector length must match the weights length of the other neurons,
+// ErrWidthMismatch is returned otherwise, ErrInvalidConfig is returned
+// if the neuron is out of the map.
+// Note that Learn initializes weights by Initializer, use KeepWeightsInitializer
+// to keep the edited weights.
+func (som *SOM) SetWeights(x, y int, vector DataVector) error {
+	if x < 0 || x >= len(som.Neurons) || y < 0 || y >= len(som.Neurons[x]) {
+		return fmt.Errorf("%w: neuron (%d, %d) is out of the map", ErrInvalidConfig, x, y)
+	}
+	if len(vector) == 0 {
+		return ErrEmptyVector
+	}
+	if width := som.weightsWidth(); width != 0 && width != len(vector) {
+		return fmt.Errorf("%w: vector length is %d, weights length is %d", ErrWidthMismatch, len(vector), width)
+	}
+	som.Neurons[x][y].Weights = append(make([]float64, 0, len(vector)), vector...)
+	return nil
+}
+
+// LoadCodebook replaces weights of all the neurons with copies of the given ones,
+// where codebook[x][y] are the weights of the neuron at (x, y).
+// The codebook must match the map size, ErrInvalidConfig is returned otherwise,
+// and all the weights must have the same length, see SetWeights.
+func (som *SOM) LoadCodebook(codebook [][][]float64) error {
+	if len(codebook) != len(som.Neurons) {
+		return fmt.Errorf("%w: codebook has %d columns, map has %d", ErrInvalidConfig, len(codebook), len(som.Neurons))
+	}
+	width := -1
+	for i := range codebook {
+		if len(codebook[i]) != len(som.Neurons[i]) {
+			return fmt.Errorf("%w: codebook column %d has %d neurons, map has %d", ErrInvalidConfig, i, len(codebook[i]), len(som.Neurons[i]))
+		}
+		for j := range codebook[i] {
+			if width == -1 {
+				width = len(codebook[i][j])
+			}
+			if len(codebook[i][j]) == 0 {
+				return fmt.Errorf("neuron (%d, %d): %w", i, j, ErrEmptyVector)
+			}
+			if len(codebook[i][j]) != width {
+				return fmt.Errorf("%w: neuron (%d, %d) has %d weights, expected %d", ErrWidthMismatch, i, j, len(codebook[i][j]), width)
+			}
+		}
+	}
+
+	for i := range codebook {
+		for j := range codebook[i] {
+			som.Neurons[i][j].Weights = append(make([]float64, 0, width), codebook[i][j]...)
+		}
+	}
+	return nil
+}
+
+// weightsWidth returns the length of the first non-empty neuron weights, or 0.
+func (som *SOM) weightsWidth() int {
+	for i := range som.Neurons {
+		for _, neuron := range som.Neurons[i] {
+			if len(neuron.Weights) != 0 {
+				return len(neuron.Weights)
+			}
+		}
+	}
+	return 0
+}
+
+// KeepWeightsInitializer keeps the current weights of neurons, e.g. edited
+// by SetWeights or LoadCodebook, so Learn fine-tunes them.
+// Neurons without weights of the data set width are initialized by Fallback,
+// ZeroValueWeightsInitializer if Fallback is nil.
+type KeepWeightsInitializer struct {
+	Fallback NeuronsInitializer
+}
+
+func (initializer *KeepWeightsInitializer) Init(set *DataSet, neurons [][]*Neuron) {
+	width := set.Width()
+	kept := make([][][]float64, len(neurons))
+	complete := true
+	for i := range neurons {
+		kept[i] = make([][]float64, len(neurons[i]))
+		for j, neuron := range neurons[i] {
+			if len(neuron.Weights) == width {
+				kept[i][j] = neuron.Weights
+			} else {
+				complete = false
+			}
+		}
+	}
+	if complete {
+		return
+	}
+
+	fallback := initializer.Fallback
+	if fallback == nil {
+		fallback = &ZeroValueWeightsInitializer{}
+	}
+	fallback.Init(set, neurons)
+	for i := range neurons {
+		for j := range neurons[i] {
+			if kept[i][j] != nil {
+				neurons[i][j].Weights = kept[i][j]
+			}
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package som_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+func TestSetWeightsValidatesWidth(t *testing.T) {
+	sm := som.New(2, 2)
+
+	if err := sm.SetWeights(0, 1, som.DataVector{1, 2}); err != nil {
+		t.Fatal(err)
+	}
+	if err := sm.SetWeights(1, 1, som.DataVector{1, 2, 3}); !errors.Is(err, som.ErrWidthMismatch) {
+		t.Fatalf("Expected ErrWidthMismatch, got %v", err)
+	}
+	if err := sm.SetWeights(2, 0, som.DataVector{1, 2}); !errors.Is(err, som.ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig for the neuron out of the map, got %v", err)
+	}
+	checkSlicesEqual(t, sm.Neurons[0][1].Weights, []float64{1, 2})
+}
+
+func TestLoadCodebook(t *testing.T) {
+	sm := som.New(1, 2)
+
+	if err := sm.LoadCodebook([][][]float64{{{1, 2}, {3}}}); !errors.Is(err, som.ErrWidthMismatch) {
+		t.Fatalf("Expected ErrWidthMismatch, got %v", err)
+	}
+	if err := sm.LoadCodebook([][][]float64{{{1, 2}}}); !errors.Is(err, som.ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig for the column size mismatch, got %v", err)
+	}
+	if err := sm.LoadCodebook([][][]float64{{{1}, {2}}, {{3}, {4}}}); !errors.Is(err, som.ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig for the columns mismatch, got %v", err)
+	}
+
+	codebook := [][][]float64{{{1, 2}, {3, 4}}}
+	if err := sm.LoadCodebook(codebook); err != nil {
+		t.Fatal(err)
+	}
+	codebook[0][1][0] = 100
+	checkSlicesEqual(t, sm.Neurons[0][1].Weights, []float64{3, 4})
+	if !sm.IsTrained() {
+		t.Fatal("Expected map with loaded codebook to be trained")
+	}
+}
+
+func TestKeepWeightsInitializerKeepsPinnedNeurons(t *testing.T) {
+	sm := som.New(1, 3)
+	sm.SetWeights(0, 2, som.DataVector{5, 5})
+	sm.Initializer = &som.KeepWeightsInitializer{}
+
+	sm.Learn(&som.DataSet{Vectors: []som.DataVector{{1, 1}}}, 0)
+
+	checkSlicesEqual(t, sm.Neurons[0][0].Weights, []float64{0, 0})
+	checkSlicesEqual(t, sm.Neurons[0][2].Weights, []float64{5, 5})
+}
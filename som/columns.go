@@ -0,0 +1,104 @@
+package som
+
+import "fmt"
+
+// Column describes a feature of the data set.
+type Column struct {
+	Name string
+
+	// Unit is the measurement unit, e.g. "cm", empty if unknown or unitless.
+	Unit string
+}
+
+// Header returns the column title for outputs, "name (unit)" or "name".
+func (c Column) Header() string {
+	if c.Unit == "" {
+		return c.Name
+	}
+	return c.Name + " (" + c.Unit + ")"
+}
+
+// Headers returns the headers of the columns, nil for nil columns,
+// so they can be passed as feature names to exporters.
+func Headers(columns []Column) []string {
+	if columns == nil {
+		return nil
+	}
+	headers := make([]string, len(columns))
+	for i, c := range columns {
+		headers[i] = c.Header()
+	}
+	return headers
+}
+
+// ColumnMapper is implemented by adapters which change the meaning of features,
+// MapColumns returns columns describing the adapted vectors.
+// Adapters which don't implement it keep the columns as they are.
+type ColumnMapper interface {
+	MapColumns(columns []Column) []Column
+}
+
+// AdaptColumns returns the columns describing vectors adapted by the adapter.
+func AdaptColumns(adapter DataAdapter, columns []Column) []Column {
+	if mapper, ok := adapter.(ColumnMapper); ok && columns != nil {
+		return mapper.MapColumns(columns)
+	}
+	return columns
+}
+
+// SetColumns attaches feature metadata to this data set, columns[k]
+// describes the k-th feature. The number of columns must match the width
+// of the data set, unless it's empty. Passing nil detaches the metadata.
+func (ds *DataSet) SetColumns(columns []Column) error {
+	if columns != nil && ds.Len() != 0 && len(columns) != ds.Width() {
+		return fmt.Errorf("%w: %d columns for data set of width %d", ErrWidthMismatch, len(columns), ds.Width())
+	}
+	ds.columns = append([]Column(nil), columns...)
+	if columns == nil {
+		ds.columns = nil
+	}
+	return nil
+}
+
+// Columns returns feature metadata of this data set, nil if it's not set.
+func (ds *DataSet) Columns() []Column {
+	if ds.columns == nil {
+		return nil
+	}
+	return append([]Column(nil), ds.columns...)
+}
+
+// AdaptedColumns returns feature metadata describing vectors returned by At,
+// i.e. the columns mapped by the data set adapter, see ColumnMapper.
+func (ds *DataSet) AdaptedColumns() []Column {
+	return AdaptColumns(ds.adapter, ds.Columns())
+}
+
+func (adapter *ScalingDataAdapter) MapColumns(columns []Column) []Column {
+	return unitless(columns)
+}
+
+func (adapter *RankDataAdapter) MapColumns(columns []Column) []Column {
+	return unitless(columns)
+}
+
+// MapColumns names PCA whitened features "pc1", "pc2", ..., ZCA ones keep their names.
+func (adapter *WhiteningDataAdapter) MapColumns(columns []Column) []Column {
+	mapped := unitless(columns)
+	if adapter.method == PCAWhitening {
+		for i := range mapped {
+			mapped[i].Name = fmt.Sprintf("pc%d", i+1)
+		}
+	}
+	return mapped
+}
+
+// unitless returns copy of the columns without units, for the adapters
+// mapping values to dimensionless scales.
+func unitless(columns []Column) []Column {
+	mapped := make([]Column, len(columns))
+	for i, c := range columns {
+		mapped[i] = Column{Name: c.Name}
+	}
+	return mapped
+}
@@ -0,0 +1,53 @@
+package som_test
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+func TestDataSetColumns(t *testing.T) {
+	ds := &som.DataSet{Vectors: []som.DataVector{{1, 2}, {3, 4}}}
+	if err := ds.SetColumns([]som.Column{{Name: "a"}}); !errors.Is(err, som.ErrWidthMismatch) {
+		t.Fatalf("Expected ErrWidthMismatch, got %v", err)
+	}
+	if err := ds.SetColumns([]som.Column{{Name: "height", Unit: "cm"}, {Name: "age"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	assertEq(t, strings.Join(som.Headers(ds.Columns()), ","), "height (cm),age")
+	assertEq(t, ds.Copy().Columns()[0].Unit, "cm")
+	assertEq(t, ds.Freeze().Select(1).DataSet().Columns()[1].Name, "age")
+
+	ds.SetAdapter(som.NewWhiteningDataAdapter(ds, som.PCAWhitening, 1e-5))
+	if !reflect.DeepEqual(ds.AdaptedColumns(), []som.Column{{Name: "pc1"}, {Name: "pc2"}}) {
+		t.Fatalf("Unexpected adapted columns %v", ds.AdaptedColumns())
+	}
+	assertEq(t, ds.Columns()[0].Unit, "cm")
+}
+
+func TestColumnsAreLoadedAndExported(t *testing.T) {
+	ds, _, err := som.ReadCSV(strings.NewReader("x,y\n1,2\n"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEq(t, strings.Join(som.Headers(ds.Columns()), ","), "x,y")
+
+	type sample struct {
+		Height float64 `unit:"cm"`
+		Weight float64 `unit:"kg"`
+	}
+	ds, _, _ = som.VectorizeStructs([]sample{{180, 80}})
+	sm := som.New(1, 1)
+	sm.LoadCodebook([][][]float64{{{170, 70}}})
+
+	buf := &bytes.Buffer{}
+	if err := sm.ExportCodebookCSV(buf, som.Headers(ds.Columns())); err != nil {
+		t.Fatal(err)
+	}
+	assertEq(t, buf.String(), "x,y,Height (cm),Weight (kg)\n0,0,170,70\n")
+}
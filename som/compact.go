@@ -0,0 +1,88 @@
+package som
+
+import (
+	"fmt"
+
+	"github.com/voievodin/self-organizing-map/som/vec"
+)
+
+// Codebook is a compact codebook of a trained map for inference on
+// devices where memory matters, e.g. embedded and edge deployments:
+// the weights are stored as T, e.g. Codebook[float32] takes half the memory
+// of the model, in a single slice. Maps learn in float64 and are converted
+// by NewCodebook, so the element type is chosen without forking the package,
+// and the vectors are converted by ConvertVector.
+// Unlike Model, Codebook finds BMUs by the Euclidean distance regardless of
+// the distance function of the map and doesn't adapt vectors, so the vectors
+// must be adapted by the caller. Codebook is safe for concurrent use.
+type Codebook[T vec.Float] struct {
+	xLen, yLen, width int
+	weights           []T
+	masked            []bool
+}
+
+// NewCodebook converts the codebook of the model to the element type T.
+func NewCodebook[T vec.Float](m *Model) *Codebook[T] {
+	xLen, yLen := m.Dims()
+	c := &Codebook[T]{
+		xLen:    xLen,
+		yLen:    yLen,
+		width:   m.Width(),
+		weights: make([]T, 0, xLen*yLen*m.Width()),
+		masked:  make([]bool, xLen*yLen),
+	}
+	for x := 0; x < xLen; x++ {
+		for y := 0; y < yLen; y++ {
+			for _, w := range m.som.Neurons[x][y].Weights {
+				c.weights = append(c.weights, T(w))
+			}
+			c.masked[x*yLen+y] = m.IsMasked(x, y)
+		}
+	}
+	return c
+}
+
+// Dims returns the size of the map grid.
+func (c *Codebook[T]) Dims() (int, int) {
+	return c.xLen, c.yLen
+}
+
+// Width returns the length of neurons weights.
+func (c *Codebook[T]) Width() int {
+	return c.width
+}
+
+// Weights returns the weights of the neuron at (x, y),
+// which share the memory of the codebook and must not be modified.
+func (c *Codebook[T]) Weights(x, y int) []T {
+	from := (x*c.yLen + y) * c.width
+	return c.weights[from : from+c.width : from+c.width]
+}
+
+// BMU returns the position of the neuron closest to the vector, the one
+// with the lowest index among equally close neurons, see Model.BMU.
+// Returns ErrWidthMismatch if the vector doesn't fit the weights and
+// ErrInvalidConfig if all the neurons are masked.
+func (c *Codebook[T]) BMU(vector Vector[T]) (GridPoint, error) {
+	if len(vector) != c.width {
+		return GridPoint{}, fmt.Errorf("%w: vector length is %d, weights length is %d", ErrWidthMismatch, len(vector), c.width)
+	}
+	bmu, min := -1, T(0)
+	for i, masked := range c.masked {
+		if masked {
+			continue
+		}
+		// the squared distance preserves the order of the distances
+		var d T
+		for k, w := range c.weights[i*c.width : (i+1)*c.width] {
+			d += (vector[k] - w) * (vector[k] - w)
+		}
+		if bmu == -1 || d < min {
+			bmu, min = i, d
+		}
+	}
+	if bmu == -1 {
+		return GridPoint{}, fmt.Errorf("%w: all the neurons are masked", ErrInvalidConfig)
+	}
+	return GridPoint{X: bmu / c.yLen, Y: bmu % c.yLen}, nil
+}
@@ -0,0 +1,108 @@
+package som_test
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+func randModel(t testing.TB, xLen, yLen, width int) *som.Model {
+	rng := rand.New(rand.NewSource(1))
+	codebook := make([][][]float64, xLen)
+	for x := range codebook {
+		codebook[x] = make([][]float64, yLen)
+		for y := range codebook[x] {
+			codebook[x][y] = make([]float64, width)
+			for k := range codebook[x][y] {
+				codebook[x][y][k] = rng.Float64()
+			}
+		}
+	}
+	sm := som.New(xLen, yLen)
+	if err := sm.LoadCodebook(codebook); err != nil {
+		t.Fatal(err)
+	}
+	model, err := sm.Model()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return model
+}
+
+func TestCodebookFindsModelBMUs(t *testing.T) {
+	model := randModel(t, 6, 4, 3)
+	compact := som.NewCodebook[float32](model)
+	xLen, yLen := compact.Dims()
+	assertEq(t, 6, xLen)
+	assertEq(t, 4, yLen)
+	assertEq(t, 3, compact.Width())
+	assertEq(t, float32(model.Weights(2, 3)[1]), compact.Weights(2, 3)[1])
+
+	rng := rand.New(rand.NewSource(2))
+	for i := 0; i < 100; i++ {
+		vector := som.DataVector{rng.Float64(), rng.Float64(), rng.Float64()}
+		expected, _ := model.BMU(vector)
+		bmu, err := compact.BMU(som.ConvertVector[float32](vector))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bmu != expected {
+			// float32 rounding may only swap nearly equidistant neurons
+			d := model.Distances(vector, nil)
+			if diff := d[bmu.X][bmu.Y] - d[expected.X][expected.Y]; diff > 1e-6 {
+				t.Fatalf("Expected BMU %v, got %v farther by %g", expected, bmu, diff)
+			}
+		}
+	}
+
+	if _, err := compact.BMU([]float32{1}); !errors.Is(err, som.ErrWidthMismatch) {
+		t.Fatalf("Expected ErrWidthMismatch, got %v", err)
+	}
+}
+
+func TestCodebookSkipsMaskedNeurons(t *testing.T) {
+	sm := som.New(1, 2)
+	if err := sm.LoadCodebook([][][]float64{{{0}, {10}}}); err != nil {
+		t.Fatal(err)
+	}
+	sm.Mask = [][]bool{{true, false}}
+	model, _ := sm.Model()
+	bmu, _ := som.NewCodebook[float64](model).BMU([]float64{1})
+	assertEq(t, som.GridPoint{X: 0, Y: 1}, bmu)
+
+	sm.Mask = [][]bool{{true, true}}
+	model, _ = sm.Model()
+	if _, err := som.NewCodebook[float64](model).BMU([]float64{1}); !errors.Is(err, som.ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig for all the neurons masked, got %v", err)
+	}
+}
+
+func benchmarkCodebookBMU[T float32 | float64](b *testing.B) {
+	compact := som.NewCodebook[T](randModel(b, 30, 30, 16))
+	vector := make([]T, 16)
+	for k := range vector {
+		vector[k] = T(k) / 16
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		compact.BMU(vector)
+	}
+}
+
+func BenchmarkCodebookBMUFloat32(b *testing.B) { benchmarkCodebookBMU[float32](b) }
+
+func BenchmarkCodebookBMUFloat64(b *testing.B) { benchmarkCodebookBMU[float64](b) }
+
+func BenchmarkModelBMU(b *testing.B) {
+	model := randModel(b, 30, 30, 16)
+	vector := make(som.DataVector, 16)
+	for k := range vector {
+		vector[k] = float64(k) / 16
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		model.BMU(vector)
+	}
+}
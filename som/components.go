@@ -0,0 +1,89 @@
+package som
+
+// Component is a connected region of neurons, see SOM.Components.
+type Component struct {
+	// Label is the label of the component neurons in the components matrix.
+	Label int
+
+	// Size is the number of neurons in the component.
+	Size int
+
+	// Bounds is the smallest rectangle containing the component neurons,
+	// the components connected across the edges of the map span the edges.
+	Bounds Rect
+}
+
+// Components thresholds the U-matrix of this map and finds the connected
+// components of the low-distance neurons, those whose U-matrix value is not
+// greater than the threshold, so each component is a cluster found by the map.
+// Neighbours are direct (non-diagonal), including the neighbours across
+// the edges connected by Topology.
+// The components matrix is indexed like Neurons, components[x][y] is the label
+// of the component the neuron belongs to, i.e. the index in the returned slice,
+// labels are assigned in the order of neuron indexes. High-distance and masked
+// neurons have the label -1. See ClusterBoundaries.
+func (som *SOM) Components(threshold float64) ([][]int, []Component) {
+	umatrix := som.UMatrix()
+	xLen, yLen := som.Dims()
+	labels := make([][]int, xLen)
+	for x := range labels {
+		labels[x] = make([]int, yLen)
+		for y := range labels[x] {
+			labels[x][y] = -1
+		}
+	}
+	low := func(x, y int) bool {
+		return !som.IsMasked(x, y) && umatrix[x][y] <= threshold
+	}
+
+	var components []Component
+	for i := 0; i < som.Len(); i++ {
+		x, y := som.Position(i)
+		if labels[x][y] != -1 || !low(x, y) {
+			continue
+		}
+		component := Component{
+			Label:  len(components),
+			Bounds: Rect{Min: GridPoint{X: x, Y: y}, Max: GridPoint{X: x + 1, Y: y + 1}},
+		}
+		labels[x][y] = component.Label
+		stack := []GridPoint{{X: x, Y: y}}
+		for len(stack) > 0 {
+			p := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			component.Size++
+			component.Bounds = component.Bounds.extend(p)
+			for _, offset := range [][2]int{{-1, 0}, {1, 0}, {0, -1}, {0, 1}} {
+				nx, ny, ok := som.neighbour(p.X, p.Y, offset[0], offset[1])
+				if ok && labels[nx][ny] == -1 && low(nx, ny) {
+					labels[nx][ny] = component.Label
+					stack = append(stack, GridPoint{X: nx, Y: ny})
+				}
+			}
+		}
+		components = append(components, component)
+	}
+	return labels, components
+}
+
+// extend returns the smallest rectangle containing r and the neuron at p.
+func (r Rect) extend(p GridPoint) Rect {
+	if p.X < r.Min.X {
+		r.Min.X = p.X
+	}
+	if p.Y < r.Min.Y {
+		r.Min.Y = p.Y
+	}
+	if p.X >= r.Max.X {
+		r.Max.X = p.X + 1
+	}
+	if p.Y >= r.Max.Y {
+		r.Max.Y = p.Y + 1
+	}
+	return r
+}
+
+// Components finds connected low-distance regions of the map, see SOM.Components.
+func (m *Model) Components(threshold float64) ([][]int, []Component) {
+	return m.som.Components(threshold)
+}
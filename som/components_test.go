@@ -0,0 +1,35 @@
+package som_test
+
+import (
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+func TestComponentsOfLowDistanceRegions(t *testing.T) {
+	// two groups of close neurons separated by the middle column
+	sm := som.New(3, 5)
+	codebook := make([][][]float64, 3)
+	for x := range codebook {
+		for _, w := range []float64{0, 1, 50, 100, 101} {
+			codebook[x] = append(codebook[x], []float64{w})
+		}
+	}
+	if err := sm.LoadCodebook(codebook); err != nil {
+		t.Fatal(err)
+	}
+
+	labels, components := sm.Components(1)
+	assertEq(t, len(components), 2)
+	checkIntsEqual(t, labels[0], []int{0, -1, -1, -1, 1})
+	assertEq(t, components[0], som.Component{
+		Label:  0,
+		Size:   3,
+		Bounds: som.Rect{Min: som.GridPoint{X: 0, Y: 0}, Max: som.GridPoint{X: 3, Y: 1}},
+	})
+	assertEq(t, components[1].Bounds.Contains(1, 4), true)
+
+	_, components = sm.Components(100)
+	assertEq(t, len(components), 1)
+	assertEq(t, components[0].Size, 15)
+}
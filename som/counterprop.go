@@ -0,0 +1,145 @@
+package som
+
+import (
+	"fmt"
+	"sort"
+)
+
+// OutstarConfig configures training of the Grossberg (outstar) output layer
+// of a counter-propagation network, see Model.TrainOutstar.
+type OutstarConfig struct {
+	// Epochs is the number of passes over the data set, <= 0 means 1.
+	Epochs int
+
+	// Rate is the initial learning rate within (0, 1], <= 0 means 0.5.
+	// It decays linearly to 0 by the end of training.
+	Rate float64
+
+	// Radius is the width of the gaussian neighbourhood kernel on the map
+	// grid around the BMU, so the neighbours of the BMU learn its output too,
+	// which fills in the neurons without data, 0 means that the BMU learns only.
+	Radius float64
+}
+
+// OutstarLayer is the Grossberg output layer of a counter-propagation
+// network built on a trained map, which is its Kohonen layer.
+type OutstarLayer struct {
+	// Weights[x][y] is the output vector of the neuron at (x, y).
+	Weights [][][]float64
+}
+
+// TrainOutstar trains the Grossberg output layer on top of this map and returns
+// a copy of this model with the layer, which maps vectors to outputs, see Output.
+// targets[i] is the desired output of ds.At(i), all of the same length.
+// The map itself is not changed: each vector is mapped to its BMU c, and the
+// output weights v of each neuron j move towards the target y
+//
+//	v(j) = v(j) + rate * h(c, j) * (y - v(j))
+//
+// where h is the neighbourhood kernel of OutstarConfig.Radius.
+// For classification use one-hot targets, see OneHot.
+func (m *Model) TrainOutstar(ds *DataSet, targets [][]float64, config OutstarConfig) (*Model, error) {
+	if len(targets) != ds.Len() {
+		return nil, fmt.Errorf("%d targets for %d vectors", len(targets), ds.Len())
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("%w: no targets to train the output layer", ErrInvalidConfig)
+	}
+	outWidth := len(targets[0])
+	for i, target := range targets {
+		if len(target) != outWidth {
+			return nil, fmt.Errorf("target %d: %w: target length is %d, expected %d", i, ErrWidthMismatch, len(target), outWidth)
+		}
+	}
+	epochs, rate := config.Epochs, config.Rate
+	if epochs <= 0 {
+		epochs = 1
+	}
+	if rate <= 0 {
+		rate = 0.5
+	}
+
+	vectors := make([]DataVector, ds.Len())
+	for i := range vectors {
+		vectors[i] = ds.At(i)
+	}
+	points, err := m.MapBatch(vectors)
+	if err != nil {
+		return nil, err
+	}
+
+	xLen, yLen := m.Dims()
+	layer := &OutstarLayer{Weights: make([][][]float64, xLen)}
+	for x := range layer.Weights {
+		layer.Weights[x] = make([][]float64, yLen)
+		for y := range layer.Weights[x] {
+			layer.Weights[x][y] = make([]float64, outWidth)
+		}
+	}
+	kernel := NewKernel(m.som.Topology, config.Radius, xLen, yLen)
+	itNum, it := epochs*len(points), 0
+	for epoch := 0; epoch < epochs; epoch++ {
+		for i, p := range points {
+			r := rate * (1 - float64(it)/float64(itNum))
+			for x := 0; x < xLen; x++ {
+				for y := 0; y < yLen; y++ {
+					h := kernel.At(p.X, p.Y, x, y)
+					if h == 0 || m.som.IsMasked(x, y) {
+						continue
+					}
+					out := layer.Weights[x][y]
+					for k := range out {
+						out[k] += r * h * (targets[i][k] - out[k])
+					}
+				}
+			}
+			it++
+		}
+	}
+
+	trained := *m
+	trained.outstar = layer
+	return &trained, nil
+}
+
+// Outstar returns the output layer of this model, nil if it's not trained.
+// The returned layer must not be modified.
+func (m *Model) Outstar() *OutstarLayer {
+	return m.outstar
+}
+
+// Output returns a copy of the output weights of the BMU of the vector.
+// Returns ErrNotCalibrated if the output layer is not trained, see TrainOutstar.
+func (m *Model) Output(vector DataVector) ([]float64, error) {
+	if m.outstar == nil {
+		return nil, ErrNotCalibrated
+	}
+	bmu, err := m.BMU(vector)
+	if err != nil {
+		return nil, err
+	}
+	return append([]float64(nil), m.outstar.Weights[bmu.X][bmu.Y]...), nil
+}
+
+// OneHot encodes the labels as targets of the output layer: targets[i][c]
+// is 1 if labels[i] is classes[c] and 0 otherwise, the classes are the distinct
+// labels in ascending order. The class of an output is the one with the maximal value.
+func OneHot(labels []string) (classes []string, targets [][]float64) {
+	index := make(map[string]int)
+	for _, label := range labels {
+		if _, ok := index[label]; !ok {
+			index[label] = 0
+			classes = append(classes, label)
+		}
+	}
+	sort.Strings(classes)
+	for i, class := range classes {
+		index[class] = i
+	}
+	targets = make([][]float64, len(labels))
+	for i, label := range labels {
+		targets[i] = make([]float64, len(classes))
+		targets[i][index[label]] = 1
+	}
+	return classes, targets
+}
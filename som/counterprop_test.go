@@ -0,0 +1,60 @@
+package som_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+	"github.com/voievodin/self-organizing-map/som/internal/somtest"
+)
+
+func TestOutstarLayerLearnsTargets(t *testing.T) {
+	model := somtest.LineModel(t, 0, 10, 20)
+	if _, err := model.Output(som.DataVector{1}); !errors.Is(err, som.ErrNotCalibrated) {
+		t.Fatalf("Expected ErrNotCalibrated, got %v", err)
+	}
+
+	ds := &som.DataSet{Vectors: []som.DataVector{{1}, {19}}}
+	trained, err := model.TrainOutstar(ds, [][]float64{{1, 2}, {5, 6}}, som.OutstarConfig{Epochs: 50, Rate: 0.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := trained.Output(som.DataVector{-3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(out[0]-1) > 1e-3 || math.Abs(out[1]-2) > 1e-3 {
+		t.Fatalf("Expected output close to [1 2], got %v", out)
+	}
+	// without the neighbourhood the neurons without data don't learn
+	out, _ = trained.Output(som.DataVector{10})
+	checkSlicesEqual(t, out, []float64{0, 0})
+
+	trained, _ = model.TrainOutstar(ds, [][]float64{{1, 2}, {5, 6}}, som.OutstarConfig{Epochs: 50, Radius: 1})
+	out, _ = trained.Output(som.DataVector{10})
+	if out[0] <= 1 || out[0] >= 5 {
+		t.Fatalf("Expected the middle neuron to learn from its neighbours, got %v", out)
+	}
+}
+
+func TestOutstarLayerClassifiesOneHotTargets(t *testing.T) {
+	classes, targets := som.OneHot([]string{"b", "a", "b"})
+	checkSlicesEqual(t, targets[1], []float64{1, 0})
+	assertEq(t, len(classes), 2)
+	assertEq(t, classes[0], "a")
+
+	ds := &som.DataSet{Vectors: []som.DataVector{{1}, {9}, {19}}}
+	trained, err := somtest.LineModel(t, 0, 10, 20).TrainOutstar(ds, targets, som.OutstarConfig{Epochs: 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, _ := trained.Output(som.DataVector{11})
+	if out[0] <= out[1] {
+		t.Fatalf("Expected class a to win, got %v", out)
+	}
+
+	if _, err := somtest.LineModel(t, 0).TrainOutstar(&som.DataSet{Vectors: []som.DataVector{{1}, {2}}}, [][]float64{{1}, {1, 2}}, som.OutstarConfig{}); !errors.Is(err, som.ErrWidthMismatch) {
+		t.Fatalf("Expected ErrWidthMismatch, got %v", err)
+	}
+}
@@ -0,0 +1,85 @@
+package som
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ReadCSV reads data set from CSV formatted input, one vector per record.
+// If header is true, the first record is treated as column names, which are returned,
+// and determines the width of the data set, otherwise the first accepted
+// record does. Empty values are read as NaN.
+// Malformed records (unparsable values, wrong number of values) are skipped
+// and reported by *LoadReport returned along with the data set of the accepted ones,
+// see LoadReport.
+func ReadCSV(r io.Reader, header bool) (*DataSet, []string, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	var names []string
+	if header {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return &DataSet{}, nil, nil
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		names = record
+	}
+
+	ds := &DataSet{}
+	report := &LoadReport{}
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			if names != nil {
+				columns := make([]Column, len(names))
+				for i, name := range names {
+					columns[i] = Column{Name: name}
+				}
+				ds.SetColumns(columns)
+			}
+			return ds, names, report.result()
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		line, _ := cr.FieldPos(0)
+
+		if names != nil && len(record) != len(names) {
+			report.Rows++
+			report.reject(&RowError{Row: line, Err: ErrWidthMismatch, Expected: len(names), Actual: len(record)})
+			continue
+		}
+		vector, err := parseCSVRecord(record)
+		if err != nil {
+			report.Rows++
+			report.reject(&RowError{Row: line, Err: err})
+			continue
+		}
+		report.addRow(ds, line, vector)
+	}
+}
+
+func parseCSVRecord(record []string) (DataVector, error) {
+	vector := make(DataVector, len(record))
+	for i, value := range record {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			vector[i] = math.NaN()
+			continue
+		}
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("column %d: bad value %q", i+1, value)
+		}
+		vector[i] = v
+	}
+	return vector, nil
+}
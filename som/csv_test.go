@@ -0,0 +1,73 @@
+package som_test
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+func TestReadCSVSkipsAndReportsMalformedRecords(t *testing.T) {
+	input := `a,b,c
+1,2,3
+4,5
+6,x,7
+8,,9
+`
+	ds, names, err := som.ReadCSV(strings.NewReader(input), true)
+
+	var report *som.LoadReport
+	if !errors.As(err, &report) {
+		t.Fatalf("Expected LoadReport, got %v", err)
+	}
+	assertEq(t, report.Rows, 4)
+	assertEq(t, report.Rejected, 2)
+
+	widthErr := report.Errors[0]
+	assertEq(t, widthErr.Row, 3)
+	assertEq(t, widthErr.Expected, 3)
+	assertEq(t, widthErr.Actual, 2)
+	assertEq(t, report.Errors[1].Row, 4)
+	if !strings.Contains(report.Error(), `bad value "x"`) {
+		t.Fatalf("Expected report to mention the bad value, got %q", report.Error())
+	}
+
+	assertEq(t, strings.Join(names, ","), "a,b,c")
+	assertEq(t, ds.Len(), 2)
+	checkSlicesEqual(t, ds.Vectors[0], []float64{1, 2, 3})
+	if !math.IsNaN(ds.Vectors[1][1]) {
+		t.Fatalf("Expected empty value to be NaN, got %f", ds.Vectors[1][1])
+	}
+}
+
+func TestReadCSVWithoutHeaderUsesFirstRecordWidth(t *testing.T) {
+	ds, names, err := som.ReadCSV(strings.NewReader("1,2\n3,4,5\n6,7\n"), false)
+
+	if !errors.Is(err, som.ErrWidthMismatch) {
+		t.Fatalf("Expected width mismatch, got %v", err)
+	}
+	if names != nil {
+		t.Fatalf("Expected no names, got %v", names)
+	}
+	assertEq(t, ds.Len(), 2)
+}
+
+func FuzzReadCSV(f *testing.F) {
+	f.Add("a,b,c\n1,2,3\n4,5\n6,x,7\n8,,9\n", true)
+	f.Add("1, 2\n\"3\",4\n", false)
+
+	f.Fuzz(func(t *testing.T, input string, header bool) {
+		ds, names, err := som.ReadCSV(strings.NewReader(input), header)
+		var report *som.LoadReport
+		if err != nil && !errors.As(err, &report) {
+			return
+		}
+		for i, vector := range ds.Vectors {
+			if len(vector) != len(ds.Vectors[0]) || (names != nil && len(vector) != len(names)) {
+				t.Fatalf("Vector %d has %d values, the first one has %d", i, len(vector), len(ds.Vectors[0]))
+			}
+		}
+	})
+}
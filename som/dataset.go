@@ -1,28 +1,94 @@
 package som
 
 import (
+	"fmt"
 	"math/rand"
 	"sort"
+
+	"github.com/voievodin/self-organizing-map/som/vec"
 )
 
-type DataVector []float64
+// Vector is a data vector of the element type T, e.g. Vector[float32]
+// takes half the memory of DataVector, see Codebook.
+type Vector[T vec.Float] []T
+
+// DataVector is the vector maps learn from and map.
+type DataVector = Vector[float64]
+
+// ConvertVector converts the vector to the element type T.
+func ConvertVector[T, S vec.Float](vector []S) Vector[T] {
+	converted := make(Vector[T], len(vector))
+	for k, v := range vector {
+		converted[k] = T(v)
+	}
+	return converted
+}
 
 // DataSet is in-memory collection of data vectors.
+//
+// DataSet is not safe for concurrent use, but its methods never modify
+// the Vectors slice in place: Shuffle, Sort and Reduce replace it with a new
+// one, so the slice obtained before the call may still be iterated by
+// another goroutine. Use Freeze to share a data set between goroutines.
 type DataSet struct {
 	Vectors []DataVector
+
+	adapter DataAdapter
+	adapted []DataVector
+	columns []Column
+}
+
+// SetAdapter attaches the adapter to this data set, so vectors returned
+// by At are adapted. Each vector is adapted once, on first access,
+// and the result is cached. The adapter receives a copy of the vector,
+// so the vectors of this data set are never modified.
+// Passing nil detaches the adapter.
+func (ds *DataSet) SetAdapter(adapter DataAdapter) {
+	ds.adapter = adapter
+	ds.adapted = nil
+}
+
+// Adapter returns the adapter attached to this data set, or nil.
+func (ds *DataSet) Adapter() DataAdapter {
+	return ds.adapter
+}
+
+// At returns the vector at the given index adapted
+// by the data set adapter, if there is one.
+// Note that At caches adapted vectors and so is not safe for concurrent use.
+func (ds *DataSet) At(i int) DataVector {
+	if ds.adapter == nil {
+		return ds.Vectors[i]
+	}
+	if len(ds.adapted) < len(ds.Vectors) {
+		ds.adapted = append(ds.adapted, make([]DataVector, len(ds.Vectors)-len(ds.adapted))...)
+	}
+	if ds.adapted[i] == nil {
+		vectorCopy := make(DataVector, len(ds.Vectors[i]))
+		copy(vectorCopy, ds.Vectors[i])
+		ds.adapted[i] = ds.adapter.Adapt(vectorCopy)
+	}
+	return ds.adapted[i]
 }
 
 // Add adds vector to this data-set.
-func (ds *DataSet) Add(vector DataVector) {
+// Data set must contain non-empty vectors of the same length,
+// ErrEmptyVector or ErrWidthMismatch is returned and
+// the vector is not added otherwise.
+func (ds *DataSet) Add(vector DataVector) error {
+	if len(vector) == 0 {
+		return ErrEmptyVector
+	}
 	if len(ds.Vectors) != 0 && ds.Width() != len(vector) {
-		panic("data set must contain vectors of the same length")
+		return fmt.Errorf("%w: data set width is %d, vector length is %d", ErrWidthMismatch, ds.Width(), len(vector))
 	}
 	ds.Vectors = append(ds.Vectors, vector)
+	return nil
 }
 
 // AddRaw adds data vector to this data set, created from the given raw values.
-func (ds *DataSet) AddRaw(vector ...float64) {
-	ds.Add(DataVector(vector))
+func (ds *DataSet) AddRaw(vector ...float64) error {
+	return ds.Add(DataVector(vector))
 }
 
 // Len returns the number of vectors carried by this data set.
@@ -46,9 +112,11 @@ func (ds *DataSet) Shuffle() {
 		shuffled[i] = ds.Vectors[j]
 	}
 	ds.Vectors = shuffled
+	ds.adapted = nil
 }
 
-// Copy copies data set vectors and returns a new instance of data set.
+// Copy copies data set vectors and returns a new instance of data set,
+// the adapter and the columns are shared with the copy.
 func (ds *DataSet) Copy() *DataSet {
 	vectorsCopy := make([]DataVector, ds.Len())
 	for i := range ds.Vectors {
@@ -56,20 +124,24 @@ func (ds *DataSet) Copy() *DataSet {
 		copy(vectorCopy, ds.Vectors[i])
 		vectorsCopy[i] = vectorCopy
 	}
-	return &DataSet{Vectors: vectorsCopy}
+	return &DataSet{Vectors: vectorsCopy, adapter: ds.adapter, columns: ds.columns}
 }
 
 // Sort sorts this data set in ascending order.
 // Vector A < Vector B, when A[k] < B[k] for the first met such k, where k [0 -> len(A)-1]
 func (ds *DataSet) Sort() {
-	sort.Slice(ds.Vectors, func(i, j int) bool {
-		for k := 0; k < ds.Width(); k++ {
-			if ds.Vectors[i][k] != ds.Vectors[j][k] {
-				return ds.Vectors[i][k] < ds.Vectors[j][k]
+	sorted := make([]DataVector, ds.Len())
+	copy(sorted, ds.Vectors)
+	sort.Slice(sorted, func(i, j int) bool {
+		for k := range sorted[i] {
+			if sorted[i][k] != sorted[j][k] {
+				return sorted[i][k] < sorted[j][k]
 			}
 		}
 		return false
 	})
+	ds.Vectors = sorted
+	ds.adapted = nil
 }
 
 // Reduce reduces the size of this data set,
@@ -85,5 +157,32 @@ func (ds *DataSet) Reduce(newLen int) {
 			vectors[i] = ds.Vectors[(left+right)>>1]
 		}
 		ds.Vectors = vectors
+		ds.adapted = nil
+	}
+}
+
+// VectorSource is a stream of data vectors, for example a file reader.
+type VectorSource interface {
+	// Next returns the next data vector from the source,
+	// or ErrNoDataLeft if the source is exhausted.
+	Next() (DataVector, error)
+}
+
+// ReadDataSet reads all the data vectors from the given source
+// into a new data set. Vectors which don't fit the data set
+// (empty or of different width) are skipped and reported by *LoadReport
+// returned along with the data set, see LoadReport.
+func ReadDataSet(src VectorSource) (*DataSet, error) {
+	ds := &DataSet{}
+	report := &LoadReport{}
+	for row := 1; ; row++ {
+		vector, err := src.Next()
+		if err == ErrNoDataLeft {
+			return ds, report.result()
+		}
+		if err != nil {
+			return nil, err
+		}
+		report.addRow(ds, row, vector)
 	}
 }
@@ -1,6 +1,7 @@
 package som_test
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/voievodin/self-organizing-map/som"
@@ -30,3 +31,39 @@ func assertEq(t *testing.T, a, b interface{}) {
 		t.Fatalf("Expected elements to be equals, but %T% v != %T %v", a, a, b, b)
 	}
 }
+
+func TestDataSetAdaptsVectorsOnceWithoutModifyingThem(t *testing.T) {
+	dataSet := &som.DataSet{Vectors: []som.DataVector{{0}, {5}, {10}}}
+	adaptations := 0
+	dataSet.SetAdapter(som.DataAdapterFunc(func(vector []float64) []float64 {
+		adaptations++
+		return som.NewScalingDataAdapter([]float64{0}, []float64{10}).Adapt(vector)
+	}))
+
+	selector := &som.SequentialSelector{}
+	for epoch := 0; epoch < 3; epoch++ {
+		selector.Init(dataSet)
+		for i := 0; i < dataSet.Len(); i++ {
+			vector, _ := selector.Next()
+			assertEq(t, vector[0], float64(i)*0.5)
+		}
+	}
+
+	assertEq(t, adaptations, 3)
+	assertEq(t, dataSet.Vectors[1][0], 5.0)
+}
+
+func TestDataSetRejectsEmptyAndUnequalWidthVectors(t *testing.T) {
+	dataSet := &som.DataSet{}
+
+	if err := dataSet.AddRaw(); !errors.Is(err, som.ErrEmptyVector) {
+		t.Fatalf("Expected ErrEmptyVector, got %v", err)
+	}
+	if err := dataSet.AddRaw(1, 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := dataSet.AddRaw(1, 2, 3); !errors.Is(err, som.ErrWidthMismatch) {
+		t.Fatalf("Expected ErrWidthMismatch, got %v", err)
+	}
+	assertEq(t, dataSet.Len(), 1)
+}
@@ -0,0 +1,220 @@
+// Package distributed trains batch maps across machines, for the data sets
+// which don't fit a single node. Each worker holds a partition of the data
+// set and computes the batch sums of its partition for the codebook it
+// receives (see som.SOM.AccumulateBatch), the coordinator merges the sums
+// of all the workers, updates the codebook and broadcasts it to the workers
+// for the next epoch. So the distributed training gives the same map
+// as som.SOM.LearnBatch over the whole data set.
+//
+// Workers and the coordinator talk over gRPC: serve a worker with Serve and
+// connect the coordinator to it with grpc.NewClient. The messages are encoded
+// by encoding/gob, so the service needs no generated code. Pass TLS transport
+// credentials, see credentials.NewTLS, requiring client certificates on the
+// workers, anyone who reaches the port of a worker may otherwise make it
+// compute over its partition and read the sums, so the port of a worker
+// served with insecure credentials must not be exposed beyond the trusted
+// network of the cluster. The codebooks of large maps exceed the default
+// message size limit of gRPC, raise it by grpc.MaxRecvMsgSize on the workers
+// and grpc.MaxCallRecvMsgSize on the coordinator.
+package distributed
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+const (
+	// serviceName is the name of the gRPC service of workers.
+	serviceName = "som.distributed.Worker"
+
+	// sumsMethod is the full name of the method computing the sums.
+	sumsMethod = "/" + serviceName + "/Sums"
+
+	// codecName is the content subtype of the messages, see gobCodec.
+	codecName = "som-gob"
+)
+
+// ErrBadReply is returned when a worker replies with the sums
+// which don't fit the map, e.g. a worker of another map.
+var ErrBadReply = errors.New("malformed worker reply")
+
+func init() {
+	encoding.RegisterCodec(gobCodec{})
+}
+
+// gobCodec encodes the messages of the service by encoding/gob.
+type gobCodec struct{}
+
+func (gobCodec) Marshal(v interface{}) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	err := gob.NewEncoder(buf).Encode(v)
+	return buf.Bytes(), err
+}
+
+func (gobCodec) Unmarshal(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+func (gobCodec) Name() string {
+	return codecName
+}
+
+// SumsRequest is the request of the coordinator to a worker.
+type SumsRequest struct {
+	// Codebook is the codebook of the epoch, see som.SOM.CopyWeights.
+	Codebook [][][]float64
+}
+
+// Service is the service of workers, which Worker implements.
+type Service interface {
+	// Sums computes the batch sums for the codebook of the request.
+	Sums(req *SumsRequest, reply *som.BatchSums) error
+}
+
+// Worker computes batch sums of its partition of the data set.
+type Worker struct {
+	// Set is the partition of the data set.
+	Set *som.DataSet
+
+	// Map is the map whose codebook is replaced by the coordinator's
+	// one on each request, it defines the adapter, the distance function
+	// and the tie breaker, som.New is enough for the defaults.
+	Map *som.SOM
+
+	mu sync.Mutex
+}
+
+// Sums loads the requested codebook and computes the batch sums of the partition.
+// It's safe for concurrent use.
+func (w *Worker) Sums(req *SumsRequest, reply *som.BatchSums) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.Map.LoadCodebook(req.Codebook); err != nil {
+		return err
+	}
+	sums, err := w.Map.AccumulateBatch(w.Set)
+	if err != nil {
+		return err
+	}
+	*reply = *sums
+	return nil
+}
+
+// Serve serves the service of the worker over gRPC on the listener until it
+// fails or the listener is closed. The options configure the server, e.g.
+// its transport credentials, see the package doc.
+func Serve(listener net.Listener, worker Service, opts ...grpc.ServerOption) error {
+	server := grpc.NewServer(opts...)
+	server.RegisterService(&serviceDesc, worker)
+	return server.Serve(listener)
+}
+
+// serviceDesc describes the service of workers to gRPC.
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: serviceName,
+	HandlerType: (*Service)(nil),
+	Methods: []grpc.MethodDesc{{
+		MethodName: "Sums",
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			req := &SumsRequest{}
+			if err := dec(req); err != nil {
+				return nil, err
+			}
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				reply := &som.BatchSums{}
+				return reply, srv.(Service).Sums(req.(*SumsRequest), reply)
+			}
+			if interceptor == nil {
+				return handler(ctx, req)
+			}
+			return interceptor(ctx, req, &grpc.UnaryServerInfo{Server: srv, FullMethod: sumsMethod}, handler)
+		},
+	}},
+}
+
+// Coordinator runs batch training over the workers.
+type Coordinator struct {
+	// Workers are the connections to the workers, e.g. created by grpc.NewClient.
+	Workers []grpc.ClientConnInterface
+}
+
+// Train trains the map by the given number of batch epochs over the workers,
+// starting from its codebook, so the map must be initialized, e.g. by
+// som.SOM.LoadCodebook. Each epoch waits for the sums of all the workers, the
+// context bounds the whole training, so cancel it or set its deadline to stop
+// waiting for a hung or unreachable worker, then the error of the context is
+// returned. Returns som.ErrInvalidConfig if there are no workers,
+// som.ErrNotTrained if the map is not initialized, the error of the first
+// failed worker, and ErrBadReply if a worker replies with the sums which
+// don't fit the map.
+func (c *Coordinator) Train(ctx context.Context, sm *som.SOM, epochs int) error {
+	if len(c.Workers) == 0 {
+		return fmt.Errorf("%w: no workers", som.ErrInvalidConfig)
+	}
+	if !sm.IsTrained() {
+		return fmt.Errorf("%w: the coordinator needs initialized weights", som.ErrNotTrained)
+	}
+	for epoch := 0; epoch < epochs; epoch++ {
+		sums, err := c.sums(ctx, &SumsRequest{Codebook: sm.CopyWeights(nil)}, sm.Len(), len(sm.Neurons[0][0].Weights))
+		if err != nil {
+			return fmt.Errorf("epoch %d: %w", epoch+1, err)
+		}
+		if err := sm.UpdateBatch(sums, epoch, epochs); err != nil {
+			return err
+		}
+		sm.Monitor.ItCompleted(epoch+1, epochs, sm)
+	}
+	return nil
+}
+
+// sums broadcasts the request to the workers and merges their sums,
+// which must be of the given number of neurons and width. The requests
+// to the other workers are cancelled once a worker fails.
+func (c *Coordinator) sums(ctx context.Context, req *SumsRequest, neurons, width int) (*som.BatchSums, error) {
+	callCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	replies := make([]som.BatchSums, len(c.Workers))
+	var failed error
+	once := sync.Once{}
+	wg := sync.WaitGroup{}
+	for i, conn := range c.Workers {
+		wg.Add(1)
+		go func(i int, conn grpc.ClientConnInterface) {
+			defer wg.Done()
+			if err := conn.Invoke(callCtx, sumsMethod, req, &replies[i], grpc.CallContentSubtype(codecName)); err != nil {
+				once.Do(func() {
+					failed = fmt.Errorf("worker %d: %w", i, err)
+					cancel()
+				})
+			}
+		}(i, conn)
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if failed != nil {
+		return nil, failed
+	}
+	merged := &replies[0]
+	if err := merged.Check(neurons, width); err != nil {
+		return nil, fmt.Errorf("worker 0: %w: %v", ErrBadReply, err)
+	}
+	for i := 1; i < len(replies); i++ {
+		if err := merged.Add(&replies[i]); err != nil {
+			return nil, fmt.Errorf("worker %d: %w: %v", i, ErrBadReply, err)
+		}
+	}
+	return merged, nil
+}
@@ -0,0 +1,170 @@
+package distributed_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/voievodin/self-organizing-map/som"
+	"github.com/voievodin/self-organizing-map/som/distributed"
+)
+
+func initialWeights() [][][]float64 {
+	weights := make([][][]float64, 3)
+	for i := range weights {
+		weights[i] = make([][]float64, 3)
+		for j := range weights[i] {
+			weights[i][j] = []float64{float64(i) / 3, float64(j) / 3}
+		}
+	}
+	return weights
+}
+
+func newMap() *som.SOM {
+	sm := som.New(3, 3)
+	sm.Influence = &som.GaussianExpDecayInfluenceFunc{InitialWidth: 1.5, MinWidth: 0.5}
+	sm.TieBreaker = &som.LowestIndexTieBreaker{}
+	return sm
+}
+
+// serve serves the service in memory and returns the connection to it.
+func serve(t *testing.T, service distributed.Service) *grpc.ClientConn {
+	listener := bufconn.Listen(1 << 20)
+	go distributed.Serve(listener, service)
+	conn, err := grpc.NewClient("passthrough:///worker",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+		listener.Close()
+	})
+	return conn
+}
+
+func connect(t *testing.T, set *som.DataSet) *grpc.ClientConn {
+	return serve(t, &distributed.Worker{Set: set, Map: newMap()})
+}
+
+func TestCoordinatorMatchesLearnBatch(t *testing.T) {
+	ds := &som.DataSet{}
+	for i := 0; i < 60; i++ {
+		ds.AddRaw(float64(i%7)/6, float64(i%5)/4)
+	}
+
+	local := newMap()
+	local.Initializer = &som.ProvidedWeightsInitializer{Weights: initialWeights()}
+	if err := local.LearnBatch(ds, 4); err != nil {
+		t.Fatal(err)
+	}
+
+	coordinator := &distributed.Coordinator{Workers: []grpc.ClientConnInterface{
+		connect(t, &som.DataSet{Vectors: ds.Vectors[:25]}),
+		connect(t, &som.DataSet{Vectors: ds.Vectors[25:]}),
+	}}
+	remote := newMap()
+	if err := remote.LoadCodebook(initialWeights()); err != nil {
+		t.Fatal(err)
+	}
+	if err := coordinator.Train(context.Background(), remote, 4); err != nil {
+		t.Fatal(err)
+	}
+
+	for x := range local.Neurons {
+		for y := range local.Neurons[x] {
+			for k, w := range local.Neurons[x][y].Weights {
+				if math.Abs(w-remote.Neurons[x][y].Weights[k]) > 1e-9 {
+					t.Fatalf("Weights of (%d, %d) differ: %v != %v", x, y, local.Neurons[x][y].Weights, remote.Neurons[x][y].Weights)
+				}
+			}
+		}
+	}
+}
+
+func TestCoordinatorReportsWorkerErrors(t *testing.T) {
+	coordinator := &distributed.Coordinator{Workers: []grpc.ClientConnInterface{
+		connect(t, &som.DataSet{Vectors: []som.DataVector{{1, 2, 3}}}),
+	}}
+	sm := newMap()
+	if err := sm.LoadCodebook(initialWeights()); err != nil {
+		t.Fatal(err)
+	}
+	if err := coordinator.Train(context.Background(), sm, 1); err == nil {
+		t.Fatal("Expected the width mismatch of the worker to fail training")
+	}
+}
+
+// malformedWorker replies with the sums of a map of a single neuron.
+type malformedWorker struct{}
+
+func (w *malformedWorker) Sums(req *distributed.SumsRequest, reply *som.BatchSums) error {
+	*reply = som.BatchSums{Sums: [][]float64{{1, 1}}, Counts: []float64{1}}
+	return nil
+}
+
+func TestCoordinatorRejectsMalformedReplies(t *testing.T) {
+	ds := &som.DataSet{Vectors: []som.DataVector{{0, 0}, {1, 1}}}
+	coordinator := &distributed.Coordinator{Workers: []grpc.ClientConnInterface{connect(t, ds), serve(t, &malformedWorker{})}}
+	sm := newMap()
+	if err := sm.LoadCodebook(initialWeights()); err != nil {
+		t.Fatal(err)
+	}
+	if err := coordinator.Train(context.Background(), sm, 1); !errors.Is(err, distributed.ErrBadReply) {
+		t.Fatalf("Expected ErrBadReply for the malformed reply, got %v", err)
+	}
+}
+
+// hungWorker never replies until it's released.
+type hungWorker struct {
+	released chan struct{}
+}
+
+func (w *hungWorker) Sums(req *distributed.SumsRequest, reply *som.BatchSums) error {
+	<-w.released
+	return errors.New("released")
+}
+
+func TestCoordinatorStopsWaitingForHungWorker(t *testing.T) {
+	ds := &som.DataSet{Vectors: []som.DataVector{{0, 0}, {1, 1}}}
+	hung := &hungWorker{released: make(chan struct{})}
+	coordinator := &distributed.Coordinator{Workers: []grpc.ClientConnInterface{connect(t, ds), serve(t, hung)}}
+	t.Cleanup(func() { close(hung.released) })
+	sm := newMap()
+	if err := sm.LoadCodebook(initialWeights()); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := coordinator.Train(ctx, sm, 1); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestWorkerDoesNotModifyPartition(t *testing.T) {
+	ds := &som.DataSet{Vectors: []som.DataVector{{0, 0}, {5, 5}, {10, 10}}}
+	worker := newMap()
+	worker.InDataAdapter = som.NewScalingDataAdapter([]float64{0, 0}, []float64{10, 10})
+	coordinator := &distributed.Coordinator{Workers: []grpc.ClientConnInterface{serve(t, &distributed.Worker{Set: ds, Map: worker})}}
+	sm := newMap()
+	if err := sm.LoadCodebook(initialWeights()); err != nil {
+		t.Fatal(err)
+	}
+	if err := coordinator.Train(context.Background(), sm, 3); err != nil {
+		t.Fatal(err)
+	}
+	for i, expected := range []float64{0, 5, 10} {
+		if v := ds.At(i)[0]; v != expected {
+			t.Fatalf("Expected vector %d to stay %v, got %v", i, expected, v)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package som
+
+import (
+	"math"
+	"sync"
+)
+
+// DriftEvent is emitted by OnlineTrainer when the distribution of
+// the recent BMUs diverges from the reference one, see DriftDetector.
+type DriftEvent struct {
+	// It is the iteration which revealed the drift.
+	It int
+
+	// Divergence is the Jensen-Shannon divergence of the distributions,
+	// which exceeds the Threshold.
+	Divergence, Threshold float64
+}
+
+func (e *DriftEvent) EventName() string { return "drift" }
+
+// DriftDetector raises an alarm on concept drift in streaming data: it keeps
+// the BMUs of the last Window iterations and compares their distribution over
+// the neurons to the reference distribution. When the Jensen-Shannon divergence
+// (in bits, within [0, 1]) of the two exceeds Threshold, the detector emits
+// DriftEvent and logs a warning, then stays quiet until the divergence
+// falls below Threshold again. Set it to OnlineTrainer.Drift to enable it.
+type DriftDetector struct {
+	// Reference[x][y] is the number of training vectors mapped to the
+	// neuron at (x, y), e.g. quality.HitMap of the training data, negative
+	// values (masked neurons) are ignored. If nil, the BMUs of the first
+	// Window iterations become the reference.
+	Reference [][]int
+
+	// Window is the number of the recent iterations, <= 0 means
+	// 10 times the number of neurons.
+	Window int
+
+	// Threshold is the divergence which raises the alarm, <= 0 means 0.1.
+	Threshold float64
+
+	mu         sync.Mutex
+	reference  []float64
+	recent     []int // BMU indices of the window
+	counts     []int
+	seen       int
+	divergence float64
+	alarmed    bool
+	alarms     int
+}
+
+// Divergence returns the last measured divergence,
+// 0 until the window is full. It's safe for concurrent use.
+func (d *DriftDetector) Divergence() float64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.divergence
+}
+
+// Alarms returns the number of raised alarms. It's safe for concurrent use.
+func (d *DriftDetector) Alarms() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.alarms
+}
+
+// observe records the iteration it whose BMU is the neuron.
+func (d *DriftDetector) observe(som *SOM, it int, bmu *Neuron) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.recent == nil {
+		window := d.Window
+		if window <= 0 {
+			window = 10 * som.Len()
+		}
+		d.recent = make([]int, window)
+		d.counts = make([]int, som.Len())
+		if d.Reference != nil {
+			d.reference = make([]float64, som.Len())
+			for x := range d.Reference {
+				for y, hits := range d.Reference[x] {
+					if hits > 0 {
+						d.reference[som.Index(x, y)] = float64(hits)
+					}
+				}
+			}
+		}
+	}
+
+	i := d.seen % len(d.recent)
+	if d.seen >= len(d.recent) {
+		d.counts[d.recent[i]]--
+	}
+	d.recent[i] = som.Index(bmu.X, bmu.Y)
+	d.counts[d.recent[i]]++
+	d.seen++
+	if d.seen < len(d.recent) {
+		return
+	}
+	if d.reference == nil {
+		d.reference = make([]float64, len(d.counts))
+		for k, n := range d.counts {
+			d.reference[k] = float64(n)
+		}
+		return
+	}
+	// comparing the distributions costs as much as a few iterations,
+	// so it's done a few times per window
+	if step := len(d.recent) / 10; step > 1 && d.seen%step != 0 {
+		return
+	}
+
+	threshold := d.Threshold
+	if threshold <= 0 {
+		threshold = 0.1
+	}
+	d.divergence = jensenShannon(d.reference, d.counts)
+	switch {
+	case d.divergence > threshold && !d.alarmed:
+		d.alarmed = true
+		d.alarms++
+		som.log(LogWarn, "concept drift", "iteration", it+1, "divergence", d.divergence, "threshold", threshold)
+		if som.listening() {
+			som.Events.OnEvent(&DriftEvent{It: it + 1, Divergence: d.divergence, Threshold: threshold})
+		}
+	case d.divergence <= threshold:
+		d.alarmed = false
+	}
+}
+
+// jensenShannon returns the Jensen-Shannon divergence in bits
+// of the distributions given by the counts.
+func jensenShannon(p []float64, q []int) float64 {
+	var pSum, qSum float64
+	for k := range p {
+		pSum += p[k]
+		qSum += float64(q[k])
+	}
+	if pSum == 0 || qSum == 0 {
+		return 0
+	}
+	divergence := 0.0
+	for k := range p {
+		pk, qk := p[k]/pSum, float64(q[k])/qSum
+		m := (pk + qk) / 2
+		if pk > 0 {
+			divergence += pk * math.Log2(pk/m) / 2
+		}
+		if qk > 0 {
+			divergence += qk * math.Log2(qk/m) / 2
+		}
+	}
+	return divergence
+}
@@ -0,0 +1,71 @@
+package som_test
+
+import (
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+func TestDriftDetectorRaisesAlarms(t *testing.T) {
+	sm := som.New(1, 2)
+	if err := sm.LoadCodebook([][][]float64{{{0}, {10}}}); err != nil {
+		t.Fatal(err)
+	}
+	var events []*som.DriftEvent
+	sm.Events = som.EventListenerFunc(func(event som.Event) {
+		if drift, ok := event.(*som.DriftEvent); ok {
+			events = append(events, drift)
+		}
+	})
+	drift := &som.DriftDetector{Reference: [][]int{{5, 5}}, Window: 10, Threshold: 0.2}
+	trainer := &som.OnlineTrainer{SOM: sm, Drift: drift}
+	learn := func(values ...float64) {
+		for _, v := range values {
+			if err := trainer.Learn(som.DataVector{v}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	for i := 0; i < 10; i++ {
+		learn(0, 10)
+	}
+	assertEq(t, 0.0, drift.Divergence())
+
+	// the data moves to the second neuron
+	for i := 0; i < 10; i++ {
+		learn(10)
+	}
+	if len(events) != 1 || events[0].It != 29 {
+		t.Fatalf("Expected an alarm at iteration 29, got %+v", *events[0])
+	}
+	if d := drift.Divergence(); d < 0.3 || d > 0.32 {
+		t.Fatalf("Unexpected divergence %v", d)
+	}
+
+	// the alarm is re-armed once the data returns
+	for i := 0; i < 10; i++ {
+		learn(0, 10)
+	}
+	for i := 0; i < 10; i++ {
+		learn(0)
+	}
+	assertEq(t, 2, drift.Alarms())
+}
+
+func TestDriftDetectorLearnsReference(t *testing.T) {
+	sm := som.New(1, 2)
+	if err := sm.LoadCodebook([][][]float64{{{0}, {10}}}); err != nil {
+		t.Fatal(err)
+	}
+	drift := &som.DriftDetector{Window: 4}
+	trainer := &som.OnlineTrainer{SOM: sm, Drift: drift}
+	for _, v := range []float64{0, 10, 0, 10, 0, 10, 0, 10} {
+		trainer.Learn(som.DataVector{v})
+	}
+	assertEq(t, 0.0, drift.Divergence())
+	for i := 0; i < 4; i++ {
+		trainer.Learn(som.DataVector{0})
+	}
+	assertEq(t, 1, drift.Alarms())
+}
@@ -0,0 +1,140 @@
+package som
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// embedNeighbours is the number of the closest neurons whose positions
+// are interpolated by Embed and MapInterpolated.
+const embedNeighbours = 3
+
+// MapPoint is a continuous position on the map grid,
+// the neuron at (x, y) is at the point (x, y).
+type MapPoint struct {
+	X, Y float64
+}
+
+// Embed maps the vectors of the set to continuous 2-D coordinates, so
+// the map can be used for dimensionality reduction like t-SNE or UMAP.
+// The position of a vector is the position of its BMU refined by
+// interpolation among the 3 closest neurons, see MapInterpolated.
+// Returns ErrWidthMismatch for the first vector which doesn't fit the weights,
+// ErrNonFinite for the first vector at NaN distance from a neuron and
+// ErrInvalidConfig for the first vector at no finite distance from the
+// neurons, e.g. if all of them are masked.
+func (m *Model) Embed(set *DataSet) ([]MapPoint, error) {
+	points := make([]MapPoint, set.Len())
+	field := m.som.borrowField()
+	defer releaseField(field)
+	for i := range points {
+		distances, err := m.distances(set.At(i), *field)
+		if err != nil {
+			return nil, fmt.Errorf("vector %d: %w", i, err)
+		}
+		if points[i], err = m.interpolatePosition(distances, embedNeighbours); err != nil {
+			return nil, fmt.Errorf("vector %d: %w", i, err)
+		}
+	}
+	return points, nil
+}
+
+// MapInterpolated returns the position of the vector on the map with
+// sub-cell precision, which is useful for trajectories and embeddings
+// that shouldn't be quantized to neurons, interpolating among the 3 closest
+// neurons, see MapInterpolatedK. It panics like SOM.Test does with the
+// errors MapInterpolatedK returns, e.g. ErrWidthMismatch if the vector
+// doesn't fit the weights.
+func (m *Model) MapInterpolated(vector DataVector) (fx, fy float64) {
+	fx, fy, err := m.MapInterpolatedK(vector, embedNeighbours)
+	if err != nil {
+		panic(err)
+	}
+	return fx, fy
+}
+
+// MapInterpolatedK returns the position of the vector on the map like
+// MapInterpolated does, but interpolating among the given number of neurons.
+// The position is the average of the positions of the k neurons closest to
+// the vector weighted by 1/d², where d is the distance from the neuron to
+// the vector, so k == 1 gives the position of the BMU. The positions of the
+// neurons across the edges connected by Topology are taken as their images
+// closest to the BMU, and the result is wrapped into the grid.
+// Returns ErrInvalidConfig if k is not positive or no neuron is at a finite
+// distance from the vector, e.g. if all of them are masked, ErrNonFinite if
+// the vector is at NaN distance from a neuron and ErrWidthMismatch if the
+// vector doesn't fit the weights.
+func (m *Model) MapInterpolatedK(vector DataVector, k int) (fx, fy float64, err error) {
+	if k <= 0 {
+		return 0, 0, fmt.Errorf("%w: k must be positive, got %d", ErrInvalidConfig, k)
+	}
+	field := m.som.borrowField()
+	defer releaseField(field)
+	distances, err := m.distances(vector, *field)
+	if err != nil {
+		return 0, 0, err
+	}
+	p, err := m.interpolatePosition(distances, k)
+	if err != nil {
+		return 0, 0, err
+	}
+	return p.X, p.Y, nil
+}
+
+// interpolatePosition returns the position of the vector given its
+// distances to the neurons, see MapInterpolatedK, the position of
+// a neuron at zero distance is returned as is. Returns ErrNonFinite if
+// any distance is NaN and ErrInvalidConfig if none of them is finite.
+func (m *Model) interpolatePosition(distances DistanceField, k int) (MapPoint, error) {
+	type neuron struct {
+		x, y int
+		d    float64
+	}
+	var closest []neuron
+	for x := range distances {
+		for y, d := range distances[x] {
+			if math.IsNaN(d) {
+				return MapPoint{}, fmt.Errorf("%w: NaN distance to the neuron (%d, %d)", ErrNonFinite, x, y)
+			}
+			if !math.IsInf(d, 1) {
+				closest = append(closest, neuron{x, y, d})
+			}
+		}
+	}
+	sort.SliceStable(closest, func(i, j int) bool { return closest[i].d < closest[j].d })
+	if len(closest) > k {
+		closest = closest[:k]
+	}
+	if len(closest) == 0 {
+		return MapPoint{}, fmt.Errorf("%w: no neuron is at a finite distance from the vector", ErrInvalidConfig)
+	}
+	bmu := closest[0]
+	if bmu.d == 0 {
+		return MapPoint{X: float64(bmu.x), Y: float64(bmu.y)}, nil
+	}
+
+	xLen, yLen := m.Dims()
+	var sumX, sumY, weights float64
+	for _, n := range closest {
+		x, y := m.som.Topology.Closest(n.x, n.y, bmu.x, bmu.y, xLen, yLen)
+		w := 1 / (n.d * n.d)
+		sumX += w * float64(x)
+		sumY += w * float64(y)
+		weights += w
+	}
+	return MapPoint{X: wrap(sumX/weights, xLen), Y: wrap(sumY/weights, yLen)}, nil
+}
+
+// wrap returns v wrapped into [-0.5, n-0.5), the span of n neurons,
+// which is only needed for the positions across the connected edges.
+func wrap(v float64, n int) float64 {
+	if v >= -0.5 && v < float64(n)-0.5 {
+		return v
+	}
+	v = math.Mod(v+0.5, float64(n))
+	if v < 0 {
+		v += float64(n)
+	}
+	return v - 0.5
+}
@@ -0,0 +1,120 @@
+package som_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+	"github.com/voievodin/self-organizing-map/som/internal/somtest"
+)
+
+func TestEmbedInterpolatesBetweenClosestNeurons(t *testing.T) {
+	model := somtest.LineModel(t, 0, 10, 20)
+	points, err := model.Embed(&som.DataSet{Vectors: []som.DataVector{{10}, {5}, {7}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEq(t, points[0], som.MapPoint{X: 0, Y: 1})
+	if points[1].X != 0 || points[1].Y <= 0.5 || points[1].Y >= 1 {
+		t.Fatalf("Expected point between the neurons (0, 0) and (0, 1), got %v", points[1])
+	}
+	if points[2].Y <= points[1].Y {
+		t.Fatalf("Expected closer vector to be closer to (0, 1), got %v and %v", points[1], points[2])
+	}
+
+	if _, err := model.Embed(&som.DataSet{Vectors: []som.DataVector{{1, 2}}}); !errors.Is(err, som.ErrWidthMismatch) {
+		t.Fatalf("Expected ErrWidthMismatch, got %v", err)
+	}
+}
+
+func TestEmbedInterpolatesAcrossConnectedEdges(t *testing.T) {
+	sm := som.New(1, 5)
+	if err := sm.LoadCodebook([][][]float64{{{0}, {10}, {20}, {15}, {5}}}); err != nil {
+		t.Fatal(err)
+	}
+	sm.Topology = &som.TorusTopology{}
+	model, err := sm.Model()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the BMU (0, 4) is pulled towards its neighbour (0, 0) across the edge
+	points, err := model.Embed(&som.DataSet{Vectors: []som.DataVector{{3}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if points[0].Y <= 4 || points[0].Y >= 4.5 {
+		t.Fatalf("Expected point between 4 and 4.5, got %v", points[0])
+	}
+}
+
+func TestEmbedRejectsVectorsWithoutFiniteDistances(t *testing.T) {
+	model := somtest.LineModel(t, 0, 10, 20)
+	if _, err := model.Embed(&som.DataSet{Vectors: []som.DataVector{{5}, {math.NaN()}}}); !errors.Is(err, som.ErrNonFinite) {
+		t.Fatalf("Expected ErrNonFinite, got %v", err)
+	}
+	if _, err := model.Embed(&som.DataSet{Vectors: []som.DataVector{{5}, {math.Inf(1)}}}); !errors.Is(err, som.ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig, got %v", err)
+	}
+}
+
+func TestMapInterpolated(t *testing.T) {
+	model := somtest.LineModel(t, 0, 10, 20)
+	x, y, err := model.MapInterpolatedK(som.DataVector{4}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEq(t, x, 0.0)
+	assertEq(t, y, 0.0)
+
+	// weights 1/16 and 1/36
+	_, y, _ = model.MapInterpolatedK(som.DataVector{4}, 2)
+	if math.Abs(y-(1.0/36)/(1.0/16+1.0/36)) > 1e-12 {
+		t.Fatalf("Unexpected interpolated position %f", y)
+	}
+
+	// weights 1/16, 1/36 and 1/256
+	x, y = model.MapInterpolated(som.DataVector{4})
+	assertEq(t, x, 0.0)
+	if expected := (1.0/36 + 2.0/256) / (1.0/16 + 1.0/36 + 1.0/256); math.Abs(y-expected) > 1e-12 {
+		t.Fatalf("Expected interpolated position %f, got %f", expected, y)
+	}
+
+	if _, _, err := model.MapInterpolatedK(som.DataVector{4}, 0); !errors.Is(err, som.ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig, got %v", err)
+	}
+}
+
+func TestMapInterpolatedWithoutFiniteDistances(t *testing.T) {
+	model := somtest.LineModel(t, 0, 10, 20)
+	if _, _, err := model.MapInterpolatedK(som.DataVector{math.NaN()}, 2); !errors.Is(err, som.ErrNonFinite) {
+		t.Fatalf("Expected ErrNonFinite for NaN vector, got %v", err)
+	}
+	if _, _, err := model.MapInterpolatedK(som.DataVector{math.Inf(-1)}, 2); !errors.Is(err, som.ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig for infinite vector, got %v", err)
+	}
+
+	sm := som.New(1, 3)
+	if err := sm.LoadCodebook([][][]float64{{{0}, {10}, {20}}}); err != nil {
+		t.Fatal(err)
+	}
+	sm.Mask = [][]bool{{true, true, true}}
+	masked, err := sm.Model()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := masked.MapInterpolatedK(som.DataVector{5}, 2); !errors.Is(err, som.ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig for all-masked model, got %v", err)
+	}
+}
+
+func TestMapInterpolatedPanicsOnWidthMismatch(t *testing.T) {
+	model := somtest.LineModel(t, 0, 10, 20)
+	defer func() {
+		if err, ok := recover().(error); !ok || !errors.Is(err, som.ErrWidthMismatch) {
+			t.Fatalf("Expected ErrWidthMismatch panic, got %v", err)
+		}
+	}()
+	model.MapInterpolated(som.DataVector{4, 4})
+}
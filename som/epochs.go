@@ -0,0 +1,80 @@
+package som
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// EpochMetrics describe the map after a learning epoch.
+type EpochMetrics struct {
+	// Epoch is 1-based number of the epoch.
+	Epoch int
+
+	// QuantizationError and TopographicError
+	// are computed on the training data set.
+	QuantizationError, TopographicError float64
+
+	// ValidationQuantizationError and ValidationTopographicError are
+	// set by Validator, e.g. computed on a held-out data set, NaN otherwise.
+	ValidationQuantizationError, ValidationTopographicError float64
+}
+
+// TrainingHistory is the metrics history recorded by LearnEpochs.
+type TrainingHistory struct {
+	Epochs []EpochMetrics
+}
+
+// Validator is called by LearnEpochs after each epoch to fill
+// the validation metrics, returned error aborts learning.
+type Validator interface {
+	Validate(som *SOM, metrics *EpochMetrics) error
+}
+
+// HeldOutValidator computes validation metrics on the held-out data set.
+type HeldOutValidator struct {
+	Set *DataSet
+}
+
+func (v *HeldOutValidator) Validate(som *SOM, metrics *EpochMetrics) error {
+	metrics.ValidationQuantizationError = som.QuantizationError(v.Set)
+	metrics.ValidationTopographicError = som.TopographicError(v.Set)
+	return nil
+}
+
+// LearnEpochs does learning of this SOM from the given data set in epochs,
+// each epoch is a pass over the data set, i.e. data set length iterations.
+// The learning schedule spans all the epochs, so it's the same as Learn
+// with epochs*set.Len() iterations, except that the selector is initialized
+// before each epoch. After each epoch quantization and topographic errors
+// are computed on the data set and validator, if not nil, is called.
+// Returns the recorded history, which is incomplete if learning fails,
+// and ErrInvalidConfig if the data set is empty.
+func (som *SOM) LearnEpochs(set *DataSet, epochs int, validator Validator) (*TrainingHistory, error) {
+	if set.Len() == 0 {
+		return &TrainingHistory{}, fmt.Errorf("%w: data set is empty", ErrInvalidConfig)
+	}
+	iterationsNumber := epochs * set.Len()
+	som.log(LogInfo, "learning started", "iterations", iterationsNumber, "epochs", epochs, "vectors", set.Len())
+	started := time.Now()
+
+	history := &TrainingHistory{}
+	it, err := som.learn(set, iterationsNumber, set.Len(), func(epoch int) error {
+		metrics := EpochMetrics{
+			Epoch:                       epoch,
+			QuantizationError:           som.QuantizationError(set),
+			TopographicError:            som.TopographicError(set),
+			ValidationQuantizationError: math.NaN(),
+			ValidationTopographicError:  math.NaN(),
+		}
+		if validator != nil {
+			if err := validator.Validate(som, &metrics); err != nil {
+				return err
+			}
+		}
+		history.Epochs = append(history.Epochs, metrics)
+		som.log(LogDebug, "epoch completed", "epoch", epoch, "qe", metrics.QuantizationError, "te", metrics.TopographicError)
+		return nil
+	}, nil)
+	return history, som.learned(it, started, err)
+}
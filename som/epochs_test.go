@@ -0,0 +1,106 @@
+package som_test
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+func epochsDataSet() *som.DataSet {
+	ds := &som.DataSet{}
+	for i := 0; i < 20; i++ {
+		ds.AddRaw(float64(i%5)/4, float64(i%4)/3)
+	}
+	return ds
+}
+
+func TestLearnEpochsRecordsHistory(t *testing.T) {
+	sm := som.New(4, 4)
+	sm.Initializer = &som.RandWeightsInitializer{Rand: rand.New(rand.NewSource(1))}
+	sm.Restraint = &som.SimpleRestraintFunc{A: 1, B: 2}
+	sm.Influence = &som.RadiusReducingConstantInfluenceFunc{Radius: 1, MinRadius: 0.5}
+
+	iterations := 0
+	sm.Monitor = progressMonitorFunc(func(it, itNum int) {
+		iterations++
+		assertEq(t, itNum, 60)
+	})
+	history, err := sm.LearnEpochs(epochsDataSet(), 3, &som.HeldOutValidator{Set: epochsDataSet()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertEq(t, iterations, 60)
+	assertEq(t, len(history.Epochs), 3)
+	for i, epoch := range history.Epochs {
+		assertEq(t, epoch.Epoch, i+1)
+		if math.IsNaN(epoch.QuantizationError) || math.IsNaN(epoch.ValidationQuantizationError) {
+			t.Fatalf("Expected epoch metrics to be computed, got %+v", epoch)
+		}
+		// the validation set is the same as the training one
+		assertEq(t, epoch.ValidationQuantizationError, epoch.QuantizationError)
+		assertEq(t, epoch.ValidationTopographicError, epoch.TopographicError)
+	}
+	for i := 1; i < len(history.Epochs); i++ {
+		if history.Epochs[i].QuantizationError >= history.Epochs[i-1].QuantizationError {
+			t.Fatalf("Expected quantization error to decrease across epochs, got %+v", history.Epochs)
+		}
+	}
+	assertEq(t, sm.TrainingState().Iterations, 60)
+}
+
+func TestLearnEpochsDoesNotModifyDataSet(t *testing.T) {
+	ds := &som.DataSet{}
+	for i := 0; i < 10; i++ {
+		ds.AddRaw(float64(i), float64(2*i))
+	}
+	sm := som.New(3, 3)
+	sm.InDataAdapter = som.NewScalingDataAdapter([]float64{0, 0}, []float64{9, 18})
+
+	if _, err := sm.LearnEpochs(ds, 3, &som.HeldOutValidator{Set: ds}); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < ds.Len(); i++ {
+		assertEq(t, ds.At(i)[0], float64(i))
+		assertEq(t, ds.At(i)[1], float64(2*i))
+	}
+}
+
+func TestLearnEpochsRejectsEmptyDataSet(t *testing.T) {
+	sm := som.New(2, 2)
+
+	if _, err := sm.LearnEpochs(&som.DataSet{}, 3, nil); !errors.Is(err, som.ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig, got %v", err)
+	}
+}
+
+type failingValidator struct{}
+
+func (v *failingValidator) Validate(sm *som.SOM, metrics *som.EpochMetrics) error {
+	if metrics.Epoch == 2 {
+		return errors.New("overfitting")
+	}
+	return nil
+}
+
+func TestLearnEpochsStopsOnValidatorError(t *testing.T) {
+	sm := som.New(2, 2)
+
+	history, err := sm.LearnEpochs(epochsDataSet(), 5, &failingValidator{})
+
+	if err == nil || err.Error() != "overfitting" {
+		t.Fatalf("Expected validator error, got %v", err)
+	}
+	assertEq(t, len(history.Epochs), 1)
+	if !math.IsNaN(history.Epochs[0].ValidationTopographicError) {
+		t.Fatal("Expected validation metrics to be NaN")
+	}
+}
+
+type progressMonitorFunc func(it, itNum int)
+
+func (f progressMonitorFunc) ItCompleted(it, itNum int, sm *som.SOM) { f(it, itNum) }
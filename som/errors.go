@@ -0,0 +1,87 @@
+package som
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Errors callers can branch on with errors.Is. Besides these,
+// ErrNoDataLeft, ErrInvalidConfig, ErrWidthMismatch, ErrEmptyVector,
+// ErrNonFinite, ErrBadModel and ErrBadSignature are reported by the
+// corresponding parts of the package.
+var (
+	// ErrNotTrained is returned when a map is used for inference
+	// before its neurons weights are initialized.
+	ErrNotTrained = errors.New("map is not trained")
+
+	// ErrTrainingPanic is wrapped by TrainingError when learning
+	// is aborted by a panic, e.g. an index out of range in a custom component.
+	ErrTrainingPanic = errors.New("training panicked")
+
+	// ErrNotCalibrated is returned when a model is used for
+	// classification before it is calibrated with labeled data.
+	ErrNotCalibrated = errors.New("model is not calibrated")
+)
+
+// TrainingError describes the learning failure and
+// the context in which it happened.
+type TrainingError struct {
+	// It is the failed iteration within bounds [1, itNum].
+	It int
+
+	// VectorIndex is the index of the processed vector in the data set,
+	// -1 if the selector doesn't implement IndexReporter.
+	VectorIndex int
+
+	// X, Y are coordinates of the neuron which caused the failure,
+	// -1 if the failure is not related to a particular neuron.
+	X, Y int
+
+	Err error
+}
+
+func (e *TrainingError) Error() string {
+	sb := &strings.Builder{}
+	fmt.Fprintf(sb, "iteration %d", e.It)
+	if e.VectorIndex >= 0 {
+		fmt.Fprintf(sb, ", vector %d", e.VectorIndex)
+	}
+	if e.X >= 0 {
+		fmt.Fprintf(sb, ", neuron (%d, %d)", e.X, e.Y)
+	}
+	sb.WriteString(": ")
+	sb.WriteString(e.Err.Error())
+	return sb.String()
+}
+
+func (e *TrainingError) Unwrap() error { return e.Err }
+
+// trainingError creates TrainingError for the failure at the given
+// iteration (within [0, itNum)) while processing the vector.
+func (som *SOM) trainingError(it int, vector DataVector, err error) *TrainingError {
+	trainingErr := &TrainingError{It: it + 1, VectorIndex: -1, X: -1, Y: -1, Err: err}
+	if reporter, ok := som.Selector.(IndexReporter); ok && vector != nil {
+		trainingErr.VectorIndex = reporter.LastIndex()
+	}
+	return trainingErr
+}
+
+// panicError creates TrainingError for the recovered panic. The neuron is
+// found as the first one whose weights don't fit the vector,
+// which is the usual cause of index panics.
+func (som *SOM) panicError(it int, vector DataVector, recovered interface{}) *TrainingError {
+	trainingErr := som.trainingError(it, vector, fmt.Errorf("%w: %v", ErrTrainingPanic, recovered))
+	if vector == nil {
+		return trainingErr
+	}
+	for i := range som.Neurons {
+		for j, neuron := range som.Neurons[i] {
+			if neuron == nil || len(neuron.Weights) != len(vector) {
+				trainingErr.X, trainingErr.Y = i, j
+				return trainingErr
+			}
+		}
+	}
+	return trainingErr
+}
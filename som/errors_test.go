@@ -0,0 +1,54 @@
+package som_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+type brokenInitializer struct{}
+
+func (initializer *brokenInitializer) Init(set *som.DataSet, neurons [][]*som.Neuron) {
+	for i := range neurons {
+		for j := range neurons[i] {
+			neurons[i][j].Weights = make([]float64, set.Width())
+		}
+	}
+	neurons[1][0].Weights = neurons[1][0].Weights[:1]
+}
+
+func TestLearnReportsWidthMismatch(t *testing.T) {
+	sm := som.New(2, 2)
+	sm.Initializer = &som.ProvidedWeightsInitializer{Weights: [][][]float64{{{0, 0}, {0, 0}}, {{0, 0}, {0, 0}}}}
+
+	err := sm.Learn(&som.DataSet{Vectors: []som.DataVector{{1, 1}, {1, 1, 1}}}, 2)
+
+	var trainingErr *som.TrainingError
+	if !errors.As(err, &trainingErr) || !errors.Is(err, som.ErrWidthMismatch) {
+		t.Fatalf("Expected TrainingError wrapping ErrWidthMismatch, got %v", err)
+	}
+	assertEq(t, trainingErr.It, 2)
+	assertEq(t, trainingErr.VectorIndex, 1)
+	assertEq(t, trainingErr.X, -1)
+}
+
+func TestLearnRecoversPanics(t *testing.T) {
+	sm := som.New(2, 2)
+	sm.Initializer = &brokenInitializer{}
+
+	err := sm.Learn(&som.DataSet{Vectors: []som.DataVector{{1, 1}}}, 1)
+
+	var trainingErr *som.TrainingError
+	if !errors.As(err, &trainingErr) || !errors.Is(err, som.ErrTrainingPanic) {
+		t.Fatalf("Expected TrainingError wrapping ErrTrainingPanic, got %v", err)
+	}
+	assertEq(t, trainingErr.It, 1)
+	assertEq(t, trainingErr.VectorIndex, 0)
+	assertEq(t, trainingErr.X, 1)
+	assertEq(t, trainingErr.Y, 0)
+	if !strings.HasPrefix(err.Error(), "iteration 1, vector 0, neuron (1, 0): training panicked: ") {
+		t.Fatalf("Unexpected error message %q", err.Error())
+	}
+}
@@ -0,0 +1,68 @@
+package som
+
+// Event is a notification emitted by SOM while learning,
+// listeners distinguish events by their concrete types.
+type Event interface {
+	// EventName returns a short name of the event, e.g. "iteration".
+	EventName() string
+}
+
+// EventListener handles events emitted by SOM.
+type EventListener interface {
+	OnEvent(event Event)
+}
+
+// EventListenerFunc is an adapter that allows to use
+// regular functions as EventListeners.
+type EventListenerFunc func(event Event)
+
+func (f EventListenerFunc) OnEvent(event Event) { f(event) }
+
+// EventBus is an EventListener which dispatches events
+// to the subscribed listeners in subscription order.
+type EventBus struct {
+	listeners []EventListener
+}
+
+// Subscribe adds the listener to this bus.
+func (bus *EventBus) Subscribe(listener EventListener) {
+	bus.listeners = append(bus.listeners, listener)
+}
+
+func (bus *EventBus) OnEvent(event Event) {
+	for _, listener := range bus.listeners {
+		listener.OnEvent(event)
+	}
+}
+
+// NoOpEventListener is a default implementation of EventListener, does nothing.
+type NoOpEventListener struct{}
+
+func (l *NoOpEventListener) OnEvent(event Event) {}
+
+// IterationEvent is emitted by Learn after each iteration
+// and describes what the iteration has done,
+// allowing to diagnose convergence problems.
+type IterationEvent struct {
+	// It is the completed iteration within bounds [1, ItNum].
+	It, ItNum int
+
+	// LearningRate is the value of the restraint function.
+	LearningRate float64
+
+	// Radius is the effective neighbourhood radius,
+	// NaN if influence function doesn't implement RadiusReporter.
+	Radius float64
+
+	// BMUX, BMUY are coordinates of the iteration BMU.
+	BMUX, BMUY int
+
+	// BMUDistance is the distance between the input vector and BMU
+	// before the weights were fixed.
+	BMUDistance float64
+
+	// MeanWeightDelta is the mean absolute change of all the weights of all neurons.
+	MeanWeightDelta float64
+}
+
+func (e *IterationEvent) EventName() string { return "iteration" }
@@ -0,0 +1,50 @@
+package som_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+func TestLearnEmitsIterationEvents(t *testing.T) {
+	dataSet := &som.DataSet{Vectors: []som.DataVector{{1}, {0}}}
+
+	somap := som.New(1, 2)
+	somap.Influence = &som.RadiusReducingConstantInfluenceFunc{Radius: 2}
+	somap.Restraint = &som.SimpleRestraintFunc{A: 1, B: 1}
+
+	var events []*som.IterationEvent
+	somap.Events = som.EventListenerFunc(func(event som.Event) {
+		events = append(events, event.(*som.IterationEvent))
+	})
+	somap.LearnEntire(dataSet)
+
+	assertEq(t, len(events), 2)
+
+	first := events[0]
+	assertEq(t, first.It, 1)
+	assertEq(t, first.ItNum, 2)
+	assertEq(t, first.LearningRate, 1.0)
+	assertEq(t, first.Radius, 2.0)
+	assertEq(t, first.BMUDistance, 1.0)
+	// both neurons moved from 0 to 1
+	assertEq(t, first.MeanWeightDelta, 1.0)
+
+	second := events[1]
+	assertEq(t, second.LearningRate, 0.5)
+	assertEq(t, math.Round(second.Radius*1000), math.Round(2/1.5*1000))
+	assertEq(t, second.MeanWeightDelta, 0.5)
+}
+
+func TestEventBusDispatchesEventsToAllListeners(t *testing.T) {
+	received := 0
+	listener := som.EventListenerFunc(func(event som.Event) { received++ })
+
+	bus := &som.EventBus{}
+	bus.Subscribe(listener)
+	bus.Subscribe(listener)
+	bus.OnEvent(&som.IterationEvent{})
+
+	assertEq(t, received, 2)
+}
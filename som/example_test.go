@@ -0,0 +1,183 @@
+package som_test
+
+import (
+	"bytes"
+	"fmt"
+	"image/png"
+	"math"
+	"math/rand"
+	"os"
+
+	"github.com/voievodin/self-organizing-map/som"
+	"github.com/voievodin/self-organizing-map/som/quality"
+)
+
+// Clustering colors: similar colors are mapped to close neurons,
+// and the U-matrix of the map is rendered as a PNG heat map.
+func Example_colorsClustering() {
+	rng := rand.New(rand.NewSource(1))
+	colors := &som.DataSet{}
+	for i := 0; i < 500; i++ {
+		colors.Add(som.DataVector{rng.Float64(), rng.Float64(), rng.Float64()})
+	}
+
+	sm := som.New(10, 10)
+	sm.Initializer = &som.RandWeightsInitializer{Rand: rng}
+	sm.Selector = &som.RandSelector{Rand: rng}
+	sm.TieBreaker = &som.LowestIndexTieBreaker{}
+	sm.Restraint = &som.ExpRestraintFunc{InitialRate: 0.5}
+	sm.Influence = &som.GaussianExpDecayInfluenceFunc{InitialWidth: 5, MinWidth: 0.5}
+	if err := sm.Learn(colors, 5000); err != nil {
+		panic(err)
+	}
+	model, err := sm.Model()
+	if err != nil {
+		panic(err)
+	}
+
+	red, _ := model.BMU(som.DataVector{1, 0, 0})
+	darkRed, _ := model.BMU(som.DataVector{0.8, 0, 0})
+	cyan, _ := model.BMU(som.DataVector{0, 1, 1})
+	fmt.Println("red is close to dark red:", gridDistance(red, darkRed) <= 2)
+	fmt.Println("red is far from cyan:", gridDistance(red, cyan) >= 5)
+
+	buf := &bytes.Buffer{}
+	if err := png.Encode(buf, quality.RenderHeatMap(model.UMatrix(), 10)); err != nil {
+		panic(err)
+	}
+	img, _ := png.Decode(buf)
+	fmt.Println("U-matrix image:", img.Bounds().Size())
+	// Output:
+	// red is close to dark red: true
+	// red is far from cyan: true
+	// U-matrix image: (100,100)
+}
+
+func gridDistance(a, b som.GridPoint) float64 {
+	return math.Hypot(float64(a.X-b.X), float64(a.Y-b.Y))
+}
+
+// Classifying irises: the map learns the measurements, it's calibrated
+// by the species of the flowers and classifies them by their BMUs.
+func Example_iris() {
+	f, err := os.Open("testdata/golden/iris.csv")
+	if err != nil {
+		panic(err)
+	}
+	defer f.Close()
+	irises, _, err := som.ReadCSV(f, true)
+	if err != nil {
+		panic(err)
+	}
+	// the data set lists 50 flowers of each species
+	species := make([]string, irises.Len())
+	for i := range species {
+		species[i] = []string{"setosa", "versicolor", "virginica"}[i/50]
+	}
+
+	// the measurements are scaled to [0, 1], so that they are equally important
+	scaling := som.NewScalingDataAdapter([]float64{4, 2, 1, 0}, []float64{8, 4.5, 7, 2.5})
+	irises.SetAdapter(scaling)
+
+	rng := rand.New(rand.NewSource(2))
+	sm := som.New(8, 8)
+	sm.Initializer = &som.RandDataSetVectorsWeightsInitializer{Rand: rng}
+	sm.Selector = &som.RandSelector{Rand: rng}
+	sm.TieBreaker = &som.LowestIndexTieBreaker{}
+	sm.Restraint = &som.ExpRestraintFunc{InitialRate: 0.5}
+	sm.Influence = &som.GaussianExpDecayInfluenceFunc{InitialWidth: 4, MinWidth: 0.5}
+	if err := sm.Learn(irises, 3000); err != nil {
+		panic(err)
+	}
+	model, _ := sm.Model()
+	calibrated, err := model.Calibrate(irises, species)
+	if err != nil {
+		panic(err)
+	}
+
+	confusion, err := quality.Confusion(calibrated, irises, species)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println("accuracy above 90%:", confusion.Accuracy() > 0.9)
+	label, _ := calibrated.Classify(scaling.Adapt(som.DataVector{5.0, 3.4, 1.5, 0.2}))
+	fmt.Println(label)
+	// Output:
+	// accuracy above 90%: true
+	// setosa
+}
+
+// Detecting anomalies: the distance of a vector to its BMU, its quantization
+// error, is its anomaly score. Vectors scoring higher than any of the normal
+// data are anomalies.
+func Example_anomalyDetection() {
+	f, err := os.Open("testdata/golden/blobs.csv")
+	if err != nil {
+		panic(err)
+	}
+	defer f.Close()
+	normal, _, err := som.ReadCSV(f, true)
+	if err != nil {
+		panic(err)
+	}
+
+	rng := rand.New(rand.NewSource(3))
+	sm := som.New(5, 5)
+	sm.Initializer = &som.RandDataSetVectorsWeightsInitializer{Rand: rng}
+	sm.Selector = &som.RandSelector{Rand: rng}
+	sm.TieBreaker = &som.LowestIndexTieBreaker{}
+	sm.Restraint = &som.ExpRestraintFunc{InitialRate: 0.5}
+	sm.Influence = &som.GaussianExpDecayInfluenceFunc{InitialWidth: 2, MinWidth: 0.5}
+	if err := sm.Learn(normal, 2000); err != nil {
+		panic(err)
+	}
+	model, _ := sm.Model()
+
+	score := func(vector som.DataVector) float64 {
+		min := math.Inf(1)
+		for _, column := range model.Distances(vector, nil) {
+			for _, d := range column {
+				min = math.Min(min, d)
+			}
+		}
+		return min
+	}
+	threshold := 0.0
+	for _, vector := range normal.Vectors {
+		threshold = math.Max(threshold, score(vector))
+	}
+
+	for _, vector := range []som.DataVector{{0.1, -0.2}, {4, 1.2}, {8, 8}, {-3, 5}} {
+		fmt.Printf("%v anomaly: %t\n", vector, score(vector) > threshold)
+	}
+	// Output:
+	// [0.1 -0.2] anomaly: false
+	// [4 1.2] anomaly: false
+	// [8 8] anomaly: true
+	// [-3 5] anomaly: true
+}
+
+// Classification with rejection: the vectors too far from
+// the calibrated neurons are classified as Unknown.
+func ExampleModel_Classify() {
+	sm := som.New(1, 4)
+	if err := sm.LoadCodebook([][][]float64{{{0, 0}, {1, 0}, {9, 9}, {10, 9}}}); err != nil {
+		panic(err)
+	}
+	model, _ := sm.Model()
+	ds := &som.DataSet{Vectors: []som.DataVector{{0, 0.1}, {1, 0.2}, {9, 9.1}, {10, 8.9}}}
+	calibrated, err := model.Calibrate(ds, []string{"small", "small", "large", "large"})
+	if err != nil {
+		panic(err)
+	}
+	calibrated = calibrated.WithRejection(som.Rejection{MaxDistance: 2})
+
+	for _, vector := range []som.DataVector{{0.5, 0.5}, {9.5, 9}, {5, 5}} {
+		label, _ := calibrated.Classify(vector)
+		fmt.Println(vector, label)
+	}
+	// Output:
+	// [0.5 0.5] small
+	// [9.5 9] large
+	// [5 5] unknown
+}
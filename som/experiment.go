@@ -0,0 +1,517 @@
+package som
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ExperimentSpec declares a learning experiment: where the data comes from,
+// how it's adapted, the map and its components, the learning schedule
+// and where the results go. All the random components of the experiment
+// use the source seeded by Seed, so running the same spec on the same data
+// produces the same map. Specs are written in JSON, e.g.
+//
+//	{
+//	  "name": "iris",
+//	  "data": {"path": "iris.csv", "format": "csv", "header": true},
+//	  "adapters": [{"type": "scaling"}],
+//	  "map": {"x": 10, "y": 10, "topology": {"type": "torus"}},
+//	  "initializer": {"type": "rand-vectors"},
+//	  "selector": {"type": "rand"},
+//	  "restraint": {"type": "exp", "params": {"initial_rate": 0.5}},
+//	  "influence": {"type": "gaussian-exp-decay", "params": {"initial_width": 5, "min_width": 0.5}},
+//	  "epochs": 20,
+//	  "seed": 42,
+//	  "outputs": {"model": "iris.json", "codebook": "iris-codebook.csv"}
+//	}
+type ExperimentSpec struct {
+	Name string   `json:"name"`
+	Data DataSpec `json:"data"`
+
+	// Adapters are fitted on the data and applied to it in order,
+	// each one is fitted on the output of the previous one.
+	Adapters []ComponentSpec `json:"adapters,omitempty"`
+
+	Map MapSpec `json:"map"`
+
+	// Components of the map, the ones with empty type are the defaults set by New.
+	Initializer ComponentSpec `json:"initializer"`
+	Selector    ComponentSpec `json:"selector"`
+	Restraint   ComponentSpec `json:"restraint"`
+	Influence   ComponentSpec `json:"influence"`
+	Distance    ComponentSpec `json:"distance"`
+	TieBreaker  ComponentSpec `json:"tie_breaker"`
+
+	// Renormalize keeps the weights of the neurons
+	// at unit length, see SOM.Renormalize.
+	Renormalize bool `json:"renormalize,omitempty"`
+
+	// Exactly one of Iterations and Epochs must be set,
+	// Epochs means learning with LearnEpochs.
+	Iterations int `json:"iterations,omitempty"`
+	Epochs     int `json:"epochs,omitempty"`
+
+	Seed int64 `json:"seed"`
+
+	Outputs OutputsSpec `json:"outputs"`
+
+	// BaseDir is the directory relative paths of the spec are resolved
+	// against, LoadExperimentSpecFile sets it to the spec file directory.
+	BaseDir string `json:"-"`
+}
+
+// DataSpec declares the data set of an experiment.
+type DataSpec struct {
+	Path string `json:"path"`
+
+	// Format is one of csv, libsvm and idx.
+	Format string `json:"format"`
+
+	// Header means that the first CSV record is the column names.
+	Header bool `json:"header,omitempty"`
+
+	// Width is the width of LIBSVM data, see ReadLIBSVM.
+	Width int `json:"width,omitempty"`
+}
+
+// MapSpec declares the size and the topology of a map.
+type MapSpec struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+
+	// Topology is one of planar (default), torus, cylinder with param
+	// axis, 0 for x and 1 for y, graph, see GraphLattice.Describe, and sphere,
+	// see SphereLattice.Describe, whose maps must have as many neurons
+	// as the lattice has nodes.
+	Topology ComponentSpec `json:"topology"`
+}
+
+// OutputsSpec declares where the results of an experiment are written,
+// empty paths are not written.
+type OutputsSpec struct {
+	// Model is written by SaveJSON if the path has .json extension
+	// and by SaveBinary otherwise.
+	Model string `json:"model,omitempty"`
+
+	// Codebook is written by ExportCodebookCSV, the weights are mapped back
+	// to the original data space if all the adapters are invertible.
+	Codebook string `json:"codebook,omitempty"`
+
+	// Metrics are the quantization and topographic errors
+	// and the training history written as JSON.
+	Metrics string `json:"metrics,omitempty"`
+}
+
+// ComponentSpec declares a component by its type name and numeric params.
+// Initializers, restraints, influences and distances are created
+// by the factories registered with their names, e.g. RegisterInfluence,
+// so the specs may refer to custom components.
+type ComponentSpec struct {
+	Type   string `json:"type"`
+	Params Params `json:"params,omitempty"`
+
+	// Values are the vectors of the fitted state of a component
+	// saved along with a map, e.g. the feature ranges of ScalingDataAdapter,
+	// see Describer. Experiment specs use them for the topology only,
+	// e.g. for the edges of the graph.
+	Values map[string][]float64 `json:"values,omitempty"`
+}
+
+// checkParams returns an error if the component has params other than known.
+func (c ComponentSpec) checkParams(known ...string) error {
+	if err := c.Params.Check(known...); err != nil {
+		return fmt.Errorf("%s: %w", c.Type, err)
+	}
+	return nil
+}
+
+// param returns the value of the named param, or def if it's not set.
+func (c ComponentSpec) param(name string, def float64) float64 {
+	return c.Params.Get(name, def)
+}
+
+// orDefault returns the type of the component, or def if it's not set.
+func (c ComponentSpec) orDefault(def string) string {
+	if c.Type == "" {
+		return def
+	}
+	return c.Type
+}
+
+// ExperimentResult is the outcome of RunExperiment.
+type ExperimentResult struct {
+	SOM *SOM
+
+	// DataSet is the adapted data set the map learned from.
+	DataSet *DataSet
+
+	// Adapters are the fitted adapters in the order they were applied.
+	Adapters []DataAdapter
+
+	// History is recorded when the experiment learns in epochs, nil otherwise.
+	History *TrainingHistory
+
+	QuantizationError float64
+	TopographicError  float64
+
+	// LoadReport describes the rejected data rows, nil if all of them are accepted.
+	LoadReport *LoadReport
+}
+
+// LoadExperimentSpec reads the spec in JSON format, unknown fields are rejected.
+func LoadExperimentSpec(r io.Reader) (*ExperimentSpec, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	spec := &ExperimentSpec{}
+	if err := dec.Decode(spec); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+	return spec, nil
+}
+
+// LoadExperimentSpecFile reads the spec from the file, see LoadExperimentSpec.
+// Relative paths of the spec are resolved against the file directory.
+func LoadExperimentSpecFile(path string) (*ExperimentSpec, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	spec, err := LoadExperimentSpec(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	spec.BaseDir = filepath.Dir(path)
+	return spec, nil
+}
+
+// RunExperiment loads the data declared by the spec, fits and applies
+// the adapters, teaches the map and writes the outputs.
+// Malformed data rows don't fail the experiment, they are reported
+// by ExperimentResult.LoadReport.
+func RunExperiment(spec *ExperimentSpec) (*ExperimentResult, error) {
+	if spec.Map.X <= 0 || spec.Map.Y <= 0 {
+		return nil, fmt.Errorf("%w: map size must be positive, got %dx%d", ErrInvalidConfig, spec.Map.X, spec.Map.Y)
+	}
+	if (spec.Iterations > 0) == (spec.Epochs > 0) {
+		return nil, fmt.Errorf("%w: exactly one of iterations and epochs must be positive", ErrInvalidConfig)
+	}
+
+	result := &ExperimentResult{}
+	ds, err := spec.loadData()
+	if err != nil {
+		var report *LoadReport
+		if !errors.As(err, &report) {
+			return nil, err
+		}
+		result.LoadReport = report
+	}
+	if ds.Len() == 0 {
+		return nil, fmt.Errorf("%w: data set %s is empty", ErrInvalidConfig, spec.Data.Path)
+	}
+
+	rng := rand.New(rand.NewSource(spec.Seed))
+	columns := ds.Columns()
+	for _, c := range spec.Adapters {
+		adapter, err := newExperimentAdapter(c, ds)
+		if err != nil {
+			return nil, err
+		}
+		ds.SetAdapter(adapter)
+		ds = ds.Freeze().DataSet()
+		result.Adapters = append(result.Adapters, adapter)
+	}
+	result.DataSet = ds
+
+	sm, err := spec.newSOM(rng)
+	if err != nil {
+		return nil, err
+	}
+	result.SOM = sm
+	if spec.Epochs > 0 {
+		result.History, err = sm.LearnEpochs(ds, spec.Epochs, nil)
+	} else {
+		err = sm.Learn(ds, spec.Iterations)
+	}
+	if err != nil {
+		return result, err
+	}
+	result.QuantizationError = sm.QuantizationError(ds)
+	result.TopographicError = sm.TopographicError(ds)
+
+	return result, spec.writeOutputs(result, columns)
+}
+
+func (spec *ExperimentSpec) path(p string) string {
+	if filepath.IsAbs(p) || spec.BaseDir == "" {
+		return p
+	}
+	return filepath.Join(spec.BaseDir, p)
+}
+
+func (spec *ExperimentSpec) loadData() (*DataSet, error) {
+	f, err := os.Open(spec.path(spec.Data.Path))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	switch spec.Data.Format {
+	case "csv":
+		ds, _, err := ReadCSV(f, spec.Data.Header)
+		return ds, err
+	case "libsvm":
+		ds, _, err := ReadLIBSVM(f, spec.Data.Width)
+		return ds, err
+	case "idx":
+		reader, err := NewIDXReader(f)
+		if err != nil {
+			return nil, err
+		}
+		return ReadDataSet(reader)
+	default:
+		return nil, fmt.Errorf("%w: unknown data format %q", ErrInvalidConfig, spec.Data.Format)
+	}
+}
+
+func (spec *ExperimentSpec) newSOM(rng *rand.Rand) (*SOM, error) {
+	sm := New(spec.Map.X, spec.Map.Y)
+	var err error
+	if sm.Topology, err = NewTopology(spec.Map.Topology.orDefault("planar"), spec.Map.Topology.Params, spec.Map.Topology.Values); err != nil {
+		return nil, err
+	}
+	if sm.Initializer, err = NewInitializer(spec.Initializer.orDefault("zero"), spec.Initializer.Params, rng); err != nil {
+		return nil, err
+	}
+	if sm.Selector, err = newExperimentSelector(spec.Selector, rng); err != nil {
+		return nil, err
+	}
+	if sm.Restraint, err = NewRestraint(spec.Restraint.orDefault("none"), spec.Restraint.Params); err != nil {
+		return nil, err
+	}
+	if sm.Influence, err = NewInfluence(spec.Influence.orDefault("bmu-only"), spec.Influence.Params); err != nil {
+		return nil, err
+	}
+	if sm.Distance, err = NewDistance(spec.Distance.orDefault("euclidean"), spec.Distance.Params); err != nil {
+		return nil, err
+	}
+	if sm.TieBreaker, err = newExperimentTieBreaker(spec.TieBreaker, rng); err != nil {
+		return nil, err
+	}
+	sm.Renormalize = spec.Renormalize
+	return sm, nil
+}
+
+// writeOutputs writes the declared outputs, columns are the data set
+// columns before adaptation, used to name the codebook weights.
+func (spec *ExperimentSpec) writeOutputs(result *ExperimentResult, columns []Column) error {
+	if spec.Outputs.Model != "" {
+		err := writeFile(spec.path(spec.Outputs.Model), func(w io.Writer) error {
+			if strings.EqualFold(filepath.Ext(spec.Outputs.Model), ".json") {
+				return result.SOM.SaveJSON(w, Precision{})
+			}
+			return result.SOM.SaveBinary(w, Precision{})
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if spec.Outputs.Codebook != "" {
+		err := writeFile(spec.path(spec.Outputs.Codebook), func(w io.Writer) error {
+			inverse, ok := invertAdapters(result.Adapters)
+			if !ok {
+				return result.SOM.ExportCodebookCSV(w, Headers(result.DataSet.Columns()))
+			}
+			return result.SOM.ExportCodebookCSVInverse(w, Headers(columns), inverse)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if spec.Outputs.Metrics != "" {
+		metrics := struct {
+			Name              string           `json:"name"`
+			QuantizationError float64          `json:"quantization_error"`
+			TopographicError  float64          `json:"topographic_error"`
+			History           *TrainingHistory `json:"history,omitempty"`
+		}{spec.Name, result.QuantizationError, result.TopographicError, result.History}
+		if result.History != nil {
+			metrics.History = &TrainingHistory{Epochs: make([]EpochMetrics, len(result.History.Epochs))}
+			for i, epoch := range result.History.Epochs {
+				metrics.History.Epochs[i] = finiteEpochMetrics(epoch)
+			}
+		}
+		err := writeFile(spec.path(spec.Outputs.Metrics), func(w io.Writer) error {
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			return enc.Encode(metrics)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// finiteEpochMetrics replaces NaN validation metrics, which can't be
+// encoded in JSON, with zeros.
+func finiteEpochMetrics(m EpochMetrics) EpochMetrics {
+	if math.IsNaN(m.ValidationQuantizationError) {
+		m.ValidationQuantizationError = 0
+	}
+	if math.IsNaN(m.ValidationTopographicError) {
+		m.ValidationTopographicError = 0
+	}
+	return m
+}
+
+func writeFile(path string, write func(w io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// adapterChain applies adapters in order and inverts them in reverse order.
+type adapterChain []InvertibleDataAdapter
+
+func (chain adapterChain) Adapt(vector []float64) []float64 {
+	for _, adapter := range chain {
+		vector = adapter.Adapt(vector)
+	}
+	return vector
+}
+
+func (chain adapterChain) Inverse(vector []float64) []float64 {
+	for i := len(chain) - 1; i >= 0; i-- {
+		vector = chain[i].Inverse(vector)
+	}
+	return vector
+}
+
+// invertAdapters returns the adapter inverting all the given ones,
+// false if some of them are not invertible.
+func invertAdapters(adapters []DataAdapter) (InvertibleDataAdapter, bool) {
+	chain := make(adapterChain, len(adapters))
+	for i, adapter := range adapters {
+		invertible, ok := adapter.(InvertibleDataAdapter)
+		if !ok {
+			return nil, false
+		}
+		chain[i] = invertible
+	}
+	return chain, true
+}
+
+func unknownComponent(kind string, c ComponentSpec) error {
+	return fmt.Errorf("%w: unknown %s %q", ErrInvalidConfig, kind, c.Type)
+}
+
+func newExperimentAdapter(c ComponentSpec, ds *DataSet) (DataAdapter, error) {
+	switch c.Type {
+	case "scaling":
+		if err := c.checkParams(); err != nil {
+			return nil, err
+		}
+		return fitScalingDataAdapter(ds), nil
+	case "rank":
+		if err := c.checkParams("max_points"); err != nil {
+			return nil, err
+		}
+		return NewRankDataAdapter(ds, int(c.param("max_points", 0))), nil
+	case "pca-whitening", "zca-whitening":
+		if err := c.checkParams("epsilon"); err != nil {
+			return nil, err
+		}
+		method := PCAWhitening
+		if c.Type == "zca-whitening" {
+			method = ZCAWhitening
+		}
+		return NewWhiteningDataAdapter(ds, method, c.param("epsilon", 1e-9)), nil
+	case "mean-imputer", "median-imputer":
+		if err := c.checkParams(); err != nil {
+			return nil, err
+		}
+		if c.Type == "mean-imputer" {
+			return NewMeanImputer(ds), nil
+		}
+		return NewMedianImputer(ds), nil
+	case "knn-imputer":
+		if err := c.checkParams("k"); err != nil {
+			return nil, err
+		}
+		return &KNNImputer{K: int(c.param("k", 5)), Set: ds.Copy()}, nil
+	default:
+		return nil, unknownComponent("adapter", c)
+	}
+}
+
+// fitScalingDataAdapter returns the adapter scaling the data set into [0, 1],
+// columns of a single value are only shifted, NaN values are ignored.
+func fitScalingDataAdapter(ds *DataSet) *ScalingDataAdapter {
+	width := ds.Width()
+	min, max := make([]float64, width), make([]float64, width)
+	for i := range min {
+		min[i], max[i] = math.Inf(1), math.Inf(-1)
+	}
+	for _, vector := range ds.Vectors {
+		for i, v := range vector {
+			if v < min[i] {
+				min[i] = v
+			}
+			if v > max[i] {
+				max[i] = v
+			}
+		}
+	}
+	for i := range min {
+		if math.IsInf(min[i], 1) {
+			min[i], max[i] = 0, 1
+		} else if max[i] == min[i] {
+			max[i] = min[i] + 1
+		}
+	}
+	return NewScalingDataAdapter(min, max)
+}
+
+func newExperimentSelector(c ComponentSpec, rng *rand.Rand) (Selector, error) {
+	if err := c.checkParams(); err != nil {
+		return nil, err
+	}
+	switch c.Type {
+	case "", "sequential":
+		return &SequentialSelector{}, nil
+	case "rand":
+		return &RandSelector{Rand: rng}, nil
+	default:
+		return nil, unknownComponent("selector", c)
+	}
+}
+
+func newExperimentTieBreaker(c ComponentSpec, rng *rand.Rand) (TieBreaker, error) {
+	if err := c.checkParams(); err != nil {
+		return nil, err
+	}
+	switch c.Type {
+	case "", "rand":
+		return &RandTieBreaker{Rand: rng}, nil
+	case "lowest-index":
+		return &LowestIndexTieBreaker{}, nil
+	case "least-recently-won":
+		return &LeastRecentlyWonTieBreaker{}, nil
+	default:
+		return nil, unknownComponent("tie breaker", c)
+	}
+}
@@ -0,0 +1,128 @@
+package som_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+const experimentSpecJSON = `{
+  "name": "test",
+  "data": {"path": "data.csv", "format": "csv", "header": true},
+  "adapters": [{"type": "scaling"}],
+  "map": {"x": 4, "y": 3, "topology": {"type": "torus"}},
+  "initializer": {"type": "rand"},
+  "selector": {"type": "rand"},
+  "restraint": {"type": "exp", "params": {"initial_rate": 0.5}},
+  "influence": {"type": "gaussian-exp-decay", "params": {"initial_width": 2, "min_width": 0.5}},
+  "epochs": 5,
+  "seed": 7,
+  "outputs": {"model": "model.json", "codebook": "codebook.csv", "metrics": "metrics.json"}
+}`
+
+func writeExperiment(t *testing.T, spec string) string {
+	dir := t.TempDir()
+	data := "a,b\n1,10\n2,20\n3,35\n4,40\n5,52\n6,60\n"
+	if err := os.WriteFile(filepath.Join(dir, "data.csv"), []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "spec.json")
+	if err := os.WriteFile(path, []byte(spec), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func runExperiment(t *testing.T, path string) *som.ExperimentResult {
+	spec, err := som.LoadExperimentSpecFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := som.RunExperiment(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func TestRunExperimentIsReproducible(t *testing.T) {
+	path := writeExperiment(t, experimentSpecJSON)
+
+	first := runExperiment(t, path)
+	second := runExperiment(t, path)
+
+	assertEq(t, len(first.History.Epochs), 5)
+	for i := range first.SOM.Neurons {
+		for j := range first.SOM.Neurons[i] {
+			if !reflect.DeepEqual(first.SOM.Neurons[i][j].Weights, second.SOM.Neurons[i][j].Weights) {
+				t.Fatalf("Neuron (%d, %d) weights differ between runs of the same spec", i, j)
+			}
+		}
+	}
+	assertEq(t, first.QuantizationError, second.QuantizationError)
+}
+
+func TestRunExperimentWritesOutputs(t *testing.T) {
+	path := writeExperiment(t, experimentSpecJSON)
+	result := runExperiment(t, path)
+	dir := filepath.Dir(path)
+
+	f, err := os.Open(filepath.Join(dir, "model.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	loaded, err := som.LoadJSON(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkSlicesEqual(t, loaded.Neurons[1][2].Weights, result.SOM.Neurons[1][2].Weights)
+
+	codebook, err := os.ReadFile(filepath.Join(dir, "codebook.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(codebook), "x,y,a,b\n") {
+		t.Fatalf("Unexpected codebook header %q", codebook)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "metrics.json")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRunExperimentOnSphere(t *testing.T) {
+	// the 4x3 map has the 12 nodes of the sphere without subdivisions
+	spec := strings.Replace(experimentSpecJSON, `{"type": "torus"}`, `{"type": "sphere", "params": {"subdivisions": 0}}`, 1)
+	result := runExperiment(t, writeExperiment(t, spec))
+	if _, ok := result.SOM.Topology.(*som.LatticeTopology); !ok {
+		t.Fatalf("Expected LatticeTopology, got %T", result.SOM.Topology)
+	}
+}
+
+func TestRunExperimentRejectsInvalidSpecs(t *testing.T) {
+	specs := []string{
+		strings.Replace(experimentSpecJSON, `"initial_rate"`, `"rate"`, 1),
+		strings.Replace(experimentSpecJSON, `"torus"`, `"klein"`, 1),
+		strings.Replace(experimentSpecJSON, `"x": 4, "y": 3, "topology": {"type": "torus"}`, `"x": 5, "y": 3, "topology": {"type": "sphere"}`, 1),
+		strings.Replace(experimentSpecJSON, `"epochs": 5`, `"epochs": 5, "iterations": 10`, 1),
+		strings.Replace(experimentSpecJSON, `"csv"`, `"xml"`, 1),
+	}
+	for _, spec := range specs {
+		loaded, err := som.LoadExperimentSpecFile(writeExperiment(t, spec))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := som.RunExperiment(loaded); !errors.Is(err, som.ErrInvalidConfig) {
+			t.Fatalf("Expected ErrInvalidConfig, got %v", err)
+		}
+	}
+
+	if _, err := som.LoadExperimentSpec(strings.NewReader(`{"mapp": {}}`)); !errors.Is(err, som.ErrInvalidConfig) {
+		t.Fatalf("Expected unknown field to be rejected, got %v", err)
+	}
+}
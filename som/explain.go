@@ -0,0 +1,128 @@
+package som
+
+import (
+	"fmt"
+	"math"
+)
+
+// Explanation tells why a vector is mapped to its BMU, see Model.Explain.
+type Explanation struct {
+	// BMU and RunnerUp are the closest and the second closest neurons,
+	// RunnerUp is (-1, -1) if the map has a single unmasked neuron.
+	BMU, RunnerUp GridPoint
+
+	// Contributions[k] is the squared difference between the k-th feature
+	// of the adapted vector and the BMU weights, so the features with
+	// the highest contributions keep the vector away from the BMU.
+	Contributions []float64
+
+	// RunnerUpContributions are the contributions of the runner-up,
+	// nil if there's no runner-up.
+	RunnerUpContributions []float64
+}
+
+// Margins returns the per-feature differences between the contributions of
+// the runner-up and the BMU, positive margins are the features which favour
+// the BMU over the runner-up. Returns nil if there's no runner-up.
+func (e *Explanation) Margins() []float64 {
+	if e.RunnerUpContributions == nil {
+		return nil
+	}
+	margins := make([]float64, len(e.Contributions))
+	for k := range margins {
+		margins[k] = e.RunnerUpContributions[k] - e.Contributions[k]
+	}
+	return margins
+}
+
+// Explain returns the per-feature contributions to the BMU decision
+// for the vector, compared to the runner-up neuron. The contributions
+// are computed for the vector adapted by the model adapter, they sum up
+// to the squared euclidean distance, which is the decision itself
+// when the model distance is euclidean.
+func (m *Model) Explain(vector DataVector) (*Explanation, error) {
+	field := m.som.borrowField()
+	defer releaseField(field)
+	distances, err := m.distances(vector, *field)
+	if err != nil {
+		return nil, err
+	}
+
+	bmu, runnerUp := GridPoint{-1, -1}, GridPoint{-1, -1}
+	first, second := math.Inf(1), math.Inf(1)
+	for x := range distances {
+		for y, d := range distances[x] {
+			switch {
+			case d < first:
+				runnerUp, second = bmu, first
+				bmu, first = GridPoint{x, y}, d
+			case d < second:
+				runnerUp, second = GridPoint{x, y}, d
+			}
+		}
+	}
+	if bmu.X == -1 {
+		return nil, fmt.Errorf("%w: all the neurons are masked", ErrInvalidConfig)
+	}
+
+	adapted := m.som.InDataAdapter.Adapt(append(DataVector(nil), vector...))
+	explanation := &Explanation{
+		BMU:           bmu,
+		RunnerUp:      runnerUp,
+		Contributions: contributions(adapted, m.som.Neurons[bmu.X][bmu.Y].Weights),
+	}
+	if runnerUp.X != -1 {
+		explanation.RunnerUpContributions = contributions(adapted, m.som.Neurons[runnerUp.X][runnerUp.Y].Weights)
+	}
+	return explanation, nil
+}
+
+// SensitivityPoint is the outcome of a perturbed vector, see Model.Sensitivity.
+type SensitivityPoint struct {
+	// Delta is the perturbation added to the feature.
+	Delta float64
+
+	// BMU is the BMU of the perturbed vector.
+	BMU GridPoint
+
+	// Distance is the distance from the perturbed vector to its BMU,
+	// i.e. its quantization error, which serves as its anomaly score.
+	Distance float64
+}
+
+// Sensitivity reports how the BMU of the vector and its distance to the BMU
+// (the anomaly score) change when the feature is perturbed by each of
+// the deltas, which supports "what if" exploration of the model:
+// result[i] is the outcome of the vector with deltas[i] added to the feature.
+// The vector itself is not modified.
+func (m *Model) Sensitivity(vector DataVector, feature int, deltas []float64) ([]SensitivityPoint, error) {
+	if feature < 0 || feature >= len(vector) {
+		return nil, fmt.Errorf("%w: feature %d is out of range [0, %d)", ErrInvalidConfig, feature, len(vector))
+	}
+	field := m.som.borrowField()
+	defer releaseField(field)
+	perturbed := append(DataVector(nil), vector...)
+	result := make([]SensitivityPoint, len(deltas))
+	for i, delta := range deltas {
+		perturbed[feature] = vector[feature] + delta
+		distances, err := m.distances(perturbed, *field)
+		if err != nil {
+			return nil, err
+		}
+		bmu := m.som.bmu(distances)
+		result[i] = SensitivityPoint{
+			Delta:    delta,
+			BMU:      GridPoint{X: bmu.X, Y: bmu.Y},
+			Distance: distances[bmu.X][bmu.Y],
+		}
+	}
+	return result, nil
+}
+
+func contributions(vector DataVector, weights []float64) []float64 {
+	result := make([]float64, len(vector))
+	for k := range vector {
+		result[k] = (vector[k] - weights[k]) * (vector[k] - weights[k])
+	}
+	return result
+}
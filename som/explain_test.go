@@ -0,0 +1,72 @@
+package som_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+	"github.com/voievodin/self-organizing-map/som/internal/somtest"
+)
+
+func TestExplainComparesBMUWithRunnerUp(t *testing.T) {
+	sm := som.New(1, 3)
+	if err := sm.LoadCodebook([][][]float64{{{0, 0}, {4, 0}, {0, 10}}}); err != nil {
+		t.Fatal(err)
+	}
+	model, err := sm.Model()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	explanation, err := model.Explain(som.DataVector{1, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEq(t, explanation.BMU, som.GridPoint{X: 0, Y: 0})
+	assertEq(t, explanation.RunnerUp, som.GridPoint{X: 0, Y: 1})
+	checkSlicesEqual(t, explanation.Contributions, []float64{1, 1})
+	checkSlicesEqual(t, explanation.RunnerUpContributions, []float64{9, 1})
+	checkSlicesEqual(t, explanation.Margins(), []float64{8, 0})
+
+	if _, err := model.Explain(som.DataVector{1}); !errors.Is(err, som.ErrWidthMismatch) {
+		t.Fatalf("Expected ErrWidthMismatch, got %v", err)
+	}
+}
+
+func TestExplainWithoutRunnerUp(t *testing.T) {
+	explanation, err := somtest.LineModel(t, 3).Explain(som.DataVector{1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkSlicesEqual(t, explanation.Contributions, []float64{4})
+	assertEq(t, explanation.RunnerUp, som.GridPoint{X: -1, Y: -1})
+	if explanation.Margins() != nil {
+		t.Fatal("Expected no margins without runner-up")
+	}
+}
+
+func TestSensitivityTracksBMUAndDistance(t *testing.T) {
+	sm := som.New(1, 2)
+	if err := sm.LoadCodebook([][][]float64{{{0, 0}, {10, 0}}}); err != nil {
+		t.Fatal(err)
+	}
+	model, err := sm.Model()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vector := som.DataVector{1, 0}
+	points, err := model.Sensitivity(vector, 0, []float64{-1, 0, 6, 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkSlicesEqual(t, vector, []float64{1, 0})
+	assertEq(t, points[0], som.SensitivityPoint{Delta: -1, BMU: som.GridPoint{X: 0, Y: 0}, Distance: 0})
+	assertEq(t, points[1].Distance, 1.0)
+	assertEq(t, points[2].BMU, som.GridPoint{X: 0, Y: 1})
+	assertEq(t, points[3].Distance, 11.0)
+
+	if _, err := model.Sensitivity(vector, 2, []float64{1}); !errors.Is(err, som.ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig, got %v", err)
+	}
+}
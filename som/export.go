@@ -0,0 +1,71 @@
+package som
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// ExportCodebookCSV writes the codebook of this SOM in CSV format,
+// one row per neuron with x, y and weights columns, preceded by a header row.
+// Weight columns are named after featureNames, if featureNames is nil
+// they are named w0, w1, ..., otherwise its length must match the weights length.
+// Use Headers(ds.AdaptedColumns()) to name them after the data set columns.
+func (som *SOM) ExportCodebookCSV(w io.Writer, featureNames []string) error {
+	return som.ExportCodebookCSVPrecision(w, featureNames, nil, Precision{})
+}
+
+// ExportCodebookCSVInverse is like ExportCodebookCSV, but weights are mapped back
+// to the original data space by the given adapter, e.g. the one used to scale input vectors.
+// Neurons weights are not modified.
+func (som *SOM) ExportCodebookCSVInverse(w io.Writer, featureNames []string, adapter InvertibleDataAdapter) error {
+	return som.ExportCodebookCSVPrecision(w, featureNames, adapter, Precision{})
+}
+
+// ExportCodebookCSVPrecision is like ExportCodebookCSVInverse, but weights
+// are written with the given precision, e.g. Precision{Digits: 6} makes
+// the output of big codebooks several times smaller.
+// The adapter may be nil, then weights are written as is.
+func (som *SOM) ExportCodebookCSVPrecision(w io.Writer, featureNames []string, adapter InvertibleDataAdapter, precision Precision) error {
+	if err := som.checkTrained(); err != nil {
+		return err
+	}
+	width := len(som.Neurons[0][0].Weights)
+	if featureNames == nil {
+		featureNames = make([]string, width)
+		for i := range featureNames {
+			featureNames[i] = "w" + strconv.Itoa(i)
+		}
+	} else if len(featureNames) != width {
+		return fmt.Errorf("%d feature names for %d weights", len(featureNames), width)
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(append([]string{"x", "y"}, featureNames...)); err != nil {
+		return err
+	}
+
+	record := make([]string, width+2)
+	weights := make([]float64, width)
+	for i := 0; i < len(som.Neurons); i++ {
+		for j := 0; j < len(som.Neurons[i]); j++ {
+			copy(weights, som.Neurons[i][j].Weights)
+			values := weights
+			if adapter != nil {
+				values = adapter.Inverse(weights)
+			}
+
+			record[0] = strconv.Itoa(i)
+			record[1] = strconv.Itoa(j)
+			for k, v := range values {
+				record[k+2] = precision.format(v)
+			}
+			if err := cw.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
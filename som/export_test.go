@@ -0,0 +1,48 @@
+package som_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+func TestExportCodebookCSV(t *testing.T) {
+	sm := som.New(1, 2)
+	sm.Initializer = &som.ProvidedWeightsInitializer{
+		Weights: [][][]float64{
+			{{0.5, 1}, {0, 0.25}},
+		},
+	}
+	sm.Learn(&som.DataSet{Vectors: []som.DataVector{{}}}, 0)
+
+	buf := &bytes.Buffer{}
+	if err := sm.ExportCodebookCSV(buf, []string{"height", "weight"}); err != nil {
+		t.Fatal(err)
+	}
+	assertEq(t, buf.String(), "x,y,height,weight\n0,0,0.5,1\n0,1,0,0.25\n")
+
+	buf.Reset()
+	adapter := som.NewScalingDataAdapter([]float64{100, 0}, []float64{200, 4})
+	if err := sm.ExportCodebookCSVInverse(buf, nil, adapter); err != nil {
+		t.Fatal(err)
+	}
+	assertEq(t, buf.String(), "x,y,w0,w1\n0,0,150,4\n0,1,100,1\n")
+	checkSlicesEqual(t, sm.Neurons[0][0].Weights, []float64{0.5, 1})
+
+	if err := sm.ExportCodebookCSV(buf, []string{"height"}); err == nil {
+		t.Fatal("Expected error for mismatching feature names")
+	}
+}
+
+func TestExportCodebookCSVPrecision(t *testing.T) {
+	sm := som.New(1, 1)
+	sm.Initializer = &som.ProvidedWeightsInitializer{Weights: [][][]float64{{{1.23456789, 1000.5}}}}
+	sm.Learn(&som.DataSet{Vectors: []som.DataVector{{}}}, 0)
+
+	buf := &bytes.Buffer{}
+	if err := sm.ExportCodebookCSVPrecision(buf, nil, nil, som.Precision{Digits: 3}); err != nil {
+		t.Fatal(err)
+	}
+	assertEq(t, buf.String(), "x,y,w0,w1\n0,0,1.23,1e+03\n")
+}
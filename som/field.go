@@ -0,0 +1,75 @@
+package som
+
+import (
+	"math"
+	"math/rand"
+)
+
+// DistanceField is a matrix of distances from a vector to each neuron,
+// the value at position (x, y) is a distance to the neuron at position (x, y).
+// Fields are owned by callers, so they can be reused between calls
+// and are never shared between SOM and its users.
+type DistanceField [][]float64
+
+// NewDistanceField allocates a field matching the given neurons matrix.
+func NewDistanceField(neurons [][]*Neuron) DistanceField {
+	field := make(DistanceField, len(neurons))
+	for i := range neurons {
+		field[i] = make([]float64, len(neurons[i]))
+	}
+	return field
+}
+
+// Min returns the position of the minimal distance in this field,
+// if there are several such positions, a random one is returned.
+func (field DistanceField) Min() (int, int) {
+	min, x, y, count := field.minimum()
+	if count <= 1 {
+		return x, y
+	}
+
+	chosen := rand.Intn(count)
+	for i := 0; i < len(field); i++ {
+		for j := 0; j < len(field[i]); j++ {
+			if field[i][j] == min {
+				if chosen == 0 {
+					return i, j
+				}
+				chosen--
+			}
+		}
+	}
+	return x, y
+}
+
+// minimum returns the minimal distance, the first position
+// of it and the number of positions having it.
+func (field DistanceField) minimum() (float64, int, int, int) {
+	min := math.Inf(1)
+	minX, minY := 0, 0
+	count := 0
+	for i := 0; i < len(field); i++ {
+		for j := 0; j < len(field[i]); j++ {
+			if field[i][j] < min {
+				min = field[i][j]
+				minX, minY = i, j
+				count = 1
+			} else if field[i][j] == min {
+				count++
+			}
+		}
+	}
+	return min, minX, minY, count
+}
+
+func (field DistanceField) fits(neurons [][]*Neuron) bool {
+	if len(field) != len(neurons) {
+		return false
+	}
+	for i := range neurons {
+		if len(field[i]) != len(neurons[i]) {
+			return false
+		}
+	}
+	return true
+}
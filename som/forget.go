@@ -0,0 +1,88 @@
+package som
+
+// Forgetting makes an online map track non-stationary distributions instead
+// of fossilizing: the neurons which haven't won for Idle iterations are stale,
+// they don't represent the current data, and each iteration they relax toward
+// the running mean of the data
+//
+//	w = w + Rate * (mean - w)
+//
+// so they are pulled back into the region of the current data, where they can
+// win and learn again. The stale neurons are updated like the learning updates
+// them, so masked and frozen neurons are kept, the weights are renormalized if
+// SOM.Renormalize is set and the updates are journaled, see JournalEntry.Relaxation.
+// Set it to OnlineTrainer.Forgetting to enable it.
+type Forgetting struct {
+	// Idle is the number of iterations since its last win after which
+	// a neuron is stale, <= 0 means 10 times the number of neurons.
+	Idle int
+
+	// Rate is the fraction of the distance to the mean a stale neuron
+	// moves each iteration, usually small, e.g. 0.001.
+	Rate float64
+
+	// MeanDecay is the weight of each vector in the exponentially weighted
+	// running mean of the data, <= 0 means 0.001, so the mean follows
+	// the last thousands of vectors.
+	MeanDecay float64
+
+	// Relaxed counts the stale neuron updates.
+	Relaxed int
+
+	mean    DataVector
+	lastWon [][]int
+}
+
+// observe records the iteration it, where the adapted vector is won by
+// the bmu, and relaxes the stale neurons of the map.
+// Returns the error of writing the journal.
+func (f *Forgetting) observe(som *SOM, it int, vector DataVector, bmu *Neuron) error {
+	decay := f.MeanDecay
+	if decay <= 0 {
+		decay = 0.001
+	}
+	if len(f.mean) != len(vector) {
+		f.mean = append(DataVector(nil), vector...)
+	} else {
+		for k, v := range vector {
+			f.mean[k] += decay * (v - f.mean[k])
+		}
+	}
+	if f.lastWon == nil {
+		f.lastWon = make([][]int, len(som.Neurons))
+		for i := range f.lastWon {
+			f.lastWon[i] = make([]int, len(som.Neurons[i]))
+			for j := range f.lastWon[i] {
+				f.lastWon[i][j] = it
+			}
+		}
+	}
+	f.lastWon[bmu.X][bmu.Y] = it
+
+	idle := f.Idle
+	if idle <= 0 {
+		idle = 10 * som.Len()
+	}
+	relaxed := 0
+	for i := range som.Neurons {
+		for j, neuron := range som.Neurons[i] {
+			if it-f.lastWon[i][j] < idle || neuron.Frozen || som.IsMasked(i, j) {
+				continue
+			}
+			som.moveWeights(i, j, it, f.Rate, f.mean)
+			relaxed++
+		}
+	}
+	f.Relaxed += relaxed
+	if relaxed == 0 {
+		return nil
+	}
+	// the cached distances to the relaxed neurons are stale
+	if som.Incremental != nil {
+		som.Incremental.reset(som.Neurons, it+1)
+	}
+	if som.Journal != nil {
+		return som.Journal.relax(it, f.mean, som.Renormalize)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package som_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+func TestForgettingRelaxesStaleNeurons(t *testing.T) {
+	sm := som.New(1, 3)
+	if err := sm.LoadCodebook([][][]float64{{{0}, {5}, {10}}}); err != nil {
+		t.Fatal(err)
+	}
+	sm.Restraint = &som.ExpRestraintFunc{InitialRate: 0.5}
+	forgetting := &som.Forgetting{Idle: 10, Rate: 0.1, MeanDecay: 0.5}
+	trainer := &som.OnlineTrainer{SOM: sm, Forgetting: forgetting}
+
+	// the data moves from 0 to 10, the neuron at 0 becomes stale
+	for i := 0; i < 100; i++ {
+		if err := trainer.Learn(som.DataVector{10}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	model, err := trainer.Model()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w := model.Weights(0, 0)[0]; w < 9 {
+		t.Fatalf("Expected the stale neuron to approach the data mean, got %v", w)
+	}
+	if forgetting.Relaxed == 0 {
+		t.Fatal("Expected relaxed neurons to be counted")
+	}
+
+	// without forgetting the neuron stays where it was
+	sm = som.New(1, 3)
+	if err := sm.LoadCodebook([][][]float64{{{0}, {5}, {10}}}); err != nil {
+		t.Fatal(err)
+	}
+	trainer = &som.OnlineTrainer{SOM: sm}
+	for i := 0; i < 100; i++ {
+		trainer.Learn(som.DataVector{10})
+	}
+	if model, _ = trainer.Model(); math.Abs(model.Weights(0, 0)[0]) > 1e-9 {
+		t.Fatalf("Expected the neuron to fossilize, got %v", model.Weights(0, 0))
+	}
+}
+
+func TestForgettingRenormalizesRelaxedNeurons(t *testing.T) {
+	sm := som.New(1, 3)
+	if err := sm.LoadCodebook([][][]float64{{{0, 1}, {-1, 0}, {1, 0}}}); err != nil {
+		t.Fatal(err)
+	}
+	sm.Distance = &som.CosineDistanceFunc{}
+	sm.Renormalize = true
+	trainer := &som.OnlineTrainer{SOM: sm, Forgetting: &som.Forgetting{Idle: 5, Rate: 0.1}}
+
+	for i := 0; i < 20; i++ {
+		if err := trainer.Learn(som.DataVector{1, 0}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for y := 0; y < 2; y++ {
+		weights := sm.Neurons[0][y].Weights
+		if norm := math.Hypot(weights[0], weights[1]); math.Abs(norm-1) > 1e-9 {
+			t.Fatalf("Expected relaxed neuron %d to keep unit length, got %v", y, weights)
+		}
+	}
+	if sm.Neurons[0][0].Weights[0] <= 0 {
+		t.Fatalf("Expected the stale neuron to relax toward the data, got %v", sm.Neurons[0][0].Weights)
+	}
+}
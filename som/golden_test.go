@@ -0,0 +1,83 @@
+package som_test
+
+import (
+	"bytes"
+	"flag"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+var updateGolden = flag.Bool("update-golden", false, "rewrite the golden models of the golden specs")
+
+// goldenTolerance is the maximal difference of a weight from its golden
+// value, which admits the differences of floating point arithmetic across
+// platforms, but catches unintended changes of learning.
+const goldenTolerance = 1e-9
+
+// TestGoldenModels trains a map for each spec in testdata/golden and compares
+// its codebook with the golden model saved next to the spec. After an
+// intended change of learning, review the changes of the maps and rewrite
+// the golden models by
+//
+//	go test ./som -run TestGoldenModels -update-golden
+func TestGoldenModels(t *testing.T) {
+	specs, err := filepath.Glob(filepath.Join(dataDir, "golden", "*.spec.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(specs) == 0 {
+		t.Fatal("No golden specs found")
+	}
+	for _, path := range specs {
+		path := path
+		name := strings.TrimSuffix(filepath.Base(path), ".spec.json")
+		t.Run(name, func(t *testing.T) {
+			result := runExperiment(t, path)
+			goldenPath := strings.TrimSuffix(path, ".spec.json") + ".golden.json"
+			if *updateGolden {
+				buf := &bytes.Buffer{}
+				if err := result.SOM.SaveJSON(buf, som.Precision{}); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(goldenPath, buf.Bytes(), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			f, err := os.Open(goldenPath)
+			if err != nil {
+				t.Fatalf("%v, run with -update-golden to create it", err)
+			}
+			defer f.Close()
+			golden, err := som.LoadJSON(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkCodebooksClose(t, golden.CopyWeights(nil), result.SOM.CopyWeights(nil))
+		})
+	}
+}
+
+func checkCodebooksClose(t *testing.T, expected, actual [][][]float64) {
+	if len(expected) != len(actual) || len(expected[0]) != len(actual[0]) {
+		t.Fatalf("Expected %dx%d map, got %dx%d", len(expected), len(expected[0]), len(actual), len(actual[0]))
+	}
+	for x := range expected {
+		for y := range expected[x] {
+			if len(expected[x][y]) != len(actual[x][y]) {
+				t.Fatalf("Neuron (%d, %d): expected %d weights, got %d", x, y, len(expected[x][y]), len(actual[x][y]))
+			}
+			for k, w := range expected[x][y] {
+				if d := math.Abs(actual[x][y][k] - w); !(d <= goldenTolerance) {
+					t.Fatalf("Neuron (%d, %d) weight %d is %v, golden %v", x, y, k, actual[x][y][k], w)
+				}
+			}
+		}
+	}
+}
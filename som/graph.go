@@ -0,0 +1,163 @@
+package som
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+)
+
+// Edge connects the nodes From and To of GraphLattice both ways,
+// Weight is its length, e.g. the length of a road.
+type Edge struct {
+	From, To int
+	Weight   float64
+}
+
+// GraphLattice is the Lattice of the nodes of an arbitrary graph, e.g. of
+// a road network, a mesh or an organizational structure, so the map of the
+// lattice, see NewLattice, takes the shape of the graph. The distance between
+// the nodes is the length of the shortest path between them divided by the mean
+// weight of the edges, the nodes which are not connected are infinitely distant,
+// so they never influence each other. The distances between all the nodes are
+// computed by NewGraphLattice, which takes n^2 floats of memory for n nodes.
+type GraphLattice struct {
+	neighbours [][]int
+	distances  [][]float64
+
+	// edges are the edges the lattice is created from, see Describe
+	edges []Edge
+}
+
+// NewGraphLattice creates the lattice of the graph of the given number of
+// nodes connected by the edges. Returns ErrInvalidConfig if an edge refers
+// to a node out of the range or its weight is not positive.
+func NewGraphLattice(nodes int, edges []Edge) (*GraphLattice, error) {
+	if nodes <= 0 {
+		return nil, fmt.Errorf("%w: graph must have nodes, got %d", ErrInvalidConfig, nodes)
+	}
+	adjacency := make([][]Edge, nodes)
+	total := 0.0
+	for i, e := range edges {
+		if e.From < 0 || e.From >= nodes || e.To < 0 || e.To >= nodes {
+			return nil, fmt.Errorf("%w: edge %d connects %d and %d, graph has %d nodes", ErrInvalidConfig, i, e.From, e.To, nodes)
+		}
+		if !(e.Weight > 0) || math.IsInf(e.Weight, 1) {
+			return nil, fmt.Errorf("%w: edge %d weight must be positive, got %v", ErrInvalidConfig, i, e.Weight)
+		}
+		adjacency[e.From] = append(adjacency[e.From], Edge{From: e.From, To: e.To, Weight: e.Weight})
+		adjacency[e.To] = append(adjacency[e.To], Edge{From: e.To, To: e.From, Weight: e.Weight})
+		total += e.Weight
+	}
+	unit := 1.0
+	if len(edges) > 0 {
+		unit = total / float64(len(edges))
+	}
+
+	lattice := &GraphLattice{
+		neighbours: make([][]int, nodes),
+		distances:  make([][]float64, nodes),
+		edges:      append([]Edge(nil), edges...),
+	}
+	for i, adjacent := range adjacency {
+		seen := make(map[int]bool)
+		for _, e := range adjacent {
+			if e.To != i && !seen[e.To] {
+				seen[e.To] = true
+				lattice.neighbours[i] = append(lattice.neighbours[i], e.To)
+			}
+		}
+		lattice.distances[i] = shortestPaths(adjacency, i)
+		for j := range lattice.distances[i] {
+			lattice.distances[i][j] /= unit
+		}
+	}
+	return lattice, nil
+}
+
+func (l *GraphLattice) Len() int {
+	return len(l.neighbours)
+}
+
+// Distance returns the length of the shortest path between the nodes
+// in the units of the mean edge weight, +Inf if they are not connected.
+func (l *GraphLattice) Distance(i, j int) float64 {
+	return l.distances[i][j]
+}
+
+func (l *GraphLattice) Neighbours(i int) []int {
+	return l.neighbours[i]
+}
+
+// Describe returns the graph topology spec, the number of the nodes is
+// the param nodes, the ends and the weights of the edges are the values
+// from, to and weight, so the maps of the lattice can be saved.
+func (l *GraphLattice) Describe() ComponentSpec {
+	from := make([]float64, len(l.edges))
+	to := make([]float64, len(l.edges))
+	weight := make([]float64, len(l.edges))
+	for i, e := range l.edges {
+		from[i], to[i], weight[i] = float64(e.From), float64(e.To), e.Weight
+	}
+	return ComponentSpec{
+		Type:   "graph",
+		Params: Params{"nodes": float64(l.Len())},
+		Values: map[string][]float64{"from": from, "to": to, "weight": weight},
+	}
+}
+
+// newGraphTopology creates LatticeTopology of the graph described by GraphLattice.Describe.
+func newGraphTopology(params Params, values map[string][]float64) (Topology, error) {
+	if err := params.Check("nodes"); err != nil {
+		return nil, err
+	}
+	nodes := params.Get("nodes", 0)
+	if nodes != math.Trunc(nodes) || nodes < 1 || nodes > maxGraphNodes {
+		return nil, fmt.Errorf("%w: graph nodes must be an integer in [1, %d], got %v", ErrInvalidConfig, maxGraphNodes, nodes)
+	}
+	from, to, weight := values["from"], values["to"], values["weight"]
+	if len(from) != len(to) || len(from) != len(weight) {
+		return nil, fmt.Errorf("%w: graph needs from, to and weight values of the same length", ErrInvalidConfig)
+	}
+	edges := make([]Edge, len(from))
+	for i := range edges {
+		if from[i] != math.Trunc(from[i]) || to[i] != math.Trunc(to[i]) ||
+			math.Abs(from[i]) > nodes || math.Abs(to[i]) > nodes {
+			return nil, fmt.Errorf("%w: edge %d connects %v and %v, graph has %v nodes", ErrInvalidConfig, i, from[i], to[i], nodes)
+		}
+		edges[i] = Edge{From: int(from[i]), To: int(to[i]), Weight: weight[i]}
+	}
+	lattice, err := NewGraphLattice(int(nodes), edges)
+	if err != nil {
+		return nil, err
+	}
+	return &LatticeTopology{Lattice: lattice}, nil
+}
+
+// maxGraphNodes limits the nodes of the graph recreated from its description,
+// so a corrupted saved map doesn't cause a huge allocation, since the lattice
+// takes n^2 floats of memory for n nodes.
+const maxGraphNodes = 1 << 12
+
+// shortestPaths returns the lengths of the shortest paths
+// from the node to all the nodes by the Dijkstra's algorithm.
+func shortestPaths(adjacency [][]Edge, from int) []float64 {
+	distances := make([]float64, len(adjacency))
+	for i := range distances {
+		distances[i] = math.Inf(1)
+	}
+	distances[from] = 0
+	queue := &pathQueue{{index: from}}
+	for queue.Len() > 0 {
+		item := heap.Pop(queue).(pathItem)
+		if item.cost > distances[item.index] {
+			continue
+		}
+		for _, e := range adjacency[item.index] {
+			if cost := item.cost + e.Weight; cost < distances[e.To] {
+				distances[e.To] = cost
+				heap.Push(queue, pathItem{index: e.To, cost: cost})
+			}
+		}
+	}
+	return distances
+}
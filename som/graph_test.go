@@ -0,0 +1,277 @@
+package som_test
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+func TestGraphLatticeShortestPaths(t *testing.T) {
+	// 0 - 1 - 2 - 3 with the shortcut 0 - 3 and the isolated node 4
+	lattice, err := som.NewGraphLattice(5, []som.Edge{
+		{From: 0, To: 1, Weight: 1},
+		{From: 1, To: 2, Weight: 1},
+		{From: 2, To: 3, Weight: 1},
+		{From: 0, To: 3, Weight: 5},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the mean edge weight is 2
+	for _, test := range []struct {
+		i, j     int
+		expected float64
+	}{
+		{0, 0, 0},
+		{0, 1, 0.5},
+		{0, 3, 1.5},
+		{3, 0, 1.5},
+		{1, 3, 1},
+	} {
+		if d := lattice.Distance(test.i, test.j); d != test.expected {
+			t.Errorf("Distance(%d, %d) = %v, expected %v", test.i, test.j, d, test.expected)
+		}
+	}
+	if d := lattice.Distance(0, 4); !math.IsInf(d, 1) {
+		t.Fatalf("Expected the isolated node infinitely distant, got %v", d)
+	}
+	if n := lattice.Neighbours(0); len(n) != 2 {
+		t.Fatalf("Expected 2 neighbours of node 0, got %v", n)
+	}
+	if n := lattice.Neighbours(4); len(n) != 0 {
+		t.Fatalf("Expected no neighbours of the isolated node, got %v", n)
+	}
+}
+
+func TestGraphLatticeRejectsInvalidEdges(t *testing.T) {
+	for _, edges := range [][]som.Edge{
+		{{From: 0, To: 2, Weight: 1}},
+		{{From: -1, To: 1, Weight: 1}},
+		{{From: 0, To: 1, Weight: 0}},
+		{{From: 0, To: 1, Weight: math.NaN()}},
+	} {
+		if _, err := som.NewGraphLattice(2, edges); !errors.Is(err, som.ErrInvalidConfig) {
+			t.Fatalf("Expected ErrInvalidConfig for %v, got %v", edges, err)
+		}
+	}
+}
+
+// pathGraph returns the lattice of the path of n nodes.
+func pathGraph(t *testing.T, n int) *som.GraphLattice {
+	edges := make([]som.Edge, 0, n-1)
+	for i := 0; i < n-1; i++ {
+		edges = append(edges, som.Edge{From: i, To: i + 1, Weight: 1})
+	}
+	lattice, err := som.NewGraphLattice(n, edges)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return lattice
+}
+
+func TestGraphMapOrdersAlongGraph(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	ds := &som.DataSet{}
+	for i := 0; i < 200; i++ {
+		ds.Add(som.DataVector{rng.Float64()})
+	}
+	sm := som.NewLattice(pathGraph(t, 10))
+	sm.Initializer = &som.RandWeightsInitializer{Rand: rng}
+	sm.Selector = &som.RandSelector{Rand: rng}
+	sm.Restraint = &som.ExpRestraintFunc{InitialRate: 0.5}
+	sm.Influence = &som.GaussianExpDecayInfluenceFunc{InitialWidth: 5, MinWidth: 0.5}
+
+	if err := sm.Learn(ds, 3000); err != nil {
+		t.Fatal(err)
+	}
+
+	// the path graph orders the weights like the 1-dimensional map does
+	neurons := sm.Neurons[0]
+	increasing := neurons[9].Weights[0] > neurons[0].Weights[0]
+	for i := 1; i < 10; i++ {
+		if (neurons[i].Weights[0] > neurons[i-1].Weights[0]) != increasing {
+			t.Fatalf("Expected the weights ordered along the path, got %v at %d after %v", neurons[i].Weights, i, neurons[i-1].Weights)
+		}
+	}
+	if te := sm.TopographicError(ds); te > 0.05 {
+		t.Fatalf("Expected the BMUs to be adjacent along the path, topographic error is %v", te)
+	}
+	umatrix := sm.UMatrix()
+	if len(umatrix) != 1 || len(umatrix[0]) != 10 || math.IsNaN(umatrix[0][0]) {
+		t.Fatalf("Unexpected U-matrix %v", umatrix)
+	}
+	// the ends of the path have one neighbour each
+	assertEq(t, umatrix[0][0], math.Abs(neurons[0].Weights[0]-neurons[1].Weights[0]))
+}
+
+func TestGraphMapNeighbourhoodFollowsShortestPaths(t *testing.T) {
+	// the star of 3 rays, the center is the node 0
+	lattice, err := som.NewGraphLattice(4, []som.Edge{
+		{From: 0, To: 1, Weight: 1},
+		{From: 0, To: 2, Weight: 1},
+		{From: 2, To: 3, Weight: 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sm := som.NewLattice(lattice)
+	sm.Initializer = &som.ProvidedWeightsInitializer{Weights: [][][]float64{{{1}, {0}, {0}, {0}}}}
+	sm.Influence = &som.GaussianInfluenceFunc{Q: func(int, int) float64 { return 1 }}
+
+	if err := sm.Learn(&som.DataSet{Vectors: []som.DataVector{{2}}}, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	// the BMU is the center, the weights move towards 2 by the gaussian of the path length
+	for i, start := range []float64{1, 0, 0, 0} {
+		h := math.Exp(-math.Pow(lattice.Distance(0, i), 2) / 2)
+		if w := sm.Neurons[0][i].Weights[0]; math.Abs(w-(start+h*(2-start))) > 1e-12 {
+			t.Fatalf("Node %d: unexpected weight %v", i, w)
+		}
+	}
+}
+
+func TestGraphMapLearnsInBatches(t *testing.T) {
+	sm := som.NewLattice(pathGraph(t, 5))
+	sm.Initializer = &som.ProvidedWeightsInitializer{Weights: [][][]float64{{{0}, {1}, {2}, {3}, {4}}}}
+	sm.Influence = &som.GaussianExpDecayInfluenceFunc{InitialWidth: 1}
+
+	if err := sm.LearnBatch(&som.DataSet{Vectors: []som.DataVector{{0}, {4}}}, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	// the middle node is the weighted average of both vectors
+	// by the kernel of its distance to their BMUs, the ends
+	if w := sm.Neurons[0][2].Weights[0]; math.Abs(w-2) > 1e-12 {
+		t.Fatalf("Expected the middle node at 2, got %v", w)
+	}
+	if w := sm.Neurons[0][1].Weights[0]; !(w > 0 && w < 2) {
+		t.Fatalf("Expected the node 1 pulled towards the nearer end, got %v", w)
+	}
+}
+
+func TestGraphMapRejectsGridInfluenceAndSize(t *testing.T) {
+	ds := &som.DataSet{Vectors: []som.DataVector{{1}}}
+	sm := som.NewLattice(pathGraph(t, 3))
+	sm.Influence = &constantInfluenceFunc{}
+	if err := sm.Learn(ds, 1); !errors.Is(err, som.ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig for the grid influence function, got %v", err)
+	}
+
+	sm = som.New(2, 2)
+	sm.Topology = &som.LatticeTopology{Lattice: pathGraph(t, 3)}
+	if err := sm.Learn(ds, 1); !errors.Is(err, som.ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig for the map not fitting the lattice, got %v", err)
+	}
+}
+
+func TestGraphMapEmitsIterationEvents(t *testing.T) {
+	sm := som.NewLattice(pathGraph(t, 3))
+	sm.Influence = &som.GaussianExpDecayInfluenceFunc{InitialWidth: 2}
+	sm.Guard = &som.NumericGuard{}
+	var events []*som.IterationEvent
+	sm.Events = som.EventListenerFunc(func(event som.Event) {
+		if e, ok := event.(*som.IterationEvent); ok {
+			events = append(events, e)
+		}
+	})
+
+	if err := sm.Learn(&som.DataSet{Vectors: []som.DataVector{{1}, {2}}}, 2); err != nil {
+		t.Fatal(err)
+	}
+	assertEq(t, len(events), 2)
+	assertEq(t, events[0].Radius, 2.0)
+	assertEq(t, events[0].BMUX, 0)
+}
+
+// undescribedLattice hides the description of the lattice.
+type undescribedLattice struct {
+	lattice som.Lattice
+}
+
+func (l undescribedLattice) Len() int                  { return l.lattice.Len() }
+func (l undescribedLattice) Distance(i, j int) float64 { return l.lattice.Distance(i, j) }
+func (l undescribedLattice) Neighbours(i int) []int    { return l.lattice.Neighbours(i) }
+
+func TestGraphMapIsSavedAndLoaded(t *testing.T) {
+	lattice, err := som.NewGraphLattice(3, []som.Edge{{From: 0, To: 1, Weight: 2}, {From: 1, To: 2, Weight: 3}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sm := som.NewLattice(lattice)
+	sm.Initializer = &som.ProvidedWeightsInitializer{Weights: [][][]float64{{{0}, {1}, {2}}}}
+	if err := sm.Learn(&som.DataSet{Vectors: []som.DataVector{{1.2}}}, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := sm.SaveJSON(buf, som.Precision{}); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := som.LoadJSON(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	topology, ok := loaded.Topology.(*som.LatticeTopology)
+	if !ok {
+		t.Fatalf("Expected LatticeTopology, got %T", loaded.Topology)
+	}
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			assertEq(t, topology.Lattice.Distance(i, j), lattice.Distance(i, j))
+		}
+	}
+	model, err := loaded.Model()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bmu, err := model.BMU(som.DataVector{2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEq(t, bmu, som.GridPoint{X: 0, Y: 2})
+
+	// the lattice which can't be described can't be saved
+	sm.Topology = &som.LatticeTopology{Lattice: undescribedLattice{lattice}}
+	if err := sm.SaveJSON(&bytes.Buffer{}, som.Precision{}); !errors.Is(err, som.ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig, got %v", err)
+	}
+}
+
+func TestGraphTopologyRejectsBadDescriptions(t *testing.T) {
+	for _, values := range []map[string][]float64{
+		{"from": {0}, "to": {1}},
+		{"from": {0.5}, "to": {1}, "weight": {1}},
+		{"from": {math.NaN()}, "to": {1}, "weight": {1}},
+		{"from": {0}, "to": {1e300}, "weight": {1}},
+	} {
+		if _, err := som.NewTopology("graph", som.Params{"nodes": 2}, values); !errors.Is(err, som.ErrInvalidConfig) {
+			t.Fatalf("Expected ErrInvalidConfig for %v, got %v", values, err)
+		}
+	}
+	if _, err := som.NewTopology("graph", som.Params{"nodes": 1e9}, nil); !errors.Is(err, som.ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig for too many nodes, got %v", err)
+	}
+}
+
+func TestKernelCacheTellsUndescribedLatticesApart(t *testing.T) {
+	triangle, err := som.NewGraphLattice(3, []som.Edge{
+		{From: 0, To: 1, Weight: 1},
+		{From: 1, To: 2, Weight: 1},
+		{From: 0, To: 2, Weight: 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache := &som.KernelCache{}
+	path := cache.Kernel(&som.LatticeTopology{Lattice: undescribedLattice{pathGraph(t, 3)}}, 1, 1, 3)
+	closed := cache.Kernel(&som.LatticeTopology{Lattice: undescribedLattice{triangle}}, 1, 1, 3)
+
+	assertEq(t, path.At(0, 0, 0, 2), math.Exp(-2))
+	assertEq(t, closed.At(0, 0, 0, 2), math.Exp(-0.5))
+}
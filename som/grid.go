@@ -0,0 +1,39 @@
+package som
+
+// Dims returns the size of the map grid: the number of neurons along x and y.
+func (som *SOM) Dims() (int, int) {
+	if len(som.Neurons) == 0 {
+		return 0, 0
+	}
+	return len(som.Neurons), len(som.Neurons[0])
+}
+
+// Len returns the number of neurons of the map, including masked ones.
+func (som *SOM) Len() int {
+	x, y := som.Dims()
+	return x * y
+}
+
+// Index returns the row-major index of the neuron at (x, y),
+// i.e. x*yLen + y, within [0, Len()).
+func (som *SOM) Index(x, y int) int {
+	_, yLen := som.Dims()
+	return x*yLen + y
+}
+
+// Position returns the (x, y) position of the neuron with the row-major index.
+func (som *SOM) Position(i int) (int, int) {
+	_, yLen := som.Dims()
+	return i / yLen, i % yLen
+}
+
+// At returns the neuron with the row-major index, see Index,
+// so the neurons can be iterated without assuming the layout of Neurons:
+//
+//	for i := 0; i < sm.Len(); i++ {
+//		neuron := sm.At(i)
+//	}
+func (som *SOM) At(i int) *Neuron {
+	x, y := som.Position(i)
+	return som.Neurons[x][y]
+}
@@ -0,0 +1,28 @@
+package som_test
+
+import (
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+func TestRowMajorAccessors(t *testing.T) {
+	sm := som.New(3, 4)
+
+	x, y := sm.Dims()
+	assertEq(t, x, 3)
+	assertEq(t, y, 4)
+	assertEq(t, sm.Len(), 12)
+
+	for i := 0; i < sm.Len(); i++ {
+		neuron := sm.At(i)
+		assertEq(t, sm.Index(neuron.X, neuron.Y), i)
+		px, py := sm.Position(i)
+		assertEq(t, px, neuron.X)
+		assertEq(t, py, neuron.Y)
+	}
+	assertEq(t, sm.Index(1, 2), 6)
+	if sm.At(6) != sm.Neurons[1][2] {
+		t.Fatal("Expected neuron (1, 2) at index 6")
+	}
+}
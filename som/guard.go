@@ -0,0 +1,145 @@
+package som
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+var (
+	// ErrNonFinite is wrapped by NonFiniteError.
+	ErrNonFinite = errors.New("non-finite weight")
+)
+
+// NumericGuard checks neurons weights for NaN and Inf values while learning,
+// so diverging configurations fail early instead of producing garbage maps.
+type NumericGuard struct {
+	// Every is the number of iterations between checks,
+	// values <= 1 mean checking after each iteration.
+	Every int
+
+	// Rollback makes the guard restore weights from the last checkpoint
+	// (taken by the last successful check) and continue learning,
+	// instead of aborting it with NonFiniteError. The restored weights
+	// are journaled, see JournalEntry.Rollback.
+	Rollback bool
+
+	checkpoint   [][][]float64
+	checkpointIt int
+}
+
+// NonFiniteError describes the first non-finite weight found by NumericGuard.
+type NonFiniteError struct {
+	// It is the iteration after which the weight was found, within bounds [1, itNum].
+	It int
+
+	// X, Y are the coordinates of the neuron.
+	X, Y int
+
+	// Feature is the index of the weight.
+	Feature int
+
+	Value float64
+}
+
+func (e *NonFiniteError) Error() string {
+	return fmt.Sprintf(
+		"%v: neuron (%d, %d) weight %d is %v after iteration %d",
+		ErrNonFinite, e.X, e.Y, e.Feature, e.Value, e.It,
+	)
+}
+
+func (e *NonFiniteError) Unwrap() error { return ErrNonFinite }
+
+// RollbackEvent is emitted when NumericGuard restores weights from the checkpoint.
+type RollbackEvent struct {
+	// Cause describes the weight which caused rollback.
+	Cause *NonFiniteError
+
+	// CheckpointIt is the iteration the checkpoint was taken after, 0 if before learning.
+	CheckpointIt int
+}
+
+func (e *RollbackEvent) EventName() string { return "rollback" }
+
+// start takes the initial checkpoint, called once neurons are initialized.
+func (guard *NumericGuard) start(neurons [][]*Neuron) {
+	guard.checkpoint = nil
+	guard.checkpointIt = 0
+	if guard.Rollback {
+		guard.checkpoint = copyWeights(neurons, guard.checkpoint)
+	}
+}
+
+// check checks the weights after the given iteration (within [1, itNum]),
+// returns true if the weights are rolled back to the checkpoint
+// and an error if learning must be aborted.
+func (guard *NumericGuard) check(it int, som *SOM) (bool, error) {
+	if guard.Every > 1 && it%guard.Every != 0 {
+		return false, nil
+	}
+
+	issue := findNonFinite(som.Neurons)
+	if issue == nil {
+		if guard.Rollback {
+			guard.checkpoint = copyWeights(som.Neurons, guard.checkpoint)
+			guard.checkpointIt = it
+		}
+		return false, nil
+	}
+
+	issue.It = it
+	if !guard.Rollback {
+		return false, issue
+	}
+
+	for i := range som.Neurons {
+		for j := range som.Neurons[i] {
+			copy(som.Neurons[i][j].Weights, guard.checkpoint[i][j])
+		}
+	}
+	if som.Incremental != nil {
+		som.Incremental.reset(som.Neurons, it)
+	}
+	som.log(LogWarn, "non-finite weights, rolled back to checkpoint", "cause", issue, "checkpoint", guard.checkpointIt)
+	if som.listening() {
+		som.Events.OnEvent(&RollbackEvent{Cause: issue, CheckpointIt: guard.checkpointIt})
+	}
+	if som.Journal != nil {
+		return true, som.Journal.rollback(it, som)
+	}
+	return true, nil
+}
+
+func findNonFinite(neurons [][]*Neuron) *NonFiniteError {
+	for i := range neurons {
+		for j := range neurons[i] {
+			for k, w := range neurons[i][j].Weights {
+				if math.IsNaN(w) || math.IsInf(w, 0) {
+					return &NonFiniteError{X: i, Y: j, Feature: k, Value: w}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// copyWeights copies neurons weights into dst, reusing it if possible.
+func copyWeights(neurons [][]*Neuron, dst [][][]float64) [][][]float64 {
+	if len(dst) != len(neurons) {
+		dst = make([][][]float64, len(neurons))
+	}
+	for i := range neurons {
+		if len(dst[i]) != len(neurons[i]) {
+			dst[i] = make([][]float64, len(neurons[i]))
+		}
+		for j := range neurons[i] {
+			weights := neurons[i][j].Weights
+			if len(dst[i][j]) != len(weights) {
+				dst[i][j] = make([]float64, len(weights))
+			}
+			copy(dst[i][j], weights)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,54 @@
+package som_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+func TestNumericGuardAbortsDivergingLearning(t *testing.T) {
+	sm := som.New(1, 2)
+	// A/(B+t) is +Inf at the first iteration
+	sm.Restraint = &som.SimpleRestraintFunc{A: 1, B: 0}
+	sm.Guard = &som.NumericGuard{}
+
+	err := sm.Learn(&som.DataSet{Vectors: []som.DataVector{{1, 2}}}, 10)
+
+	var nonFinite *som.NonFiniteError
+	if !errors.As(err, &nonFinite) || !errors.Is(err, som.ErrNonFinite) {
+		t.Fatalf("Expected NonFiniteError, got %v", err)
+	}
+	assertEq(t, nonFinite.It, 1)
+	assertEq(t, nonFinite.Feature, 0)
+}
+
+func TestNumericGuardRollsBackToCheckpoint(t *testing.T) {
+	sm := som.New(1, 2)
+	sm.Restraint = &som.SimpleRestraintFunc{A: 1, B: 0}
+	sm.Guard = &som.NumericGuard{Rollback: true}
+
+	rollbacks := 0
+	sm.Events = som.EventListenerFunc(func(event som.Event) {
+		if rollback, ok := event.(*som.RollbackEvent); ok {
+			rollbacks++
+			assertEq(t, rollback.CheckpointIt, 0)
+		}
+	})
+
+	if err := sm.Learn(&som.DataSet{Vectors: []som.DataVector{{1, 2}}}, 5); err != nil {
+		t.Fatal(err)
+	}
+
+	assertEq(t, rollbacks, 1)
+	for _, row := range sm.Neurons {
+		for _, neuron := range row {
+			for _, w := range neuron.Weights {
+				if math.IsNaN(w) || math.IsInf(w, 0) {
+					t.Fatalf("Expected weights to be finite, got %v", neuron.Weights)
+				}
+			}
+		}
+	}
+}
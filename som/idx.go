@@ -0,0 +1,235 @@
+package som
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// IDX data types, see http://yann.lecun.com/exdb/mnist/.
+const (
+	idxUnsignedByte = 0x08
+	idxSignedByte   = 0x09
+	idxShort        = 0x0B
+	idxInt          = 0x0C
+	idxFloat        = 0x0D
+	idxDouble       = 0x0E
+)
+
+var (
+	// ErrBadIDXHeader is returned by IDXReader when the stream
+	// does not start with a valid IDX magic number.
+	ErrBadIDXHeader = errors.New("bad idx header")
+)
+
+// MaxIDXItemSize limits the number of values of an item IDXReader accepts,
+// so a forged header doesn't make Next allocate a huge vector.
+const MaxIDXItemSize = 1 << 24
+
+// IDXReader reads data vectors from a stream in IDX format,
+// the format of MNIST data sets, see http://yann.lecun.com/exdb/mnist/.
+// Each item (the slice of data along the first dimension)
+// is flattened into a single data vector, so 60000x28x28 images file
+// is read as 60000 vectors of 784 elements.
+type IDXReader struct {
+	// Downsample is a factor by which the last two dimensions
+	// of each item are reduced, e.g. 2 turns 28x28 image into 14x14,
+	// each resulting value is an average of the corresponding block.
+	// Values <= 1 disable downsampling.
+	Downsample int
+
+	// Raw disables normalization. By default integer values are
+	// scaled to [0, 1] according to the range of their type,
+	// floating point values are never scaled.
+	Raw bool
+
+	r        *bufio.Reader
+	dataType byte
+	dims     []int
+	buf      []byte
+	read     int
+}
+
+// NewIDXReader reads IDX header from the given reader
+// and returns IDXReader ready to stream items.
+func NewIDXReader(r io.Reader) (*IDXReader, error) {
+	br := bufio.NewReader(r)
+	magic := make([]byte, 4)
+	if _, err := io.ReadFull(br, magic); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBadIDXHeader, err)
+	}
+	if magic[0] != 0 || magic[1] != 0 || idxTypeSize(magic[2]) == 0 || magic[3] == 0 {
+		return nil, fmt.Errorf("%w: magic %x", ErrBadIDXHeader, magic)
+	}
+
+	dims := make([]int, magic[3])
+	for i := range dims {
+		var dim uint32
+		if err := binary.Read(br, binary.BigEndian, &dim); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrBadIDXHeader, err)
+		}
+		dims[i] = int(dim)
+	}
+	size := 1
+	for _, dim := range dims[1:] {
+		if dim == 0 || size > MaxIDXItemSize/dim {
+			return nil, fmt.Errorf("%w: item dimensions %v exceed %d values", ErrBadIDXHeader, dims[1:], MaxIDXItemSize)
+		}
+		size *= dim
+	}
+
+	return &IDXReader{
+		r:        br,
+		dataType: magic[2],
+		dims:     dims,
+		buf:      make([]byte, idxTypeSize(magic[2])),
+	}, nil
+}
+
+// Dims returns dimensions of the data as declared in the header,
+// the first one is the number of items.
+func (reader *IDXReader) Dims() []int {
+	return append([]int(nil), reader.dims...)
+}
+
+// Len returns the number of items in the stream.
+func (reader *IDXReader) Len() int {
+	return reader.dims[0]
+}
+
+// Width returns the length of the vectors returned by Next,
+// taking downsampling into account.
+func (reader *IDXReader) Width() int {
+	rows, cols := reader.itemShape()
+	rows, cols = reader.downsampledShape(rows, cols)
+	return rows * cols
+}
+
+// Next reads the next item and returns it as a data vector,
+// ErrNoDataLeft is returned when all the items are read.
+func (reader *IDXReader) Next() (DataVector, error) {
+	if reader.read >= reader.Len() {
+		return nil, ErrNoDataLeft
+	}
+
+	rows, cols := reader.itemShape()
+	// the vector grows as it's read, so a truncated
+	// stream doesn't cause a huge allocation
+	capacity := rows * cols
+	if capacity > 1024 {
+		capacity = 1024
+	}
+	vector := make(DataVector, 0, capacity)
+	for i := 0; i < rows*cols; i++ {
+		v, err := reader.readValue()
+		if err != nil {
+			return nil, fmt.Errorf("reading idx item %d: %w", reader.read, err)
+		}
+		vector = append(vector, v)
+	}
+	reader.read++
+
+	return reader.downsample(vector, rows, cols), nil
+}
+
+// itemShape returns item dimensions as a matrix of rows*cols,
+// all but the last item dimensions are folded into rows.
+func (reader *IDXReader) itemShape() (int, int) {
+	if len(reader.dims) == 1 {
+		return 1, 1
+	}
+	rows := 1
+	for _, dim := range reader.dims[1 : len(reader.dims)-1] {
+		rows *= dim
+	}
+	return rows, reader.dims[len(reader.dims)-1]
+}
+
+func (reader *IDXReader) downsampledShape(rows, cols int) (int, int) {
+	if reader.Downsample <= 1 || len(reader.dims) < 3 {
+		return rows, cols
+	}
+	return (rows + reader.Downsample - 1) / reader.Downsample, (cols + reader.Downsample - 1) / reader.Downsample
+}
+
+func (reader *IDXReader) downsample(vector DataVector, rows, cols int) DataVector {
+	dRows, dCols := reader.downsampledShape(rows, cols)
+	if dRows == rows && dCols == cols {
+		return vector
+	}
+
+	f := reader.Downsample
+	result := make(DataVector, dRows*dCols)
+	for i := 0; i < dRows; i++ {
+		for j := 0; j < dCols; j++ {
+			sum, count := 0.0, 0
+			for ii := i * f; ii < (i+1)*f && ii < rows; ii++ {
+				for jj := j * f; jj < (j+1)*f && jj < cols; jj++ {
+					sum += vector[ii*cols+jj]
+					count++
+				}
+			}
+			result[i*dCols+j] = sum / float64(count)
+		}
+	}
+	return result
+}
+
+func (reader *IDXReader) readValue() (float64, error) {
+	buf := reader.buf
+	if _, err := io.ReadFull(reader.r, buf); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return 0, err
+	}
+
+	switch reader.dataType {
+	case idxUnsignedByte:
+		v := float64(buf[0])
+		if !reader.Raw {
+			v /= math.MaxUint8
+		}
+		return v, nil
+	case idxSignedByte:
+		v := float64(int8(buf[0]))
+		if !reader.Raw {
+			v = (v - math.MinInt8) / math.MaxUint8
+		}
+		return v, nil
+	case idxShort:
+		v := float64(int16(binary.BigEndian.Uint16(buf)))
+		if !reader.Raw {
+			v = (v - math.MinInt16) / math.MaxUint16
+		}
+		return v, nil
+	case idxInt:
+		v := float64(int32(binary.BigEndian.Uint32(buf)))
+		if !reader.Raw {
+			v = (v - math.MinInt32) / math.MaxUint32
+		}
+		return v, nil
+	case idxFloat:
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(buf))), nil
+	default:
+		return math.Float64frombits(binary.BigEndian.Uint64(buf)), nil
+	}
+}
+
+func idxTypeSize(dataType byte) int {
+	switch dataType {
+	case idxUnsignedByte, idxSignedByte:
+		return 1
+	case idxShort:
+		return 2
+	case idxInt, idxFloat:
+		return 4
+	case idxDouble:
+		return 8
+	default:
+		return 0
+	}
+}
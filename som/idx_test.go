@@ -0,0 +1,107 @@
+package som_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+func TestIDXReaderReadsNormalizedFlattenedItems(t *testing.T) {
+	data := []byte{
+		0, 0, 0x08, 3, // ubyte, 3 dims
+		0, 0, 0, 2, // 2 items
+		0, 0, 0, 2, // 2 rows
+		0, 0, 0, 2, // 2 cols
+		0, 255, 51, 102,
+		255, 255, 0, 0,
+	}
+
+	reader, err := som.NewIDXReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ds, err := som.ReadDataSet(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertEq(t, ds.Len(), 2)
+	checkSlicesEqual(t, ds.Vectors[0], []float64{0, 1, 0.2, 0.4})
+	checkSlicesEqual(t, ds.Vectors[1], []float64{1, 1, 0, 0})
+}
+
+func TestIDXReaderDownsamplesItems(t *testing.T) {
+	data := []byte{
+		0, 0, 0x08, 3,
+		0, 0, 0, 1,
+		0, 0, 0, 2,
+		0, 0, 0, 4,
+		0, 2, 4, 6,
+		2, 4, 6, 8,
+	}
+
+	reader, err := som.NewIDXReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader.Raw = true
+	reader.Downsample = 2
+
+	assertEq(t, reader.Width(), 2)
+	vector, err := reader.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkSlicesEqual(t, vector, []float64{2, 6})
+
+	if _, err := reader.Next(); err != som.ErrNoDataLeft {
+		t.Fatalf("Expected ErrNoDataLeft, got %v", err)
+	}
+}
+
+func TestIDXReaderRejectsBadHeader(t *testing.T) {
+	_, err := som.NewIDXReader(bytes.NewReader([]byte{1, 2, 3, 4}))
+	if !errors.Is(err, som.ErrBadIDXHeader) {
+		t.Fatalf("Expected ErrBadIDXHeader, got %v", err)
+	}
+}
+
+func TestIDXReaderRejectsHugeItems(t *testing.T) {
+	data := []byte{
+		0, 0, 0x08, 4,
+		0, 0, 0, 1,
+		0xFF, 0xFF, 0xFF, 0xFF,
+		0xFF, 0xFF, 0xFF, 0xFF,
+		0x7F, 0xFF, 0xFF, 0xFF,
+	}
+	_, err := som.NewIDXReader(bytes.NewReader(data))
+	if !errors.Is(err, som.ErrBadIDXHeader) {
+		t.Fatalf("Expected ErrBadIDXHeader, got %v", err)
+	}
+}
+
+func FuzzIDXReader(f *testing.F) {
+	f.Add([]byte{0, 0, 0x08, 3, 0, 0, 0, 2, 0, 0, 0, 2, 0, 0, 0, 2, 0, 255, 51, 102, 255, 255, 0, 0}, 0)
+	f.Add([]byte{0, 0, 0x0D, 2, 0, 0, 0, 1, 0, 0, 0, 1, 0x3F, 0x80, 0, 0}, 2)
+	f.Add([]byte{0, 0, 0x0B, 1, 0, 0, 0, 2, 0x80, 0, 0x7F, 0xFF}, 0)
+
+	f.Fuzz(func(t *testing.T, data []byte, downsample int) {
+		reader, err := som.NewIDXReader(bytes.NewReader(data))
+		if err != nil {
+			return
+		}
+		reader.Downsample = downsample % 8
+		width := reader.Width()
+		for {
+			vector, err := reader.Next()
+			if err != nil {
+				return
+			}
+			if len(vector) != width {
+				t.Fatalf("Vector has %d values, width is %d", len(vector), width)
+			}
+		}
+	})
+}
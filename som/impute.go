@@ -0,0 +1,195 @@
+package som
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// ImputingDataAdapter replaces NaN values of the k-th feature with Values[k].
+// Note that the original vector is modified.
+type ImputingDataAdapter struct {
+	Values []float64
+}
+
+func (adapter *ImputingDataAdapter) Adapt(vector []float64) []float64 {
+	for k, v := range vector {
+		if math.IsNaN(v) {
+			vector[k] = adapter.Values[k]
+		}
+	}
+	return vector
+}
+
+func (adapter *ImputingDataAdapter) Describe() ComponentSpec {
+	return ComponentSpec{Type: "imputing", Values: map[string][]float64{
+		"values": append([]float64(nil), adapter.Values...),
+	}}
+}
+
+// newImputingDataAdapter recreates the described adapter, see Describe.
+func newImputingDataAdapter(params Params, values map[string][]float64) (DataAdapter, error) {
+	if len(values["values"]) == 0 {
+		return nil, fmt.Errorf("%w: imputing needs values", ErrInvalidConfig)
+	}
+	return &ImputingDataAdapter{Values: values["values"]}, params.Check()
+}
+
+// NewMeanImputer creates ImputingDataAdapter replacing missing values
+// with the mean of the feature in the data set, NaNs are ignored.
+// Features which are missing in all the vectors are replaced with 0.
+func NewMeanImputer(ds *DataSet) *ImputingDataAdapter {
+	return newImputer(ds, func(column []float64) float64 {
+		sum := 0.0
+		for _, v := range column {
+			sum += v
+		}
+		return sum / float64(len(column))
+	})
+}
+
+// NewMedianImputer creates ImputingDataAdapter replacing missing values
+// with the median of the feature in the data set, see NewMeanImputer.
+func NewMedianImputer(ds *DataSet) *ImputingDataAdapter {
+	return newImputer(ds, func(column []float64) float64 {
+		sort.Float64s(column)
+		n := len(column)
+		if n%2 == 1 {
+			return column[n/2]
+		}
+		return (column[n/2-1] + column[n/2]) / 2
+	})
+}
+
+func newImputer(ds *DataSet, statistic func(column []float64) float64) *ImputingDataAdapter {
+	values := make([]float64, ds.Width())
+	column := make([]float64, 0, ds.Len())
+	for k := range values {
+		column = column[:0]
+		for _, vector := range ds.Vectors {
+			if !math.IsNaN(vector[k]) {
+				column = append(column, vector[k])
+			}
+		}
+		if len(column) != 0 {
+			values[k] = statistic(column)
+		}
+	}
+	return &ImputingDataAdapter{Values: values}
+}
+
+// KNNImputer replaces missing values with the average of the K nearest
+// vectors of Set which have the value. The distance between vectors is euclidean
+// over the features present in both, scaled up to the full width, so vectors
+// sharing fewer features are not favoured. Values which no vector of Set has
+// are kept NaN. Note that the original vector is modified.
+type KNNImputer struct {
+	K   int
+	Set *DataSet
+}
+
+func (imputer *KNNImputer) Adapt(vector []float64) []float64 {
+	var missing []int
+	for k, v := range vector {
+		if math.IsNaN(v) {
+			missing = append(missing, k)
+		}
+	}
+	if len(missing) == 0 {
+		return vector
+	}
+
+	type neighbour struct {
+		vector   DataVector
+		distance float64
+	}
+	neighbours := make([]neighbour, 0, imputer.Set.Len())
+	for _, candidate := range imputer.Set.Vectors {
+		if d, ok := partialDistance(vector, candidate); ok {
+			neighbours = append(neighbours, neighbour{candidate, d})
+		}
+	}
+	sort.SliceStable(neighbours, func(i, j int) bool {
+		return neighbours[i].distance < neighbours[j].distance
+	})
+
+	for _, k := range missing {
+		sum, n := 0.0, 0
+		for _, nb := range neighbours {
+			if n == imputer.K {
+				break
+			}
+			if !math.IsNaN(nb.vector[k]) {
+				sum += nb.vector[k]
+				n++
+			}
+		}
+		if n != 0 {
+			vector[k] = sum / float64(n)
+		}
+	}
+	return vector
+}
+
+// Describe returns the spec of the imputer with the vectors of Set, as JSON
+// can't encode NaNs, the missing values are zeros listed by their indices.
+func (imputer *KNNImputer) Describe() ComponentSpec {
+	width := 0
+	if imputer.Set.Len() != 0 {
+		width = imputer.Set.Width()
+	}
+	set := make([]float64, 0, imputer.Set.Len()*width)
+	var missing []float64
+	for _, vector := range imputer.Set.Vectors {
+		for _, v := range vector {
+			if math.IsNaN(v) {
+				missing = append(missing, float64(len(set)))
+				v = 0
+			}
+			set = append(set, v)
+		}
+	}
+	return ComponentSpec{
+		Type:   "knn-imputer",
+		Params: Params{"k": float64(imputer.K), "width": float64(width)},
+		Values: map[string][]float64{"set": set, "missing": missing},
+	}
+}
+
+// newKNNImputer recreates the described imputer, see Describe.
+func newKNNImputer(params Params, values map[string][]float64) (DataAdapter, error) {
+	k, width := int(params.Get("k", 0)), int(params.Get("width", 0))
+	set := append([]float64(nil), values["set"]...)
+	if k <= 0 || width < 0 || (len(set) != 0 && (width == 0 || len(set)%width != 0)) {
+		return nil, fmt.Errorf("%w: knn imputer needs positive k and set of width %d vectors", ErrInvalidConfig, width)
+	}
+	for _, idx := range values["missing"] {
+		if idx < 0 || int(idx) >= len(set) {
+			return nil, fmt.Errorf("%w: missing value index %v is out of the set", ErrInvalidConfig, idx)
+		}
+		set[int(idx)] = math.NaN()
+	}
+	ds := &DataSet{}
+	for i := 0; i < len(set); i += width {
+		ds.Vectors = append(ds.Vectors, set[i:i+width:i+width])
+	}
+	return &KNNImputer{K: k, Set: ds}, params.Check("k", "width")
+}
+
+// partialDistance returns euclidean distance over the features present
+// in both vectors, scaled by width/common, ok is false if there are no common features.
+func partialDistance(a, b []float64) (float64, bool) {
+	sum, common := 0.0, 0
+	for k := range a {
+		if math.IsNaN(a[k]) || math.IsNaN(b[k]) {
+			continue
+		}
+		d := a[k] - b[k]
+		sum += d * d
+		common++
+	}
+	if common == 0 {
+		return 0, false
+	}
+	return math.Sqrt(sum * float64(len(a)) / float64(common)), true
+}
@@ -0,0 +1,39 @@
+package som_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+var nan = math.NaN()
+
+func imputeDataSet() *som.DataSet {
+	return &som.DataSet{Vectors: []som.DataVector{
+		{1, 10, nan},
+		{2, 20, nan},
+		{9, nan, nan},
+	}}
+}
+
+func TestMeanAndMedianImputers(t *testing.T) {
+	checkSlicesEqual(t, som.NewMeanImputer(imputeDataSet()).Adapt([]float64{nan, nan, nan}), []float64{4, 15, 0})
+	checkSlicesEqual(t, som.NewMedianImputer(imputeDataSet()).Adapt([]float64{nan, 7, nan}), []float64{2, 7, 0})
+}
+
+func TestKNNImputer(t *testing.T) {
+	imputer := &som.KNNImputer{K: 2, Set: imputeDataSet()}
+
+	// the nearest ones are {1, 10} and {2, 20}
+	vector := imputer.Adapt([]float64{1.5, nan, nan})
+	assertEq(t, vector[0], 1.5)
+	assertEq(t, vector[1], 15.0)
+	if !math.IsNaN(vector[2]) {
+		t.Fatalf("Expected value missing in the set to be kept NaN, got %f", vector[2])
+	}
+
+	// {9} is the nearest, but it doesn't have the second value
+	imputer.K = 1
+	assertEq(t, imputer.Adapt([]float64{8, nan, 0})[1], 20.0)
+}
@@ -0,0 +1,107 @@
+package som
+
+import "math"
+
+// IncrementalDistances speeds up BMU search of Learn on huge maps whose
+// influence function changes a small neighbourhood of the BMU only.
+// The distances from each data vector to the neurons are cached, when the
+// vector is selected again only the distances to the neurons changed since
+// then are recomputed. A neuron is considered changed once its weights
+// drift by more than Tolerance (sum of absolute changes), so with a positive
+// tolerance the distances are approximate, which is corrected by a full
+// recomputation (validation sweep) every SweepEvery iterations.
+//
+// The cache takes a distance field per data vector and relies on
+// the selector reporting the index of the selected vector (see IndexReporter),
+// otherwise the distances are always fully computed. OnlineTrainer doesn't
+// use the cache, since streamed vectors are not selected from a data set.
+// Set it to SOM.Incremental to enable the mode.
+type IncrementalDistances struct {
+	// Tolerance is the weights drift of a neuron which is ignored,
+	// 0 means that the distances are exact.
+	Tolerance float64
+
+	// SweepEvery is the number of iterations after which
+	// all the distances are recomputed, <= 0 means never.
+	SweepEvery int
+
+	// Recomputed and Reused count distances computed
+	// and taken from the cache respectively.
+	Recomputed, Reused int
+
+	fields    map[int]DistanceField
+	stamps    map[int]int
+	changedAt [][]int
+	drift     [][]float64
+	lastSweep int
+}
+
+// reset invalidates all the cached distances,
+// called whenever weights are changed outside of Learn iterations.
+func (inc *IncrementalDistances) reset(neurons [][]*Neuron, it int) {
+	inc.fields = make(map[int]DistanceField)
+	inc.stamps = make(map[int]int)
+	inc.changedAt = make([][]int, len(neurons))
+	inc.drift = make([][]float64, len(neurons))
+	for i := range neurons {
+		inc.changedAt[i] = make([]int, len(neurons[i]))
+		for j := range inc.changedAt[i] {
+			inc.changedAt[i][j] = -1
+		}
+		inc.drift[i] = make([]float64, len(neurons[i]))
+	}
+	inc.lastSweep = it
+}
+
+// distances returns the distances from the vector selected at the given
+// iteration to the neurons, the returned field must not be modified.
+func (inc *IncrementalDistances) distances(som *SOM, it int, vector DataVector) DistanceField {
+	reporter, ok := som.Selector.(IndexReporter)
+	if !ok {
+		inc.Recomputed += len(som.Neurons) * len(som.Neurons[0])
+		return som.computeDistances(vector, som.distances)
+	}
+	if inc.SweepEvery > 0 && it-inc.lastSweep >= inc.SweepEvery {
+		inc.reset(som.Neurons, it)
+	}
+
+	idx := reporter.LastIndex()
+	field, cached := inc.fields[idx]
+	if !cached || !field.fits(som.Neurons) {
+		field = som.computeDistances(vector, nil)
+		inc.fields[idx] = field
+		inc.stamps[idx] = it
+		inc.Recomputed += len(som.Neurons) * len(som.Neurons[0])
+		return field
+	}
+
+	stamp := inc.stamps[idx]
+	for i := range som.Neurons {
+		for j := range som.Neurons[i] {
+			if inc.changedAt[i][j] < stamp {
+				inc.Reused++
+				continue
+			}
+			if som.IsMasked(i, j) {
+				field[i][j] = math.Inf(1)
+			} else {
+				field[i][j] = som.Distance.Apply(vector, som.Neurons[i][j].Weights)
+			}
+			inc.Recomputed++
+		}
+	}
+	inc.stamps[idx] = it
+	return field
+}
+
+// changed records the weights change of the neuron at (x, y) made at the given iteration.
+func (inc *IncrementalDistances) changed(x, y, it int, delta float64) {
+	if delta == 0 {
+		return
+	}
+	inc.drift[x][y] += delta
+	if inc.drift[x][y] > inc.Tolerance {
+		inc.drift[x][y] = 0
+		inc.changedAt[x][y] = it
+	}
+}
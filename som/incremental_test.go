@@ -0,0 +1,75 @@
+package som_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+func incrementalSOM() *som.SOM {
+	sm := som.New(10, 10)
+	sm.Initializer = &som.ProvidedWeightsInitializer{Weights: gridWeights(10, 10)}
+	sm.Restraint = &som.ExpRestraintFunc{InitialRate: 0.5}
+	sm.Influence = &som.RadiusReducingConstantInfluenceFunc{Radius: 1.5}
+	sm.TieBreaker = &som.LowestIndexTieBreaker{}
+	return sm
+}
+
+func gridWeights(x, y int) [][][]float64 {
+	weights := make([][][]float64, x)
+	for i := range weights {
+		weights[i] = make([][]float64, y)
+		for j := range weights[i] {
+			weights[i][j] = []float64{float64(i) / float64(x), float64(j) / float64(y)}
+		}
+	}
+	return weights
+}
+
+func TestIncrementalDistancesAreExactWithoutTolerance(t *testing.T) {
+	ds := &som.DataSet{Vectors: []som.DataVector{{0.1, 0.2}, {0.8, 0.3}, {0.5, 0.9}, {0.3, 0.6}}}
+
+	exact := incrementalSOM()
+	if _, err := exact.LearnEpochs(ds, 20, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	incremental := incrementalSOM()
+	incremental.Incremental = &som.IncrementalDistances{}
+	if _, err := incremental.LearnEpochs(ds, 20, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := range exact.Neurons {
+		for j := range exact.Neurons[i] {
+			if !reflect.DeepEqual(exact.Neurons[i][j].Weights, incremental.Neurons[i][j].Weights) {
+				t.Fatalf("Neuron (%d, %d) weights differ %v != %v", i, j, exact.Neurons[i][j].Weights, incremental.Neurons[i][j].Weights)
+			}
+		}
+	}
+	if incremental.Incremental.Reused <= incremental.Incremental.Recomputed {
+		t.Fatalf("Expected most of the distances to be reused, reused %d, recomputed %d",
+			incremental.Incremental.Reused, incremental.Incremental.Recomputed)
+	}
+}
+
+func TestIncrementalDistancesSweep(t *testing.T) {
+	ds := &som.DataSet{Vectors: []som.DataVector{{0.1, 0.2}, {0.8, 0.3}}}
+
+	sm := incrementalSOM()
+	sm.Incremental = &som.IncrementalDistances{SweepEvery: 2}
+	if err := sm.Learn(ds, 2); err != nil {
+		t.Fatal(err)
+	}
+	// each vector is selected once, so nothing is cached
+	assertEq(t, sm.Incremental.Recomputed, 200)
+	assertEq(t, sm.Incremental.Reused, 0)
+
+	sm.Incremental = &som.IncrementalDistances{SweepEvery: 2}
+	if _, err := sm.LearnEpochs(ds, 2, nil); err != nil {
+		t.Fatal(err)
+	}
+	// the sweep at the start of the second epoch invalidates the cache
+	assertEq(t, sm.Incremental.Reused, 0)
+}
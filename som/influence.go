@@ -0,0 +1,18 @@
+package som
+
+// InfluenceMap computes the values of the influence function on the map
+// of xLen*yLen size for the BMU at (bmuX, bmuY) at the given iteration,
+// so the neighbourhood the chosen radius or width params produce can be
+// inspected before a long training run, e.g. rendered as a heat map.
+// The result is indexed like Neurons, the iteration is within [0, itNum).
+func InfluenceMap(f InfluenceFunc, xLen, yLen, bmuX, bmuY, it, itNum int) [][]float64 {
+	bmu := &Neuron{X: bmuX, Y: bmuY}
+	values := make([][]float64, xLen)
+	for x := range values {
+		values[x] = make([]float64, yLen)
+		for y := range values[x] {
+			values[x][y] = f.Apply(bmu, it, itNum, x, y)
+		}
+	}
+	return values
+}
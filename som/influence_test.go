@@ -0,0 +1,130 @@
+package som_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"testing/quick"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+func influenceFuncs() []som.InfluenceFunc {
+	return []som.InfluenceFunc{
+		&som.BMUOnlyInfluencedFunc{},
+		&som.RadiusReducingConstantInfluenceFunc{Radius: 5},
+		&som.RadiusReducingConstantInfluenceFunc{Radius: 5, MinRadius: 3},
+		&som.GaussianExpDecayInfluenceFunc{InitialWidth: 4},
+		&som.GaussianExpDecayInfluenceFunc{InitialWidth: 0},
+		&som.GaussianExpDecayInfluenceFunc{InitialWidth: 4, MinWidth: 1},
+		&som.GaussianInfluenceFunc{Q: func(currentIt, iterationsNumber int) float64 {
+			return 3 * (1 - float64(currentIt)/float64(iterationsNumber))
+		}},
+		&som.AdaptiveGaussianInfluenceFunc{InitialWidth: 4, MinWidth: 1, Shrink: 0.8, Threshold: 0.01, Window: 10},
+	}
+}
+
+func TestInfluenceFuncsAreBoundedAndDecayWithDistance(t *testing.T) {
+	for _, f := range influenceFuncs() {
+		property := func(dx, dy uint8, it, itNum uint16) bool {
+			itNum = itNum%1000 + 1
+			it %= itNum
+			bmu := &som.Neuron{X: 0, Y: 0}
+			near := f.Apply(bmu, int(it), int(itNum), int(dx%50), int(dy%50))
+			farX := f.Apply(bmu, int(it), int(itNum), int(dx%50)+1, int(dy%50))
+			farY := f.Apply(bmu, int(it), int(itNum), int(dx%50), int(dy%50)+1)
+			return near >= 0 && near <= 1 && farX >= 0 && farX <= near && farY >= 0 && farY <= near
+		}
+		if err := quick.Check(property, nil); err != nil {
+			t.Fatalf("%T: %v", f, err)
+		}
+	}
+}
+
+func TestInfluenceFuncsDecayOverTime(t *testing.T) {
+	for _, f := range influenceFuncs() {
+		property := func(dx, dy uint8, it, itNum uint16) bool {
+			itNum = itNum%1000 + 2
+			it %= itNum - 1
+			bmu := &som.Neuron{X: 0, Y: 0}
+			x, y := int(dx%50), int(dy%50)
+			return f.Apply(bmu, int(it)+1, int(itNum), x, y) <= f.Apply(bmu, int(it), int(itNum), x, y)
+		}
+		if err := quick.Check(property, nil); err != nil {
+			t.Fatalf("%T: %v", f, err)
+		}
+	}
+}
+
+func TestInfluenceFuncsAlwaysInfluenceBMU(t *testing.T) {
+	for _, f := range influenceFuncs() {
+		bmu := &som.Neuron{X: 3, Y: 3}
+		if v := f.Apply(bmu, 99, 100, 3, 3); v != 1 {
+			t.Fatalf("%T: expected BMU influence 1, got %f", f, v)
+		}
+	}
+}
+
+func TestGaussianExpDecayInfluenceFuncRespectsMinWidth(t *testing.T) {
+	f := &som.GaussianExpDecayInfluenceFunc{InitialWidth: 4, MinWidth: 3}
+
+	assertEq(t, f.EffectiveRadius(0, 100), 4.0)
+	assertEq(t, f.EffectiveRadius(99, 100), 3.0)
+	if v := f.Apply(&som.Neuron{}, 99, 100, 3, 0); math.Abs(v-math.Exp(-0.5)) > 1e-12 {
+		t.Fatalf("Expected influence exp(-0.5), got %f", v)
+	}
+}
+
+func TestRadiusReducingConstantInfluenceFuncKeepsNeighbourhoodLate(t *testing.T) {
+	for _, f := range []*som.RadiusReducingConstantInfluenceFunc{{Radius: 1}, {Radius: 3}, {Radius: 3, MinRadius: 2}} {
+		bmu := &som.Neuron{X: 5, Y: 5}
+		// online learning continues far past the iterations number
+		for _, it := range []int{99, 1000, 1000000} {
+			neighbours := 0
+			for x := 0; x < 11; x++ {
+				for y := 0; y < 11; y++ {
+					if (x != bmu.X || y != bmu.Y) && f.Apply(bmu, it, 100, x, y) > 0 {
+						neighbours++
+					}
+				}
+			}
+			if neighbours < 4 {
+				t.Fatalf("%+v: expected the neighbours of the BMU to be influenced at iteration %d, got %d", *f, it, neighbours)
+			}
+		}
+	}
+
+	zero := &som.RadiusReducingConstantInfluenceFunc{Radius: 0}
+	assertEq(t, zero.EffectiveRadius(1000, 100), 0.0)
+	floored := &som.RadiusReducingConstantInfluenceFunc{Radius: 3, MinRadius: 2}
+	assertEq(t, floored.EffectiveRadius(1000000, 100), 2.0)
+}
+
+func TestInfluenceFuncsValidateParameters(t *testing.T) {
+	invalid := []som.ParamsValidator{
+		&som.RadiusReducingConstantInfluenceFunc{Radius: -1},
+		&som.GaussianExpDecayInfluenceFunc{InitialWidth: math.NaN()},
+		&som.GaussianExpDecayInfluenceFunc{InitialWidth: 1, MinWidth: -1},
+		&som.GaussianInfluenceFunc{},
+	}
+	for _, v := range invalid {
+		if err := v.Validate(); !errors.Is(err, som.ErrInvalidConfig) {
+			t.Fatalf("%T: expected ErrInvalidConfig, got %v", v, err)
+		}
+	}
+
+	if err := (&som.GaussianExpDecayInfluenceFunc{InitialWidth: 4}).Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestInfluenceMapShrinksWithIterations(t *testing.T) {
+	f := &som.GaussianExpDecayInfluenceFunc{InitialWidth: 3}
+	early := som.InfluenceMap(f, 5, 5, 2, 2, 0, 100)
+	late := som.InfluenceMap(f, 5, 5, 2, 2, 99, 100)
+	assertEq(t, early[2][2], 1.0)
+	assertEq(t, early[0][0], f.Apply(&som.Neuron{X: 2, Y: 2}, 0, 100, 0, 0))
+	if late[1][2] >= early[1][2] {
+		t.Fatalf("Expected neighbourhood to shrink, %f >= %f", late[1][2], early[1][2])
+	}
+}
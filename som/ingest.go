@@ -0,0 +1,146 @@
+package som
+
+import (
+	"errors"
+	"math/rand"
+	"sync"
+)
+
+// ErrBufferClosed is returned when vectors are pushed to a closed IngestBuffer.
+var ErrBufferClosed = errors.New("ingest buffer closed")
+
+// OverflowPolicy decides what IngestBuffer does with vectors pushed to it when it's full.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock blocks the producer until the buffer has room.
+	OverflowBlock OverflowPolicy = iota
+
+	// OverflowDropOldest drops the oldest buffered vector
+	// to make room for the pushed one.
+	OverflowDropOldest
+
+	// OverflowSample keeps a uniform random sample of the vectors pushed
+	// since the buffer got full: the n-th of them replaces a random buffered
+	// vector with probability Capacity/(Capacity+n) and is dropped otherwise.
+	OverflowSample
+)
+
+// IngestStats are the counters of an IngestBuffer.
+type IngestStats struct {
+	// Buffered is the number of vectors waiting in the buffer.
+	Buffered int
+
+	// Pushed is the number of vectors pushed to the buffer and Dropped is
+	// the number of them dropped because of overflows, either pushed or buffered.
+	Pushed, Dropped int64
+}
+
+// IngestBuffer is a bounded FIFO queue of vectors between bursty producers,
+// e.g. network handlers, and the online trainer consuming it, see
+// OnlineTrainer.Consume. When it's full, the pushed vectors are handled
+// by its overflow policy, so the memory of the trainer stays bounded.
+// IngestBuffer is safe for concurrent use.
+type IngestBuffer struct {
+	policy OverflowPolicy
+	rng    *rand.Rand
+
+	mu       sync.Mutex
+	notEmpty *sync.Cond
+	notFull  *sync.Cond
+	ring     []DataVector
+	head     int
+	size     int
+	overflow int // vectors pushed since the buffer got full, for OverflowSample
+	closed   bool
+	pushed   int64
+	dropped  int64
+}
+
+// NewIngestBuffer creates a buffer of capacity vectors, capacity <= 0 means 1.
+// The rng is used by OverflowSample, nil means the global source.
+func NewIngestBuffer(capacity int, policy OverflowPolicy, rng *rand.Rand) *IngestBuffer {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	b := &IngestBuffer{policy: policy, rng: rng, ring: make([]DataVector, capacity)}
+	b.notEmpty = sync.NewCond(&b.mu)
+	b.notFull = sync.NewCond(&b.mu)
+	return b
+}
+
+// Push adds the vector to the buffer, the vector must not be modified afterwards.
+// Returns ErrBufferClosed if the buffer is closed, even while blocked.
+func (b *IngestBuffer) Push(vector DataVector) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for b.policy == OverflowBlock && b.size == len(b.ring) && !b.closed {
+		b.notFull.Wait()
+	}
+	if b.closed {
+		return ErrBufferClosed
+	}
+	b.pushed++
+	if b.size < len(b.ring) {
+		b.overflow = 0
+		b.ring[(b.head+b.size)%len(b.ring)] = vector
+		b.size++
+		b.notEmpty.Signal()
+		return nil
+	}
+
+	b.dropped++
+	switch b.policy {
+	case OverflowDropOldest:
+		b.ring[b.head] = vector
+		b.head = (b.head + 1) % len(b.ring)
+	case OverflowSample:
+		b.overflow++
+		if i := b.intn(len(b.ring) + b.overflow); i < len(b.ring) {
+			b.ring[(b.head+i)%len(b.ring)] = vector
+		}
+	}
+	return nil
+}
+
+func (b *IngestBuffer) intn(n int) int {
+	if b.rng == nil {
+		return rand.Intn(n)
+	}
+	return b.rng.Intn(n)
+}
+
+// Pop removes and returns the oldest vector, blocking until there's one.
+// Returns false once the buffer is closed and drained.
+func (b *IngestBuffer) Pop() (DataVector, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for b.size == 0 && !b.closed {
+		b.notEmpty.Wait()
+	}
+	if b.size == 0 {
+		return nil, false
+	}
+	vector := b.ring[b.head]
+	b.ring[b.head] = nil
+	b.head = (b.head + 1) % len(b.ring)
+	b.size--
+	b.notFull.Signal()
+	return vector, true
+}
+
+// Close closes the buffer, the buffered vectors can still be popped.
+func (b *IngestBuffer) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	b.notEmpty.Broadcast()
+	b.notFull.Broadcast()
+}
+
+// Stats returns the counters of the buffer.
+func (b *IngestBuffer) Stats() IngestStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return IngestStats{Buffered: b.size, Pushed: b.pushed, Dropped: b.dropped}
+}
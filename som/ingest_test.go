@@ -0,0 +1,97 @@
+package som_test
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+func pushAll(t *testing.T, b *som.IngestBuffer, values ...float64) {
+	for _, v := range values {
+		if err := b.Push(som.DataVector{v}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func popAll(b *som.IngestBuffer) []float64 {
+	b.Close()
+	var values []float64
+	for vector, ok := b.Pop(); ok; vector, ok = b.Pop() {
+		values = append(values, vector[0])
+	}
+	return values
+}
+
+func TestIngestBufferDropsOldest(t *testing.T) {
+	b := som.NewIngestBuffer(3, som.OverflowDropOldest, nil)
+	pushAll(t, b, 1, 2, 3, 4, 5)
+	assertEq(t, som.IngestStats{Buffered: 3, Pushed: 5, Dropped: 2}, b.Stats())
+	checkSlicesEqual(t, []float64{3, 4, 5}, popAll(b))
+	if err := b.Push(som.DataVector{6}); !errors.Is(err, som.ErrBufferClosed) {
+		t.Fatalf("Expected ErrBufferClosed, got %v", err)
+	}
+}
+
+func TestIngestBufferSamples(t *testing.T) {
+	b := som.NewIngestBuffer(10, som.OverflowSample, rand.New(rand.NewSource(1)))
+	for i := 0; i < 1000; i++ {
+		pushAll(t, b, float64(i))
+	}
+	assertEq(t, som.IngestStats{Buffered: 10, Pushed: 1000, Dropped: 990}, b.Stats())
+	late := 0
+	for _, v := range popAll(b) {
+		if v >= 500 {
+			late++
+		}
+	}
+	// a uniform sample keeps the early vectors too
+	if late == 0 || late == 10 {
+		t.Fatalf("Expected a sample of the whole burst, got %d late vectors of 10", late)
+	}
+}
+
+func TestIngestBufferBlocks(t *testing.T) {
+	b := som.NewIngestBuffer(1, som.OverflowBlock, nil)
+	pushAll(t, b, 1)
+	pushed := make(chan error)
+	go func() { pushed <- b.Push(som.DataVector{2}) }()
+	select {
+	case <-pushed:
+		t.Fatal("Expected push to the full buffer to block")
+	case <-time.After(20 * time.Millisecond):
+	}
+	if vector, _ := b.Pop(); vector[0] != 1 {
+		t.Fatalf("Expected 1, got %v", vector)
+	}
+	if err := <-pushed; err != nil {
+		t.Fatal(err)
+	}
+	assertEq(t, som.IngestStats{Buffered: 1, Pushed: 2}, b.Stats())
+}
+
+func TestOnlineTrainerConsumesBuffer(t *testing.T) {
+	sm := som.New(1, 2)
+	if err := sm.LoadCodebook([][][]float64{{{0}, {1}}}); err != nil {
+		t.Fatal(err)
+	}
+	trainer := &som.OnlineTrainer{SOM: sm}
+	b := som.NewIngestBuffer(4, som.OverflowBlock, nil)
+	var errs []error
+	done := make(chan struct{})
+	go func() {
+		trainer.Consume(b, func(err error) { errs = append(errs, err) })
+		close(done)
+	}()
+	pushAll(t, b, 0, 1, 2)
+	b.Push(som.DataVector{1, 2})
+	b.Close()
+	<-done
+	assertEq(t, 3, trainer.Iterations())
+	if len(errs) != 1 || !errors.Is(errs[0], som.ErrWidthMismatch) {
+		t.Fatalf("Expected the width mismatch, got %v", errs)
+	}
+}
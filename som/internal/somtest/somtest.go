@@ -0,0 +1,27 @@
+// Package somtest provides the fixtures the tests of the som packages share.
+package somtest
+
+import (
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+// LineModel returns the model of a 1xN map of single weight neurons,
+// whose weights are the given ones in order along the line.
+func LineModel(t testing.TB, weights ...float64) *som.Model {
+	t.Helper()
+	codebook := [][][]float64{make([][]float64, len(weights))}
+	for y, w := range weights {
+		codebook[0][y] = []float64{w}
+	}
+	sm := som.New(1, len(weights))
+	if err := sm.LoadCodebook(codebook); err != nil {
+		t.Fatal(err)
+	}
+	model, err := sm.Model()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return model
+}
@@ -23,9 +23,9 @@ const (
 
 func TestIrisesClustering(t *testing.T) {
 	irises := readIrisData(t)
-	ds := &som.DataSet{}
-	for _, iris := range irises {
-		ds.Add(iris.toDataVector())
+	ds, mapper, err := som.VectorizeStructs(irises)
+	if err != nil {
+		t.Fatal(err)
 	}
 	ds.Shuffle()
 
@@ -34,27 +34,27 @@ func TestIrisesClustering(t *testing.T) {
 	somap.Influence = &som.RadiusReducingConstantInfluenceFunc{Radius: 3}
 	somap.Learn(ds, ds.Len())
 
-	compareDispersion(t, irises, somap, "sl-sw", func(iris iris) (float64, float64) {
+	compareDispersion(t, irises, somap, mapper, "sl-sw", func(iris iris) (float64, float64) {
 		return iris.SepalLength, iris.SepalWidth
 	})
-	compareDispersion(t, irises, somap, "pl-pw", func(iris iris) (float64, float64) {
+	compareDispersion(t, irises, somap, mapper, "pl-pw", func(iris iris) (float64, float64) {
 		return iris.PetalLength, iris.PetalWidth
 	})
-	compareDispersion(t, irises, somap, "pl-sw", func(iris iris) (float64, float64) {
+	compareDispersion(t, irises, somap, mapper, "pl-sw", func(iris iris) (float64, float64) {
 		return iris.PetalLength, iris.SepalWidth
 	})
-	compareDispersion(t, irises, somap, "pl-sl", func(iris iris) (float64, float64) {
+	compareDispersion(t, irises, somap, mapper, "pl-sl", func(iris iris) (float64, float64) {
 		return iris.PetalLength, iris.SepalLength
 	})
-	compareDispersion(t, irises, somap, "pw-sw", func(iris iris) (float64, float64) {
+	compareDispersion(t, irises, somap, mapper, "pw-sw", func(iris iris) (float64, float64) {
 		return iris.PetalWidth, iris.SepalWidth
 	})
-	compareDispersion(t, irises, somap, "pw-sl", func(iris iris) (float64, float64) {
+	compareDispersion(t, irises, somap, mapper, "pw-sl", func(iris iris) (float64, float64) {
 		return iris.PetalWidth, iris.SepalLength
 	})
 }
 
-func compareDispersion(t *testing.T, irises []iris, somap *som.SOM, classifier string, xyExt func(iris) (float64, float64)) {
+func compareDispersion(t *testing.T, irises []iris, somap *som.SOM, mapper *som.StructMapper, classifier string, xyExt func(iris) (float64, float64)) {
 	imgW := 200
 	imgH := 100
 
@@ -83,7 +83,9 @@ func compareDispersion(t *testing.T, irises []iris, somap *som.SOM, classifier s
 	// draw som (+imgW x offset)
 	for i := 0; i < len(somap.Neurons); i++ {
 		for j := 0; j < len(somap.Neurons[i]); j++ {
-			x, y := xyExt(toIris(somap.Neurons[i][j].Weights))
+			var neuronIris iris
+			mapper.Struct(somap.Neurons[i][j].Weights, &neuronIris)
+			x, y := xyExt(neuronIris)
 			img.SetRGBA(int(x*10)+100, int(y*10), colors["som"])
 		}
 	}
@@ -99,24 +101,6 @@ type iris struct {
 	Name        string
 }
 
-func (ir *iris) toDataVector() som.DataVector {
-	return som.DataVector{
-		ir.SepalLength,
-		ir.SepalWidth,
-		ir.PetalLength,
-		ir.PetalWidth,
-	}
-}
-
-func toIris(vector []float64) iris {
-	return iris{
-		SepalLength: vector[0],
-		SepalWidth:  vector[1],
-		PetalLength: vector[2],
-		PetalWidth:  vector[3],
-	}
-}
-
 func readIrisData(t *testing.T) []iris {
 	f, err := os.Open(irisDataSetPath)
 	if err != nil {
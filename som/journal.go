@@ -0,0 +1,254 @@
+package som
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// JournalEntry is the record of a learning iteration in the Journal,
+// or of the codebook learning starts from.
+type JournalEntry struct {
+	// It is the number of the iteration the entry records, starting from 1,
+	// or the number of the completed iterations for the codebook entry.
+	It int `json:"it"`
+
+	// Rollback means that the entry is the codebook NumericGuard restored
+	// from its checkpoint after the iteration It made the weights non-finite.
+	// The iterations since the checkpoint whose updates are not finite
+	// are not journaled, the codebook entry replaces their updates.
+	Rollback bool `json:"rollback,omitempty"`
+
+	// Time is the time the iteration completed or learning started at.
+	Time time.Time `json:"time"`
+
+	// Codebook, if not nil, is the weights learning starts from, see
+	// SOM.CopyWeights, or restores if Rollback is set, and the entry
+	// has no other fields set.
+	Codebook [][][]float64 `json:"codebook,omitempty"`
+
+	// BMU is the position of the best matching unit of the vector.
+	BMU *GridPoint `json:"bmu,omitempty"`
+
+	// Vector is the learned vector as adapted by the input adapter.
+	Vector DataVector `json:"vector,omitempty"`
+
+	// Updates are the updates of the neurons weights,
+	// the neurons which didn't change are omitted.
+	Updates []NeuronUpdate `json:"updates,omitempty"`
+
+	// Renormalized means that the updated weights were rescaled
+	// to unit length, see SOM.Renormalize.
+	Renormalized bool `json:"renormalized,omitempty"`
+
+	// Relaxation means that the entry records the updates of the stale
+	// neurons made by Forgetting after the iteration It, then Vector is
+	// the running mean of the data they relaxed toward and BMU is not set.
+	Relaxation bool `json:"relaxation,omitempty"`
+}
+
+// NeuronUpdate is the update of the weights w of the neuron at (X, Y)
+// towards the vector v of the journal entry:
+//
+//	w = w + Coefficient * (v - w)
+//
+// followed by rescaling w to unit length if the entry is Renormalized.
+type NeuronUpdate struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+
+	// Coefficient is the product of the restraint
+	// and the influence coefficients of the update.
+	Coefficient float64 `json:"coef"`
+
+	// Delta is the norm of the weights change,
+	// the sum of the absolute changes of the weights.
+	Delta float64 `json:"delta"`
+}
+
+// Journal is an append-only log of the codebook updates, which audits how
+// and when the map changed, e.g. while it learns online in regulated
+// environments. The codebook learning starts from, e.g. after Learn
+// initializes the map or OnlineTrainer.Rollback loads the snapshot, each
+// learning iteration and the stale neurons updates made by Forgetting are
+// written to W as JournalEntry in JSON on its own line,
+// so the journal can be replayed, see ReplayJournal. So is the codebook
+// NumericGuard rolls back to, see JournalEntry.Rollback.
+// A failure to write the journal fails the iteration, so does an update
+// making the weights non-finite, unless the map has NumericGuard,
+// which then aborts learning or rolls the weights back.
+// Set it to SOM.Journal to enable journaling.
+type Journal struct {
+	W io.Writer
+
+	// MinDelta is the minimal delta of a journaled update, updates changing
+	// the weights less are omitted, which keeps the journal of big maps sparse,
+	// but makes its replay approximate. 0 means that all updates are journaled.
+	MinDelta float64
+
+	entry JournalEntry
+
+	// guarded is true if the map has NumericGuard
+	guarded bool
+}
+
+// update records the update of the weights of the neuron at (x, y).
+func (j *Journal) update(x, y int, coefficient, delta float64) {
+	if coefficient == 0 || delta < j.MinDelta {
+		return
+	}
+	j.entry.Updates = append(j.entry.Updates, NeuronUpdate{X: x, Y: y, Coefficient: coefficient, Delta: delta})
+}
+
+// start writes the codebook learning starts from after it completed iterations.
+func (j *Journal) start(som *SOM, it int) error {
+	j.entry = JournalEntry{Updates: j.entry.Updates[:0]}
+	j.guarded = som.Guard != nil
+	return j.write(&JournalEntry{It: it, Time: time.Now(), Codebook: som.CopyWeights(nil)})
+}
+
+// rollback discards the recorded updates and writes the codebook
+// NumericGuard restored after the iteration it (within [1, itNum]).
+func (j *Journal) rollback(it int, som *SOM) error {
+	j.entry = JournalEntry{Updates: j.entry.Updates[:0]}
+	return j.write(&JournalEntry{It: it, Time: time.Now(), Codebook: som.CopyWeights(nil), Rollback: true})
+}
+
+// commit writes the entry of the iteration it (0 based) whose
+// updates are recorded, then the next iteration starts recording.
+func (j *Journal) commit(it int, bmu *Neuron, vector DataVector, renormalized bool) error {
+	j.entry.BMU = &GridPoint{X: bmu.X, Y: bmu.Y}
+	return j.flush(it, vector, renormalized)
+}
+
+// relax writes the entry of the stale neurons updates recorded after
+// the iteration it (0 based) toward the running mean, see Forgetting.
+func (j *Journal) relax(it int, mean DataVector, renormalized bool) error {
+	j.entry.Relaxation = true
+	return j.flush(it, mean, renormalized)
+}
+
+// flush writes the recorded entry of the iteration it (0 based) with
+// the vector its updates move the weights toward and resets the entry.
+// The entry whose updates are not finite is not written, it's an error
+// unless the map has NumericGuard, which checks the weights.
+func (j *Journal) flush(it int, vector DataVector, renormalized bool) error {
+	j.entry.It = it + 1
+	j.entry.Renormalized = renormalized
+	j.entry.Time = time.Now()
+	j.entry.Vector = vector
+	var err error
+	if !j.entry.finite() {
+		if !j.guarded {
+			err = fmt.Errorf("journal: %w: updates of iteration %d are not finite", ErrNonFinite, it+1)
+		}
+	} else {
+		err = j.write(&j.entry)
+	}
+	j.entry = JournalEntry{Updates: j.entry.Updates[:0]}
+	return err
+}
+
+// finite returns false if the vector or the updates of the entry
+// have non-finite values, which can't be written.
+func (e *JournalEntry) finite() bool {
+	for _, v := range e.Vector {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return false
+		}
+	}
+	for _, u := range e.Updates {
+		if math.IsNaN(u.Coefficient) || math.IsInf(u.Coefficient, 0) || math.IsNaN(u.Delta) || math.IsInf(u.Delta, 0) {
+			return false
+		}
+	}
+	return true
+}
+
+func (j *Journal) write(entry *JournalEntry) error {
+	if err := json.NewEncoder(j.W).Encode(entry); err != nil {
+		return fmt.Errorf("journal: %w", err)
+	}
+	return nil
+}
+
+// ReadJournal decodes the entries written by Journal one by one
+// and passes them to fn, which may stop reading by returning an error.
+// The entry passed to fn must not be retained.
+func ReadJournal(r io.Reader, fn func(entry *JournalEntry) error) error {
+	dec := json.NewDecoder(r)
+	for n := 1; ; n++ {
+		var entry JournalEntry
+		if err := dec.Decode(&entry); errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("journal entry %d: %w", n, err)
+		}
+		if err := fn(&entry); err != nil {
+			return err
+		}
+	}
+}
+
+// ReplayJournal replays the journal written by Journal and returns the map
+// with the weights restored at the given time, zero means the end of the journal.
+// The weights are restored exactly unless the journal omits small updates,
+// see Journal.MinDelta. Returns ErrInvalidConfig if the journal
+// doesn't start with the codebook.
+func ReplayJournal(r io.Reader, until time.Time) (*SOM, error) {
+	var som *SOM
+	errUntil := errors.New("until")
+	err := ReadJournal(r, func(entry *JournalEntry) error {
+		if !until.IsZero() && entry.Time.After(until) {
+			return errUntil
+		}
+		if som == nil {
+			if len(entry.Codebook) == 0 || len(entry.Codebook[0]) == 0 {
+				return fmt.Errorf("%w: journal doesn't start with the codebook", ErrInvalidConfig)
+			}
+			som = New(len(entry.Codebook), len(entry.Codebook[0]))
+		}
+		return entry.Replay(som)
+	})
+	if err != nil && err != errUntil {
+		return nil, err
+	}
+	if som == nil {
+		return nil, fmt.Errorf("%w: journal has no entries until %v", ErrInvalidConfig, until)
+	}
+	return som, nil
+}
+
+// Replay applies the entry to the map: loads the codebook of the codebook
+// entry or applies the updates of the iteration entry to the weights.
+func (e *JournalEntry) Replay(som *SOM) error {
+	if e.Codebook != nil {
+		if err := som.LoadCodebook(e.Codebook); err != nil {
+			return fmt.Errorf("iteration %d: %w", e.It, err)
+		}
+		return nil
+	}
+	if err := som.checkTrained(); err != nil {
+		return err
+	}
+	xLen, yLen := som.Dims()
+	for _, u := range e.Updates {
+		if u.X < 0 || u.X >= xLen || u.Y < 0 || u.Y >= yLen {
+			return fmt.Errorf("%w: iteration %d updates neuron (%d, %d) outside of %dx%d map", ErrInvalidConfig, e.It, u.X, u.Y, xLen, yLen)
+		}
+		weights := som.Neurons[u.X][u.Y].Weights
+		if len(e.Vector) != len(weights) {
+			return fmt.Errorf("iteration %d: %w: vector length is %d, weights length is %d", e.It, ErrWidthMismatch, len(e.Vector), len(weights))
+		}
+		for k := range weights {
+			weights[k] += u.Coefficient * (e.Vector[k] - weights[k])
+		}
+		if e.Renormalized {
+			normalize(weights)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,305 @@
+package som_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+func journaledSOM(t *testing.T) *som.SOM {
+	rng := rand.New(rand.NewSource(1))
+	codebook := make([][][]float64, 4)
+	for x := range codebook {
+		codebook[x] = make([][]float64, 4)
+		for y := range codebook[x] {
+			codebook[x][y] = []float64{rng.Float64(), rng.Float64()}
+		}
+	}
+	sm := som.New(4, 4)
+	if err := sm.LoadCodebook(codebook); err != nil {
+		t.Fatal(err)
+	}
+	sm.Initializer = &som.KeepWeightsInitializer{}
+	sm.Selector = &som.RandSelector{Rand: rand.New(rand.NewSource(2))}
+	sm.Restraint = &som.ExpRestraintFunc{InitialRate: 0.5}
+	sm.Influence = &som.GaussianExpDecayInfluenceFunc{InitialWidth: 2}
+	return sm
+}
+
+func TestJournalReplayRestoresWeights(t *testing.T) {
+	ds := &som.DataSet{}
+	rng := rand.New(rand.NewSource(3))
+	for i := 0; i < 50; i++ {
+		ds.Vectors = append(ds.Vectors, som.DataVector{rng.Float64(), rng.Float64()})
+	}
+	journal := &bytes.Buffer{}
+	sm := journaledSOM(t)
+	sm.Journal = &som.Journal{W: journal}
+	if err := sm.Learn(ds, 100); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := 0
+	err := som.ReadJournal(bytes.NewReader(journal.Bytes()), func(entry *som.JournalEntry) error {
+		if entries == 0 {
+			assertEq(t, 0, entry.It)
+			if !reflect.DeepEqual(journaledSOM(t).CopyWeights(nil), entry.Codebook) {
+				t.Fatalf("Unexpected journaled codebook %v", entry.Codebook)
+			}
+		} else {
+			assertEq(t, entries, entry.It)
+			if len(entry.Updates) != 16 {
+				t.Fatalf("Expected all 16 neurons updated, got %d", len(entry.Updates))
+			}
+		}
+		entries++
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEq(t, 101, entries)
+
+	replayed, err := som.ReplayJournal(journal, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for x := range sm.Neurons {
+		for y := range sm.Neurons[x] {
+			checkSlicesEqual(t, sm.Neurons[x][y].Weights, replayed.Neurons[x][y].Weights)
+		}
+	}
+}
+
+func TestJournalReplaysRenormalization(t *testing.T) {
+	ds := &som.DataSet{Vectors: []som.DataVector{{1, 0}, {0, 1}, {0.6, 0.8}}}
+	journal := &bytes.Buffer{}
+	sm := journaledSOM(t)
+	sm.Distance = &som.CosineDistanceFunc{}
+	sm.Renormalize = true
+	sm.Journal = &som.Journal{W: journal}
+	if err := sm.Learn(ds, 20); err != nil {
+		t.Fatal(err)
+	}
+
+	replayed, err := som.ReplayJournal(journal, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for x := range sm.Neurons {
+		for y := range sm.Neurons[x] {
+			checkSlicesEqual(t, sm.Neurons[x][y].Weights, replayed.Neurons[x][y].Weights)
+		}
+	}
+}
+
+func TestJournalOmitsSmallUpdates(t *testing.T) {
+	journal := &bytes.Buffer{}
+	sm := journaledSOM(t)
+	sm.Journal = &som.Journal{W: journal, MinDelta: 0.2}
+	if err := sm.Learn(&som.DataSet{Vectors: []som.DataVector{{0, 0}}}, 1); err != nil {
+		t.Fatal(err)
+	}
+	err := som.ReadJournal(journal, func(entry *som.JournalEntry) error {
+		if entry.Codebook != nil {
+			return nil
+		}
+		if len(entry.Updates) == 0 || len(entry.Updates) == 16 {
+			t.Fatalf("Expected the updates of the neurons close to the BMU only, got %d", len(entry.Updates))
+		}
+		for _, u := range entry.Updates {
+			if u.Delta < 0.2 {
+				t.Fatalf("Unexpected journaled update %+v", u)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReplayJournalStopsAtTime(t *testing.T) {
+	start := time.Date(2024, 11, 29, 0, 0, 0, 0, time.UTC)
+	journal := &bytes.Buffer{}
+	enc := json.NewEncoder(journal)
+	enc.Encode(&som.JournalEntry{Time: start, Codebook: [][][]float64{{{0}, {10}}}})
+	enc.Encode(&som.JournalEntry{It: 1, Time: start.Add(time.Hour), Vector: som.DataVector{4}, Updates: []som.NeuronUpdate{{X: 0, Y: 0, Coefficient: 0.5}}})
+	enc.Encode(&som.JournalEntry{It: 2, Time: start.Add(2 * time.Hour), Vector: som.DataVector{4}, Updates: []som.NeuronUpdate{{X: 0, Y: 0, Coefficient: 0.5}}})
+
+	replayed, err := som.ReplayJournal(bytes.NewReader(journal.Bytes()), start.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkSlicesEqual(t, []float64{2}, replayed.Neurons[0][0].Weights)
+
+	if _, err := som.ReplayJournal(bytes.NewReader(journal.Bytes()), start.Add(-time.Hour)); !errors.Is(err, som.ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig for no entries, got %v", err)
+	}
+	if _, err := som.ReplayJournal(bytes.NewReader([]byte(`{"it": 1}`)), time.Time{}); !errors.Is(err, som.ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig for no codebook, got %v", err)
+	}
+}
+
+func TestJournalEntryReplayRejectsForeignMap(t *testing.T) {
+	entry := &som.JournalEntry{It: 1, Vector: som.DataVector{1}, Updates: []som.NeuronUpdate{{X: 5, Y: 0, Coefficient: 1}}}
+	if err := entry.Replay(journaledSOM(t)); !errors.Is(err, som.ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig, got %v", err)
+	}
+	entry.Updates[0].X = 0
+	if err := entry.Replay(journaledSOM(t)); !errors.Is(err, som.ErrWidthMismatch) {
+		t.Fatalf("Expected ErrWidthMismatch, got %v", err)
+	}
+}
+
+func TestJournalRecordsRollback(t *testing.T) {
+	journal := &bytes.Buffer{}
+	trainer := onlineLineTrainer(t, "")
+	trainer.SOM.Journal = &som.Journal{W: journal}
+	trainer.Learn(som.DataVector{3})
+	if err := trainer.TakeSnapshot("stable"); err != nil {
+		t.Fatal(err)
+	}
+	trainer.Learn(som.DataVector{1})
+	if err := trainer.Rollback("stable"); err != nil {
+		t.Fatal(err)
+	}
+	trainer.Learn(som.DataVector{8})
+
+	replayed, err := som.ReplayJournal(journal, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	model, _ := trainer.Model()
+	checkSlicesEqual(t, model.Weights(0, 0), replayed.Neurons[0][0].Weights)
+	checkSlicesEqual(t, model.Weights(0, 1), replayed.Neurons[0][1].Weights)
+	checkSlicesEqual(t, []float64{8}, replayed.Neurons[0][1].Weights)
+}
+
+// constantRestraint is the learning rate which doesn't decay.
+type constantRestraint float64
+
+func (r constantRestraint) Apply(currentIt, iterationsNumber int) float64 { return float64(r) }
+
+func TestJournalRecordsGuardRollbacks(t *testing.T) {
+	journal := &bytes.Buffer{}
+	sm := som.New(1, 1)
+	sm.Selector = &som.RandSelector{}
+	// the weight overshoots the vector more each iteration, until it overflows
+	sm.Restraint = constantRestraint(3)
+	sm.Guard = &som.NumericGuard{Rollback: true}
+	sm.Journal = &som.Journal{W: journal}
+	if err := sm.Learn(&som.DataSet{Vectors: []som.DataVector{{1}}}, 2000); err != nil {
+		t.Fatal(err)
+	}
+
+	rollbacks := 0
+	err := som.ReadJournal(bytes.NewReader(journal.Bytes()), func(entry *som.JournalEntry) error {
+		if entry.Rollback {
+			rollbacks++
+			if entry.It <= 1000 || len(entry.Codebook) != 1 {
+				t.Fatalf("Unexpected rollback entry %+v", entry)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rollbacks == 0 {
+		t.Fatal("Expected rollbacks to be journaled")
+	}
+
+	replayed, err := som.ReplayJournal(journal, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkSlicesEqual(t, sm.Neurons[0][0].Weights, replayed.Neurons[0][0].Weights)
+}
+
+func TestJournalLeavesNonFiniteUpdatesToGuard(t *testing.T) {
+	learn := func(guard *som.NumericGuard) error {
+		sm := som.New(1, 1)
+		sm.Selector = &som.RandSelector{}
+		sm.Restraint = constantRestraint(3)
+		sm.Guard = guard
+		sm.Journal = &som.Journal{W: &bytes.Buffer{}}
+		return sm.Learn(&som.DataSet{Vectors: []som.DataVector{{1}}}, 2000)
+	}
+
+	// the updates between the checks are left to the guard
+	if err := learn(&som.NumericGuard{Every: 7, Rollback: true}); err != nil {
+		t.Fatal(err)
+	}
+	var nonFinite *som.NonFiniteError
+	if err := learn(&som.NumericGuard{Every: 7}); !errors.As(err, &nonFinite) {
+		t.Fatalf("Expected NonFiniteError of the guard, got %v", err)
+	}
+	if err := learn(nil); !errors.Is(err, som.ErrNonFinite) {
+		t.Fatalf("Expected ErrNonFinite without the guard, got %v", err)
+	}
+}
+
+func FuzzReplayJournal(f *testing.F) {
+	f.Add([]byte(`{"it":0,"codebook":[[[0],[10]]]}
+{"it":1,"vector":[4],"updates":[{"x":0,"y":0,"coef":0.5}]}
+`))
+	f.Add([]byte(`{"it":0,"codebook":[[]]}
+{"it":1,"vector":[],"updates":[{"x":0,"y":0}]}
+`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		sm, err := som.ReplayJournal(bytes.NewReader(data), time.Time{})
+		if err == nil && !sm.IsTrained() {
+			t.Fatal("Expected the replayed map trained")
+		}
+	})
+}
+
+func TestJournalReplaysForgetting(t *testing.T) {
+	journal := &bytes.Buffer{}
+	sm := journaledSOM(t)
+	sm.Journal = &som.Journal{W: journal}
+	forgetting := &som.Forgetting{Idle: 5, Rate: 0.2, MeanDecay: 0.1}
+	trainer := &som.OnlineTrainer{SOM: sm, Horizon: 50, Forgetting: forgetting}
+
+	rng := rand.New(rand.NewSource(3))
+	for i := 0; i < 100; i++ {
+		if err := trainer.Learn(som.DataVector{0.9 + 0.1*rng.Float64(), 0.9 + 0.1*rng.Float64()}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if forgetting.Relaxed == 0 {
+		t.Fatal("Expected stale neurons to be relaxed")
+	}
+
+	relaxations := 0
+	err := som.ReadJournal(bytes.NewReader(journal.Bytes()), func(entry *som.JournalEntry) error {
+		if entry.Relaxation {
+			relaxations++
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if relaxations == 0 {
+		t.Fatal("Expected relaxations to be journaled")
+	}
+
+	replayed, err := som.ReplayJournal(journal, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for x := range sm.Neurons {
+		for y := range sm.Neurons[x] {
+			checkSlicesEqual(t, sm.Neurons[x][y].Weights, replayed.Neurons[x][y].Weights)
+		}
+	}
+}
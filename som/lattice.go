@@ -0,0 +1,87 @@
+package som
+
+import "fmt"
+
+// Lattice is the arrangement of the neurons of the map which isn't
+// a rectangular grid, e.g. SphereLattice or GraphLattice, see LatticeTopology.
+// The nodes of the lattice are indexed from 0 to Len()-1.
+type Lattice interface {
+	// Len returns the number of the nodes.
+	Len() int
+
+	// Distance returns the distance between the nodes i and j along the lattice,
+	// in the units of the typical distance between neighbours, so the radii
+	// of influence functions mean about the same as on the grid maps.
+	Distance(i, j int) float64
+
+	// Neighbours returns the nodes adjacent to the node i.
+	Neighbours(i int) []int
+}
+
+// LatticeTopology arranges the neurons of the map as the nodes of the Lattice
+// rather than as the cells of the grid, see NewLattice. The neuron at (x, y)
+// of the xLen*yLen map is the node x*yLen+y, so the map must have as many
+// neurons as the lattice has nodes. The influence functions of the map must
+// implement DistanceInfluenceFunc. The U-matrix, the topographic error and
+// batch learning follow the lattice, while the analyses walking the grid,
+// e.g. Watershed or MapInterpolated, see the neurons as the cells of the grid.
+type LatticeTopology struct {
+	Lattice Lattice
+}
+
+// NewLattice creates the map of the nodes of the lattice with the same default
+// components as New. The map is 1xLen, the neuron of the node i is Neurons[0][i].
+func NewLattice(lattice Lattice) *SOM {
+	som := New(1, lattice.Len())
+	som.Topology = &LatticeTopology{Lattice: lattice}
+	return som
+}
+
+// Closest returns (x, y), the nodes have no images.
+func (t *LatticeTopology) Closest(x, y, toX, toY, xLen, yLen int) (int, int) {
+	return x, y
+}
+
+// Distance returns the distance between the nodes along the lattice.
+func (t *LatticeTopology) Distance(x1, y1, x2, y2, xLen, yLen int) float64 {
+	return t.Lattice.Distance(x1*yLen+y1, x2*yLen+y2)
+}
+
+// Neighbours returns the positions of the nodes adjacent to the node at (x, y).
+func (t *LatticeTopology) Neighbours(x, y, xLen, yLen int) [][2]int {
+	nodes := t.Lattice.Neighbours(x*yLen + y)
+	positions := make([][2]int, len(nodes))
+	for i, node := range nodes {
+		positions[i] = [2]int{node / yLen, node % yLen}
+	}
+	return positions
+}
+
+func (t *LatticeTopology) Validate() error {
+	if t.Lattice == nil {
+		return fmt.Errorf("%w: lattice is not set", ErrInvalidConfig)
+	}
+	return nil
+}
+
+// Describe returns the description of the lattice, or the spec without
+// the type if the lattice doesn't implement Describer, so the map can't
+// be saved, like the one of a topology which isn't a Describer.
+func (t *LatticeTopology) Describe() ComponentSpec {
+	if describer, ok := t.Lattice.(Describer); ok {
+		return describer.Describe()
+	}
+	return ComponentSpec{}
+}
+
+func (t *LatticeTopology) validateSize(xLen, yLen int) error {
+	if n := t.Lattice.Len(); xLen*yLen != n {
+		return fmt.Errorf("%w: %dx%d map doesn't fit the lattice of %d nodes", ErrInvalidConfig, xLen, yLen, n)
+	}
+	return nil
+}
+
+// sizeValidator is implemented by topologies which fit the maps of some sizes only.
+type sizeValidator interface {
+	validateSize(xLen, yLen int) error
+}
@@ -0,0 +1,126 @@
+package som_test
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+// sphereData returns the unit vectors scattered around the given directions.
+func sphereData(rng *rand.Rand, directions [][3]float64, n int) *som.DataSet {
+	ds := &som.DataSet{}
+	for i := 0; i < n; i++ {
+		d := directions[i%len(directions)]
+		v := som.DataVector{d[0] + 0.1*rng.NormFloat64(), d[1] + 0.1*rng.NormFloat64(), d[2] + 0.1*rng.NormFloat64()}
+		ds.Add((&som.UnitNormAdapter{}).Adapt(v))
+	}
+	return ds
+}
+
+func TestSphereMapLearns(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	ds := sphereData(rng, [][3]float64{{1, 0, 0}, {0, 0, 1}, {-1, 0, 0}}, 300)
+	sm := som.NewLattice(som.NewSphereLattice(2))
+	sm.Initializer = &som.RandDataSetVectorsWeightsInitializer{Rand: rng}
+	sm.Selector = &som.RandSelector{Rand: rng}
+	sm.Restraint = &som.ExpRestraintFunc{InitialRate: 0.5}
+	sm.Influence = &som.GaussianExpDecayInfluenceFunc{InitialWidth: 3, MinWidth: 0.5}
+
+	if err := sm.Learn(ds, 3000); err != nil {
+		t.Fatal(err)
+	}
+
+	if qe := sm.QuantizationError(ds); qe > 0.1 {
+		t.Fatalf("Expected the map to fit the clusters, quantization error is %v", qe)
+	}
+	umatrix := sm.UMatrix()
+	if len(umatrix) != 1 || len(umatrix[0]) != 162 || math.IsNaN(umatrix[0][0]) {
+		t.Fatalf("Unexpected U-matrix %v", umatrix)
+	}
+	model, err := sm.Model()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bmu, err := model.BMU(som.DataVector{0, 0, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w := model.Weights(bmu.X, bmu.Y); w[2] < 0.9 {
+		t.Fatalf("Expected the BMU of the pole to be close to it, got %v", w)
+	}
+	if _, err := model.BMU(som.DataVector{1}); !errors.Is(err, som.ErrWidthMismatch) {
+		t.Fatalf("Expected ErrWidthMismatch, got %v", err)
+	}
+}
+
+func TestSphereMapNeighbourhoodFollowsLattice(t *testing.T) {
+	lattice := som.NewSphereLattice(1)
+	sm := som.NewLattice(lattice)
+	sm.Influence = &som.GaussianInfluenceFunc{Q: func(int, int) float64 { return 1 }}
+	sm.Restraint = &som.ExpRestraintFunc{InitialRate: 1}
+	sm.TieBreaker = &som.LowestIndexTieBreaker{}
+	ds := &som.DataSet{Vectors: []som.DataVector{{1}}}
+
+	if err := sm.Learn(ds, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	// zero weights move towards 1 by the neighbourhood of the BMU, node 0
+	for i, neuron := range sm.Neurons[0] {
+		expected := math.Exp(-math.Pow(lattice.Distance(0, i), 2) / 2)
+		if math.Abs(neuron.Weights[0]-expected) > 1e-12 {
+			t.Fatalf("Node %d: expected weight %v, got %v", i, expected, neuron.Weights[0])
+		}
+	}
+}
+
+func TestSphereMapIsNotTrained(t *testing.T) {
+	if _, err := som.NewLattice(som.NewSphereLattice(0)).Model(); !errors.Is(err, som.ErrNotTrained) {
+		t.Fatalf("Expected ErrNotTrained, got %v", err)
+	}
+}
+
+func TestSphereMapReportsEmptySet(t *testing.T) {
+	s := som.NewLattice(som.NewSphereLattice(0))
+	s.Initializer = &som.RandDataSetVectorsWeightsInitializer{}
+	var trainingErr *som.TrainingError
+	if err := s.Learn(&som.DataSet{}, 2); !errors.As(err, &trainingErr) {
+		t.Fatalf("Expected TrainingError, got %v", err)
+	}
+	if err := s.LearnBatch(&som.DataSet{}, 2); !errors.Is(err, som.ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig, got %v", err)
+	}
+}
+
+func TestSphereMapIsSavedAndLoaded(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	sm := som.NewLattice(som.NewSphereLattice(1))
+	sm.Initializer = &som.RandWeightsInitializer{Rand: rng}
+	sm.Influence = &som.GaussianExpDecayInfluenceFunc{InitialWidth: 1}
+	if err := sm.Learn(sphereData(rng, [][3]float64{{1, 0, 0}}, 10), 10); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := sm.SaveBinary(buf, som.Precision{}); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := som.LoadBinary(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	topology, ok := loaded.Topology.(*som.LatticeTopology)
+	if !ok || topology.Lattice.Len() != 42 {
+		t.Fatalf("Expected the lattice of 42 nodes, got %#v", loaded.Topology)
+	}
+	assertEq(t, topology.Lattice.Distance(3, 17), sm.Topology.(*som.LatticeTopology).Lattice.Distance(3, 17))
+	checkSlicesEqual(t, loaded.UMatrix()[0], sm.UMatrix()[0])
+
+	if _, err := som.NewTopology("sphere", som.Params{"subdivisions": 100}, nil); !errors.Is(err, som.ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig for too many subdivisions, got %v", err)
+	}
+}
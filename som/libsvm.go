@@ -0,0 +1,152 @@
+package som
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ReadLIBSVM reads data set in LIBSVM sparse text format, where each line is
+//
+//	<label> <index>:<value> <index>:<value> ...
+//
+// with 1-based ascending indices. Vectors are densified, missing values
+// are zeros. If width is <= 0, the width of the data set is the maximum
+// index met in the input, which must not exceed MaxLIBSVMWidth, otherwise
+// indices greater than width are rejected. Rows of zero width, the input
+// without any index when width isn't given, are rejected with ErrEmptyVector,
+// and the densified data set must not exceed MaxLIBSVMValues.
+// Returns the data set and the labels of its vectors.
+// Malformed lines are skipped and reported by *LoadReport returned
+// along with the data set of the accepted lines, see LoadReport.
+func ReadLIBSVM(r io.Reader, width int) (*DataSet, []string, error) {
+	var (
+		rows    []sparseRow
+		labels  []string
+		maxIdx  int
+		lineNum int
+		report  = &LoadReport{}
+	)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		row, err := parseLIBSVMPairs(fields[1:], width)
+		if err != nil {
+			report.Rows++
+			report.reject(&RowError{Row: lineNum, Err: err})
+			continue
+		}
+		row.line = lineNum
+		if n := len(row.indices); n != 0 && row.indices[n-1] > maxIdx {
+			maxIdx = row.indices[n-1]
+		}
+
+		rows = append(rows, row)
+		labels = append(labels, fields[0])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	if width <= 0 {
+		width = maxIdx
+	}
+	if width > 0 && len(rows) > MaxLIBSVMValues/width {
+		return nil, nil, fmt.Errorf("libsvm: %d vectors of width %d exceed %d values", len(rows), width, MaxLIBSVMValues)
+	}
+	ds := &DataSet{}
+	accepted := labels[:0]
+	for i, row := range rows {
+		vector := make(DataVector, width)
+		for k, idx := range row.indices {
+			vector[idx-1] = row.values[k]
+		}
+		if report.addRow(ds, row.line, vector); ds.Len() > len(accepted) {
+			accepted = append(accepted, labels[i])
+		}
+	}
+	return ds, accepted, report.result()
+}
+
+// MaxLIBSVMWidth limits the indices ReadLIBSVM accepts when the width isn't
+// given, so a forged index doesn't densify every vector into a huge one.
+const MaxLIBSVMWidth = 1 << 20
+
+// MaxLIBSVMValues limits the number of values of the data set ReadLIBSVM
+// densifies, so many sparse rows don't densify into a huge data set.
+const MaxLIBSVMValues = 1 << 26
+
+type sparseRow struct {
+	line    int
+	indices []int
+	values  []float64
+}
+
+func parseLIBSVMPairs(pairs []string, width int) (sparseRow, error) {
+	row := sparseRow{}
+	prevIdx := 0
+	for _, pair := range pairs {
+		sep := strings.IndexByte(pair, ':')
+		if sep < 0 {
+			return row, fmt.Errorf("malformed pair %q", pair)
+		}
+		idx, err := strconv.Atoi(pair[:sep])
+		if err != nil || idx <= prevIdx {
+			return row, fmt.Errorf("bad index %q", pair[:sep])
+		}
+		if width > 0 && idx > width {
+			return row, fmt.Errorf("%w: index %d exceeds width %d", ErrWidthMismatch, idx, width)
+		}
+		if width <= 0 && idx > MaxLIBSVMWidth {
+			return row, fmt.Errorf("%w: index %d exceeds maximal width %d", ErrWidthMismatch, idx, MaxLIBSVMWidth)
+		}
+		value, err := strconv.ParseFloat(pair[sep+1:], 64)
+		if err != nil {
+			return row, fmt.Errorf("bad value %q", pair[sep+1:])
+		}
+		row.indices = append(row.indices, idx)
+		row.values = append(row.values, value)
+		prevIdx = idx
+	}
+	return row, nil
+}
+
+// WriteLIBSVM writes data set in LIBSVM sparse text format, see ReadLIBSVM.
+// Zero values are omitted. Labels may be nil, then each vector is labeled as 0.
+func WriteLIBSVM(w io.Writer, ds *DataSet, labels []string) error {
+	if labels != nil && len(labels) != ds.Len() {
+		return fmt.Errorf("libsvm: %d labels for %d vectors", len(labels), ds.Len())
+	}
+
+	bw := bufio.NewWriter(w)
+	for i, vector := range ds.Vectors {
+		label := "0"
+		if labels != nil {
+			label = labels[i]
+		}
+		bw.WriteString(label)
+		for k, value := range vector {
+			if value == 0 {
+				continue
+			}
+			bw.WriteByte(' ')
+			bw.WriteString(strconv.Itoa(k + 1))
+			bw.WriteByte(':')
+			bw.WriteString(strconv.FormatFloat(value, 'g', -1, 64))
+		}
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,109 @@
+package som_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+func TestReadLIBSVMDensifiesVectors(t *testing.T) {
+	input := `+1 1:0.5 3:2
+# comment line
+-1 2:1.5 # trailing comment
+`
+	ds, labels, err := som.ReadLIBSVM(strings.NewReader(input), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(labels, []string{"+1", "-1"}) {
+		t.Fatalf("Unexpected labels %v", labels)
+	}
+	checkSlicesEqual(t, ds.Vectors[0], []float64{0.5, 0, 2})
+	checkSlicesEqual(t, ds.Vectors[1], []float64{0, 1.5, 0})
+}
+
+func TestReadLIBSVMReportsMalformedLines(t *testing.T) {
+	ds, labels, err := som.ReadLIBSVM(strings.NewReader("1 1:1\n1 2:x\n2 4:1\n3 2:2\n"), 3)
+
+	var report *som.LoadReport
+	if !errors.As(err, &report) {
+		t.Fatalf("Expected LoadReport, got %v", err)
+	}
+	assertEq(t, report.Rows, 4)
+	assertEq(t, report.Rejected, 2)
+	assertEq(t, report.Errors[0].Row, 2)
+	assertEq(t, report.Errors[1].Row, 3)
+	if !errors.Is(err, som.ErrWidthMismatch) {
+		t.Fatal("Expected index exceeding width to be reported as width mismatch")
+	}
+
+	assertEq(t, ds.Len(), 2)
+	if !reflect.DeepEqual(labels, []string{"1", "3"}) {
+		t.Fatalf("Unexpected labels %v", labels)
+	}
+}
+
+func TestReadLIBSVMRejectsZeroWidthRows(t *testing.T) {
+	ds, labels, err := som.ReadLIBSVM(strings.NewReader("1\n2\n"), 0)
+
+	var report *som.LoadReport
+	if !errors.As(err, &report) || !errors.Is(err, som.ErrEmptyVector) {
+		t.Fatalf("Expected LoadReport of empty vectors, got %v", err)
+	}
+	assertEq(t, report.Rows, 2)
+	assertEq(t, report.Rejected, 2)
+	assertEq(t, ds.Len(), 0)
+	assertEq(t, len(labels), 0)
+}
+
+func TestReadLIBSVMBoundsDensifiedSize(t *testing.T) {
+	input := strings.Repeat(fmt.Sprintf("1 %d:1\n", som.MaxLIBSVMWidth), som.MaxLIBSVMValues/som.MaxLIBSVMWidth+1)
+	if _, _, err := som.ReadLIBSVM(strings.NewReader(input), 0); err == nil {
+		t.Fatal("Expected the densified size to be bounded")
+	}
+}
+
+func TestWriteLIBSVMRoundTrip(t *testing.T) {
+	ds := &som.DataSet{Vectors: []som.DataVector{{0, 1.25, 0, 3}, {7, 0, 0, 0}}}
+
+	buf := &bytes.Buffer{}
+	if err := som.WriteLIBSVM(buf, ds, []string{"a", "b"}); err != nil {
+		t.Fatal(err)
+	}
+	assertEq(t, buf.String(), "a 2:1.25 4:3\nb 1:7\n")
+
+	read, labels, err := som.ReadLIBSVM(buf, ds.Width())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(read, ds) || !reflect.DeepEqual(labels, []string{"a", "b"}) {
+		t.Fatalf("Round trip mismatch %v %v", read.Vectors, labels)
+	}
+}
+
+func FuzzReadLIBSVM(f *testing.F) {
+	f.Add("+1 1:0.5 3:2\n# comment line\n-1 2:1.5 # trailing comment\n", 0)
+	f.Add("a 1:1 2:x\nb 5:1\n", 3)
+
+	f.Fuzz(func(t *testing.T, input string, width int) {
+		ds, labels, err := som.ReadLIBSVM(strings.NewReader(input), width%100)
+		var report *som.LoadReport
+		if err != nil && !errors.As(err, &report) {
+			return
+		}
+		if len(labels) != ds.Len() {
+			t.Fatalf("%d labels for %d vectors", len(labels), ds.Len())
+		}
+		for i, vector := range ds.Vectors {
+			if len(vector) != len(ds.Vectors[0]) || len(vector) > som.MaxLIBSVMWidth {
+				t.Fatalf("Vector %d has unexpected width %d", i, len(vector))
+			}
+		}
+	})
+}
@@ -0,0 +1,112 @@
+package som
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrWidthMismatch is returned when a vector length
+	// differs from the width of the data set.
+	ErrWidthMismatch = errors.New("width mismatch")
+
+	// ErrEmptyVector is returned when a zero-length vector
+	// is added to a data set.
+	ErrEmptyVector = errors.New("empty vector")
+)
+
+// maxReportedRowErrors limits the number of errors kept by LoadReport,
+// so a completely malformed input does not produce a huge report.
+const maxReportedRowErrors = 100
+
+// RowError describes a row rejected by a loader.
+type RowError struct {
+	// Row is 1-based number of the row, for text formats it is the line number.
+	Row int
+
+	// Expected and Actual are the widths of the data set and
+	// of the row respectively, set for ErrWidthMismatch errors.
+	Expected, Actual int
+
+	Err error
+}
+
+func (e *RowError) Error() string {
+	if e.Expected > 0 {
+		return fmt.Sprintf("row %d: %v: expected %d values, got %d", e.Row, e.Err, e.Expected, e.Actual)
+	}
+	return fmt.Sprintf("row %d: %v", e.Row, e.Err)
+}
+
+func (e *RowError) Unwrap() error { return e.Err }
+
+// LoadReport collects row-level errors of a loader, which skips
+// malformed rows instead of failing on the first one. Loaders return
+// the report as an error along with the data set of accepted rows,
+// so callers may decide whether the data set is still usable:
+//
+//	ds, err := som.ReadCSV(r, true)
+//	var report *som.LoadReport
+//	if errors.As(err, &report) {
+//		log.Printf("skipped %d of %d rows: %v", report.Rejected, report.Rows, report)
+//	} else if err != nil {
+//		return err
+//	}
+type LoadReport struct {
+	// Rows is the number of read rows, Rejected is the number of skipped ones.
+	Rows, Rejected int
+
+	// Errors are the errors of the first rejected rows.
+	Errors []*RowError
+}
+
+func (report *LoadReport) Error() string {
+	messages := make([]string, len(report.Errors))
+	for i, err := range report.Errors {
+		messages[i] = err.Error()
+	}
+	more := ""
+	if report.Rejected > len(report.Errors) {
+		more = fmt.Sprintf("; and %d more", report.Rejected-len(report.Errors))
+	}
+	return fmt.Sprintf("%d of %d rows rejected: %s%s", report.Rejected, report.Rows, strings.Join(messages, "; "), more)
+}
+
+// Unwrap returns the row errors, so errors.Is(report, ErrWidthMismatch) works.
+func (report *LoadReport) Unwrap() []error {
+	errs := make([]error, len(report.Errors))
+	for i, err := range report.Errors {
+		errs[i] = err
+	}
+	return errs
+}
+
+// reject records the error of the given row.
+func (report *LoadReport) reject(err *RowError) {
+	report.Rejected++
+	if len(report.Errors) < maxReportedRowErrors {
+		report.Errors = append(report.Errors, err)
+	}
+}
+
+// addRow adds the vector to the data set, rejecting it if it does not fit.
+func (report *LoadReport) addRow(ds *DataSet, row int, vector DataVector) {
+	report.Rows++
+	if err := ds.Add(vector); err != nil {
+		rowErr := &RowError{Row: row, Err: err}
+		if errors.Is(err, ErrWidthMismatch) {
+			rowErr.Err = ErrWidthMismatch
+			rowErr.Expected, rowErr.Actual = ds.Width(), len(vector)
+		}
+		report.reject(rowErr)
+	}
+}
+
+// result returns the report as an error if any row is rejected.
+func (report *LoadReport) result() error {
+	if report.Rejected == 0 {
+		return nil
+	}
+	return report
+}
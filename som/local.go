@@ -0,0 +1,152 @@
+package som
+
+import (
+	"fmt"
+	"math"
+)
+
+// CalibrateLocalRegression is like CalibrateRegression, but it also fits
+// a linear model per neuron on the vectors mapped to it (its receptive field),
+// so Predict evaluates the local model of the closest calibrated neuron
+// instead of interpolating constant means, which fits smooth functions better.
+// The models are fitted by ridge regression, the ridge penalizes
+// the slopes but not the intercepts, 0 means ordinary least squares.
+// Neurons with too few vectors to fit a model (e.g. fewer than the vector
+// width + 1 with no ridge) keep predicting their means.
+func (m *Model) CalibrateLocalRegression(ds *DataSet, targets []float64, ridge float64) (*Model, error) {
+	if ridge < 0 {
+		return nil, fmt.Errorf("%w: ridge must be non-negative, got %f", ErrInvalidConfig, ridge)
+	}
+	calibrated, err := m.CalibrateRegression(ds, targets)
+	if err != nil {
+		return nil, err
+	}
+	vectors := make([]DataVector, ds.Len())
+	for i := range vectors {
+		vectors[i] = ds.At(i)
+	}
+	points, err := calibrated.MapBatch(vectors)
+	if err != nil {
+		return nil, err
+	}
+
+	xLen, yLen := m.Dims()
+	samples := make([][][]int, xLen)
+	for x := range samples {
+		samples[x] = make([][]int, yLen)
+	}
+	for i, p := range points {
+		samples[p.X][p.Y] = append(samples[p.X][p.Y], i)
+	}
+	reg := calibrated.regression
+	reg.Coefficients = make([][][]float64, xLen)
+	for x := range reg.Coefficients {
+		reg.Coefficients[x] = make([][]float64, yLen)
+		for y := range reg.Coefficients[x] {
+			if len(samples[x][y]) > 0 {
+				reg.Coefficients[x][y] = fitLinear(ds, targets, samples[x][y], ridge)
+			}
+		}
+	}
+	return calibrated, nil
+}
+
+// fitLinear fits the ridge regression of the targets of the vectors
+// with the given indexes, returns nil if the normal equations are singular.
+func fitLinear(ds *DataSet, targets []float64, indexes []int, ridge float64) []float64 {
+	n := len(ds.At(indexes[0])) + 1
+	a := make([][]float64, n)
+	for i := range a {
+		a[i] = make([]float64, n)
+		if i > 0 {
+			a[i][i] = ridge
+		}
+	}
+	b := make([]float64, n)
+	row := make([]float64, n)
+	for _, idx := range indexes {
+		row[0] = 1
+		copy(row[1:], ds.At(idx))
+		for i := range row {
+			for j := range row {
+				a[i][j] += row[i] * row[j]
+			}
+			b[i] += row[i] * targets[idx]
+		}
+	}
+	// the equations are scaled to the unit diagonal, so whether they are
+	// singular doesn't depend on the scale of the features
+	scale := make([]float64, n)
+	for i := range a {
+		if a[i][i] <= 0 {
+			return nil
+		}
+		scale[i] = 1 / math.Sqrt(a[i][i])
+	}
+	for i := range a {
+		for j := range a[i] {
+			a[i][j] *= scale[i] * scale[j]
+		}
+		b[i] *= scale[i]
+	}
+	x := solveLinear(a, b)
+	for i := range x {
+		x[i] *= scale[i]
+	}
+	return x
+}
+
+// solveLinear solves a*x = b by gaussian elimination with partial pivoting,
+// a and b are modified, returns nil if a is singular, i.e. a pivot is
+// negligible relative to the norm of a, so the feature scale doesn't matter.
+func solveLinear(a [][]float64, b []float64) []float64 {
+	n := len(b)
+	// the infinity norm, the maximal absolute row sum
+	norm := 0.0
+	for i := range a {
+		sum := 0.0
+		for _, v := range a[i] {
+			sum += math.Abs(v)
+		}
+		norm = math.Max(norm, sum)
+	}
+	tolerance := 1e-12 * norm
+	for col := 0; col < n; col++ {
+		pivot := col
+		for i := col + 1; i < n; i++ {
+			if math.Abs(a[i][col]) > math.Abs(a[pivot][col]) {
+				pivot = i
+			}
+		}
+		if math.Abs(a[pivot][col]) <= tolerance {
+			return nil
+		}
+		a[col], a[pivot] = a[pivot], a[col]
+		b[col], b[pivot] = b[pivot], b[col]
+		for i := col + 1; i < n; i++ {
+			f := a[i][col] / a[col][col]
+			for j := col; j < n; j++ {
+				a[i][j] -= f * a[col][j]
+			}
+			b[i] -= f * b[col]
+		}
+	}
+	x := make([]float64, n)
+	for i := n - 1; i >= 0; i-- {
+		sum := b[i]
+		for j := i + 1; j < n; j++ {
+			sum -= a[i][j] * x[j]
+		}
+		x[i] = sum / a[i][i]
+	}
+	return x
+}
+
+// linear evaluates the local model with the coefficients at the vector.
+func linear(coefficients []float64, vector DataVector) float64 {
+	y := coefficients[0]
+	for i, v := range vector {
+		y += coefficients[i+1] * v
+	}
+	return y
+}
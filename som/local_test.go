@@ -0,0 +1,91 @@
+package som_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+	"github.com/voievodin/self-organizing-map/som/internal/somtest"
+)
+
+func TestLocalRegressionFitsLinearPieces(t *testing.T) {
+	// y = 2x around 0 and y = 100 - x around 10
+	ds := &som.DataSet{Vectors: []som.DataVector{{-1}, {0}, {1}, {9}, {10}, {11}}}
+	targets := []float64{-2, 0, 2, 91, 90, 89}
+	model, err := somtest.LineModel(t, 0, 10).CalibrateLocalRegression(ds, targets, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct{ x, y float64 }{{0.5, 1}, {-2, -4}, {12, 88}} {
+		prediction, err := model.Predict(som.DataVector{tc.x})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(prediction-tc.y) > 1e-9 {
+			t.Fatalf("Expected prediction %f for %f, got %f", tc.y, tc.x, prediction)
+		}
+	}
+
+	constant, _ := somtest.LineModel(t, 0, 10).CalibrateRegression(ds, targets)
+	prediction, _ := constant.Predict(som.DataVector{0.5})
+	if math.Abs(prediction-1) < 0.5 {
+		t.Fatalf("Expected the constant model to be less accurate, got %f", prediction)
+	}
+}
+
+func TestLocalRegressionDoesNotDependOnFeatureScale(t *testing.T) {
+	// y = 2e7x, the features are too small for an absolute pivot threshold
+	ds := &som.DataSet{Vectors: []som.DataVector{{-1e-7}, {0}, {1e-7}, {2e-7}}}
+	targets := []float64{-2, 0, 2, 4}
+	model, err := somtest.LineModel(t, 0).CalibrateLocalRegression(ds, targets, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if model.Regression().Coefficients[0][0] == nil {
+		t.Fatal("Expected a local model")
+	}
+	prediction, _ := model.Predict(som.DataVector{1.5e-7})
+	if math.Abs(prediction-3) > 1e-6 {
+		t.Fatalf("Expected prediction 3, got %f", prediction)
+	}
+}
+
+func TestLocalRegressionMapsAdaptedVectors(t *testing.T) {
+	// the data set adapter moves the vectors next to the second neuron
+	ds := &som.DataSet{Vectors: []som.DataVector{{-1}, {0}, {1}}}
+	ds.SetAdapter(som.DataAdapterFunc(func(vector []float64) []float64 {
+		vector[0] += 10
+		return vector
+	}))
+	model, err := somtest.LineModel(t, 0, 10).CalibrateLocalRegression(ds, []float64{-2, 0, 2}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if model.Regression().Coefficients[0][1] == nil || model.Regression().Coefficients[0][0] != nil {
+		t.Fatal("Expected the local model of the second neuron only")
+	}
+}
+
+func TestLocalRegressionFallsBackToMean(t *testing.T) {
+	// a single vector per neuron can't fit a line without the ridge
+	ds := &som.DataSet{Vectors: []som.DataVector{{1}, {9}}}
+	model, err := somtest.LineModel(t, 0, 10).CalibrateLocalRegression(ds, []float64{3, 5}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if model.Regression().Coefficients[0][0] != nil {
+		t.Fatal("Expected no local model")
+	}
+	prediction, _ := model.Predict(som.DataVector{0})
+	assertEq(t, prediction, 3.0)
+
+	model, _ = somtest.LineModel(t, 0, 10).CalibrateLocalRegression(ds, []float64{3, 5}, 1)
+	if model.Regression().Coefficients[0][0] == nil {
+		t.Fatal("Expected the ridge to make the model solvable")
+	}
+
+	if _, err := somtest.LineModel(t, 0).CalibrateLocalRegression(ds, []float64{3, 5}, -1); !errors.Is(err, som.ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig, got %v", err)
+	}
+}
@@ -0,0 +1,80 @@
+package som
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// LogLevel is the severity of a log message.
+type LogLevel int
+
+const (
+	LogDebug LogLevel = iota
+	LogInfo
+	LogWarn
+	LogError
+)
+
+func (level LogLevel) String() string {
+	switch level {
+	case LogDebug:
+		return "DEBUG"
+	case LogInfo:
+		return "INFO"
+	case LogWarn:
+		return "WARN"
+	case LogError:
+		return "ERROR"
+	default:
+		return fmt.Sprintf("LEVEL(%d)", int(level))
+	}
+}
+
+// Logger receives messages about library activity, e.g. learning started,
+// finished or failed, so they can be routed into application logs.
+// Keyvals are alternating keys and values, keys are strings,
+// like in log/slog. See SlogLogger for log/slog integration.
+type Logger interface {
+	Log(level LogLevel, msg string, keyvals ...interface{})
+}
+
+// NoOpLogger is a default implementation of Logger, does nothing.
+type NoOpLogger struct{}
+
+func (l *NoOpLogger) Log(level LogLevel, msg string, keyvals ...interface{}) {}
+
+// StdLogger writes messages to the standard library logger
+// as "LEVEL msg key=value ...", messages below MinLevel are skipped.
+type StdLogger struct {
+	Logger   *log.Logger
+	MinLevel LogLevel
+}
+
+func (l *StdLogger) Log(level LogLevel, msg string, keyvals ...interface{}) {
+	if level < l.MinLevel {
+		return
+	}
+	sb := &strings.Builder{}
+	sb.WriteString(level.String())
+	sb.WriteByte(' ')
+	sb.WriteString(msg)
+	for i := 0; i < len(keyvals); i += 2 {
+		if i+1 < len(keyvals) {
+			fmt.Fprintf(sb, " %v=%v", keyvals[i], keyvals[i+1])
+		} else {
+			fmt.Fprintf(sb, " %v", keyvals[i])
+		}
+	}
+	if l.Logger == nil {
+		log.Print(sb.String())
+	} else {
+		l.Logger.Print(sb.String())
+	}
+}
+
+func (som *SOM) log(level LogLevel, msg string, keyvals ...interface{}) {
+	if som.Logger != nil {
+		som.Logger.Log(level, msg, keyvals...)
+	}
+}
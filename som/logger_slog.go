@@ -0,0 +1,35 @@
+//go:build go1.21
+
+package som
+
+import (
+	"context"
+	"log/slog"
+)
+
+// SlogLogger is a Logger writing to log/slog logger,
+// levels are mapped to the corresponding slog levels.
+type SlogLogger struct {
+	Logger *slog.Logger
+}
+
+func (l *SlogLogger) Log(level LogLevel, msg string, keyvals ...interface{}) {
+	logger := l.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	logger.Log(context.Background(), slogLevel(level), msg, keyvals...)
+}
+
+func slogLevel(level LogLevel) slog.Level {
+	switch level {
+	case LogDebug:
+		return slog.LevelDebug
+	case LogInfo:
+		return slog.LevelInfo
+	case LogWarn:
+		return slog.LevelWarn
+	default:
+		return slog.LevelError
+	}
+}
@@ -0,0 +1,29 @@
+//go:build go1.21
+
+package som_test
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+func TestSlogLogger(t *testing.T) {
+	buf := &bytes.Buffer{}
+	handler := slog.NewTextHandler(buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+	logger := &som.SlogLogger{Logger: slog.New(handler)}
+
+	logger.Log(som.LogWarn, "rolled back", "checkpoint", 10)
+
+	assertEq(t, strings.TrimSpace(buf.String()), "level=WARN msg=\"rolled back\" checkpoint=10")
+}
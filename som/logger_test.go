@@ -0,0 +1,46 @@
+package som_test
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+type failingSelector struct{}
+
+func (sel *failingSelector) Init(set *som.DataSet) {}
+
+func (sel *failingSelector) Next() (som.DataVector, error) {
+	return nil, errors.New("broken source")
+}
+
+func TestLearnLogsStartAndFinish(t *testing.T) {
+	buf := &bytes.Buffer{}
+	sm := som.New(2, 2)
+	sm.Logger = &som.StdLogger{Logger: log.New(buf, "", 0)}
+
+	sm.Learn(&som.DataSet{Vectors: []som.DataVector{{1}, {2}}}, 2)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assertEq(t, len(lines), 2)
+	assertEq(t, lines[0], "INFO learning started iterations=2 vectors=2")
+	if !strings.HasPrefix(lines[1], "INFO learning finished iterations=2 duration=") {
+		t.Fatalf("Unexpected finish message %q", lines[1])
+	}
+}
+
+func TestLearnLogsFailure(t *testing.T) {
+	buf := &bytes.Buffer{}
+	sm := som.New(2, 2)
+	sm.Selector = &failingSelector{}
+	sm.Logger = &som.StdLogger{Logger: log.New(buf, "", 0), MinLevel: som.LogError}
+
+	if err := sm.Learn(&som.DataSet{Vectors: []som.DataVector{{1}}}, 2); err == nil {
+		t.Fatal("Expected learning to fail")
+	}
+	assertEq(t, buf.String(), "ERROR learning failed iteration=0 error=broken source\n")
+}
@@ -0,0 +1,26 @@
+package som
+
+// CircleMask creates a mask for SOM.Mask which leaves in the map
+// only the neurons whose cell centers lie within the circle
+// inscribed into the xLen*yLen grid, i.e. an elliptic map if xLen != yLen.
+func CircleMask(xLen, yLen int) [][]bool {
+	rx, ry := float64(xLen)/2, float64(yLen)/2
+	return FuncMask(xLen, yLen, func(x, y int) bool {
+		dx := (float64(x) + 0.5 - rx) / rx
+		dy := (float64(y) + 0.5 - ry) / ry
+		return dx*dx+dy*dy > 1
+	})
+}
+
+// FuncMask creates a mask for SOM.Mask, masked(x, y) returns
+// true for the neurons which must be excluded from the map.
+func FuncMask(xLen, yLen int, masked func(x, y int) bool) [][]bool {
+	mask := make([][]bool, xLen)
+	for x := range mask {
+		mask[x] = make([]bool, yLen)
+		for y := range mask[x] {
+			mask[x][y] = masked(x, y)
+		}
+	}
+	return mask
+}
@@ -0,0 +1,74 @@
+package som_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+func TestMaskedNeuronsAreExcludedFromLearningAndTesting(t *testing.T) {
+	sm := som.New(1, 3)
+	sm.Mask = [][]bool{{false, true, false}}
+	sm.Initializer = &som.ProvidedWeightsInitializer{
+		Weights: [][][]float64{{{0}, {1}, {5}}},
+	}
+	sm.Influence = &som.GaussianExpDecayInfluenceFunc{InitialWidth: 10}
+	sm.LearnEntire(&som.DataSet{Vectors: []som.DataVector{{1}}})
+
+	assertEq(t, sm.Neurons[0][1].Weights[0], 1.0)
+	if sm.Neurons[0][0].Weights[0] == 0 || sm.Neurons[0][2].Weights[0] == 5 {
+		t.Fatal("Expected not masked neurons weights to be fixed")
+	}
+
+	bmu, field := sm.TestDistances(som.DataVector{1}, nil)
+	if bmu.Y == 1 {
+		t.Fatal("Masked neuron must not be BMU")
+	}
+	if !math.IsInf(field[0][1], 1) {
+		t.Fatalf("Expected masked neuron distance to be +Inf, got %f", field[0][1])
+	}
+}
+
+func TestCircleMask(t *testing.T) {
+	mask := som.CircleMask(4, 4)
+
+	assertEq(t, mask[0][0], true)
+	assertEq(t, mask[3][3], true)
+	assertEq(t, mask[1][1], false)
+	assertEq(t, mask[0][1], false)
+}
+
+func TestLearnRejectsInvalidMasks(t *testing.T) {
+	ds := &som.DataSet{Vectors: []som.DataVector{{1}}}
+	for _, mask := range [][][]bool{
+		{{false, false}, {false, false}},
+		{{false, false, false}, {false, false}, {false, false, false}},
+		{{true, true, true}, {true, true, true}, {true, true, true}},
+	} {
+		sm := som.New(3, 3)
+		sm.Mask = mask
+		if err := sm.Learn(ds, 1); !errors.Is(err, som.ErrInvalidConfig) {
+			t.Fatalf("Expected ErrInvalidConfig for mask %v, got %v", mask, err)
+		}
+		if err := sm.LearnBatch(ds, 1); !errors.Is(err, som.ErrInvalidConfig) {
+			t.Fatalf("Expected ErrInvalidConfig from LearnBatch for mask %v, got %v", mask, err)
+		}
+	}
+}
+
+func TestFindBMUWithoutFiniteDistances(t *testing.T) {
+	sm := som.New(2, 2)
+	if err := sm.Learn(&som.DataSet{Vectors: []som.DataVector{{1}}}, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := sm.FindBMU(som.DataVector{math.Inf(1)}); !errors.Is(err, som.ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig for the infinite vector, got %v", err)
+	}
+	sm.Mask = [][]bool{{true, true}, {true, true}}
+	if _, err := sm.FindBMU(som.DataVector{1}); !errors.Is(err, som.ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig when all the neurons are masked, got %v", err)
+	}
+}
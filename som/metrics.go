@@ -0,0 +1,82 @@
+package som
+
+import "math"
+
+// QuantizationError returns the average distance between
+// the vectors of the data set and their BMUs, copies of the vectors are
+// adapted by InDataAdapter. Returns NaN for an empty data set or if the map
+// is not trained, it panics if the vectors don't fit the weights, see Test.
+func (som *SOM) QuantizationError(ds *DataSet) float64 {
+	if ds.Len() == 0 || !som.IsTrained() {
+		return math.NaN()
+	}
+	var field DistanceField
+	var buf DataVector
+	sum := 0.0
+	for i := 0; i < ds.Len(); i++ {
+		buf = append(buf[:0], ds.At(i)...)
+		field = som.ComputeDistances(buf, field)
+		min, _, _, _ := field.minimum()
+		sum += min
+	}
+	return sum / float64(ds.Len())
+}
+
+// TopographicError returns the share of the data set vectors for which
+// the first and the second BMUs are not adjacent on the map (including diagonal
+// neighbours and the neighbours across connected edges of Topology, or the
+// neighbours of MetricTopology). Returns NaN and panics like QuantizationError does.
+func (som *SOM) TopographicError(ds *DataSet) float64 {
+	if ds.Len() == 0 || !som.IsTrained() {
+		return math.NaN()
+	}
+	xLen, yLen := len(som.Neurons), len(som.Neurons[0])
+	var field DistanceField
+	var buf DataVector
+	misses := 0
+	for i := 0; i < ds.Len(); i++ {
+		buf = append(buf[:0], ds.At(i)...)
+		field = som.ComputeDistances(buf, field)
+		x1, y1, x2, y2, ok := field.twoBest()
+		if !ok {
+			continue
+		}
+		if !som.adjacent(x1, y1, x2, y2, xLen, yLen) {
+			misses++
+		}
+	}
+	return float64(misses) / float64(ds.Len())
+}
+
+// adjacent returns true if the neurons at (x1, y1) and (x2, y2) are neighbours,
+// including the diagonal ones on the grid.
+func (som *SOM) adjacent(x1, y1, x2, y2, xLen, yLen int) bool {
+	if metric, ok := som.Topology.(MetricTopology); ok {
+		for _, p := range metric.Neighbours(x1, y1, xLen, yLen) {
+			if p[0] == x2 && p[1] == y2 {
+				return true
+			}
+		}
+		return false
+	}
+	x1, y1 = som.Topology.Closest(x1, y1, x2, y2, xLen, yLen)
+	return absInt(x1-x2) <= 1 && absInt(y1-y2) <= 1
+}
+
+// twoBest returns positions of the two smallest finite distances,
+// ok is false if there are less than two such distances.
+func (f DistanceField) twoBest() (x1, y1, x2, y2 int, ok bool) {
+	first, second := math.Inf(1), math.Inf(1)
+	x1, x2 = -1, -1
+	for i := range f {
+		for j, d := range f[i] {
+			if d < first {
+				second, x2, y2 = first, x1, y1
+				first, x1, y1 = d, i, j
+			} else if d < second {
+				second, x2, y2 = d, i, j
+			}
+		}
+	}
+	return x1, y1, x2, y2, x2 != -1
+}
@@ -0,0 +1,25 @@
+package som_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+func TestQuantizationAndTopographicErrors(t *testing.T) {
+	sm := som.New(1, 3)
+	sm.LoadCodebook([][][]float64{{{0}, {10}, {1}}})
+	ds := &som.DataSet{Vectors: []som.DataVector{{0}, {9}, {2}}}
+
+	assertEq(t, sm.QuantizationError(ds), 2.0/3)
+	// the best pairs are (0, 2), (1, 2) and (2, 0)
+	assertEq(t, sm.TopographicError(ds), 2.0/3)
+
+	sm.Topology = &som.TorusTopology{}
+	assertEq(t, sm.TopographicError(ds), 0.0)
+
+	if !math.IsNaN(sm.QuantizationError(&som.DataSet{})) {
+		t.Fatal("Expected NaN for an empty data set")
+	}
+}
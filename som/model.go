@@ -0,0 +1,258 @@
+package som
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"runtime"
+	"sync"
+)
+
+// Model is an immutable trained map: the codebook, the mask, the topology,
+// the distance function and the input adapter. Model exposes inference,
+// analysis and serialization only and is safe for concurrent use,
+// as long as its distance function and adapter are stateless,
+// which is the case for the ones provided by this package.
+// Unlike SOM, Model never modifies input vectors and resolves BMU ties
+// deterministically, choosing the neuron with the lowest index.
+type Model struct {
+	som         *SOM
+	calibration *Calibration
+	rejection   Rejection
+	regression  *Regression
+	outstar     *OutstarLayer
+}
+
+// Model returns an immutable snapshot of this trained map, the weights and
+// the mask are copied, so learning can continue without affecting the model.
+// Returns ErrNotTrained if neurons weights are not initialized.
+func (som *SOM) Model() (*Model, error) {
+	if err := som.checkTrained(); err != nil {
+		return nil, err
+	}
+	var mask [][]bool
+	if som.Mask != nil {
+		mask = make([][]bool, len(som.Mask))
+		for i := range som.Mask {
+			mask[i] = append([]bool(nil), som.Mask[i]...)
+		}
+	}
+	snapshot := loadedSOM(som.CopyWeights(nil), mask)
+	snapshot.Topology = som.Topology
+	snapshot.Distance = som.Distance
+	snapshot.InDataAdapter = som.InDataAdapter
+	if running, ok := som.InDataAdapter.(*RunningScalingDataAdapter); ok {
+		snapshot.InDataAdapter = running.Frozen()
+	}
+	snapshot.TieBreaker = &LowestIndexTieBreaker{}
+	snapshot.Logger = som.Logger
+	snapshot.SkipUndescribed = som.SkipUndescribed
+	return &Model{som: snapshot}, nil
+}
+
+// Dims returns the size of the map grid, see SOM.Dims.
+func (m *Model) Dims() (int, int) {
+	return m.som.Dims()
+}
+
+// Width returns the length of neurons weights.
+func (m *Model) Width() int {
+	return len(m.som.Neurons[0][0].Weights)
+}
+
+// Weights returns a copy of the weights of the neuron at (x, y).
+func (m *Model) Weights(x, y int) []float64 {
+	return append([]float64(nil), m.som.Neurons[x][y].Weights...)
+}
+
+// Codebook returns a copy of all the neurons weights, see SOM.CopyWeights.
+func (m *Model) Codebook() [][][]float64 {
+	return m.som.CopyWeights(nil)
+}
+
+// IsMasked returns true if the neuron at (x, y) is excluded from the map.
+func (m *Model) IsMasked(x, y int) bool {
+	return m.som.IsMasked(x, y)
+}
+
+// Topology returns the topology of the map.
+func (m *Model) Topology() Topology {
+	return m.som.Topology
+}
+
+// BMU returns the position of the best matching unit of the vector, which
+// is adapted by a copy of the adapter input, see SOM.FindBMU for errors.
+func (m *Model) BMU(vector DataVector) (GridPoint, error) {
+	bmu, err := m.som.FindBMU(append(DataVector(nil), vector...))
+	if err != nil {
+		return GridPoint{}, err
+	}
+	return GridPoint{X: bmu.X, Y: bmu.Y}, nil
+}
+
+// MapIgnoring returns the position of the best matching unit of the vector
+// like BMU does, but the features at the ignore indices are excluded from the
+// distances, e.g. the features which are unavailable at inference despite
+// being present in training, so their values in the vector don't matter.
+// The vector is adapted before the features are excluded, so the adapter must
+// not mix the features, e.g. WhiteningDataAdapter does. Returns ErrWidthMismatch
+// if the adapted vector doesn't fit the weights and ErrInvalidConfig if an index
+// is out of the weights range or all the features are ignored.
+func (m *Model) MapIgnoring(vector DataVector, ignore []int) (GridPoint, error) {
+	full, err := m.som.adaptInput(append(DataVector(nil), vector...))
+	if err != nil {
+		return GridPoint{}, err
+	}
+	width := m.Width()
+	ignored := make([]bool, width)
+	kept := width
+	for _, k := range ignore {
+		if k < 0 || k >= width {
+			return GridPoint{}, fmt.Errorf("%w: ignored feature %d is out of weights length %d", ErrInvalidConfig, k, width)
+		}
+		if !ignored[k] {
+			ignored[k] = true
+			kept--
+		}
+	}
+	if kept == 0 {
+		return GridPoint{}, fmt.Errorf("%w: all %d features are ignored", ErrInvalidConfig, width)
+	}
+
+	project := func(dst, src []float64) []float64 {
+		dst = dst[:0]
+		for k, v := range src {
+			if !ignored[k] {
+				dst = append(dst, v)
+			}
+		}
+		return dst
+	}
+	adapted := project(make(DataVector, 0, kept), full)
+	weights := make([]float64, 0, kept)
+	field := NewDistanceField(m.som.Neurons)
+	for x := range field {
+		for y := range field[x] {
+			if m.som.IsMasked(x, y) {
+				field[x][y] = math.Inf(1)
+			} else {
+				weights = project(weights, m.som.Neurons[x][y].Weights)
+				field[x][y] = m.som.Distance.Apply(adapted, weights)
+			}
+		}
+	}
+	bmu := m.som.bmu(field)
+	return GridPoint{X: bmu.X, Y: bmu.Y}, nil
+}
+
+// minBatchPerWorker is the minimal number of vectors mapped by a MapBatch
+// worker, smaller batches don't pay off the goroutine overhead.
+const minBatchPerWorker = 64
+
+// MapBatch returns the positions of BMUs of the vectors, like BMU does,
+// but amortizes the overhead of many queries: the distance field and
+// the adapted vector buffer are shared by the queries of a worker,
+// and big batches are split between GOMAXPROCS parallel workers.
+// Returns ErrWidthMismatch for the first vector which doesn't fit the weights.
+func (m *Model) MapBatch(vectors []DataVector) ([]GridPoint, error) {
+	result := make([]GridPoint, len(vectors))
+	workers := runtime.GOMAXPROCS(0)
+	if n := (len(vectors) + minBatchPerWorker - 1) / minBatchPerWorker; n < workers {
+		workers = n
+	}
+	if workers <= 1 {
+		return result, m.mapRange(vectors, result, 0, len(vectors))
+	}
+
+	errs := make([]error, workers)
+	chunk := (len(vectors) + workers - 1) / workers
+	wg := sync.WaitGroup{}
+	for w := 0; w < workers; w++ {
+		from, to := w*chunk, (w+1)*chunk
+		if to > len(vectors) {
+			to = len(vectors)
+		}
+		wg.Add(1)
+		go func(w, from, to int) {
+			defer wg.Done()
+			errs[w] = m.mapRange(vectors, result, from, to)
+		}(w, from, to)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// mapRange maps vectors[from:to] into result[from:to].
+func (m *Model) mapRange(vectors []DataVector, result []GridPoint, from, to int) error {
+	field := NewDistanceField(m.som.Neurons)
+	var buf DataVector
+	for i := from; i < to; i++ {
+		buf = append(buf[:0], vectors[i]...)
+		adapted, err := m.som.adaptInput(buf)
+		if err != nil {
+			return fmt.Errorf("vector %d: %w", i, err)
+		}
+		field = m.som.computeDistances(adapted, field)
+		bmu := m.som.bmu(field)
+		result[i] = GridPoint{X: bmu.X, Y: bmu.Y}
+	}
+	return nil
+}
+
+// Distances computes distances from the vector to the neurons into the field,
+// see SOM.ComputeDistances, the vector is not modified.
+func (m *Model) Distances(vector DataVector, field DistanceField) DistanceField {
+	return m.som.ComputeDistances(append(DataVector(nil), vector...), field)
+}
+
+// distances is Distances returning ErrWidthMismatch if the adapted
+// vector doesn't fit the weights, see SOM.DistancesTo.
+func (m *Model) distances(vector DataVector, field DistanceField) (DistanceField, error) {
+	return m.som.DistancesTo(append(DataVector(nil), vector...), field)
+}
+
+// QuantizationError returns the quantization error of the data set, see SOM.QuantizationError.
+func (m *Model) QuantizationError(ds *DataSet) float64 {
+	return m.som.QuantizationError(ds)
+}
+
+// TopographicError returns the topographic error of the data set, see SOM.TopographicError.
+func (m *Model) TopographicError(ds *DataSet) float64 {
+	return m.som.TopographicError(ds)
+}
+
+// UMatrix computes the unified distance matrix, see SOM.UMatrix.
+func (m *Model) UMatrix() [][]float64 {
+	return m.som.UMatrix()
+}
+
+// SaveJSON writes the model, see SOM.SaveJSON.
+func (m *Model) SaveJSON(w io.Writer, precision Precision) error {
+	return m.som.SaveJSON(w, precision)
+}
+
+// SaveBinary writes the model, see SOM.SaveBinary.
+func (m *Model) SaveBinary(w io.Writer, precision Precision) error {
+	return m.som.SaveBinary(w, precision)
+}
+
+// ExportCodebookCSV writes the codebook, see SOM.ExportCodebookCSV.
+func (m *Model) ExportCodebookCSV(w io.Writer, featureNames []string) error {
+	return m.som.ExportCodebookCSV(w, featureNames)
+}
+
+// Trainer returns a new SOM starting from the weights of this model,
+// with its topology, distance function and adapter, and the other
+// components set by New, so the model can be fine-tuned.
+func (m *Model) Trainer() *SOM {
+	model, _ := m.som.Model()
+	trainer := model.som
+	trainer.Initializer = &KeepWeightsInitializer{}
+	trainer.TieBreaker = &RandTieBreaker{}
+	return trainer
+}
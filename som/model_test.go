@@ -0,0 +1,167 @@
+package som_test
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+func TestModelIsSnapshotOfTrainer(t *testing.T) {
+	trainer := trainedSOM(t)
+	model, err := trainer.Model()
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := model.Weights(1, 1)
+
+	trainer.Neurons[1][1].Weights[0] += 1
+	checkSlicesEqual(t, model.Weights(1, 1), before)
+
+	model.Weights(1, 1)[0] += 1
+	checkSlicesEqual(t, model.Weights(1, 1), before)
+}
+
+func TestModelDoesNotModifyVectors(t *testing.T) {
+	trainer := trainedSOM(t)
+	trainer.InDataAdapter = som.NewScalingDataAdapter([]float64{0, 0, 0}, []float64{2, 2, 2})
+	model, err := trainer.Model()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vector := som.DataVector{1, 1, 1}
+	if _, err := model.BMU(vector); err != nil {
+		t.Fatal(err)
+	}
+	checkSlicesEqual(t, vector, []float64{1, 1, 1})
+
+	if _, err := model.BMU(som.DataVector{1}); !errors.Is(err, som.ErrWidthMismatch) {
+		t.Fatalf("Expected ErrWidthMismatch, got %v", err)
+	}
+}
+
+func TestModelMetricsDoNotModifyDataSet(t *testing.T) {
+	trainer := trainedSOM(t)
+	trainer.InDataAdapter = som.NewScalingDataAdapter([]float64{0, 0, 0}, []float64{2, 2, 2})
+	model, err := trainer.Model()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ds := &som.DataSet{Vectors: []som.DataVector{{1, 2, 0}, {0, 1, 2}}}
+
+	qe, te := model.QuantizationError(ds), model.TopographicError(ds)
+	assertEq(t, model.QuantizationError(ds), qe)
+	assertEq(t, model.TopographicError(ds), te)
+	checkSlicesEqual(t, ds.At(0), []float64{1, 2, 0})
+}
+
+func TestModelIsSafeForConcurrentInference(t *testing.T) {
+	model, err := trainedSOM(t).Model()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, _ := model.BMU(som.DataVector{0.3, 0.6, 0.9})
+
+	wg := sync.WaitGroup{}
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				if bmu, _ := model.BMU(som.DataVector{0.3, 0.6, 0.9}); bmu != expected {
+					t.Errorf("Expected BMU %v, got %v", expected, bmu)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestModelTrainerContinuesFromModelWeights(t *testing.T) {
+	model, err := trainedSOM(t).Model()
+	if err != nil {
+		t.Fatal(err)
+	}
+	trainer := model.Trainer()
+	trainer.Restraint = &som.SimpleRestraintFunc{A: 0, B: 1}
+	if err := trainer.Learn(&som.DataSet{Vectors: []som.DataVector{{5, 5, 5}}}, 1); err != nil {
+		t.Fatal(err)
+	}
+	checkSlicesEqual(t, trainer.Neurons[2][3].Weights, model.Weights(2, 3))
+}
+
+func TestUntrainedMapHasNoModel(t *testing.T) {
+	if _, err := som.New(2, 2).Model(); !errors.Is(err, som.ErrNotTrained) {
+		t.Fatalf("Expected ErrNotTrained, got %v", err)
+	}
+}
+
+func TestModelMapBatchMatchesBMU(t *testing.T) {
+	model, err := trainedSOM(t).Model()
+	if err != nil {
+		t.Fatal(err)
+	}
+	vectors := make([]som.DataVector, 1000)
+	for i := range vectors {
+		v := float64(i) / 1000
+		vectors[i] = som.DataVector{v, 1 - v, v * v}
+	}
+
+	points, err := model.MapBatch(vectors)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, vector := range vectors {
+		if bmu, _ := model.BMU(vector); bmu != points[i] {
+			t.Fatalf("Vector %d mapped to %v, BMU is %v", i, points[i], bmu)
+		}
+	}
+
+	vectors[700] = som.DataVector{1}
+	if _, err := model.MapBatch(vectors); !errors.Is(err, som.ErrWidthMismatch) {
+		t.Fatalf("Expected ErrWidthMismatch, got %v", err)
+	}
+}
+
+func TestModelMapIgnoring(t *testing.T) {
+	s := som.New(1, 2)
+	if err := s.LoadCodebook([][][]float64{{{0, 0, 100}, {1, 1, 0}}}); err != nil {
+		t.Fatal(err)
+	}
+	model, err := s.Model()
+	if err != nil {
+		t.Fatal(err)
+	}
+	vector := som.DataVector{0.1, 0.1, math.NaN()}
+
+	bmu, err := model.MapIgnoring(vector, []int{2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bmu != (som.GridPoint{X: 0, Y: 0}) {
+		t.Fatalf("Expected the ignored feature not to matter, got %v", bmu)
+	}
+	if bmu, _ := model.MapIgnoring(som.DataVector{0.1, 0.1, 0}, nil); bmu != (som.GridPoint{X: 0, Y: 1}) {
+		t.Fatalf("Expected all the features to matter without ignored ones, got %v", bmu)
+	}
+	if bmu, _ := model.MapIgnoring(som.DataVector{0.1, 0.1, 0}, []int{2, 2}); bmu != (som.GridPoint{X: 0, Y: 0}) {
+		t.Fatalf("Expected duplicate indices to be ignored once, got %v", bmu)
+	}
+	if !math.IsNaN(vector[2]) {
+		t.Fatalf("Expected the vector not to be modified, got %v", vector)
+	}
+
+	if _, err := model.MapIgnoring(som.DataVector{0, 0}, nil); !errors.Is(err, som.ErrWidthMismatch) {
+		t.Fatalf("Expected ErrWidthMismatch, got %v", err)
+	}
+	if _, err := model.MapIgnoring(vector, []int{3}); !errors.Is(err, som.ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig for out of range index, got %v", err)
+	}
+	if _, err := model.MapIgnoring(vector, []int{0, 1, 2}); !errors.Is(err, som.ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig for all features ignored, got %v", err)
+	}
+}
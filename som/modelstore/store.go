@@ -0,0 +1,357 @@
+// Package modelstore pushes and pulls versioned models to blob stores,
+// so inference servers can fetch (and hot-reload) the newest trained map.
+//
+// Models of a name are numbered 1, 2, ... in push order and may carry
+// tags, e.g. "prod". The store layout is
+//
+//	<name>/versions/<version>.somb  the model saved by som.Model.SaveBinary
+//	<name>/tags/<tag>               the version the tag points at
+//
+// The models are signed by som.WriteSigned if the registry has a key.
+// A reference to a model is "latest", a tag or a version number.
+// Names and tags are single path elements, e.g. "digits", so they
+// can't address the blobs of other models.
+package modelstore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+var (
+	// ErrNotFound is returned when a blob, a model or a tag doesn't exist.
+	ErrNotFound = errors.New("not found")
+
+	// ErrExists is returned by CreateStore when the blob already exists.
+	ErrExists = errors.New("already exists")
+)
+
+// Latest is the reference to the model with the highest version.
+const Latest = "latest"
+
+// BlobStore is a flat key-value storage of blobs, implement it
+// to keep models in S3-compatible or other object stores.
+// Keys are slash separated paths.
+type BlobStore interface {
+	// Put stores the blob under the key, replacing the existing one.
+	Put(ctx context.Context, key string, blob []byte) error
+
+	// Get returns the blob stored under the key, or an error wrapping ErrNotFound.
+	Get(ctx context.Context, key string) ([]byte, error)
+
+	// List returns the keys starting with the prefix in any order.
+	List(ctx context.Context, prefix string) ([]string, error)
+}
+
+// CreateStore is a BlobStore which creates blobs atomically, implement it
+// so concurrent pushes of a name don't overwrite each other's version,
+// e.g. by a conditional put of the object store.
+type CreateStore interface {
+	BlobStore
+
+	// Create stores the blob under the key, or returns an error wrapping
+	// ErrExists if the key exists.
+	Create(ctx context.Context, key string, blob []byte) error
+}
+
+// DirStore is a CreateStore keeping blobs as files in the directory,
+// keys escaping the directory, e.g. "../x", are rejected.
+type DirStore struct {
+	Dir string
+}
+
+func (s *DirStore) Put(ctx context.Context, key string, blob []byte) error {
+	file, tmp, err := s.writeTmp(key, blob)
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, file); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+func (s *DirStore) Create(ctx context.Context, key string, blob []byte) error {
+	file, tmp, err := s.writeTmp(key, blob)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp)
+	// unlike rename, link fails if the file exists
+	err = os.Link(tmp, file)
+	if errors.Is(err, os.ErrExist) {
+		return fmt.Errorf("blob %s: %w", key, ErrExists)
+	}
+	return err
+}
+
+// writeTmp writes the blob next to the file of the key, so it's moved
+// to the file at once and readers never see partial blobs.
+func (s *DirStore) writeTmp(key string, blob []byte) (file, tmp string, err error) {
+	if file, err = s.file(key); err != nil {
+		return "", "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return "", "", err
+	}
+	tmp, err = writeTemp(filepath.Dir(file), filepath.Base(file)+".*.tmp", blob)
+	return file, tmp, err
+}
+
+// writeTemp writes the blob to a new file of the directory,
+// whose name is made by the pattern like os.CreateTemp does.
+func writeTemp(dir, pattern string, blob []byte) (string, error) {
+	f, err := os.CreateTemp(dir, pattern)
+	if err != nil {
+		return "", err
+	}
+	if _, err = f.Write(blob); err == nil {
+		err = f.Chmod(0o644)
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// file returns the file of the key.
+func (s *DirStore) file(key string) (string, error) {
+	rel := filepath.FromSlash(key)
+	if !filepath.IsLocal(rel) {
+		return "", fmt.Errorf("%w: key %q escapes the store directory", som.ErrInvalidConfig, key)
+	}
+	return filepath.Join(s.Dir, rel), nil
+}
+
+func (s *DirStore) Get(ctx context.Context, key string) ([]byte, error) {
+	file, err := s.file(key)
+	if err != nil {
+		return nil, err
+	}
+	blob, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("blob %s: %w", key, ErrNotFound)
+	}
+	return blob, err
+}
+
+func (s *DirStore) List(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	err := filepath.WalkDir(s.Dir, func(file string, entry os.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || strings.HasSuffix(file, ".tmp") {
+			return err
+		}
+		rel, err := filepath.Rel(s.Dir, file)
+		if err != nil {
+			return err
+		}
+		if key := filepath.ToSlash(rel); strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return keys, err
+}
+
+// Registry is a client of versioned models kept in a blob store.
+type Registry struct {
+	Store BlobStore
+
+	// Precision is the precision models are pushed with.
+	Precision som.Precision
+
+	// Key, if not empty, signs the pushed models and verifies the pulled
+	// ones, which are rejected with som.ErrBadSignature unless they are
+	// signed with the key, see som.WriteSigned.
+	Key []byte
+}
+
+// Push stores the model as the next version of the name,
+// points the tags at it and returns the version.
+// Concurrent pushes of the name get distinct versions if the store is
+// a CreateStore, otherwise they may store the same version, the last
+// one winning, so they must be serialized by the caller.
+func (r *Registry) Push(ctx context.Context, name string, model *som.Model, tags ...string) (int, error) {
+	for _, tag := range tags {
+		if err := checkTag(tag); err != nil {
+			return 0, err
+		}
+	}
+	buf := &bytes.Buffer{}
+	if err := model.SaveBinary(buf, r.Precision); err != nil {
+		return 0, err
+	}
+	blob := buf.Bytes()
+	if len(r.Key) > 0 {
+		signed := &bytes.Buffer{}
+		if err := som.WriteSigned(signed, r.Key, blob); err != nil {
+			return 0, err
+		}
+		blob = signed.Bytes()
+	}
+	version, err := r.put(ctx, name, blob)
+	if err != nil {
+		return 0, err
+	}
+	for _, tag := range tags {
+		if err := r.Tag(ctx, name, version, tag); err != nil {
+			return 0, err
+		}
+	}
+	return version, nil
+}
+
+// put stores the blob as the next version of the name and returns the version.
+func (r *Registry) put(ctx context.Context, name string, blob []byte) (int, error) {
+	create, atomic := r.Store.(CreateStore)
+	for {
+		versions, err := r.Versions(ctx, name)
+		if err != nil {
+			return 0, err
+		}
+		version := 1
+		if len(versions) > 0 {
+			version = versions[len(versions)-1] + 1
+		}
+		if !atomic {
+			return version, r.Store.Put(ctx, versionKey(name, version), blob)
+		}
+		// another push took the version, take the next one
+		if err := create.Create(ctx, versionKey(name, version), blob); !errors.Is(err, ErrExists) {
+			return version, err
+		}
+	}
+}
+
+// Tag points the tag of the name at the version, moving it if it exists.
+func (r *Registry) Tag(ctx context.Context, name string, version int, tag string) error {
+	if err := checkName(name); err != nil {
+		return err
+	}
+	if err := checkTag(tag); err != nil {
+		return err
+	}
+	return r.Store.Put(ctx, path.Join(name, "tags", tag), []byte(strconv.Itoa(version)))
+}
+
+// Versions returns the versions of the name in ascending order.
+func (r *Registry) Versions(ctx context.Context, name string) ([]int, error) {
+	if err := checkName(name); err != nil {
+		return nil, err
+	}
+	keys, err := r.Store.List(ctx, path.Join(name, "versions")+"/")
+	if err != nil {
+		return nil, err
+	}
+	var versions []int
+	for _, key := range keys {
+		if v, err := strconv.Atoi(strings.TrimSuffix(path.Base(key), ".somb")); err == nil {
+			versions = append(versions, v)
+		}
+	}
+	sort.Ints(versions)
+	return versions, nil
+}
+
+// Resolve returns the version the reference of the name points at.
+func (r *Registry) Resolve(ctx context.Context, name, ref string) (int, error) {
+	switch {
+	case ref == Latest:
+		versions, err := r.Versions(ctx, name)
+		if err != nil {
+			return 0, err
+		}
+		if len(versions) == 0 {
+			return 0, fmt.Errorf("model %s: %w", name, ErrNotFound)
+		}
+		return versions[len(versions)-1], nil
+	case isVersion(ref):
+		if err := checkName(name); err != nil {
+			return 0, err
+		}
+		return strconv.Atoi(ref)
+	default:
+		if err := checkName(name); err != nil {
+			return 0, err
+		}
+		if err := checkTag(ref); err != nil {
+			return 0, err
+		}
+		blob, err := r.Store.Get(ctx, path.Join(name, "tags", ref))
+		if err != nil {
+			return 0, fmt.Errorf("model %s tag %s: %w", name, ref, err)
+		}
+		return strconv.Atoi(strings.TrimSpace(string(blob)))
+	}
+}
+
+// Pull resolves the reference of the name and returns the model with its version.
+func (r *Registry) Pull(ctx context.Context, name, ref string) (*som.Model, int, error) {
+	version, err := r.Resolve(ctx, name, ref)
+	if err != nil {
+		return nil, 0, err
+	}
+	blob, err := r.Store.Get(ctx, versionKey(name, version))
+	if err != nil {
+		return nil, 0, fmt.Errorf("model %s version %d: %w", name, version, err)
+	}
+	if len(r.Key) > 0 {
+		if blob, err = som.ReadSigned(bytes.NewReader(blob), r.Key); err != nil {
+			return nil, 0, fmt.Errorf("model %s version %d: %w", name, version, err)
+		}
+	}
+	sm, err := som.LoadBinary(bytes.NewReader(blob))
+	if err != nil {
+		return nil, 0, err
+	}
+	model, err := sm.Model()
+	return model, version, err
+}
+
+func versionKey(name string, version int) string {
+	return path.Join(name, "versions", strconv.Itoa(version)+".somb")
+}
+
+// checkName returns ErrInvalidConfig unless the name is a single path element.
+func checkName(name string) error {
+	if !isElement(name) {
+		return fmt.Errorf("%w: invalid model name %q", som.ErrInvalidConfig, name)
+	}
+	return nil
+}
+
+// checkTag returns ErrInvalidConfig unless the tag is a single path element
+// which isn't a reference by itself.
+func checkTag(tag string) error {
+	if !isElement(tag) || tag == Latest || isVersion(tag) {
+		return fmt.Errorf("%w: invalid tag %q", som.ErrInvalidConfig, tag)
+	}
+	return nil
+}
+
+func isElement(s string) bool {
+	return s != "" && s != "." && s != ".." && !strings.ContainsAny(s, `/\`)
+}
+
+func isVersion(ref string) bool {
+	_, err := strconv.Atoi(ref)
+	return err == nil
+}
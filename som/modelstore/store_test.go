@@ -0,0 +1,148 @@
+package modelstore_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+	"github.com/voievodin/self-organizing-map/som/internal/somtest"
+	"github.com/voievodin/self-organizing-map/som/modelstore"
+)
+
+func pull(t *testing.T, registry *modelstore.Registry, ref string) (float64, int) {
+	model, version, err := registry.Pull(context.Background(), "digits", ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return model.Weights(0, 0)[0], version
+}
+
+func TestRegistryResolvesLatestTagsAndVersions(t *testing.T) {
+	ctx := context.Background()
+	registry := &modelstore.Registry{Store: &modelstore.DirStore{Dir: t.TempDir()}}
+	for i, tags := range [][]string{{"prod"}, nil, {"canary"}} {
+		version, err := registry.Push(ctx, "digits", somtest.LineModel(t, float64(i+1), 0), tags...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if version != i+1 {
+			t.Fatalf("Pushed version %d, expected %d", version, i+1)
+		}
+	}
+
+	for ref, expected := range map[string]int{"latest": 3, "prod": 1, "canary": 3, "2": 2} {
+		w, version := pull(t, registry, ref)
+		if version != expected || w != float64(expected) {
+			t.Fatalf("Pulled %s as version %d with weight %v, expected version %d", ref, version, w, expected)
+		}
+	}
+
+	if err := registry.Tag(ctx, "digits", 2, "prod"); err != nil {
+		t.Fatal(err)
+	}
+	if _, version := pull(t, registry, "prod"); version != 2 {
+		t.Fatalf("Moved tag resolves to version %d, expected 2", version)
+	}
+}
+
+func TestRegistryReportsMissingModels(t *testing.T) {
+	ctx := context.Background()
+	registry := &modelstore.Registry{Store: &modelstore.DirStore{Dir: t.TempDir()}}
+	if _, _, err := registry.Pull(ctx, "digits", modelstore.Latest); !errors.Is(err, modelstore.ErrNotFound) {
+		t.Fatalf("Expected ErrNotFound for no versions, got %v", err)
+	}
+	if _, err := registry.Push(ctx, "digits", somtest.LineModel(t, 1, 2)); err != nil {
+		t.Fatal(err)
+	}
+	for _, ref := range []string{"prod", "7"} {
+		if _, _, err := registry.Pull(ctx, "digits", ref); !errors.Is(err, modelstore.ErrNotFound) {
+			t.Fatalf("Expected ErrNotFound for %s, got %v", ref, err)
+		}
+	}
+	if err := registry.Tag(ctx, "digits", 1, "latest"); err == nil {
+		t.Fatal("Expected error for reserved tag")
+	}
+}
+
+func TestRegistrySignsModels(t *testing.T) {
+	ctx := context.Background()
+	store := &modelstore.DirStore{Dir: t.TempDir()}
+	registry := &modelstore.Registry{Store: store, Key: []byte("secret")}
+	if _, err := registry.Push(ctx, "digits", somtest.LineModel(t, 1, 2)); err != nil {
+		t.Fatal(err)
+	}
+	if w, _ := pull(t, registry, modelstore.Latest); w != 1 {
+		t.Fatalf("Pulled weight %v, expected 1", w)
+	}
+
+	for _, other := range []*modelstore.Registry{{Store: store, Key: []byte("other")}, {Store: store}} {
+		if _, _, err := other.Pull(ctx, "digits", modelstore.Latest); err == nil {
+			t.Fatal("Expected the model signed with another key not to be pulled")
+		}
+	}
+	if _, err := (&modelstore.Registry{Store: store}).Push(ctx, "digits", somtest.LineModel(t, 3, 4)); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := registry.Pull(ctx, "digits", "2"); !errors.Is(err, som.ErrBadSignature) {
+		t.Fatalf("Expected ErrBadSignature for the unsigned model, got %v", err)
+	}
+}
+
+func TestRegistryRejectsNamesAndRefsEscapingTheModel(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	registry := &modelstore.Registry{Store: &modelstore.DirStore{Dir: filepath.Join(dir, "store")}}
+	for _, name := range []string{"", ".", "..", "../x", "a/b"} {
+		if _, err := registry.Push(ctx, name, somtest.LineModel(t, 1)); !errors.Is(err, som.ErrInvalidConfig) {
+			t.Fatalf("Expected ErrInvalidConfig for name %q, got %v", name, err)
+		}
+		if _, err := registry.Resolve(ctx, name, modelstore.Latest); !errors.Is(err, som.ErrInvalidConfig) {
+			t.Fatalf("Expected ErrInvalidConfig resolving name %q, got %v", name, err)
+		}
+	}
+	for _, ref := range []string{"..", "../../x", "a/b"} {
+		if _, err := registry.Resolve(ctx, "digits", ref); !errors.Is(err, som.ErrInvalidConfig) {
+			t.Fatalf("Expected ErrInvalidConfig for ref %q, got %v", ref, err)
+		}
+	}
+	if err := registry.Tag(ctx, "digits", 1, "latest"); !errors.Is(err, som.ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig for the reserved tag, got %v", err)
+	}
+	if _, err := (&modelstore.DirStore{Dir: dir}).Get(ctx, "../x"); !errors.Is(err, som.ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig for the key escaping the directory, got %v", err)
+	}
+}
+
+func TestRegistryPushesConcurrentlyToDistinctVersions(t *testing.T) {
+	ctx := context.Background()
+	registry := &modelstore.Registry{Store: &modelstore.DirStore{Dir: t.TempDir()}}
+	model := somtest.LineModel(t, 1)
+	versions := make([]int, 8)
+	errs := make([]error, len(versions))
+	wg := sync.WaitGroup{}
+	for i := range versions {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			versions[i], errs[i] = registry.Push(ctx, "digits", model)
+		}(i)
+	}
+	wg.Wait()
+
+	seen := map[int]bool{}
+	for i, version := range versions {
+		if errs[i] != nil {
+			t.Fatal(errs[i])
+		}
+		if seen[version] {
+			t.Fatalf("Version %d pushed twice", version)
+		}
+		seen[version] = true
+	}
+	if stored, err := registry.Versions(ctx, "digits"); err != nil || len(stored) != len(versions) {
+		t.Fatalf("Expected %d stored versions, got %v, %v", len(versions), stored, err)
+	}
+}
@@ -0,0 +1,5 @@
+//go:build !race
+
+package som_test
+
+const raceEnabled = false
@@ -0,0 +1,184 @@
+package som
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// OnlineTrainer teaches the map from an endless stream of vectors, one
+// vector at a time, e.g. telemetry received over the network, while the
+// current map stays readable. The restraint and the influence functions of
+// the map follow their schedules over the first Horizon iterations as they do
+// in Learn and then hold their final values, so the map keeps adapting to
+// new data. Events, the guard, the watchdog and the snapshot publisher of
+// the map work as in Learn, the iteration events report the position
+// in the schedule. OnlineTrainer is safe for concurrent use.
+//
+// If the map has no weights, the first Warmup vectors are collected
+// and the map is initialized from them by its Initializer, then it learns
+// them and the rest of the stream.
+type OnlineTrainer struct {
+	SOM *SOM
+
+	// Horizon is the number of iterations the schedules
+	// of the learning parameters span, <= 0 means 10000.
+	Horizon int
+
+	// Warmup is the number of vectors the map is initialized from,
+	// <= 0 means the number of neurons.
+	Warmup int
+
+	// Forgetting, if not nil, relaxes the stale neurons
+	// toward the current data, see Forgetting.
+	Forgetting *Forgetting
+
+	// Drift, if not nil, raises alarms when the recent data
+	// diverges from the reference, see DriftDetector.
+	Drift *DriftDetector
+
+	// SnapshotDir is the directory the named snapshots are saved to,
+	// empty means that they are kept in memory only, see TakeSnapshot.
+	SnapshotDir string
+
+	mu        sync.Mutex
+	it        int
+	started   bool
+	warmup    *DataSet
+	snapshots map[string]*Model
+}
+
+// Learn does a learning iteration of the vector, which is not modified,
+// see SOM.Learn for the errors. The vector is only collected
+// if the map is not initialized yet, see Warmup.
+func (t *OnlineTrainer) Learn(vector DataVector) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.started {
+		return t.collect(vector)
+	}
+	return t.learn(append(DataVector(nil), vector...))
+}
+
+// learn does the iteration of the copy of the vector.
+func (t *OnlineTrainer) learn(vector DataVector) (err error) {
+	som := t.SOM
+	defer func() {
+		if r := recover(); r != nil {
+			err = som.panicError(t.it, vector, r)
+		}
+	}()
+	horizon := t.Horizon
+	if horizon <= 0 {
+		horizon = 10000
+	}
+	schedule := t.it
+	if schedule >= horizon {
+		schedule = horizon - 1
+	}
+	vector = som.InDataAdapter.Adapt(vector)
+	// streamed vectors have no index in a data set, so Incremental is bypassed
+	bmu, err := som.iterate(t.it, schedule, horizon, vector, false)
+	if err != nil {
+		som.log(LogError, "online learning failed", "iteration", t.it, "error", err)
+		return err
+	}
+	if t.Forgetting != nil {
+		if err := t.Forgetting.observe(som, t.it, vector, bmu); err != nil {
+			err = som.trainingError(t.it, vector, err)
+			som.log(LogError, "online learning failed", "iteration", t.it, "error", err)
+			return err
+		}
+	}
+	if t.Drift != nil {
+		t.Drift.observe(som, t.it, bmu)
+	}
+	t.it++
+	som.state.Iterations++
+	som.state.Width = len(vector)
+	som.state.TrainedAt = time.Now()
+	return nil
+}
+
+// collect collects the warmup vector and starts learning once they're all collected.
+func (t *OnlineTrainer) collect(vector DataVector) error {
+	som := t.SOM
+	if t.warmup == nil {
+		t.warmup = &DataSet{}
+		if som.IsTrained() {
+			return t.start(vector)
+		}
+	}
+	if t.warmup.Len() > 0 {
+		if width := len(t.warmup.At(0)); len(vector) != width {
+			return fmt.Errorf("%w: vector length is %d, warmup vectors length is %d", ErrWidthMismatch, len(vector), width)
+		}
+	}
+	t.warmup.Vectors = append(t.warmup.Vectors, append(DataVector(nil), vector...))
+	warmup := t.Warmup
+	if warmup <= 0 {
+		warmup = som.Len()
+	}
+	if t.warmup.Len() < warmup {
+		return nil
+	}
+	if err := som.validateInit(t.warmup); err != nil {
+		return err
+	}
+	som.Initializer.Init(t.warmup, som.Neurons)
+	return t.start(nil)
+}
+
+// start starts learning with the warmup vectors followed by the vector, if not nil.
+// If learning fails to start, the next vector retries starting it.
+func (t *OnlineTrainer) start(vector DataVector) error {
+	if err := t.SOM.startLearning(t.it); err != nil {
+		if t.warmup.Len() == 0 {
+			t.warmup = nil
+		}
+		return err
+	}
+	t.started = true
+	t.SOM.log(LogInfo, "online learning started", "warmup", t.warmup.Len())
+	vectors := t.warmup.Vectors
+	t.warmup = nil
+	if vector != nil {
+		vectors = append(vectors, append(DataVector(nil), vector...))
+	}
+	for _, v := range vectors {
+		if err := t.learn(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Iterations returns the number of completed learning iterations.
+func (t *OnlineTrainer) Iterations() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.it
+}
+
+// Model returns an immutable snapshot of the map, see SOM.Model.
+// Returns ErrNotTrained until the map is initialized.
+func (t *OnlineTrainer) Model() (*Model, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.SOM.Model()
+}
+
+// Consume learns the vectors popped from the buffer until it's closed and
+// drained. The errors of learning the vectors are reported to onError,
+// may be nil, and don't stop consuming.
+func (t *OnlineTrainer) Consume(buffer *IngestBuffer, onError func(err error)) {
+	for {
+		vector, ok := buffer.Pop()
+		if !ok {
+			return
+		}
+		if err := t.Learn(vector); err != nil && onError != nil {
+			onError(err)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package som_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+// scheduleRecorder is the restraint recording the last schedule position.
+type scheduleRecorder struct {
+	t, T int
+}
+
+func (r *scheduleRecorder) Apply(currentIt, iterationsNumber int) float64 {
+	r.t, r.T = currentIt, iterationsNumber
+	return 1
+}
+
+func TestOnlineTrainerWarmsUpAndLearns(t *testing.T) {
+	sm := som.New(1, 2)
+	sm.TieBreaker = &som.LowestIndexTieBreaker{}
+	restraint := &scheduleRecorder{}
+	sm.Restraint = restraint
+	trainer := &som.OnlineTrainer{SOM: sm, Horizon: 2, Warmup: 2}
+
+	if err := trainer.Learn(som.DataVector{1}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := trainer.Model(); !errors.Is(err, som.ErrNotTrained) {
+		t.Fatalf("Expected ErrNotTrained while warming up, got %v", err)
+	}
+	if err := trainer.Learn(som.DataVector{1, 2}); !errors.Is(err, som.ErrWidthMismatch) {
+		t.Fatalf("Expected ErrWidthMismatch, got %v", err)
+	}
+
+	// the zero map learns the warmup vectors 1 and 3, both won by (0, 0)
+	if err := trainer.Learn(som.DataVector{3}); err != nil {
+		t.Fatal(err)
+	}
+	assertEq(t, 2, trainer.Iterations())
+	assertEq(t, scheduleRecorder{1, 2}, *restraint)
+
+	vector := som.DataVector{0.5}
+	if err := trainer.Learn(vector); err != nil {
+		t.Fatal(err)
+	}
+	assertEq(t, 3, trainer.Iterations())
+	assertEq(t, scheduleRecorder{1, 2}, *restraint)
+	model, err := trainer.Model()
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkSlicesEqual(t, []float64{3}, model.Weights(0, 0))
+	checkSlicesEqual(t, []float64{0.5}, model.Weights(0, 1))
+	assertEq(t, 3, sm.TrainingState().Iterations)
+
+	if err := trainer.Learn(som.DataVector{1, 2}); !errors.Is(err, som.ErrWidthMismatch) {
+		t.Fatalf("Expected ErrWidthMismatch, got %v", err)
+	}
+}
+
+func TestOnlineTrainerContinuesTrainedMap(t *testing.T) {
+	sm := som.New(1, 2)
+	if err := sm.LoadCodebook([][][]float64{{{0}, {1}}}); err != nil {
+		t.Fatal(err)
+	}
+	sm.Restraint = &som.ExpRestraintFunc{InitialRate: 0.1}
+	trainer := &som.OnlineTrainer{SOM: sm, Horizon: 100}
+
+	wg := sync.WaitGroup{}
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				trainer.Learn(som.DataVector{1.5})
+			}
+		}()
+	}
+	wg.Wait()
+	assertEq(t, 200, trainer.Iterations())
+	model, err := trainer.Model()
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkSlicesEqual(t, []float64{0}, model.Weights(0, 0))
+	if w := model.Weights(0, 1)[0]; w < 1.4 || w > 1.5 {
+		t.Fatalf("Expected the neuron to approach the stream, got %v", w)
+	}
+}
+
+func TestOnlineTrainerFindsBMUsWithIncrementalDistances(t *testing.T) {
+	sm := som.New(1, 3)
+	if err := sm.LoadCodebook([][][]float64{{{0}, {10}, {20}}}); err != nil {
+		t.Fatal(err)
+	}
+	sm.Restraint = &scheduleRecorder{}
+	sm.Influence = &som.RadiusReducingConstantInfluenceFunc{Radius: 0}
+	sm.Incremental = &som.IncrementalDistances{}
+	trainer := &som.OnlineTrainer{SOM: sm, Horizon: 1}
+
+	// each vector matches its BMU, so the weights don't move unless another neuron wins
+	for _, v := range []float64{20, 10, 0, 20} {
+		if err := trainer.Learn(som.DataVector{v}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	model, err := trainer.Model()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for y, expected := range []float64{0, 10, 20} {
+		checkSlicesEqual(t, []float64{expected}, model.Weights(0, y))
+	}
+}
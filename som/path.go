@@ -0,0 +1,91 @@
+package som
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+)
+
+// Path returns the shortest path across the map grid from the BMU
+// of fromVector to the BMU of toVector, both ends included. Steps go
+// between direct (non-diagonal) neighbours, including the neighbours
+// across the edges connected by Topology, and cost the distance between
+// the weights of the neurons, so the path follows the data manifold
+// (valleys of the U-matrix) rather than the straight line, which allows
+// "morphing" analyses between two data states. Masked neurons are avoided.
+// Returns an error if the BMUs are not connected by unmasked neurons.
+func (m *Model) Path(fromVector, toVector DataVector) ([]GridPoint, error) {
+	from, err := m.BMU(fromVector)
+	if err != nil {
+		return nil, err
+	}
+	to, err := m.BMU(toVector)
+	if err != nil {
+		return nil, err
+	}
+
+	sm := m.som
+	xLen, yLen := sm.Dims()
+	costs := make([]float64, sm.Len())
+	previous := make([]int, sm.Len())
+	for i := range costs {
+		costs[i], previous[i] = math.Inf(1), -1
+	}
+	start, end := sm.Index(from.X, from.Y), sm.Index(to.X, to.Y)
+	costs[start] = 0
+	queue := &pathQueue{{index: start}}
+	for queue.Len() > 0 {
+		item := heap.Pop(queue).(pathItem)
+		if item.index == end {
+			break
+		}
+		if item.cost > costs[item.index] {
+			continue
+		}
+		x, y := sm.Position(item.index)
+		for _, offset := range [][2]int{{-1, 0}, {1, 0}, {0, -1}, {0, 1}} {
+			nx, ny, ok := sm.neighbour(x, y, offset[0], offset[1])
+			if !ok || sm.IsMasked(nx, ny) {
+				continue
+			}
+			next := sm.Index(nx, ny)
+			cost := item.cost + sm.Distance.Apply(sm.Neurons[x][y].Weights, sm.Neurons[nx][ny].Weights)
+			if cost < costs[next] {
+				costs[next], previous[next] = cost, item.index
+				heap.Push(queue, pathItem{index: next, cost: cost})
+			}
+		}
+	}
+	if math.IsInf(costs[end], 1) {
+		return nil, fmt.Errorf("neurons (%d, %d) and (%d, %d) of %dx%d map are not connected", from.X, from.Y, to.X, to.Y, xLen, yLen)
+	}
+
+	var path []GridPoint
+	for i := end; i != -1; i = previous[i] {
+		x, y := sm.Position(i)
+		path = append(path, GridPoint{X: x, Y: y})
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path, nil
+}
+
+type pathItem struct {
+	index int
+	cost  float64
+}
+
+// pathQueue is a min-heap of path items by cost.
+type pathQueue []pathItem
+
+func (q pathQueue) Len() int            { return len(q) }
+func (q pathQueue) Less(i, j int) bool  { return q[i].cost < q[j].cost }
+func (q pathQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *pathQueue) Push(x interface{}) { *q = append(*q, x.(pathItem)) }
+func (q *pathQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
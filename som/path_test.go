@@ -0,0 +1,53 @@
+package som_test
+
+import (
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+func wallSOM(t *testing.T) *som.SOM {
+	sm := som.New(3, 3)
+	codebook := [][][]float64{
+		{{0}, {1}, {2}},
+		{{100}, {100}, {3}},
+		{{6}, {5}, {4}},
+	}
+	if err := sm.LoadCodebook(codebook); err != nil {
+		t.Fatal(err)
+	}
+	return sm
+}
+
+func TestPathGoesAroundDistantNeurons(t *testing.T) {
+	model, err := wallSOM(t).Model()
+	if err != nil {
+		t.Fatal(err)
+	}
+	path, err := model.Path(som.DataVector{0.1}, som.DataVector{6.2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []som.GridPoint{{0, 0}, {0, 1}, {0, 2}, {1, 2}, {2, 2}, {2, 1}, {2, 0}}
+	if len(path) != len(expected) {
+		t.Fatalf("Expected path %v, got %v", expected, path)
+	}
+	for i := range path {
+		assertEq(t, path[i], expected[i])
+	}
+
+	path, _ = model.Path(som.DataVector{4}, som.DataVector{4})
+	assertEq(t, len(path), 1)
+}
+
+func TestPathFailsBetweenDisconnectedNeurons(t *testing.T) {
+	sm := wallSOM(t)
+	sm.Mask = [][]bool{{false, false, false}, {true, true, true}, {false, false, false}}
+	model, err := sm.Model()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := model.Path(som.DataVector{0}, som.DataVector{6}); err == nil {
+		t.Fatal("Expected error for disconnected neurons")
+	}
+}
@@ -0,0 +1,91 @@
+package som
+
+import (
+	"fmt"
+	"math"
+)
+
+// PLSOM implements the parameterless SOM (Berglund, Sitte), where both
+// the learning rate and the neighbourhood width are driven by the normalized
+// fitting error of each sample instead of the iteration schedule:
+//
+//	ε(t) = d(t)² / r(t),  r(t) = max(d(t)², r(t-1))
+//	Θ(t) = Beta * ε(t)
+//	g(t) = exp( -dist² / Θ(t)² )
+//
+// where d(t) is the distance from the sample to its BMU, r(t) is the largest
+// such squared distance seen so far and dist is the grid distance from the BMU.
+// PLSOM provides both the restraint and the influence functions sharing its state:
+//
+//	plsom := &som.PLSOM{Beta: 10}
+//	sm.Restraint, sm.Influence = plsom.Restraint(), plsom.Influence()
+type PLSOM struct {
+	// Beta scales the neighbourhood width, usually about the map size.
+	Beta float64
+
+	started bool
+	maxErr  float64
+	eps     float64
+}
+
+func (p *PLSOM) Validate() error {
+	if !(p.Beta > 0) || math.IsInf(p.Beta, 0) {
+		return fmt.Errorf("%w: beta must be a positive number, got %v", ErrInvalidConfig, p.Beta)
+	}
+	return nil
+}
+
+// Restraint returns the restraint function giving the learning rate ε(t).
+func (p *PLSOM) Restraint() RestraintFunc {
+	return (*plsomRestraint)(p)
+}
+
+// Influence returns the influence function, which also observes
+// the BMU distances and reports the neighbourhood width Θ(t).
+func (p *PLSOM) Influence() InfluenceFunc {
+	return (*plsomInfluence)(p)
+}
+
+func (p *PLSOM) observe(currentIt int, bmuDistance float64) {
+	if currentIt == 0 || !p.started {
+		p.started = true
+		p.maxErr = 0
+	}
+	fitErr := bmuDistance * bmuDistance
+	p.maxErr = math.Max(p.maxErr, fitErr)
+	if p.maxErr == 0 {
+		p.eps = 0
+	} else {
+		p.eps = fitErr / p.maxErr
+	}
+}
+
+type plsomRestraint PLSOM
+
+func (r *plsomRestraint) Apply(currentIt, iterationsNumber int) float64 {
+	return r.eps
+}
+
+type plsomInfluence PLSOM
+
+func (f *plsomInfluence) Validate() error {
+	return (*PLSOM)(f).Validate()
+}
+
+func (f *plsomInfluence) ObserveQuantization(currentIt int, bmuDistance float64) {
+	(*PLSOM)(f).observe(currentIt, bmuDistance)
+}
+
+// EffectiveRadius returns the neighbourhood width Θ(t).
+func (f *plsomInfluence) EffectiveRadius(currentIt, iterationsNumber int) float64 {
+	return f.Beta * f.eps
+}
+
+// Apply returns exp(-dist²/Θ²), gaussian is parametrized by 2q² = Θ², so q = Θ/√2.
+func (f *plsomInfluence) Apply(bmu *Neuron, currentIt, iterationsNumber, x, y int) float64 {
+	return f.ApplyDistance(gridDistance(bmu, x, y), currentIt, iterationsNumber)
+}
+
+func (f *plsomInfluence) ApplyDistance(d float64, currentIt, iterationsNumber int) float64 {
+	return gaussian(d, f.Beta*f.eps/math.Sqrt2)
+}
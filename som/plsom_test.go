@@ -0,0 +1,57 @@
+package som_test
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+func TestPLSOMRatesFollowNormalizedError(t *testing.T) {
+	plsom := &som.PLSOM{Beta: 4}
+	restraint, influence := plsom.Restraint(), plsom.Influence()
+	observer := influence.(som.QuantizationObserver)
+	radius := influence.(som.RadiusReporter)
+
+	observer.ObserveQuantization(0, 2)
+	assertEq(t, restraint.Apply(0, 10), 1.0)
+	assertEq(t, radius.EffectiveRadius(0, 10), 4.0)
+
+	observer.ObserveQuantization(1, 1)
+	assertEq(t, restraint.Apply(1, 10), 0.25)
+	assertEq(t, radius.EffectiveRadius(1, 10), 1.0)
+	assertEq(t, influence.Apply(&som.Neuron{X: 0, Y: 0}, 1, 10, 0, 0), 1.0)
+
+	// a new learning forgets the largest error
+	observer.ObserveQuantization(0, 1)
+	assertEq(t, restraint.Apply(0, 10), 1.0)
+}
+
+func TestPLSOMLearning(t *testing.T) {
+	plsom := &som.PLSOM{Beta: 5}
+	if err := plsom.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	rand.Seed(1)
+	sm := som.New(5, 5)
+	sm.Initializer = &som.RandWeightsInitializer{}
+	sm.Restraint, sm.Influence = plsom.Restraint(), plsom.Influence()
+	sm.Selector = &som.RandSelector{}
+	sm.TieBreaker = &som.LowestIndexTieBreaker{}
+
+	ds := epochsDataSet()
+	sm.Initializer.Init(ds, sm.Neurons)
+	before := sm.QuantizationError(ds)
+	sm.Initializer = &som.KeepWeightsInitializer{}
+	if err := sm.Learn(ds, 500); err != nil {
+		t.Fatal(err)
+	}
+
+	if after := sm.QuantizationError(ds); after >= before {
+		t.Fatalf("Expected quantization error to decrease, %f >= %f", after, before)
+	}
+	if err := (&som.PLSOM{}).Validate(); !errors.Is(err, som.ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig, got %v", err)
+	}
+}
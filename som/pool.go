@@ -0,0 +1,56 @@
+package som
+
+import "sync"
+
+// fieldPool holds distance fields of the calls which don't return them,
+// e.g. Test and FindBMU, so tight inference loops don't allocate.
+var fieldPool sync.Pool
+
+// borrowField returns a field matching the neurons of this map,
+// it must be returned by releaseField once it's not used.
+func (som *SOM) borrowField() *DistanceField {
+	if field, ok := fieldPool.Get().(*DistanceField); ok {
+		if !field.fits(som.Neurons) {
+			*field = NewDistanceField(som.Neurons)
+		}
+		return field
+	}
+	field := NewDistanceField(som.Neurons)
+	return &field
+}
+
+func releaseField(field *DistanceField) {
+	fieldPool.Put(field)
+}
+
+// SeparateWeightsInto is like SeparateWeights, but reuses the matrices of dst
+// if they match the map, so it doesn't allocate when called repeatedly
+// with its previous result, e.g. to render the map after each epoch.
+func (som *SOM) SeparateWeightsInto(dst [][][]float64) [][][]float64 {
+	width := len(som.Neurons[0][0].Weights)
+	if len(dst) != width {
+		dst = make([][][]float64, width)
+	}
+	for si := range dst {
+		if len(dst[si]) != len(som.Neurons) {
+			dst[si] = make([][]float64, len(som.Neurons))
+		}
+		for i := range dst[si] {
+			if len(dst[si][i]) != len(som.Neurons[i]) {
+				dst[si][i] = make([]float64, len(som.Neurons[i]))
+			}
+			for j := range dst[si][i] {
+				dst[si][i][j] = som.Neurons[i][j].Weights[si]
+			}
+		}
+	}
+	return dst
+}
+
+// CopyWeights takes a snapshot of neurons weights, dst[x][y] holds the
+// weights of the neuron at (x, y). The matrices and the weights slices
+// of dst are reused if they match the map, so periodic snapshots,
+// e.g. by ProgressMonitor, don't allocate.
+func (som *SOM) CopyWeights(dst [][][]float64) [][][]float64 {
+	return copyWeights(som.Neurons, dst)
+}
@@ -0,0 +1,64 @@
+package som_test
+
+import (
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+func trainedSOM(t *testing.T) *som.SOM {
+	sm := som.New(4, 4)
+	sm.Initializer = &som.RandWeightsInitializer{}
+	if err := sm.Learn(&som.DataSet{Vectors: []som.DataVector{{0, 1, 2}}}, 1); err != nil {
+		t.Fatal(err)
+	}
+	return sm
+}
+
+func TestInferenceDoesNotAllocate(t *testing.T) {
+	sm := trainedSOM(t)
+	sm.TieBreaker = &som.LowestIndexTieBreaker{}
+	vector := som.DataVector{0.5, 0.5, 0.5}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		if _, err := sm.FindBMU(vector); err != nil {
+			t.Fatal(err)
+		}
+		sm.Test(vector)
+	})
+	assertNoAllocs(t, allocs)
+}
+
+func TestSeparateWeightsIntoReusesMatrices(t *testing.T) {
+	sm := trainedSOM(t)
+	separations := sm.SeparateWeightsInto(nil)
+
+	allocs := testing.AllocsPerRun(10, func() {
+		separations = sm.SeparateWeightsInto(separations)
+	})
+	assertNoAllocs(t, allocs)
+	assertEq(t, separations[2][1][3], sm.Neurons[1][3].Weights[2])
+}
+
+func TestCopyWeightsTakesSnapshot(t *testing.T) {
+	sm := trainedSOM(t)
+	snapshot := sm.CopyWeights(nil)
+	before := sm.Neurons[0][0].Weights[0]
+	sm.Neurons[0][0].Weights[0] = before + 1
+
+	assertEq(t, snapshot[0][0][0], before)
+	allocs := testing.AllocsPerRun(10, func() {
+		snapshot = sm.CopyWeights(snapshot)
+	})
+	assertNoAllocs(t, allocs)
+	assertEq(t, snapshot[0][0][0], before+1)
+}
+
+// assertNoAllocs checks the allocations measured by testing.AllocsPerRun,
+// unless the race detector is on, see raceEnabled.
+func assertNoAllocs(t *testing.T, allocs float64) {
+	t.Helper()
+	if !raceEnabled {
+		assertEq(t, allocs, 0.0)
+	}
+}
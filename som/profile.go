@@ -0,0 +1,158 @@
+package som
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"runtime/pprof"
+	"text/tabwriter"
+	"time"
+)
+
+// Phase is a phase of a learning iteration.
+type Phase int
+
+const (
+	// PhaseDistance computes distances from the input vector to the neurons.
+	PhaseDistance Phase = iota
+	// PhaseBMU searches the BMU and notifies the observers of it.
+	PhaseBMU
+	// PhaseUpdate fixes neurons weights and checks them by Guard.
+	PhaseUpdate
+	// PhaseMonitor emits events and notifies Monitor.
+	PhaseMonitor
+
+	phasesNum = int(PhaseMonitor) + 1
+)
+
+var phaseNames = [phasesNum]string{"distance", "bmu", "update", "monitor"}
+
+func (p Phase) String() string {
+	if p < 0 || int(p) >= phasesNum {
+		return fmt.Sprintf("Phase(%d)", int(p))
+	}
+	return phaseNames[p]
+}
+
+// PhaseLabel is the pprof label key set to the current phase name
+// when PhaseProfile.Labels is enabled, e.g. `go tool pprof -tagfocus som.phase=bmu`.
+const PhaseLabel = "som.phase"
+
+// PhaseProfile records the time Learn and LearnBatch spend in each phase,
+// set it to SOM.Profile to see where the configuration spends time.
+// The breakdown is reset by each learning call, so it describes the last run.
+// Timing adds a couple of clock reads per phase, which is negligible
+// unless the map is tiny.
+type PhaseProfile struct {
+	// Labels makes learning set pprof labels of the current phase,
+	// see PhaseLabel, so CPU profiles can be broken down by phases.
+	Labels bool
+
+	// Context holds the labels of the learning goroutine,
+	// which are restored when learning ends, nil means no labels.
+	Context context.Context
+
+	// Iterations is the number of iterations of the last run.
+	Iterations int
+
+	durations [phasesNum]time.Duration
+	contexts  [phasesNum]context.Context
+}
+
+// Duration returns the time spent in the phase by the last run.
+func (p *PhaseProfile) Duration(phase Phase) time.Duration {
+	return p.durations[phase]
+}
+
+// Total returns the time spent in all the phases by the last run.
+func (p *PhaseProfile) Total() time.Duration {
+	var total time.Duration
+	for _, d := range p.durations {
+		total += d
+	}
+	return total
+}
+
+// WriteTo writes the breakdown as a table of phases with their
+// total and per iteration durations and shares of the total time.
+func (p *PhaseProfile) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+	tw := tabwriter.NewWriter(cw, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(tw, "phase\ttotal\tper iteration\tshare\t\n")
+	total := p.Total()
+	for phase, d := range p.durations {
+		perIt, share := time.Duration(0), 0.0
+		if p.Iterations > 0 {
+			perIt = d / time.Duration(p.Iterations)
+		}
+		if total > 0 {
+			share = 100 * float64(d) / float64(total)
+		}
+		fmt.Fprintf(tw, "%s\t%v\t%v\t%.1f%%\t\n", Phase(phase), d, perIt, share)
+	}
+	err := tw.Flush()
+	return cw.n, err
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(b []byte) (int, error) {
+	n, err := cw.w.Write(b)
+	cw.n += int64(n)
+	return n, err
+}
+
+// start resets the breakdown, called when learning starts.
+func (p *PhaseProfile) start() {
+	if p == nil {
+		return
+	}
+	p.durations = [phasesNum]time.Duration{}
+	p.Iterations = 0
+	if p.Labels {
+		base := p.baseContext()
+		for i := range p.contexts {
+			p.contexts[i] = pprof.WithLabels(base, pprof.Labels(PhaseLabel, Phase(i).String()))
+		}
+	}
+}
+
+// enter marks the beginning of the phase, the result is passed to leave.
+func (p *PhaseProfile) enter(phase Phase) time.Time {
+	if p == nil {
+		return time.Time{}
+	}
+	if p.Labels {
+		pprof.SetGoroutineLabels(p.contexts[phase])
+	}
+	return time.Now()
+}
+
+// leave records the time spent in the phase since the given mark.
+func (p *PhaseProfile) leave(phase Phase, mark time.Time) {
+	if p == nil {
+		return
+	}
+	p.durations[phase] += time.Since(mark)
+}
+
+// stop restores the goroutine labels, called when learning ends after it iterations.
+func (p *PhaseProfile) stop(it int) {
+	if p == nil {
+		return
+	}
+	p.Iterations = it
+	if p.Labels {
+		pprof.SetGoroutineLabels(p.baseContext())
+	}
+}
+
+func (p *PhaseProfile) baseContext() context.Context {
+	if p.Context == nil {
+		return context.Background()
+	}
+	return p.Context
+}
@@ -0,0 +1,57 @@
+package som_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+func TestPhaseProfileBreaksDownLearning(t *testing.T) {
+	sm := som.New(10, 10)
+	sm.Initializer = &som.RandWeightsInitializer{}
+	sm.Influence = &som.GaussianExpDecayInfluenceFunc{InitialWidth: 3}
+	sm.Selector = &som.RandSelector{}
+	sm.Profile = &som.PhaseProfile{Labels: true}
+
+	ds := &som.DataSet{Vectors: []som.DataVector{{0, 1}, {1, 0}, {0.5, 0.5}}}
+	if err := sm.Learn(ds, 30); err != nil {
+		t.Fatal(err)
+	}
+
+	assertEq(t, sm.Profile.Iterations, 30)
+	for _, phase := range []som.Phase{som.PhaseDistance, som.PhaseBMU, som.PhaseUpdate, som.PhaseMonitor} {
+		if sm.Profile.Duration(phase) <= 0 {
+			t.Fatalf("Expected %s phase to take time", phase)
+		}
+	}
+	if sm.Profile.Duration(som.PhaseUpdate) > sm.Profile.Total() {
+		t.Fatal("Expected total to include all the phases")
+	}
+
+	buf := &bytes.Buffer{}
+	if _, err := sm.Profile.WriteTo(buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"distance", "bmu", "update", "monitor"} {
+		if !strings.Contains(buf.String(), name) {
+			t.Fatalf("Expected %s phase in the breakdown\n%s", name, buf)
+		}
+	}
+}
+
+func TestPhaseProfileIsResetByEachRun(t *testing.T) {
+	sm := som.New(2, 2)
+	sm.Profile = &som.PhaseProfile{}
+	ds := &som.DataSet{Vectors: []som.DataVector{{0}, {1}}}
+
+	if err := sm.LearnBatch(ds, 3); err != nil {
+		t.Fatal(err)
+	}
+	assertEq(t, sm.Profile.Iterations, 6)
+	if err := sm.Learn(ds, 1); err != nil {
+		t.Fatal(err)
+	}
+	assertEq(t, sm.Profile.Iterations, 1)
+}
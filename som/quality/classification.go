@@ -0,0 +1,133 @@
+package quality
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+// Purity computes the class purity of each neuron of the calibration,
+// the share of its majority class among the vectors mapped to it
+// (NaN for the neurons without vectors), and the overall purity of the map,
+// the share of the vectors which belong to the majority class of their BMU.
+// The overall purity is NaN if there are no calibration vectors.
+func Purity(cal *som.Calibration) ([][]float64, float64) {
+	purity := make([][]float64, len(cal.Counts))
+	majority, total := 0, 0
+	for x := range cal.Counts {
+		purity[x] = make([]float64, len(cal.Counts[x]))
+		for y, counts := range cal.Counts[x] {
+			hits, max := cal.Hits(x, y), 0
+			for _, n := range counts {
+				if n > max {
+					max = n
+				}
+			}
+			if hits == 0 {
+				purity[x][y] = math.NaN()
+				continue
+			}
+			purity[x][y] = float64(max) / float64(hits)
+			majority += max
+			total += hits
+		}
+	}
+	if total == 0 {
+		return purity, math.NaN()
+	}
+	return purity, float64(majority) / float64(total)
+}
+
+// ConfusionMatrix counts the classification results,
+// Counts[a][p] is the number of vectors of the class Classes[a]
+// classified as Classes[p].
+type ConfusionMatrix struct {
+	Classes []string
+	Counts  [][]int
+}
+
+// Confusion classifies the labeled vectors of the test data set
+// by the calibrated model and returns the confusion matrix of the results.
+// Classes are the classes of the model and the test labels in ascending order.
+func Confusion(model *som.Model, ds *som.DataSet, labels []string) (*ConfusionMatrix, error) {
+	if len(labels) != ds.Len() {
+		return nil, fmt.Errorf("%d labels for %d vectors", len(labels), ds.Len())
+	}
+	cal := model.Calibration()
+	if cal == nil {
+		return nil, som.ErrNotCalibrated
+	}
+
+	index := make(map[string]int)
+	matrix := &ConfusionMatrix{}
+	for _, class := range append(append([]string(nil), cal.Classes...), labels...) {
+		if _, ok := index[class]; !ok {
+			index[class] = 0
+			matrix.Classes = append(matrix.Classes, class)
+		}
+	}
+	sort.Strings(matrix.Classes)
+	matrix.Counts = make([][]int, len(matrix.Classes))
+	for i, class := range matrix.Classes {
+		index[class] = i
+		matrix.Counts[i] = make([]int, len(matrix.Classes))
+	}
+
+	for i := 0; i < ds.Len(); i++ {
+		predicted, err := model.Classify(ds.At(i))
+		if err != nil {
+			return nil, fmt.Errorf("vector %d: %w", i, err)
+		}
+		matrix.Counts[index[labels[i]]][index[predicted]]++
+	}
+	return matrix, nil
+}
+
+// Total returns the number of classified vectors.
+func (m *ConfusionMatrix) Total() int {
+	total := 0
+	for _, row := range m.Counts {
+		for _, n := range row {
+			total += n
+		}
+	}
+	return total
+}
+
+// Accuracy returns the share of the correctly classified vectors, NaN if there are none.
+func (m *ConfusionMatrix) Accuracy() float64 {
+	correct := 0
+	for i := range m.Counts {
+		correct += m.Counts[i][i]
+	}
+	return ratio(correct, m.Total())
+}
+
+// Precision returns the share of the vectors classified as the class
+// which actually belong to it, NaN if no vectors are classified as the class.
+func (m *ConfusionMatrix) Precision(class int) float64 {
+	predicted := 0
+	for i := range m.Counts {
+		predicted += m.Counts[i][class]
+	}
+	return ratio(m.Counts[class][class], predicted)
+}
+
+// Recall returns the share of the vectors of the class which are
+// classified as the class, NaN if there are no vectors of the class.
+func (m *ConfusionMatrix) Recall(class int) float64 {
+	actual := 0
+	for _, n := range m.Counts[class] {
+		actual += n
+	}
+	return ratio(m.Counts[class][class], actual)
+}
+
+func ratio(a, b int) float64 {
+	if b == 0 {
+		return math.NaN()
+	}
+	return float64(a) / float64(b)
+}
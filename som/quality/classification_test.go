@@ -0,0 +1,57 @@
+package quality_test
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+	"github.com/voievodin/self-organizing-map/som/internal/somtest"
+	"github.com/voievodin/self-organizing-map/som/quality"
+)
+
+func TestPurity(t *testing.T) {
+	ds := &som.DataSet{Vectors: []som.DataVector{{1}, {2}, {-1}, {9}, {11}, {12}}}
+	model, err := somtest.LineModel(t, 0, 10, 20).Calibrate(ds, []string{"a", "a", "b", "b", "b", "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	purity, overall := quality.Purity(model.Calibration())
+	if math.Abs(purity[0][0]-2.0/3) > 1e-12 || math.Abs(purity[0][1]-2.0/3) > 1e-12 || !math.IsNaN(purity[0][2]) {
+		t.Fatalf("Unexpected purity %v", purity)
+	}
+	if math.Abs(overall-4.0/6) > 1e-12 {
+		t.Fatalf("Expected overall purity 4/6, got %f", overall)
+	}
+}
+
+func TestConfusion(t *testing.T) {
+	train := &som.DataSet{Vectors: []som.DataVector{{1}, {9}, {21}}}
+	model, err := somtest.LineModel(t, 0, 10, 20).Calibrate(train, []string{"a", "b", "c"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	test := &som.DataSet{Vectors: []som.DataVector{{0}, {4}, {6}, {12}, {19}}}
+	matrix, err := quality.Confusion(model, test, []string{"a", "b", "b", "b", "d"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(matrix.Classes, []string{"a", "b", "c", "d"}) {
+		t.Fatalf("Unexpected classes %v", matrix.Classes)
+	}
+	expected := [][]int{{1, 0, 0, 0}, {1, 2, 0, 0}, {0, 0, 0, 0}, {0, 0, 1, 0}}
+	if !reflect.DeepEqual(matrix.Counts, expected) {
+		t.Fatalf("Expected %v, got %v", expected, matrix.Counts)
+	}
+	if math.Abs(matrix.Accuracy()-0.6) > 1e-12 || matrix.Precision(0) != 0.5 || math.Abs(matrix.Recall(1)-2.0/3) > 1e-12 {
+		t.Fatalf("Unexpected scores %f %f %f", matrix.Accuracy(), matrix.Precision(0), matrix.Recall(1))
+	}
+
+	if _, err := quality.Confusion(somtest.LineModel(t, 0, 10, 20), test, make([]string, 5)); !errors.Is(err, som.ErrNotCalibrated) {
+		t.Fatalf("Expected ErrNotCalibrated, got %v", err)
+	}
+}
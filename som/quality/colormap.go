@@ -0,0 +1,80 @@
+package quality
+
+import (
+	"image/color"
+	"math"
+	"sort"
+)
+
+// ColorMap maps normalized values to colors, see RenderOptions.
+type ColorMap interface {
+	// At returns the color of t => [0, 1], values out of the range are clamped.
+	At(t float64) color.NRGBA
+}
+
+// Gradient is a ColorMap interpolating linearly between the colors,
+// which are evenly spaced over [0, 1]: the first one is the color of 0
+// and the last one is the color of 1.
+type Gradient []color.NRGBA
+
+func (g Gradient) At(t float64) color.NRGBA {
+	if !(t > 0) {
+		return g[0]
+	}
+	if t >= 1 {
+		return g[len(g)-1]
+	}
+	pos := t * float64(len(g)-1)
+	i := int(pos)
+	frac := pos - float64(i)
+	from, to := g[i], g[i+1]
+	lerp := func(a, b uint8) uint8 {
+		return uint8(math.Round(float64(a) + frac*(float64(b)-float64(a))))
+	}
+	return color.NRGBA{lerp(from.R, to.R), lerp(from.G, to.G), lerp(from.B, to.B), lerp(from.A, to.A)}
+}
+
+var (
+	// Viridis is the perceptually uniform dark blue-green-yellow color map,
+	// which stays readable in grayscale and for color blind viewers.
+	// Suits sequential values like hits or distances.
+	Viridis = Gradient{
+		{0x44, 0x01, 0x54, 255}, {0x47, 0x2c, 0x7a, 255}, {0x3b, 0x51, 0x8b, 255},
+		{0x2c, 0x71, 0x8e, 255}, {0x21, 0x90, 0x8d, 255}, {0x27, 0xad, 0x81, 255},
+		{0x5c, 0xc8, 0x63, 255}, {0xaa, 0xdc, 0x32, 255}, {0xfd, 0xe7, 0x25, 255},
+	}
+
+	// Magma is the perceptually uniform black-purple-orange-white color map.
+	Magma = Gradient{
+		{0x00, 0x00, 0x04, 255}, {0x1c, 0x10, 0x44, 255}, {0x4f, 0x12, 0x7b, 255},
+		{0x81, 0x25, 0x81, 255}, {0xb5, 0x36, 0x7a, 255}, {0xe5, 0x59, 0x64, 255},
+		{0xfb, 0x87, 0x61, 255}, {0xfe, 0xc2, 0x87, 255}, {0xfc, 0xfd, 0xbf, 255},
+	}
+
+	// Diverging is the blue-white-red color map for signed values,
+	// e.g. differences of maps, rendered with RenderOptions.Symmetric
+	// so that zero is white.
+	Diverging = Gradient{{0, 0, 255, 255}, {255, 255, 255, 255}, {255, 0, 0, 255}}
+
+	// Grays is the black-white color map.
+	Grays = Gradient{{0, 0, 0, 255}, {255, 255, 255, 255}}
+)
+
+// ColorMaps are the predefined color maps by their names,
+// e.g. for selecting a color map by a flag or a query parameter.
+var ColorMaps = map[string]ColorMap{
+	"viridis":   Viridis,
+	"magma":     Magma,
+	"diverging": Diverging,
+	"grays":     Grays,
+}
+
+// ColorMapNames returns the sorted names of ColorMaps.
+func ColorMapNames() []string {
+	names := make([]string, 0, len(ColorMaps))
+	for name := range ColorMaps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
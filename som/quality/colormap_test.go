@@ -0,0 +1,45 @@
+package quality_test
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som/quality"
+)
+
+func TestGradient(t *testing.T) {
+	g := quality.Gradient{{0, 0, 0, 255}, {200, 100, 0, 255}, {200, 200, 200, 255}}
+
+	for _, test := range []struct {
+		t        float64
+		expected color.NRGBA
+	}{
+		{-1, color.NRGBA{0, 0, 0, 255}},
+		{0, color.NRGBA{0, 0, 0, 255}},
+		{0.25, color.NRGBA{100, 50, 0, 255}},
+		{0.5, color.NRGBA{200, 100, 0, 255}},
+		{0.75, color.NRGBA{200, 150, 100, 255}},
+		{1, color.NRGBA{200, 200, 200, 255}},
+		{2, color.NRGBA{200, 200, 200, 255}},
+	} {
+		if c := g.At(test.t); c != test.expected {
+			t.Errorf("At(%v) = %v, expected %v", test.t, c, test.expected)
+		}
+	}
+}
+
+func TestColorMapsAreMonotonicInLightness(t *testing.T) {
+	for _, name := range []string{"viridis", "magma", "grays"} {
+		cm := quality.ColorMaps[name]
+		prev := -1.0
+		for i := 0; i <= 20; i++ {
+			c := cm.At(float64(i) / 20)
+			// the relative luminance approximation
+			l := 0.2126*float64(c.R) + 0.7152*float64(c.G) + 0.0722*float64(c.B)
+			if l < prev {
+				t.Fatalf("%s lightness decreases at %v", name, float64(i)/20)
+			}
+			prev = l
+		}
+	}
+}
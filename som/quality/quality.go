@@ -0,0 +1,129 @@
+// Package quality provides metrics estimating how well a trained map fits the data.
+package quality
+
+import (
+	"math"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+// HitMap counts how many vectors of the data set have each neuron as their BMU,
+// the value at position (x, y) is the number of hits of the neuron at (x, y).
+// Masked neurons are marked with -1, so they are ignored by the metrics of this package.
+func HitMap(s *som.SOM, ds *som.DataSet) [][]int {
+	hits := make([][]int, len(s.Neurons))
+	for x := range hits {
+		hits[x] = make([]int, len(s.Neurons[x]))
+		for y := range hits[x] {
+			if s.IsMasked(x, y) {
+				hits[x][y] = -1
+			}
+		}
+	}
+
+	var field som.DistanceField
+	for i := 0; i < ds.Len(); i++ {
+		var bmu *som.Neuron
+		bmu, field = s.TestDistances(ds.At(i), field)
+		hits[bmu.X][bmu.Y]++
+	}
+	return hits
+}
+
+// MapEntropy computes Shannon entropy (in bits) of the distribution of hits
+// over the neurons of the hit map. The entropy is maximal, log2(neurons),
+// when all the neurons are hit equally often and is 0 when all the vectors
+// hit a single neuron. Returns 0 if there are no hits.
+func MapEntropy(hitMap [][]int) float64 {
+	total := 0
+	for _, row := range hitMap {
+		for _, hits := range row {
+			if hits > 0 {
+				total += hits
+			}
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+
+	entropy := 0.0
+	for _, row := range hitMap {
+		for _, hits := range row {
+			if hits > 0 {
+				p := float64(hits) / float64(total)
+				entropy -= p * math.Log2(p)
+			}
+		}
+	}
+	return entropy
+}
+
+// ActivationSummary describes how evenly the map uses its neurons.
+// Many dead neurons and low normalized entropy suggest the map is too large
+// for the data, while few dead neurons with high maximal hits suggest
+// it is too small.
+type ActivationSummary struct {
+	// Neurons is the number of unmasked neurons.
+	Neurons int
+
+	// Hits is the total number of hits.
+	Hits int
+
+	// Dead is the number of neurons which are never hit.
+	Dead int
+
+	// MaxHits is the maximal number of hits of a single neuron.
+	MaxHits int
+
+	// MeanHits is the average number of hits per neuron.
+	MeanHits float64
+
+	// Histogram[k] is the number of neurons hit exactly k times, k => [0, MaxHits].
+	Histogram []int
+
+	// Entropy is MapEntropy of the hit map.
+	Entropy float64
+
+	// NormalizedEntropy is Entropy divided by its maximum log2(Neurons),
+	// 1 means that all the neurons are hit equally often.
+	NormalizedEntropy float64
+}
+
+// Activation computes ActivationSummary of the hit map.
+func Activation(hitMap [][]int) *ActivationSummary {
+	summary := &ActivationSummary{}
+	for _, row := range hitMap {
+		for _, hits := range row {
+			if hits < 0 {
+				continue
+			}
+			summary.Neurons++
+			summary.Hits += hits
+			if hits == 0 {
+				summary.Dead++
+			}
+			if hits > summary.MaxHits {
+				summary.MaxHits = hits
+			}
+		}
+	}
+	if summary.Neurons == 0 {
+		return summary
+	}
+
+	summary.MeanHits = float64(summary.Hits) / float64(summary.Neurons)
+	summary.Histogram = make([]int, summary.MaxHits+1)
+	for _, row := range hitMap {
+		for _, hits := range row {
+			if hits >= 0 {
+				summary.Histogram[hits]++
+			}
+		}
+	}
+	summary.Entropy = MapEntropy(hitMap)
+	if summary.Neurons > 1 {
+		summary.NormalizedEntropy = summary.Entropy / math.Log2(float64(summary.Neurons))
+	}
+	return summary
+}
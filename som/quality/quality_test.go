@@ -0,0 +1,58 @@
+package quality_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+	"github.com/voievodin/self-organizing-map/som/quality"
+)
+
+func TestMapEntropy(t *testing.T) {
+	cases := []struct {
+		hitMap   [][]int
+		expected float64
+	}{
+		{[][]int{{0, 0}, {0, 0}}, 0},
+		{[][]int{{5, 0}, {0, 0}}, 0},
+		{[][]int{{1, 1}, {1, 1}}, 2},
+		{[][]int{{2, 2}, {0, -1}}, 1},
+	}
+
+	for _, aCase := range cases {
+		if entropy := quality.MapEntropy(aCase.hitMap); math.Abs(entropy-aCase.expected) > 1e-12 {
+			t.Fatalf("Expected entropy of %v to be %f, got %f", aCase.hitMap, aCase.expected, entropy)
+		}
+	}
+}
+
+func TestActivation(t *testing.T) {
+	summary := quality.Activation([][]int{{3, 0}, {1, -1}})
+
+	if summary.Neurons != 3 || summary.Hits != 4 || summary.Dead != 1 || summary.MaxHits != 3 {
+		t.Fatalf("Unexpected summary %+v", summary)
+	}
+	expectedHistogram := []int{1, 1, 0, 1}
+	for k := range expectedHistogram {
+		if summary.Histogram[k] != expectedHistogram[k] {
+			t.Fatalf("Expected histogram %v, got %v", expectedHistogram, summary.Histogram)
+		}
+	}
+	if summary.NormalizedEntropy <= 0 || summary.NormalizedEntropy >= 1 {
+		t.Fatalf("Expected normalized entropy in (0, 1), got %f", summary.NormalizedEntropy)
+	}
+}
+
+func TestHitMap(t *testing.T) {
+	sm := som.New(1, 3)
+	for y, n := range sm.Neurons[0] {
+		n.Weights = []float64{float64(y)}
+	}
+	sm.Mask = [][]bool{{false, false, true}}
+
+	hits := quality.HitMap(sm, &som.DataSet{Vectors: []som.DataVector{{0}, {0.1}, {0.9}, {2}}})
+
+	if hits[0][0] != 2 || hits[0][1] != 2 || hits[0][2] != -1 {
+		t.Fatalf("Unexpected hit map %v", hits)
+	}
+}
@@ -0,0 +1,87 @@
+package quality
+
+import (
+	"math"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+// Smooth smooths per neuron values, e.g. HitValues, ErrorSurface or
+// ComponentPlane, with the gaussian kernel of the given radius: the value at
+// (x, y) becomes the kernel weighted average of the values around the neuron,
+// which makes the maps of small data sets less noisy. The grid distances follow
+// the topology, so on a torus the values at the edges are averaged with the
+// values at the opposite edges, nil topology means som.PlanarTopology.
+// NaN values, e.g. of masked neurons, don't contribute to the averages and stay NaN.
+// The radius <= 0 returns a copy of the values.
+func Smooth(values [][]float64, topology som.Topology, radius float64) [][]float64 {
+	xLen, yLen := len(values), len(values[0])
+	smoothed := make([][]float64, xLen)
+	for x := range smoothed {
+		smoothed[x] = append([]float64(nil), values[x]...)
+	}
+	if radius <= 0 {
+		return smoothed
+	}
+	if topology == nil {
+		topology = &som.PlanarTopology{}
+	}
+
+	kernel := som.NewKernel(topology, radius, xLen, yLen)
+	for x := range smoothed {
+		for y := range smoothed[x] {
+			if math.IsNaN(values[x][y]) {
+				continue
+			}
+			sum, weights := 0.0, 0.0
+			for nx := range values {
+				for ny, v := range values[nx] {
+					if math.IsNaN(v) {
+						continue
+					}
+					w := kernel.At(nx, ny, x, y)
+					sum += w * v
+					weights += w
+				}
+			}
+			smoothed[x][y] = sum / weights
+		}
+	}
+	return smoothed
+}
+
+// HitValues converts the hit map to floats, e.g. for Smooth or RenderHeatMap,
+// masked neurons have NaN values.
+func HitValues(hitMap [][]int) [][]float64 {
+	values := make([][]float64, len(hitMap))
+	for x := range hitMap {
+		values[x] = make([]float64, len(hitMap[x]))
+		for y, hits := range hitMap[x] {
+			if hits < 0 {
+				values[x][y] = math.NaN()
+			} else {
+				values[x][y] = float64(hits)
+			}
+		}
+	}
+	return values
+}
+
+// ComponentPlane returns the weights of the feature across the neurons of the map,
+// the value at (x, y) is the weight of the neuron at (x, y), masked neurons have NaN values.
+// Panics if the feature is out of the weights range.
+func ComponentPlane(m *som.Model, feature int) [][]float64 {
+	xLen, yLen := m.Dims()
+	plane := make([][]float64, xLen)
+	for x := range plane {
+		plane[x] = make([]float64, yLen)
+		for y := range plane[x] {
+			if m.IsMasked(x, y) {
+				plane[x][y] = math.NaN()
+			} else {
+				plane[x][y] = m.Weights(x, y)[feature]
+			}
+		}
+	}
+	return plane
+}
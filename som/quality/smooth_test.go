@@ -0,0 +1,90 @@
+package quality_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+	"github.com/voievodin/self-organizing-map/som/quality"
+)
+
+func TestSmoothKeepsConstantValues(t *testing.T) {
+	values := [][]float64{{2, 2, 2}, {2, 2, 2}}
+
+	smoothed := quality.Smooth(values, nil, 1)
+
+	for x := range smoothed {
+		for y, v := range smoothed[x] {
+			if math.Abs(v-2) > 1e-12 {
+				t.Fatalf("Expected constant values to stay 2, got %v at (%d, %d)", v, x, y)
+			}
+		}
+	}
+}
+
+func TestSmoothHonorsTopology(t *testing.T) {
+	values := [][]float64{{4, 0, 0, 0, 0}}
+
+	planar := quality.Smooth(values, &som.PlanarTopology{}, 1)
+	torus := quality.Smooth(values, &som.TorusTopology{}, 1)
+
+	if !(planar[0][0] < values[0][0] && planar[0][1] > 0) {
+		t.Fatalf("Expected the peak to spread to its neighbours, got %v", planar)
+	}
+	if !(planar[0][4] < planar[0][1]) {
+		t.Fatalf("Expected the far edge of planar map to get less than the neighbour, got %v", planar)
+	}
+	if math.Abs(torus[0][4]-torus[0][1]) > 1e-12 {
+		t.Fatalf("Expected the edges of torus to be neighbours, got %v", torus)
+	}
+}
+
+func TestSmoothSkipsNaN(t *testing.T) {
+	values := [][]float64{{1, math.NaN(), 3}}
+
+	smoothed := quality.Smooth(values, nil, 1)
+
+	if !math.IsNaN(smoothed[0][1]) {
+		t.Fatalf("Expected NaN to stay NaN, got %v", smoothed[0][1])
+	}
+	if math.IsNaN(smoothed[0][0]) || math.IsNaN(smoothed[0][2]) {
+		t.Fatalf("Expected NaN not to contribute to the averages, got %v", smoothed)
+	}
+}
+
+func TestSmoothZeroRadiusCopiesValues(t *testing.T) {
+	values := [][]float64{{1, 2}, {3, 4}}
+
+	smoothed := quality.Smooth(values, nil, 0)
+	smoothed[0][0] = 10
+
+	if values[0][0] != 1 || smoothed[1][1] != 4 {
+		t.Fatalf("Expected a copy of the values, got %v", smoothed)
+	}
+}
+
+func TestHitValues(t *testing.T) {
+	values := quality.HitValues([][]int{{3, -1}, {0, 1}})
+
+	if values[0][0] != 3 || !math.IsNaN(values[0][1]) || values[1][0] != 0 || values[1][1] != 1 {
+		t.Fatalf("Unexpected hit values %v", values)
+	}
+}
+
+func TestComponentPlane(t *testing.T) {
+	s := som.New(1, 3)
+	if err := s.LoadCodebook([][][]float64{{{0, 1}, {10, 11}, {20, 21}}}); err != nil {
+		t.Fatal(err)
+	}
+	s.Mask = [][]bool{{false, false, true}}
+	model, err := s.Model()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plane := quality.ComponentPlane(model, 1)
+
+	if plane[0][0] != 1 || plane[0][1] != 11 || !math.IsNaN(plane[0][2]) {
+		t.Fatalf("Unexpected component plane %v", plane)
+	}
+}
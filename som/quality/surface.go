@@ -0,0 +1,163 @@
+package quality
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+// ErrorSurface computes the mean quantization error of the vectors of the data set
+// per neuron: the value at (x, y) is the average distance between the neuron
+// at (x, y) and the vectors it is BMU of. Neurons which are not BMU of any vector
+// and masked neurons have NaN values. Passing a subset of the data, e.g. a class
+// or a time window, shows the regions of the map which poorly represent it.
+func ErrorSurface(s *som.SOM, ds *som.DataSet) [][]float64 {
+	sums := make([][]float64, len(s.Neurons))
+	// no hits yet, but masked neurons are marked
+	hits := HitMap(s, &som.DataSet{})
+	for x := range sums {
+		sums[x] = make([]float64, len(s.Neurons[x]))
+	}
+
+	var field som.DistanceField
+	for i := 0; i < ds.Len(); i++ {
+		var bmu *som.Neuron
+		bmu, field = s.TestDistances(ds.At(i), field)
+		sums[bmu.X][bmu.Y] += field[bmu.X][bmu.Y]
+		hits[bmu.X][bmu.Y]++
+	}
+
+	for x := range sums {
+		for y := range sums[x] {
+			if hits[x][y] <= 0 {
+				sums[x][y] = math.NaN()
+			} else {
+				sums[x][y] /= float64(hits[x][y])
+			}
+		}
+	}
+	return sums
+}
+
+// RenderOptions configure rendering of per neuron values, see Render.
+type RenderOptions struct {
+	// Scale is the size of the square of a neuron in pixels, < 1 means 1.
+	Scale int
+
+	// ColorMap colors the values, nil means Diverging.
+	ColorMap ColorMap
+
+	// Symmetric makes the range of the values symmetric around zero,
+	// so that zero is in the middle of the color map, e.g. of Diverging.
+	Symmetric bool
+
+	// Legend appends the color bar below the map, which spans the range of the
+	// values, see ValueRange, from the minimum at the left to the maximum at the right.
+	// Ticks under the bar mark its quarters.
+	Legend bool
+}
+
+const (
+	legendGap   = 2
+	legendBar   = 8
+	legendTicks = 3
+)
+
+// RenderHeatMap renders per neuron values, e.g. ErrorSurface or HitValues,
+// as an image where each neuron is a scale*scale square colored from blue
+// (the minimal value) through white to red (the maximal one).
+// NaN values are transparent. See Render for other color maps.
+func RenderHeatMap(values [][]float64, scale int) *image.NRGBA {
+	return Render(values, RenderOptions{Scale: scale})
+}
+
+// Render renders per neuron values as an image where each neuron is
+// a square colored by the color map according to its value's position
+// in the range of the values. NaN values are transparent.
+func Render(values [][]float64, options RenderOptions) *image.NRGBA {
+	scale := options.Scale
+	if scale < 1 {
+		scale = 1
+	}
+	colors := options.ColorMap
+	if colors == nil {
+		colors = Diverging
+	}
+	min, max := ValueRange(values, options.Symmetric)
+	norm := func(v float64) float64 {
+		if max > min {
+			return (v - min) / (max - min)
+		}
+		return 0.5
+	}
+
+	width, height := len(values)*scale, len(values[0])*scale
+	imgHeight := height
+	if options.Legend {
+		imgHeight += legendGap + legendBar + legendTicks
+	}
+	img := image.NewNRGBA(image.Rect(0, 0, width, imgHeight))
+	for x, column := range values {
+		for y, v := range column {
+			if math.IsNaN(v) {
+				continue
+			}
+			c := colors.At(norm(v))
+			for px := 0; px < scale; px++ {
+				for py := 0; py < scale; py++ {
+					img.SetNRGBA(x*scale+px, y*scale+py, c)
+				}
+			}
+		}
+	}
+
+	if options.Legend {
+		top := height + legendGap
+		for px := 0; px < width; px++ {
+			t := 0.5
+			if width > 1 {
+				t = float64(px) / float64(width-1)
+			}
+			c := colors.At(t)
+			for py := top; py < top+legendBar; py++ {
+				img.SetNRGBA(px, py, c)
+			}
+		}
+		for q := 0; q <= 4; q++ {
+			px := int(math.Round(float64(q) * float64(width-1) / 4))
+			for py := top + legendBar; py < imgHeight; py++ {
+				img.SetNRGBA(px, py, color.NRGBA{0, 0, 0, 255})
+			}
+		}
+	}
+	return img
+}
+
+// ValueRange returns the minimal and the maximal values ignoring NaN,
+// which Render maps to the ends of the color map. If symmetric is true,
+// the range is [-m, m], where m is the maximal absolute value.
+// Returns +Inf and -Inf if all the values are NaN.
+func ValueRange(values [][]float64, symmetric bool) (min, max float64) {
+	min, max = math.Inf(1), math.Inf(-1)
+	for _, column := range values {
+		for _, v := range column {
+			if !math.IsNaN(v) {
+				min, max = math.Min(min, v), math.Max(max, v)
+			}
+		}
+	}
+	if symmetric && min <= max {
+		m := math.Max(math.Abs(min), math.Abs(max))
+		min, max = -m, m
+	}
+	return min, max
+}
+
+// RenderInfluence renders the neighbourhood of the influence function
+// on the map of xLen*yLen size for the BMU at (bmuX, bmuY) at the given
+// iteration as a heat map, see som.InfluenceMap and RenderHeatMap.
+func RenderInfluence(f som.InfluenceFunc, xLen, yLen, bmuX, bmuY, it, itNum, scale int) *image.NRGBA {
+	return RenderHeatMap(som.InfluenceMap(f, xLen, yLen, bmuX, bmuY, it, itNum), scale)
+}
@@ -0,0 +1,84 @@
+package quality_test
+
+import (
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+	"github.com/voievodin/self-organizing-map/som/quality"
+)
+
+func TestErrorSurface(t *testing.T) {
+	s := som.New(1, 3)
+	s.Neurons[0][0].Weights = []float64{0}
+	s.Neurons[0][1].Weights = []float64{10}
+	s.Neurons[0][2].Weights = []float64{20}
+	s.Mask = [][]bool{{false, false, true}}
+
+	ds := &som.DataSet{Vectors: []som.DataVector{{1}, {3}, {9}, {19}}}
+	surface := quality.ErrorSurface(s, ds)
+
+	if surface[0][0] != 2 || surface[0][1] != 5 || !math.IsNaN(surface[0][2]) {
+		t.Fatalf("Unexpected error surface %v", surface)
+	}
+}
+
+func TestRenderHeatMap(t *testing.T) {
+	img := quality.RenderHeatMap([][]float64{{0, math.NaN()}, {1, 2}}, 2)
+
+	if img.Bounds().Dx() != 4 || img.Bounds().Dy() != 4 {
+		t.Fatalf("Unexpected image size %v", img.Bounds())
+	}
+	if c := img.NRGBAAt(0, 0); c != (color.NRGBA{0, 0, 255, 255}) {
+		t.Fatalf("Expected minimum to be blue, got %v", c)
+	}
+	if c := img.NRGBAAt(3, 3); c != (color.NRGBA{255, 0, 0, 255}) {
+		t.Fatalf("Expected maximum to be red, got %v", c)
+	}
+	if c := img.NRGBAAt(1, 3); c.A != 0 {
+		t.Fatalf("Expected NaN to be transparent, got %v", c)
+	}
+}
+
+func TestRenderInfluence(t *testing.T) {
+	img := quality.RenderInfluence(&som.BMUOnlyInfluencedFunc{}, 3, 2, 1, 0, 0, 10, 2)
+	if img.Bounds().Dx() != 6 || img.Bounds().Dy() != 4 {
+		t.Fatalf("Unexpected image size %v", img.Bounds())
+	}
+	if c := img.NRGBAAt(2, 0); c != (color.NRGBA{255, 0, 0, 255}) {
+		t.Fatalf("Expected the BMU to be red, got %v", c)
+	}
+	if c := img.NRGBAAt(0, 0); c != (color.NRGBA{0, 0, 255, 255}) {
+		t.Fatalf("Expected the other neurons to be blue, got %v", c)
+	}
+}
+
+func TestRenderWithLegend(t *testing.T) {
+	img := quality.Render([][]float64{{0, 1}, {2, 3}}, quality.RenderOptions{Scale: 4, ColorMap: quality.Viridis, Legend: true})
+
+	if img.Bounds().Dx() != 8 || img.Bounds().Dy() <= 8 {
+		t.Fatalf("Expected the legend below the 8x8 map, got %v", img.Bounds())
+	}
+	if c := img.NRGBAAt(0, 0); c != quality.Viridis[0] {
+		t.Fatalf("Expected minimum to be the first viridis color, got %v", c)
+	}
+	if c := img.NRGBAAt(7, 7); c != quality.Viridis[len(quality.Viridis)-1] {
+		t.Fatalf("Expected maximum to be the last viridis color, got %v", c)
+	}
+	bar := img.Bounds().Dy() - 4
+	if left, right := img.NRGBAAt(0, bar), img.NRGBAAt(7, bar); left != quality.Viridis[0] || right != quality.Viridis[len(quality.Viridis)-1] {
+		t.Fatalf("Expected the legend to span the color map, got %v and %v", left, right)
+	}
+}
+
+func TestRenderSymmetric(t *testing.T) {
+	img := quality.Render([][]float64{{-1, 0.5, 0}}, quality.RenderOptions{ColorMap: quality.Diverging, Symmetric: true})
+
+	if c := img.NRGBAAt(0, 2); c != (color.NRGBA{255, 255, 255, 255}) {
+		t.Fatalf("Expected zero to be white, got %v", c)
+	}
+	if c := img.NRGBAAt(0, 0); c != (color.NRGBA{0, 0, 255, 255}) {
+		t.Fatalf("Expected -1 to be blue, got %v", c)
+	}
+}
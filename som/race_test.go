@@ -0,0 +1,7 @@
+//go:build race
+
+package som_test
+
+// raceEnabled is true when the tests run under the race detector,
+// which makes sync.Pool drop items, so allocations can't be asserted.
+const raceEnabled = true
@@ -0,0 +1,154 @@
+package som
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// RankDataAdapter maps each feature value to its empirical CDF value within [0, 1],
+// i.e. to the share of the data set values which are less than it, interpolated
+// linearly between the data set values. Unlike ScalingDataAdapter, it's not affected
+// by heavy tails and outliers, as only the order of the values matters.
+// Values outside of the fitted range are mapped to the CDF bounds, NaNs are kept.
+// Note that the original vector is modified.
+type RankDataAdapter struct {
+	// Values[k] are the ascending distinct values of the k-th feature
+	// and Ranks[k] are their CDF values.
+	Values, Ranks [][]float64
+}
+
+// NewRankDataAdapter fits RankDataAdapter to the data set,
+// NaN values are ignored. If maxPoints is > 0, each feature CDF is
+// approximated by at most maxPoints quantiles, which bounds the adapter size
+// for big data sets, otherwise all the distinct values are kept.
+func NewRankDataAdapter(ds *DataSet, maxPoints int) *RankDataAdapter {
+	width := ds.Width()
+	adapter := &RankDataAdapter{Values: make([][]float64, width), Ranks: make([][]float64, width)}
+	column := make([]float64, 0, ds.Len())
+	for k := 0; k < width; k++ {
+		column = column[:0]
+		for _, vector := range ds.Vectors {
+			if !math.IsNaN(vector[k]) {
+				column = append(column, vector[k])
+			}
+		}
+		sort.Float64s(column)
+		values, ranks := empiricalCDF(column)
+		if maxPoints > 0 && len(values) > maxPoints {
+			values, ranks = downsampleCDF(values, ranks, maxPoints)
+		}
+		adapter.Values[k], adapter.Ranks[k] = values, ranks
+	}
+	return adapter
+}
+
+// empiricalCDF returns distinct values of the sorted column and their ranks,
+// the i-th of n values has the rank i/(n-1), equal values have the average rank.
+func empiricalCDF(sorted []float64) ([]float64, []float64) {
+	var values, ranks []float64
+	if len(sorted) == 1 {
+		return []float64{sorted[0]}, []float64{0.5}
+	}
+	for i := 0; i < len(sorted); {
+		j := i
+		for j+1 < len(sorted) && sorted[j+1] == sorted[i] {
+			j++
+		}
+		values = append(values, sorted[i])
+		ranks = append(ranks, float64(i+j)/2/float64(len(sorted)-1))
+		i = j + 1
+	}
+	if len(values) == 1 {
+		ranks[0] = 0.5
+	}
+	return values, ranks
+}
+
+// downsampleCDF keeps n points evenly spaced by index, including the first and the last ones.
+func downsampleCDF(values, ranks []float64, n int) ([]float64, []float64) {
+	if n < 2 {
+		n = 2
+	}
+	sampledValues, sampledRanks := make([]float64, n), make([]float64, n)
+	for i := 0; i < n; i++ {
+		idx := int(math.Round(float64(i) * float64(len(values)-1) / float64(n-1)))
+		sampledValues[i], sampledRanks[i] = values[idx], ranks[idx]
+	}
+	return sampledValues, sampledRanks
+}
+
+func (adapter *RankDataAdapter) Adapt(vector []float64) []float64 {
+	for k := range vector {
+		vector[k] = interpolate(vector[k], adapter.Values[k], adapter.Ranks[k])
+	}
+	return vector
+}
+
+// Describe returns the spec of the adapter, the CDF points of all the
+// features are concatenated, "lengths" are the numbers of points of each.
+func (adapter *RankDataAdapter) Describe() ComponentSpec {
+	lengths := make([]float64, len(adapter.Values))
+	var values, ranks []float64
+	for k := range adapter.Values {
+		lengths[k] = float64(len(adapter.Values[k]))
+		values = append(values, adapter.Values[k]...)
+		ranks = append(ranks, adapter.Ranks[k]...)
+	}
+	return ComponentSpec{Type: "rank", Values: map[string][]float64{
+		"lengths": lengths,
+		"values":  values,
+		"ranks":   ranks,
+	}}
+}
+
+// newRankDataAdapter recreates the described adapter, see Describe.
+func newRankDataAdapter(params Params, values map[string][]float64) (DataAdapter, error) {
+	lengths, points, ranks := values["lengths"], values["values"], values["ranks"]
+	if len(lengths) == 0 || len(points) != len(ranks) {
+		return nil, fmt.Errorf("%w: rank needs lengths, and values and ranks of the same length", ErrInvalidConfig)
+	}
+	adapter := &RankDataAdapter{Values: make([][]float64, len(lengths)), Ranks: make([][]float64, len(lengths))}
+	offset := 0
+	for k, length := range lengths {
+		n := int(length)
+		if n < 0 || float64(n) != length || n > len(points)-offset {
+			return nil, fmt.Errorf("%w: rank feature %d has bad length %v", ErrInvalidConfig, k, length)
+		}
+		adapter.Values[k] = points[offset : offset+n : offset+n]
+		adapter.Ranks[k] = ranks[offset : offset+n : offset+n]
+		offset += n
+	}
+	if offset != len(points) {
+		return nil, fmt.Errorf("%w: rank lengths don't match %d values", ErrInvalidConfig, len(points))
+	}
+	return adapter, params.Check()
+}
+
+// Inverse maps CDF values back to the feature values.
+// Note that the original vector is modified.
+func (adapter *RankDataAdapter) Inverse(vector []float64) []float64 {
+	for k := range vector {
+		vector[k] = interpolate(vector[k], adapter.Ranks[k], adapter.Values[k])
+	}
+	return vector
+}
+
+// interpolate maps x from the ascending xs points to the ys points linearly,
+// values out of the xs range are mapped to the bounds of ys.
+func interpolate(x float64, xs, ys []float64) float64 {
+	if math.IsNaN(x) || len(xs) == 0 {
+		return x
+	}
+	i := sort.SearchFloat64s(xs, x)
+	switch {
+	case i == 0:
+		return ys[0]
+	case i == len(xs):
+		return ys[len(ys)-1]
+	case xs[i] == x:
+		return ys[i]
+	}
+	t := (x - xs[i-1]) / (xs[i] - xs[i-1])
+	return ys[i-1] + t*(ys[i]-ys[i-1])
+}
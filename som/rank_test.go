@@ -0,0 +1,66 @@
+package som_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"testing/quick"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+func TestRankDataAdapter(t *testing.T) {
+	ds := &som.DataSet{Vectors: []som.DataVector{
+		{1, 5},
+		{2, 5},
+		{3, math.NaN()},
+		{1000000, 5},
+		{4, 5},
+	}}
+	adapter := som.NewRankDataAdapter(ds, 0)
+
+	// the outlier doesn't squeeze the other values
+	checkSlicesEqual(t, adapter.Adapt([]float64{2, 5}), []float64{0.25, 0.5})
+	checkSlicesEqual(t, adapter.Adapt([]float64{3.5, 5}), []float64{0.625, 0.5})
+	checkSlicesEqual(t, adapter.Adapt([]float64{-10, 5}), []float64{0, 0.5})
+	checkSlicesEqual(t, adapter.Adapt([]float64{2e6, 5}), []float64{1, 0.5})
+
+	checkSlicesEqual(t, adapter.Inverse([]float64{0.625, 0.5}), []float64{3.5, 5})
+	if v := adapter.Adapt([]float64{math.NaN(), 5})[0]; !math.IsNaN(v) {
+		t.Fatalf("Expected NaN to be kept, got %f", v)
+	}
+}
+
+func TestRankDataAdapterMaxPoints(t *testing.T) {
+	ds := &som.DataSet{}
+	for i := 0; i <= 100; i++ {
+		ds.AddRaw(float64(i))
+	}
+
+	adapter := som.NewRankDataAdapter(ds, 11)
+
+	assertEq(t, len(adapter.Values[0]), 11)
+	checkSlicesEqual(t, adapter.Adapt([]float64{35}), []float64{0.35})
+}
+
+func TestRankDataAdapterInverseRestoresValuesInRange(t *testing.T) {
+	property := func(seed int64, at float64) bool {
+		rng := rand.New(rand.NewSource(seed))
+		ds := &som.DataSet{}
+		for i := 0; i < 30; i++ {
+			ds.Vectors = append(ds.Vectors, som.DataVector{math.Round(rng.ExpFloat64() * 10)})
+		}
+		adapter := som.NewRankDataAdapter(ds, 0)
+		lo, hi := adapter.Values[0][0], adapter.Values[0][len(adapter.Values[0])-1]
+		for _, v := range append(ds.Vectors, som.DataVector{lo + math.Abs(math.Mod(at, 1))*(hi-lo)}) {
+			restored := adapter.Inverse(adapter.Adapt(append([]float64(nil), v...)))
+			if math.Abs(restored[0]-v[0]) > 1e-9*(1+math.Abs(v[0])) {
+				return false
+			}
+		}
+		return true
+	}
+	if err := quick.Check(property, nil); err != nil {
+		t.Fatal(err)
+	}
+}
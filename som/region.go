@@ -0,0 +1,74 @@
+package som
+
+import (
+	"fmt"
+	"math"
+)
+
+// Rect is a rectangular region of the map grid,
+// it contains the neurons (x, y) such that Min.X <= x < Max.X and Min.Y <= y < Max.Y.
+type Rect struct {
+	Min, Max GridPoint
+}
+
+// Contains returns true if the neuron at (x, y) is inside this region.
+func (r Rect) Contains(x, y int) bool {
+	return r.Min.X <= x && x < r.Max.X && r.Min.Y <= y && y < r.Max.Y
+}
+
+// TestWithin finds BMU (Neuron) for the given vector among
+// the neurons inside the region, the region is clipped to the map.
+// Masked neurons are skipped, ties are resolved by TieBreaker, like TestDistances does.
+// Returns nil if there are no unmasked neurons inside the region.
+// Note that this func:
+//   - DOES NOT CHANGE the values of neuron.Distance props;
+//   - ADAPTS input vector using som.InDataAdapter;
+//   - panics like Test does, FindBMUWithin returns the errors instead.
+func (som *SOM) TestWithin(vector DataVector, region Rect) *Neuron {
+	return som.bmuWithin(som.mustAdaptInput(vector), region)
+}
+
+// FindBMUWithin finds BMU (Neuron) for the given vector among the neurons
+// inside the region like TestWithin does, but instead of panicking returns
+// the errors of FindBMU, and ErrInvalidConfig if there are no unmasked
+// neurons inside the region.
+func (som *SOM) FindBMUWithin(vector DataVector, region Rect) (*Neuron, error) {
+	adapted, err := som.adaptInput(vector)
+	if err != nil {
+		return nil, err
+	}
+	bmu := som.bmuWithin(adapted, region)
+	if bmu == nil {
+		return nil, fmt.Errorf("%w: no unmasked neurons inside the region %v", ErrInvalidConfig, region)
+	}
+	return bmu, nil
+}
+
+func (som *SOM) bmuWithin(vector DataVector, region Rect) *Neuron {
+	min := math.Inf(1)
+	var candidates []*Neuron
+	for i := 0; i < len(som.Neurons); i++ {
+		for j := 0; j < len(som.Neurons[i]); j++ {
+			if !region.Contains(i, j) || som.IsMasked(i, j) {
+				continue
+			}
+			d := som.Distance.Apply(vector, som.Neurons[i][j].Weights)
+			if d < min {
+				min = d
+				candidates = append(candidates[:0], som.Neurons[i][j])
+			} else if d == min {
+				candidates = append(candidates, som.Neurons[i][j])
+			}
+		}
+	}
+	switch {
+	case len(candidates) == 0:
+		return nil
+	case len(candidates) == 1:
+		return candidates[0]
+	case som.TieBreaker == nil:
+		return (&RandTieBreaker{}).Break(candidates)
+	default:
+		return som.TieBreaker.Break(candidates)
+	}
+}
@@ -0,0 +1,45 @@
+package som_test
+
+import (
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+func TestTestWithinSearchesOnlyRegion(t *testing.T) {
+	sm := som.New(3, 3)
+	for i := range sm.Neurons {
+		for j := range sm.Neurons[i] {
+			sm.Neurons[i][j].Weights = []float64{float64(i*3 + j)}
+		}
+	}
+
+	bmu := sm.TestWithin(som.DataVector{0}, som.Rect{Min: som.GridPoint{X: 1, Y: 1}, Max: som.GridPoint{X: 3, Y: 3}})
+
+	assertEq(t, bmu.X, 1)
+	assertEq(t, bmu.Y, 1)
+}
+
+func TestTestWithinSkipsMaskedNeuronsAndClipsRegion(t *testing.T) {
+	sm := som.New(2, 2)
+	for i := range sm.Neurons {
+		for j := range sm.Neurons[i] {
+			sm.Neurons[i][j].Weights = []float64{float64(i*2 + j)}
+		}
+	}
+	sm.Mask = [][]bool{{true, false}, {false, false}}
+
+	bmu := sm.TestWithin(som.DataVector{0}, som.Rect{Min: som.GridPoint{X: -5, Y: -5}, Max: som.GridPoint{X: 1, Y: 10}})
+
+	assertEq(t, bmu.X, 0)
+	assertEq(t, bmu.Y, 1)
+}
+
+func TestTestWithinReturnsNilForEmptyRegion(t *testing.T) {
+	sm := som.New(2, 2)
+	sm.Initializer.Init(&som.DataSet{Vectors: []som.DataVector{{0}}}, sm.Neurons)
+
+	if bmu := sm.TestWithin(som.DataVector{0}, som.Rect{Min: som.GridPoint{X: 5, Y: 5}, Max: som.GridPoint{X: 6, Y: 6}}); bmu != nil {
+		t.Fatalf("Expected no BMU, got %v", bmu)
+	}
+}
@@ -0,0 +1,340 @@
+package som
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Params are numeric params of a component created by name,
+// e.g. by a factory registered with RegisterInfluence.
+type Params map[string]float64
+
+// Get returns the value of the named param, or def if it's not set.
+func (p Params) Get(name string, def float64) float64 {
+	if v, ok := p[name]; ok {
+		return v
+	}
+	return def
+}
+
+// Check returns an error wrapping ErrInvalidConfig
+// if there are params other than the known ones.
+func (p Params) Check(known ...string) error {
+	for name := range p {
+		found := false
+		for _, k := range known {
+			if name == k {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%w: unknown param %q", ErrInvalidConfig, name)
+		}
+	}
+	return nil
+}
+
+// DistanceFactory creates a distance function from its params.
+type DistanceFactory func(params Params) (DistanceFunc, error)
+
+// InfluenceFactory creates an influence function from its params.
+type InfluenceFactory func(params Params) (InfluenceFunc, error)
+
+// RestraintFactory creates a restraint function from its params.
+type RestraintFactory func(params Params) (RestraintFunc, error)
+
+// InitializerFactory creates a neurons initializer from its params,
+// random initializers should use the given source, so they are reproducible.
+type InitializerFactory func(params Params, rng *rand.Rand) (NeuronsInitializer, error)
+
+// TopologyFactory creates a topology from its params and values,
+// e.g. the edges of GraphLattice.
+type TopologyFactory func(params Params, values map[string][]float64) (Topology, error)
+
+// AdapterFactory creates an input adapter from its params and
+// the values of its fitted state, e.g. the ranges of the features.
+type AdapterFactory func(params Params, values map[string][]float64) (DataAdapter, error)
+
+// Describer is implemented by the components which can be recreated by the
+// factories they are registered with, so the maps using them as the topology,
+// the distance function or the input adapter can be saved, see SOM.SaveJSON.
+type Describer interface {
+	// Describe returns the name of the factory and the params
+	// and the values which it recreates the component from.
+	// The spec without the type means that the component can't be
+	// recreated, e.g. LatticeTopology of the lattice which isn't a Describer.
+	Describe() ComponentSpec
+}
+
+const (
+	distanceKind    = "distance"
+	influenceKind   = "influence"
+	restraintKind   = "restraint"
+	initializerKind = "initializer"
+	topologyKind    = "topology"
+	adapterKind     = "adapter"
+)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]map[string]interface{}{}
+)
+
+// RegisterDistance makes the distance function available by the name,
+// e.g. to experiment specs. It panics if the name is already registered,
+// so it's usually called from init functions.
+func RegisterDistance(name string, factory DistanceFactory) {
+	register(distanceKind, name, factory)
+}
+
+// RegisterInfluence makes the influence function available by the name, see RegisterDistance.
+func RegisterInfluence(name string, factory InfluenceFactory) {
+	register(influenceKind, name, factory)
+}
+
+// RegisterRestraint makes the restraint function available by the name, see RegisterDistance.
+func RegisterRestraint(name string, factory RestraintFactory) {
+	register(restraintKind, name, factory)
+}
+
+// RegisterInitializer makes the neurons initializer available by the name, see RegisterDistance.
+func RegisterInitializer(name string, factory InitializerFactory) {
+	register(initializerKind, name, factory)
+}
+
+// RegisterTopology makes the topology available by the name, see RegisterDistance.
+func RegisterTopology(name string, factory TopologyFactory) {
+	register(topologyKind, name, factory)
+}
+
+// RegisterAdapter makes the input adapter available by the name, see RegisterDistance.
+func RegisterAdapter(name string, factory AdapterFactory) {
+	register(adapterKind, name, factory)
+}
+
+// NewDistance creates the distance function registered by the name.
+// Unknown names and invalid params are reported by errors wrapping ErrInvalidConfig.
+func NewDistance(name string, params Params) (DistanceFunc, error) {
+	factory, err := lookup(distanceKind, name)
+	if err != nil {
+		return nil, err
+	}
+	distance, err := factory.(DistanceFactory)(params)
+	if err := componentError(distanceKind, name, distance, err); err != nil {
+		return nil, err
+	}
+	return distance, nil
+}
+
+// NewInfluence creates the influence function registered by the name, see NewDistance.
+// Functions implementing ParamsValidator are validated.
+func NewInfluence(name string, params Params) (InfluenceFunc, error) {
+	factory, err := lookup(influenceKind, name)
+	if err != nil {
+		return nil, err
+	}
+	influence, err := factory.(InfluenceFactory)(params)
+	if err := componentError(influenceKind, name, influence, err); err != nil {
+		return nil, err
+	}
+	return influence, nil
+}
+
+// NewRestraint creates the restraint function registered by the name, see NewInfluence.
+func NewRestraint(name string, params Params) (RestraintFunc, error) {
+	factory, err := lookup(restraintKind, name)
+	if err != nil {
+		return nil, err
+	}
+	restraint, err := factory.(RestraintFactory)(params)
+	if err := componentError(restraintKind, name, restraint, err); err != nil {
+		return nil, err
+	}
+	return restraint, nil
+}
+
+// NewInitializer creates the neurons initializer registered by the name, see NewDistance.
+// The rng may be nil, then random initializers use the global source.
+func NewInitializer(name string, params Params, rng *rand.Rand) (NeuronsInitializer, error) {
+	factory, err := lookup(initializerKind, name)
+	if err != nil {
+		return nil, err
+	}
+	initializer, err := factory.(InitializerFactory)(params, rng)
+	if err := componentError(initializerKind, name, initializer, err); err != nil {
+		return nil, err
+	}
+	return initializer, nil
+}
+
+// NewTopology creates the topology registered by the name, see NewDistance.
+func NewTopology(name string, params Params, values map[string][]float64) (Topology, error) {
+	factory, err := lookup(topologyKind, name)
+	if err != nil {
+		return nil, err
+	}
+	topology, err := factory.(TopologyFactory)(params, values)
+	if err := componentError(topologyKind, name, topology, err); err != nil {
+		return nil, err
+	}
+	return topology, nil
+}
+
+// NewAdapter creates the input adapter registered by the name, see NewDistance.
+func NewAdapter(name string, params Params, values map[string][]float64) (DataAdapter, error) {
+	factory, err := lookup(adapterKind, name)
+	if err != nil {
+		return nil, err
+	}
+	adapter, err := factory.(AdapterFactory)(params, values)
+	if err := componentError(adapterKind, name, adapter, err); err != nil {
+		return nil, err
+	}
+	return adapter, nil
+}
+
+func register(kind, name string, factory interface{}) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if name == "" {
+		panic("som: " + kind + " name is empty")
+	}
+	if registry[kind] == nil {
+		registry[kind] = map[string]interface{}{}
+	}
+	if _, dup := registry[kind][name]; dup {
+		panic("som: " + kind + " " + name + " is registered twice")
+	}
+	registry[kind][name] = factory
+}
+
+func lookup(kind, name string) (interface{}, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	factory, ok := registry[kind][name]
+	if !ok {
+		names := make([]string, 0, len(registry[kind]))
+		for n := range registry[kind] {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("%w: unknown %s %q, registered: %s", ErrInvalidConfig, kind, name, strings.Join(names, ", "))
+	}
+	return factory, nil
+}
+
+// componentError validates the created component
+// and describes the error, if there is one.
+func componentError(kind, name string, component interface{}, err error) error {
+	if err == nil {
+		if validator, ok := component.(ParamsValidator); ok {
+			err = validator.Validate()
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", kind, name, err)
+	}
+	return nil
+}
+
+func init() {
+	RegisterDistance("euclidean", func(params Params) (DistanceFunc, error) {
+		return &EuclideanDistanceFunc{}, params.Check()
+	})
+	RegisterDistance("manhattan", func(params Params) (DistanceFunc, error) {
+		return &ManhattanDistanceFunc{}, params.Check()
+	})
+	RegisterDistance("chebyshev", func(params Params) (DistanceFunc, error) {
+		return &ChebyshevDistanceFunc{}, params.Check()
+	})
+	RegisterDistance("cosine", func(params Params) (DistanceFunc, error) {
+		return &CosineDistanceFunc{}, params.Check()
+	})
+
+	RegisterInfluence("bmu-only", func(params Params) (InfluenceFunc, error) {
+		return &BMUOnlyInfluencedFunc{}, params.Check()
+	})
+	RegisterInfluence("constant", func(params Params) (InfluenceFunc, error) {
+		return &RadiusReducingConstantInfluenceFunc{
+			Radius:    params.Get("radius", 1),
+			MinRadius: params.Get("min_radius", 0),
+		}, params.Check("radius", "min_radius")
+	})
+	RegisterInfluence("gaussian-exp-decay", func(params Params) (InfluenceFunc, error) {
+		return &GaussianExpDecayInfluenceFunc{
+			InitialWidth: params.Get("initial_width", 1),
+			MinWidth:     params.Get("min_width", 0),
+		}, params.Check("initial_width", "min_width")
+	})
+	RegisterInfluence("adaptive-gaussian", func(params Params) (InfluenceFunc, error) {
+		return &AdaptiveGaussianInfluenceFunc{
+			InitialWidth: params.Get("initial_width", 1),
+			MinWidth:     params.Get("min_width", 0),
+			Shrink:       params.Get("shrink", 0.8),
+			Threshold:    params.Get("threshold", 0.01),
+			Window:       int(params.Get("window", 100)),
+		}, params.Check("initial_width", "min_width", "shrink", "threshold", "window")
+	})
+
+	RegisterRestraint("none", func(params Params) (RestraintFunc, error) {
+		return &NoRestraintFunc{}, params.Check()
+	})
+	RegisterRestraint("simple", func(params Params) (RestraintFunc, error) {
+		return &SimpleRestraintFunc{A: params.Get("a", 1), B: params.Get("b", 1)}, params.Check("a", "b")
+	})
+	RegisterRestraint("exp", func(params Params) (RestraintFunc, error) {
+		return &ExpRestraintFunc{
+			InitialRate: params.Get("initial_rate", 1),
+			N:           params.Get("n", 0),
+		}, params.Check("initial_rate", "n")
+	})
+
+	RegisterTopology("planar", func(params Params, values map[string][]float64) (Topology, error) {
+		return &PlanarTopology{}, params.Check()
+	})
+	RegisterTopology("torus", func(params Params, values map[string][]float64) (Topology, error) {
+		return &TorusTopology{}, params.Check()
+	})
+	RegisterTopology("cylinder", func(params Params, values map[string][]float64) (Topology, error) {
+		axis := Axis(params.Get("axis", 0))
+		if axis != AxisX && axis != AxisY {
+			return nil, fmt.Errorf("%w: cylinder axis must be 0 or 1, got %d", ErrInvalidConfig, axis)
+		}
+		return &CylinderTopology{Wrapped: axis}, params.Check("axis")
+	})
+	RegisterTopology("graph", newGraphTopology)
+	RegisterTopology("sphere", newSphereTopology)
+
+	RegisterAdapter("no-op", func(params Params, values map[string][]float64) (DataAdapter, error) {
+		return &NoOpAdapter{}, params.Check()
+	})
+	RegisterAdapter("unit-norm", func(params Params, values map[string][]float64) (DataAdapter, error) {
+		return &UnitNormAdapter{}, params.Check()
+	})
+	RegisterAdapter("scaling", func(params Params, values map[string][]float64) (DataAdapter, error) {
+		min, diff := values["min"], values["max_min_diff"]
+		if len(min) == 0 || len(min) != len(diff) {
+			return nil, fmt.Errorf("%w: scaling needs min and max_min_diff values of the same length", ErrInvalidConfig)
+		}
+		return &ScalingDataAdapter{Min: min, MaxMinDiff: diff}, params.Check()
+	})
+	RegisterAdapter("running-scaling", newRunningScalingDataAdapter)
+	RegisterAdapter("rank", newRankDataAdapter)
+	RegisterAdapter("whitening", newWhiteningDataAdapter)
+	RegisterAdapter("imputing", newImputingDataAdapter)
+	RegisterAdapter("knn-imputer", newKNNImputer)
+
+	RegisterInitializer("zero", func(params Params, rng *rand.Rand) (NeuronsInitializer, error) {
+		return &ZeroValueWeightsInitializer{}, params.Check()
+	})
+	RegisterInitializer("rand", func(params Params, rng *rand.Rand) (NeuronsInitializer, error) {
+		return &RandWeightsInitializer{Rand: rng}, params.Check()
+	})
+	RegisterInitializer("rand-vectors", func(params Params, rng *rand.Rand) (NeuronsInitializer, error) {
+		return &RandDataSetVectorsWeightsInitializer{Rand: rng}, params.Check()
+	})
+}
@@ -0,0 +1,132 @@
+package som_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+type scaledDistanceFunc struct {
+	scale float64
+}
+
+func (d *scaledDistanceFunc) Apply(x, y []float64) float64 {
+	return d.scale * (&som.EuclideanDistanceFunc{}).Apply(x, y)
+}
+
+func (d *scaledDistanceFunc) Describe() som.ComponentSpec {
+	return som.ComponentSpec{Type: "test-scaled", Params: som.Params{"scale": d.scale}}
+}
+
+func init() {
+	som.RegisterDistance("test-scaled", func(params som.Params) (som.DistanceFunc, error) {
+		return &scaledDistanceFunc{scale: params.Get("scale", 1)}, params.Check("scale")
+	})
+}
+
+func TestNewDistanceCreatesRegisteredFunc(t *testing.T) {
+	distance, err := som.NewDistance("test-scaled", som.Params{"scale": 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEq(t, distance.Apply([]float64{0, 0}, []float64{3, 4}), 10.0)
+
+	if _, err := som.NewDistance("test-scaled", som.Params{"scal": 2}); !errors.Is(err, som.ErrInvalidConfig) {
+		t.Fatalf("Expected unknown param to be rejected, got %v", err)
+	}
+}
+
+func TestNewComponentRejectsUnknownNames(t *testing.T) {
+	_, err := som.NewInfluence("no-such-influence", nil)
+	if !errors.Is(err, som.ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "gaussian-exp-decay") {
+		t.Fatalf("Expected registered names to be listed, got %v", err)
+	}
+}
+
+func TestNewInfluenceValidatesParams(t *testing.T) {
+	if _, err := som.NewInfluence("gaussian-exp-decay", som.Params{"initial_width": -1}); !errors.Is(err, som.ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig, got %v", err)
+	}
+}
+
+func TestRegisterPanicsOnDuplicateName(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Expected panic")
+		}
+	}()
+	som.RegisterRestraint("exp", func(params som.Params) (som.RestraintFunc, error) {
+		return &som.NoRestraintFunc{}, nil
+	})
+}
+
+func TestExperimentUsesRegisteredComponents(t *testing.T) {
+	spec := strings.Replace(experimentSpecJSON, `"epochs"`, `"distance": {"type": "test-scaled", "params": {"scale": 3}}, "epochs"`, 1)
+	result := runExperiment(t, writeExperiment(t, spec))
+	if _, ok := result.SOM.Distance.(*scaledDistanceFunc); !ok {
+		t.Fatalf("Unexpected distance %T", result.SOM.Distance)
+	}
+}
+
+func TestDescribedComponentsAreRecreatedByName(t *testing.T) {
+	topology := &som.CylinderTopology{Wrapped: som.AxisY}
+	spec := topology.Describe()
+	recreated, err := som.NewTopology(spec.Type, spec.Params, spec.Values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEq(t, *recreated.(*som.CylinderTopology), *topology)
+
+	adapter := som.NewScalingDataAdapter([]float64{0, 10}, []float64{2, 20})
+	spec = adapter.Describe()
+	created, err := som.NewAdapter(spec.Type, spec.Params, spec.Values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vector := created.Adapt([]float64{1, 15})
+	assertEq(t, vector[0], 0.5)
+	assertEq(t, vector[1], 0.5)
+
+	if _, err := som.NewAdapter("scaling", nil, nil); !errors.Is(err, som.ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig, got %v", err)
+	}
+}
+
+func TestRunningScalingDataAdapterIsRecreatedWithStatistics(t *testing.T) {
+	adapter := &som.RunningScalingDataAdapter{Method: som.StandardScaling}
+	adapter.Adapt([]float64{1, 2})
+	adapter.Adapt([]float64{3, 6})
+	adapter.Freeze()
+
+	spec := adapter.Describe()
+	created, err := som.NewAdapter(spec.Type, spec.Params, spec.Values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recreated := created.(*som.RunningScalingDataAdapter)
+	assertEq(t, recreated.Count(), 2)
+	assertEq(t, recreated.IsFrozen(), true)
+	vector := recreated.Adapt([]float64{3, 2})
+	assertEq(t, vector[0], 1.0)
+	assertEq(t, vector[1], -1.0)
+}
+
+func TestLearnValidatesComponents(t *testing.T) {
+	ds := &som.DataSet{Vectors: []som.DataVector{{0}, {1}}}
+	sm := som.New(2, 2)
+	sm.Influence = &som.RadiusReducingConstantInfluenceFunc{Radius: -1}
+	if err := sm.Learn(ds, 10); !errors.Is(err, som.ErrInvalidConfig) {
+		t.Fatalf("Expected Learn to return ErrInvalidConfig, got %v", err)
+	}
+	if err := sm.LearnBatch(ds, 2); !errors.Is(err, som.ErrInvalidConfig) {
+		t.Fatalf("Expected LearnBatch to return ErrInvalidConfig, got %v", err)
+	}
+	if sm.IsTrained() {
+		t.Fatal("Expected the map not to be trained with the invalid influence")
+	}
+}
@@ -0,0 +1,131 @@
+package som
+
+import (
+	"fmt"
+	"math"
+)
+
+// Regression records the numeric targets of the vectors mapped to each neuron,
+// which makes a model a regressor, see Model.CalibrateRegression.
+type Regression struct {
+	// Mean[x][y] and Variance[x][y] are the mean and the population variance
+	// of the targets of the vectors which have the neuron at (x, y) as their BMU,
+	// both are NaN if no vectors are mapped to the neuron.
+	Mean, Variance [][]float64
+
+	// Mapped[x][y] is the number of vectors mapped to the neuron at (x, y).
+	Mapped [][]int
+
+	// Coefficients[x][y] are the intercept and the slopes of the local linear
+	// model of the neuron at (x, y), nil if the neuron has no model.
+	// Coefficients is nil unless the model is calibrated by
+	// Model.CalibrateLocalRegression.
+	Coefficients [][][]float64
+}
+
+// CalibrateRegression maps the vectors, targets[i] is the target of ds.At(i),
+// and returns a copy of this model calibrated by them, which can Predict targets.
+// The copy shares the codebook with this model.
+func (m *Model) CalibrateRegression(ds *DataSet, targets []float64) (*Model, error) {
+	if len(targets) != ds.Len() {
+		return nil, fmt.Errorf("%d targets for %d vectors", len(targets), ds.Len())
+	}
+	vectors := make([]DataVector, ds.Len())
+	for i := range vectors {
+		vectors[i] = ds.At(i)
+	}
+	points, err := m.MapBatch(vectors)
+	if err != nil {
+		return nil, err
+	}
+
+	xLen, yLen := m.Dims()
+	reg := &Regression{
+		Mean:     make([][]float64, xLen),
+		Variance: make([][]float64, xLen),
+		Mapped:   make([][]int, xLen),
+	}
+	for x := 0; x < xLen; x++ {
+		reg.Mean[x] = make([]float64, yLen)
+		reg.Variance[x] = make([]float64, yLen)
+		reg.Mapped[x] = make([]int, yLen)
+	}
+	// Welford's online algorithm
+	for i, p := range points {
+		reg.Mapped[p.X][p.Y]++
+		delta := targets[i] - reg.Mean[p.X][p.Y]
+		reg.Mean[p.X][p.Y] += delta / float64(reg.Mapped[p.X][p.Y])
+		reg.Variance[p.X][p.Y] += delta * (targets[i] - reg.Mean[p.X][p.Y])
+	}
+	for x := 0; x < xLen; x++ {
+		for y := 0; y < yLen; y++ {
+			if n := reg.Mapped[x][y]; n == 0 {
+				reg.Mean[x][y], reg.Variance[x][y] = math.NaN(), math.NaN()
+			} else {
+				reg.Variance[x][y] /= float64(n)
+			}
+		}
+	}
+
+	calibrated := *m
+	calibrated.regression = reg
+	return &calibrated, nil
+}
+
+// Regression returns the regression calibration of this model,
+// nil if it's not calibrated for regression.
+// The returned regression must not be modified.
+func (m *Model) Regression() *Regression {
+	return m.regression
+}
+
+// Predict returns the target estimated for the vector. The estimate is
+// interpolated from the means of the closest calibrated neuron (the BMU among
+// the neurons having vectors mapped to them) and of its calibrated direct
+// neighbours on the map, weighted by 1/d², where d is the distance from
+// the neuron to the vector. The mean of a neuron at zero distance is returned as is.
+// If the closest calibrated neuron has a local linear model, the estimate
+// is the value of the model instead, see CalibrateLocalRegression.
+// Returns ErrNotCalibrated if the model is not calibrated for regression.
+func (m *Model) Predict(vector DataVector) (float64, error) {
+	if m.regression == nil {
+		return 0, ErrNotCalibrated
+	}
+	field := m.som.borrowField()
+	defer releaseField(field)
+	distances, err := m.distances(vector, *field)
+	if err != nil {
+		return 0, err
+	}
+
+	reg := m.regression
+	bx, by, min := -1, -1, math.Inf(1)
+	for x := range distances {
+		for y, d := range distances[x] {
+			if d < min && reg.Mapped[x][y] > 0 {
+				bx, by, min = x, y, d
+			}
+		}
+	}
+	if bx == -1 {
+		return 0, ErrNotCalibrated
+	}
+	if reg.Coefficients != nil && reg.Coefficients[bx][by] != nil {
+		return linear(reg.Coefficients[bx][by], vector), nil
+	}
+	if min == 0 {
+		return reg.Mean[bx][by], nil
+	}
+
+	sum, weights := reg.Mean[bx][by]/(min*min), 1/(min*min)
+	for _, offset := range [][2]int{{-1, 0}, {1, 0}, {0, -1}, {0, 1}} {
+		x, y, ok := m.som.neighbour(bx, by, offset[0], offset[1])
+		if !ok || reg.Mapped[x][y] == 0 || math.IsInf(distances[x][y], 1) {
+			continue
+		}
+		w := 1 / (distances[x][y] * distances[x][y])
+		sum += w * reg.Mean[x][y]
+		weights += w
+	}
+	return sum / weights, nil
+}
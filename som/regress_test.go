@@ -0,0 +1,52 @@
+package som_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+	"github.com/voievodin/self-organizing-map/som/internal/somtest"
+)
+
+func TestPredictInterpolatesNeighbourMeans(t *testing.T) {
+	model := somtest.LineModel(t, 0, 10, 20)
+	if _, err := model.Predict(som.DataVector{1}); !errors.Is(err, som.ErrNotCalibrated) {
+		t.Fatalf("Expected ErrNotCalibrated, got %v", err)
+	}
+
+	ds := &som.DataSet{Vectors: []som.DataVector{{-1}, {1}, {9}, {11}, {20}}}
+	calibrated, err := model.CalibrateRegression(ds, []float64{1, 3, 10, 10, 40})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reg := calibrated.Regression()
+	checkSlicesEqual(t, reg.Mean[0], []float64{2, 10, 40})
+	checkSlicesEqual(t, reg.Variance[0], []float64{1, 0, 0})
+
+	prediction, _ := calibrated.Predict(som.DataVector{20})
+	assertEq(t, prediction, 40.0)
+
+	// the BMU (0, 1) and its neighbours at 9 and 11
+	prediction, err = calibrated.Predict(som.DataVector{9})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := (10.0 + 2.0/81 + 40.0/121) / (1 + 1.0/81 + 1.0/121)
+	if math.Abs(prediction-expected) > 1e-9 {
+		t.Fatalf("Expected prediction %f, got %f", expected, prediction)
+	}
+}
+
+func TestPredictSkipsNeuronsWithoutTargets(t *testing.T) {
+	ds := &som.DataSet{Vectors: []som.DataVector{{0}}}
+	calibrated, err := somtest.LineModel(t, 0, 10).CalibrateRegression(ds, []float64{7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !math.IsNaN(calibrated.Regression().Mean[0][1]) {
+		t.Fatal("Expected NaN mean of the neuron without vectors")
+	}
+	prediction, _ := calibrated.Predict(som.DataVector{9})
+	assertEq(t, prediction, 7.0)
+}
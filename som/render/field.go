@@ -0,0 +1,148 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+// Flow returns the direction from each neuron of the map towards its most
+// similar neighbour, the one of the 8 surrounding neurons including the
+// neighbours across the edges connected by the topology of the map, whose
+// weights are the closest by the Euclidean distance. The value at (x, y) is
+// the offset of the neighbour of the neuron at (x, y), each coordinate is
+// -1, 0 or 1. Masked neurons and the neurons without unmasked neighbours
+// have zero offsets. The flow shows the internal structure of the map:
+// arrows converge within clusters and fold lines show where they collide.
+func Flow(m *som.Model) [][]image.Point {
+	xLen, yLen := m.Dims()
+	topology := m.Topology()
+	flow := make([][]image.Point, xLen)
+	for x := range flow {
+		flow[x] = make([]image.Point, yLen)
+		for y := range flow[x] {
+			if m.IsMasked(x, y) {
+				continue
+			}
+			weights := m.Weights(x, y)
+			min := math.Inf(1)
+			for dx := -1; dx <= 1; dx++ {
+				for dy := -1; dy <= 1; dy++ {
+					nx, ny, ok := neighbour(topology, x, y, dx, dy, xLen, yLen)
+					if !ok || m.IsMasked(nx, ny) {
+						continue
+					}
+					if d := euclidean(weights, m.Weights(nx, ny)); d < min {
+						min = d
+						flow[x][y] = image.Point{dx, dy}
+					}
+				}
+			}
+		}
+	}
+	return flow
+}
+
+// neighbour returns the position of the neuron at (x+dx, y+dy) offset from
+// the neuron at (x, y), wrapping the offset across the edges connected by the
+// topology. Returns false if there is no such neuron, or it's the neuron itself.
+func neighbour(topology som.Topology, x, y, dx, dy, xLen, yLen int) (int, int, bool) {
+	if dx == 0 && dy == 0 {
+		return 0, 0, false
+	}
+	nx, ny := x+dx, y+dy
+	if nx >= 0 && nx < xLen && ny >= 0 && ny < yLen {
+		return nx, ny, true
+	}
+	nx, ny = (nx+xLen)%xLen, (ny+yLen)%yLen
+	if nx == x && ny == y {
+		return 0, 0, false
+	}
+	// the wrapped neuron is the neighbour if the topology connects the edges
+	step := math.Sqrt(float64(dx*dx + dy*dy))
+	if som.GridDistance(topology, nx, ny, x, y, xLen, yLen) != step {
+		return 0, 0, false
+	}
+	return nx, ny, true
+}
+
+func euclidean(a, b []float64) float64 {
+	sum := 0.0
+	for i := range a {
+		sum += (a[i] - b[i]) * (a[i] - b[i])
+	}
+	return math.Sqrt(sum)
+}
+
+// VectorFieldOptions configure VectorField.
+type VectorFieldOptions struct {
+	// Scale is the size of the square of a neuron in the base image in pixels.
+	Scale int
+
+	// Color is the color of the arrows, zero means black.
+	Color color.NRGBA
+}
+
+// VectorField draws the Flow of the map as arrows on top of the base image,
+// e.g. the U-matrix rendered by quality.Render: the arrow in the square of
+// a neuron points from its center towards its most similar neighbour.
+// The base image is not modified. Returns an error if the map doesn't fit the base image.
+func VectorField(base image.Image, m *som.Model, options VectorFieldOptions) (*image.NRGBA, error) {
+	if options.Scale < 1 {
+		return nil, fmt.Errorf("%w: scale must be positive, got %d", som.ErrInvalidConfig, options.Scale)
+	}
+	xLen, yLen := m.Dims()
+	img := copyImage(base)
+	bounds := img.Bounds()
+	if xLen*options.Scale > bounds.Dx() || yLen*options.Scale > bounds.Dy() {
+		return nil, fmt.Errorf("%dx%d map of scale %d is bigger than %v image", xLen, yLen, options.Scale, bounds.Size())
+	}
+	c := options.Color
+	if c == (color.NRGBA{}) {
+		c = color.NRGBA{A: 255}
+	}
+
+	for x, column := range Flow(m) {
+		for y, d := range column {
+			if d == (image.Point{}) {
+				continue
+			}
+			cx := float64(bounds.Min.X) + (float64(x)+0.5)*float64(options.Scale)
+			cy := float64(bounds.Min.Y) + (float64(y)+0.5)*float64(options.Scale)
+			length := 0.4 * float64(options.Scale)
+			norm := math.Hypot(float64(d.X), float64(d.Y))
+			ux, uy := float64(d.X)/norm, float64(d.Y)/norm
+			drawArrow(img, cx-ux*length/2, cy-uy*length/2, cx+ux*length/2, cy+uy*length/2, c)
+		}
+	}
+	return img, nil
+}
+
+// drawArrow draws the arrow from (x1, y1) to (x2, y2) with the head at (x2, y2).
+func drawArrow(img *image.NRGBA, x1, y1, x2, y2 float64, c color.NRGBA) {
+	drawLine(img, x1, y1, x2, y2, c)
+	length := math.Hypot(x2-x1, y2-y1)
+	head := math.Max(1, length*0.4)
+	angle := math.Atan2(y2-y1, x2-x1)
+	for _, side := range []float64{-1, 1} {
+		a := angle + math.Pi + side*math.Pi/6
+		drawLine(img, x2, y2, x2+head*math.Cos(a), y2+head*math.Sin(a), c)
+	}
+}
+
+func drawLine(img *image.NRGBA, x1, y1, x2, y2 float64, c color.NRGBA) {
+	steps := int(math.Ceil(math.Max(math.Abs(x2-x1), math.Abs(y2-y1))))
+	for i := 0; i <= steps; i++ {
+		t := 0.0
+		if steps > 0 {
+			t = float64(i) / float64(steps)
+		}
+		p := image.Point{int(math.Floor(x1 + t*(x2-x1))), int(math.Floor(y1 + t*(y2-y1)))}
+		if p.In(img.Rect) {
+			img.SetNRGBA(p.X, p.Y, c)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package render_test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+	"github.com/voievodin/self-organizing-map/som/render"
+)
+
+func flowModel(t *testing.T, topology som.Topology, codebook [][][]float64) *som.Model {
+	s := som.New(len(codebook), len(codebook[0]))
+	s.Topology = topology
+	if err := s.LoadCodebook(codebook); err != nil {
+		t.Fatal(err)
+	}
+	m, err := s.Model()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestFlow(t *testing.T) {
+	m := flowModel(t, &som.PlanarTopology{}, [][][]float64{{{0}, {1}, {5}, {6}}})
+
+	flow := render.Flow(m)
+
+	expected := []image.Point{{0, 1}, {0, -1}, {0, 1}, {0, -1}}
+	for y, d := range flow[0] {
+		if d != expected[y] {
+			t.Fatalf("Expected flow %v, got %v", expected, flow[0])
+		}
+	}
+}
+
+func TestFlowHonorsTopology(t *testing.T) {
+	codebook := [][][]float64{{{0}, {3}, {6}, {1}}}
+
+	if d := render.Flow(flowModel(t, &som.PlanarTopology{}, codebook))[0][0]; d != (image.Point{0, 1}) {
+		t.Fatalf("Expected the planar map to flow to the only neighbour, got %v", d)
+	}
+	if d := render.Flow(flowModel(t, &som.TorusTopology{}, codebook))[0][0]; d != (image.Point{0, -1}) {
+		t.Fatalf("Expected the torus to flow across the edge, got %v", d)
+	}
+}
+
+func TestVectorField(t *testing.T) {
+	m := flowModel(t, &som.PlanarTopology{}, [][][]float64{{{0}}, {{1}}, {{5}}})
+	base := image.NewNRGBA(image.Rect(0, 0, 30, 10))
+	green := color.NRGBA{0, 255, 0, 255}
+
+	img, err := render.VectorField(base, m, render.VectorFieldOptions{Scale: 10, Color: green})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for x := 0; x < 3; x++ {
+		if countColor(img, image.Rect(x*10, 0, (x+1)*10, 10), green) == 0 {
+			t.Fatalf("Expected an arrow in the square of the neuron (%d, 0)", x)
+		}
+	}
+	// the arrow of (0, 0) points right, its head is at the right of its center
+	left, right := countColor(img, image.Rect(0, 0, 5, 10), green), countColor(img, image.Rect(5, 0, 10, 10), green)
+	if right <= left {
+		t.Fatalf("Expected the arrow to point right, got %d pixels at the left and %d at the right", left, right)
+	}
+
+	if _, err := render.VectorField(image.NewNRGBA(image.Rect(0, 0, 10, 10)), m, render.VectorFieldOptions{Scale: 10}); err == nil {
+		t.Fatal("Expected the map bigger than the image to be rejected")
+	}
+}
@@ -0,0 +1,159 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"sort"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+// GlyphShape is the shape of the glyphs drawn by Glyphs.
+type GlyphShape int
+
+const (
+	// PieGlyph is the pie chart whose slices are the shares of the labels,
+	// clockwise from 12 o'clock in the sorted order of the labels.
+	PieGlyph GlyphShape = iota
+
+	// BarGlyph is the stacked bar whose segments are the shares of the labels,
+	// from the bottom in the sorted order of the labels.
+	BarGlyph
+)
+
+// GlyphOptions configure Glyphs.
+type GlyphOptions struct {
+	// Scale is the size of the square of a neuron in the base image in pixels.
+	Scale int
+
+	// Colors are the colors of the labels, nil means LabelColors of the labels.
+	// The labels missing from the colors are drawn black.
+	Colors map[string]color.NRGBA
+
+	// Shape is the shape of the glyphs, PieGlyph by default.
+	Shape GlyphShape
+}
+
+// Glyphs draws the label composition of the samples projected on the map,
+// e.g. by som.Model.MapBatch, on top of the base image, e.g. the U-matrix
+// rendered by quality.Render: the glyph in the square of a neuron shows the
+// shares of the labels of the samples the neuron is BMU of, see GlyphShape.
+// The neurons which are not BMU of any sample have no glyphs.
+// The base image is not modified. Returns an error if the numbers of the
+// projections and the labels differ or a projection is outside of the base image.
+func Glyphs(base image.Image, projections []som.GridPoint, labels []string, options GlyphOptions) (*image.NRGBA, error) {
+	if len(labels) != len(projections) {
+		return nil, fmt.Errorf("%d labels for %d projections", len(labels), len(projections))
+	}
+	if options.Scale < 1 {
+		return nil, fmt.Errorf("%w: scale must be positive, got %d", som.ErrInvalidConfig, options.Scale)
+	}
+	colors := options.Colors
+	if colors == nil {
+		colors = LabelColors(labels)
+	}
+
+	img := copyImage(base)
+	bounds := img.Bounds()
+	compositions := make(map[som.GridPoint]map[string]int)
+	for i, p := range projections {
+		cell := image.Rect(p.X*options.Scale, p.Y*options.Scale, (p.X+1)*options.Scale, (p.Y+1)*options.Scale).Add(bounds.Min)
+		if !cell.In(bounds) {
+			return nil, fmt.Errorf("projection %d at (%d, %d) is outside of %v image of scale %d", i, p.X, p.Y, bounds.Size(), options.Scale)
+		}
+		if compositions[p] == nil {
+			compositions[p] = make(map[string]int)
+		}
+		compositions[p][labels[i]]++
+	}
+
+	for p, counts := range compositions {
+		cell := image.Rect(p.X*options.Scale, p.Y*options.Scale, (p.X+1)*options.Scale, (p.Y+1)*options.Scale).Add(bounds.Min)
+		shares := newShares(counts, colors)
+		if options.Shape == BarGlyph {
+			drawBar(img, cell, shares)
+		} else {
+			drawPie(img, cell, shares)
+		}
+	}
+	return img, nil
+}
+
+// share is the cumulative share of the labels up to and including the label.
+type share struct {
+	upTo  float64
+	color color.NRGBA
+}
+
+func newShares(counts map[string]int, colors map[string]color.NRGBA) []share {
+	labels := make([]string, 0, len(counts))
+	total := 0
+	for label, n := range counts {
+		labels = append(labels, label)
+		total += n
+	}
+	sort.Strings(labels)
+
+	shares := make([]share, len(labels))
+	sum := 0
+	for i, label := range labels {
+		sum += counts[label]
+		c, ok := colors[label]
+		if !ok {
+			c = color.NRGBA{A: 255}
+		}
+		shares[i] = share{upTo: float64(sum) / float64(total), color: c}
+	}
+	return shares
+}
+
+// colorAt returns the color of the label whose share covers t => [0, 1).
+func colorAt(shares []share, t float64) color.NRGBA {
+	for _, s := range shares {
+		if t < s.upTo {
+			return s.color
+		}
+	}
+	return shares[len(shares)-1].color
+}
+
+func drawPie(img *image.NRGBA, cell image.Rectangle, shares []share) {
+	size := cell.Dx()
+	// the center is between the pixels for even sizes
+	c := float64(size-1) / 2
+	r := float64(size) / 2
+	if size > 2 {
+		r--
+	}
+	for px := 0; px < size; px++ {
+		for py := 0; py < size; py++ {
+			dx, dy := float64(px)-c, float64(py)-c
+			if dx*dx+dy*dy > r*r {
+				continue
+			}
+			// clockwise from 12 o'clock, y grows downwards
+			t := math.Atan2(dx, -dy) / (2 * math.Pi)
+			if t < 0 {
+				t++
+			}
+			img.SetNRGBA(cell.Min.X+px, cell.Min.Y+py, colorAt(shares, t))
+		}
+	}
+}
+
+func drawBar(img *image.NRGBA, cell image.Rectangle, shares []share) {
+	bar := cell
+	if cell.Dx() > 2 {
+		bar = cell.Inset(1)
+	}
+	height := bar.Dy()
+	for py := 0; py < height; py++ {
+		// from the bottom
+		c := colorAt(shares, (float64(py)+0.5)/float64(height))
+		for x := bar.Min.X; x < bar.Max.X; x++ {
+			img.SetNRGBA(x, bar.Max.Y-1-py, c)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package render_test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+	"github.com/voievodin/self-organizing-map/som/render"
+)
+
+var (
+	red  = color.NRGBA{255, 0, 0, 255}
+	blue = color.NRGBA{0, 0, 255, 255}
+)
+
+func TestPieGlyphs(t *testing.T) {
+	base := image.NewNRGBA(image.Rect(0, 0, 40, 20))
+	projections := []som.GridPoint{{X: 0, Y: 0}, {X: 0, Y: 0}, {X: 0, Y: 0}, {X: 0, Y: 0}}
+	labels := []string{"a", "b", "b", "b"}
+	colors := map[string]color.NRGBA{"a": red, "b": blue}
+
+	img, err := render.Glyphs(base, projections, labels, render.GlyphOptions{Scale: 20, Colors: colors})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reds, blues := countColor(img, image.Rect(0, 0, 20, 20), red), countColor(img, image.Rect(0, 0, 20, 20), blue)
+	if share := float64(reds) / float64(reds+blues); share < 0.2 || share > 0.3 {
+		t.Fatalf("Expected about 1/4 of the pie to be red, got %d red and %d blue pixels", reds, blues)
+	}
+	// the first slice starts at 12 o'clock and goes clockwise
+	if c := img.NRGBAAt(13, 3); c != red {
+		t.Fatalf("Expected the top right quarter to be red, got %v", c)
+	}
+	if c := img.NRGBAAt(6, 3); c != blue {
+		t.Fatalf("Expected the top left quarter to be blue, got %v", c)
+	}
+	if n := countColor(img, image.Rect(20, 0, 40, 20), red) + countColor(img, image.Rect(20, 0, 40, 20), blue); n != 0 {
+		t.Fatal("Expected no glyph for the neuron without samples")
+	}
+}
+
+func TestBarGlyphs(t *testing.T) {
+	base := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	colors := map[string]color.NRGBA{"a": red, "b": blue}
+
+	img, err := render.Glyphs(base, []som.GridPoint{{}, {}}, []string{"b", "a"}, render.GlyphOptions{Scale: 10, Colors: colors, Shape: render.BarGlyph})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c := img.NRGBAAt(5, 8); c != red {
+		t.Fatalf("Expected the first label at the bottom, got %v", c)
+	}
+	if c := img.NRGBAAt(5, 1); c != blue {
+		t.Fatalf("Expected the last label at the top, got %v", c)
+	}
+	if red, blue := countColor(img, img.Bounds(), red), countColor(img, img.Bounds(), blue); red != blue {
+		t.Fatalf("Expected the equal segments, got %d and %d pixels", red, blue)
+	}
+}
+
+func TestGlyphsRejectInvalidInput(t *testing.T) {
+	base := image.NewNRGBA(image.Rect(0, 0, 8, 8))
+
+	if _, err := render.Glyphs(base, []som.GridPoint{{}}, nil, render.GlyphOptions{Scale: 4}); err == nil {
+		t.Fatal("Expected missing labels to be rejected")
+	}
+	if _, err := render.Glyphs(base, []som.GridPoint{{X: 0, Y: 2}}, []string{"a"}, render.GlyphOptions{Scale: 4}); err == nil {
+		t.Fatal("Expected the projection outside of the image to be rejected")
+	}
+}
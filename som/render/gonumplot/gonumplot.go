@@ -0,0 +1,50 @@
+// Package gonumplot provides gonum.org/v1/plot plotters of the maps,
+// so U-matrices, hit maps and component planes compose with other gonum figures:
+//
+//	p := plot.New()
+//	p.Add(gonumplot.UMatrix(model, palette.Heat(12, 1)))
+//	p.Save(4*vg.Inch, 4*vg.Inch, "umatrix.png")
+//
+// The neurons are centered at integer coordinates, the neuron x coordinate
+// goes along the X axis and its y coordinate along the Y axis, see render.Grid.
+package gonumplot
+
+import (
+	"gonum.org/v1/plot/palette"
+	"gonum.org/v1/plot/plotter"
+
+	"github.com/voievodin/self-organizing-map/som"
+	"github.com/voievodin/self-organizing-map/som/render"
+)
+
+// HeatMap returns the heat map of the grid colored by the palette,
+// masked neurons are left blank. The colors span the range of the values,
+// the returned plotter's Min and Max may be set to fix the range, e.g. to
+// compare several maps. The grid of the same values, or of NaNs only,
+// is colored by the middle of the palette.
+func HeatMap(g *render.Grid, p palette.Palette) *plotter.HeatMap {
+	h := plotter.NewHeatMap(g, p)
+	if h.Min > h.Max {
+		h.Min, h.Max = 0, 0
+	}
+	if h.Min == h.Max {
+		h.Min, h.Max = h.Min-1, h.Max+1
+	}
+	return h
+}
+
+// UMatrix returns the heat map of the U-matrix of the map.
+func UMatrix(m *som.Model, p palette.Palette) *plotter.HeatMap {
+	return HeatMap(render.UMatrixGrid(m), p)
+}
+
+// Hits returns the heat map of the hit map, see quality.HitValues.
+func Hits(hitMap [][]int, p palette.Palette) *plotter.HeatMap {
+	return HeatMap(render.HitGrid(hitMap), p)
+}
+
+// ComponentPlane returns the heat map of the component plane of the feature,
+// see quality.ComponentPlane.
+func ComponentPlane(m *som.Model, feature int, p palette.Palette) *plotter.HeatMap {
+	return HeatMap(render.ComponentGrid(m, feature), p)
+}
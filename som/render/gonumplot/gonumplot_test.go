@@ -0,0 +1,103 @@
+package gonumplot_test
+
+import (
+	"image/color"
+	"math"
+	"testing"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/palette"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+	"gonum.org/v1/plot/vg/vgimg"
+
+	"github.com/voievodin/self-organizing-map/som"
+	"github.com/voievodin/self-organizing-map/som/render"
+	"github.com/voievodin/self-organizing-map/som/render/gonumplot"
+)
+
+func testModel(t *testing.T) *som.Model {
+	s := som.New(2, 3)
+	if err := s.LoadCodebook([][][]float64{{{0, 1}, {1, 2}, {2, 3}}, {{3, 4}, {4, 5}, {5, 6}}}); err != nil {
+		t.Fatal(err)
+	}
+	s.Mask = [][]bool{{false, false, false}, {false, false, true}}
+	m, err := s.Model()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+// drawCells draws the plotter filling the whole image
+// and returns the colors at the centers of the cells of the c x r grid.
+func drawCells(t *testing.T, plotter plot.Plotter, c, r int) [][]color.Color {
+	p := plot.New()
+	p.HideAxes()
+	p.Add(plotter)
+	canvas := vgimg.New(vg.Points(float64(c*20)), vg.Points(float64(r*20)))
+	p.Draw(draw.New(canvas))
+	img := canvas.Image()
+
+	bounds := img.Bounds()
+	cells := make([][]color.Color, c)
+	for i := range cells {
+		cells[i] = make([]color.Color, r)
+		for j := range cells[i] {
+			x := bounds.Min.X + (2*i+1)*bounds.Dx()/(2*c)
+			y := bounds.Max.Y - 1 - (2*j+1)*bounds.Dy()/(2*r)
+			cells[i][j] = img.At(x, y)
+		}
+	}
+	return cells
+}
+
+func sameColor(a, b color.Color) bool {
+	r1, g1, b1, a1 := a.RGBA()
+	r2, g2, b2, a2 := b.RGBA()
+	return r1 == r2 && g1 == g2 && b1 == b2 && a1 == a2
+}
+
+func TestComponentPlaneColorsTheWeights(t *testing.T) {
+	pal := palette.Heat(2, 1)
+	cells := drawCells(t, gonumplot.ComponentPlane(testModel(t), 0, pal), 2, 3)
+
+	low, high := pal.Colors()[0], pal.Colors()[1]
+	if !sameColor(cells[0][0], low) || !sameColor(cells[1][1], high) {
+		t.Fatalf("Expected the lowest and the highest weights colored by the palette ends, got %v and %v", cells[0][0], cells[1][1])
+	}
+	if sameColor(cells[1][2], low) || sameColor(cells[1][2], high) {
+		t.Fatalf("Expected the masked neuron to be left blank, got %v", cells[1][2])
+	}
+}
+
+func TestHeatMapOfUniformValues(t *testing.T) {
+	pal := palette.Heat(3, 1)
+	for name, grid := range map[string]*render.Grid{
+		"same": {Values: [][]float64{{2, 2}}},
+		"nans": {Values: [][]float64{{math.NaN(), math.NaN()}}},
+	} {
+		h := gonumplot.HeatMap(grid, pal)
+		if !(h.Min < h.Max) {
+			t.Fatalf("%s: expected non-empty range, got [%v, %v]", name, h.Min, h.Max)
+		}
+		cells := drawCells(t, h, 1, 2)
+		if name == "same" && !sameColor(cells[0][1], pal.Colors()[1]) {
+			t.Fatalf("Expected the middle of the palette, got %v", cells[0][1])
+		}
+	}
+}
+
+func TestHitsAndUMatrix(t *testing.T) {
+	pal := palette.Heat(4, 1)
+	cells := drawCells(t, gonumplot.Hits([][]int{{1, 0, 2}, {0, 3, -1}}, pal), 2, 3)
+	if !sameColor(cells[1][1], pal.Colors()[3]) || !sameColor(cells[0][1], pal.Colors()[0]) {
+		t.Fatalf("Expected the hits colored by the palette, got %v and %v", cells[1][1], cells[0][1])
+	}
+
+	h := gonumplot.UMatrix(testModel(t), pal)
+	if c, r := h.GridXYZ.Dims(); c != 2 || r != 3 {
+		t.Fatalf("Expected 2x3 U-matrix, got %dx%d", c, r)
+	}
+	drawCells(t, h, 2, 3)
+}
@@ -0,0 +1,54 @@
+package render
+
+import (
+	"github.com/voievodin/self-organizing-map/som"
+	"github.com/voievodin/self-organizing-map/som/quality"
+)
+
+// Grid exposes per neuron values as the grid of gonum.org/v1/plot, it
+// implements plotter.GridXYZ, see the gonumplot package for the plotters.
+// The column c is the neuron x coordinate and the row r is its y coordinate,
+// the rows of the plot go upwards, so the image is flipped vertically
+// compared with quality.Render. The package doesn't depend on gonum.
+type Grid struct {
+	Values [][]float64
+}
+
+// UMatrixGrid returns the grid of the U-matrix of the map.
+func UMatrixGrid(m *som.Model) *Grid {
+	return &Grid{Values: m.UMatrix()}
+}
+
+// HitGrid returns the grid of the hit map, see quality.HitValues.
+func HitGrid(hitMap [][]int) *Grid {
+	return &Grid{Values: quality.HitValues(hitMap)}
+}
+
+// ComponentGrid returns the grid of the component plane of the feature, see quality.ComponentPlane.
+func ComponentGrid(m *som.Model, feature int) *Grid {
+	return &Grid{Values: quality.ComponentPlane(m, feature)}
+}
+
+// Dims returns the number of columns and rows of the grid,
+// which are the size of the map grid, or zeros if the grid is empty.
+func (g *Grid) Dims() (c, r int) {
+	if len(g.Values) == 0 {
+		return 0, 0
+	}
+	return len(g.Values), len(g.Values[0])
+}
+
+// Z returns the value of the neuron at (c, r), NaN for masked neurons.
+func (g *Grid) Z(c, r int) float64 {
+	return g.Values[c][r]
+}
+
+// X returns the coordinate of the column c, the centers of the neurons are at integers.
+func (g *Grid) X(c int) float64 {
+	return float64(c)
+}
+
+// Y returns the coordinate of the row r.
+func (g *Grid) Y(r int) float64 {
+	return float64(r)
+}
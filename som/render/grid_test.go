@@ -0,0 +1,59 @@
+package render_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+	"github.com/voievodin/self-organizing-map/som/render"
+)
+
+// gridXYZ is the plotter.GridXYZ interface of gonum.org/v1/plot.
+type gridXYZ interface {
+	Dims() (c, r int)
+	Z(c, r int) float64
+	X(c int) float64
+	Y(r int) float64
+}
+
+var _ gridXYZ = (*render.Grid)(nil)
+
+func TestGrids(t *testing.T) {
+	s := som.New(2, 3)
+	if err := s.LoadCodebook([][][]float64{{{0, 1}, {1, 2}, {2, 3}}, {{3, 4}, {4, 5}, {5, 6}}}); err != nil {
+		t.Fatal(err)
+	}
+	s.Mask = [][]bool{{false, false, false}, {false, false, true}}
+	m, err := s.Model()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, grid := range map[string]*render.Grid{
+		"umatrix":   render.UMatrixGrid(m),
+		"hits":      render.HitGrid([][]int{{1, 0, 2}, {0, 3, -1}}),
+		"component": render.ComponentGrid(m, 1),
+	} {
+		if c, r := grid.Dims(); c != 2 || r != 3 {
+			t.Fatalf("%s: expected 2x3 grid, got %dx%d", name, c, r)
+		}
+		if !math.IsNaN(grid.Z(1, 2)) {
+			t.Fatalf("%s: expected NaN for the masked neuron, got %v", name, grid.Z(1, 2))
+		}
+		if grid.X(1) != 1 || grid.Y(2) != 2 {
+			t.Fatalf("%s: expected the neurons at integer coordinates, got %v, %v", name, grid.X(1), grid.Y(2))
+		}
+	}
+	if z := render.ComponentGrid(m, 1).Z(1, 0); z != 4 {
+		t.Fatalf("Expected the weight of the feature, got %v", z)
+	}
+	if z := render.HitGrid([][]int{{1, 0, 2}, {0, 3, -1}}).Z(1, 1); z != 3 {
+		t.Fatalf("Expected the hits, got %v", z)
+	}
+}
+
+func TestEmptyGridDims(t *testing.T) {
+	if c, r := (&render.Grid{}).Dims(); c != 0 || r != 0 {
+		t.Fatalf("Expected empty grid, got %dx%d", c, r)
+	}
+}
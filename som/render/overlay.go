@@ -0,0 +1,86 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math/rand"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+// OverlayOptions configure Overlay.
+type OverlayOptions struct {
+	// Scale is the size of the square of a neuron in the base image in pixels,
+	// the scale the base image is rendered with, e.g. by quality.Render.
+	Scale int
+
+	// Colors are the colors of the labels, nil means LabelColors of the labels.
+	// The labels missing from the colors are drawn black.
+	Colors map[string]color.NRGBA
+
+	// Radius is the radius of a sample dot in pixels, <= 0 means Scale/8, but at least 1.
+	Radius int
+
+	// Rand jitters the samples, nil means the source seeded by 1,
+	// so the same samples are drawn at the same positions.
+	Rand *rand.Rand
+}
+
+// Overlay draws the samples projected on the map, e.g. by som.Model.MapBatch,
+// on top of the base image, e.g. the U-matrix or a component plane rendered
+// by quality.Render: each sample is a dot colored by its label at a random
+// position within the square of its BMU, so the samples of the same neuron
+// don't hide each other. The labels may be nil, then all the dots are black.
+// The base image is not modified. Returns an error if the numbers of the
+// projections and the labels differ or a projection is outside of the base image.
+func Overlay(base image.Image, projections []som.GridPoint, labels []string, options OverlayOptions) (*image.NRGBA, error) {
+	if labels != nil && len(labels) != len(projections) {
+		return nil, fmt.Errorf("%d labels for %d projections", len(labels), len(projections))
+	}
+	if options.Scale < 1 {
+		return nil, fmt.Errorf("%w: scale must be positive, got %d", som.ErrInvalidConfig, options.Scale)
+	}
+	colors := options.Colors
+	if colors == nil {
+		colors = LabelColors(labels)
+	}
+	radius := options.Radius
+	if radius <= 0 {
+		radius = options.Scale / 8
+	}
+	if radius < 1 {
+		radius = 1
+	}
+	rng := options.Rand
+	if rng == nil {
+		rng = rand.New(rand.NewSource(1))
+	}
+
+	img := copyImage(base)
+	bounds := img.Bounds()
+	for i, p := range projections {
+		cell := image.Rect(p.X*options.Scale, p.Y*options.Scale, (p.X+1)*options.Scale, (p.Y+1)*options.Scale).Add(bounds.Min)
+		if !cell.In(bounds) {
+			return nil, fmt.Errorf("projection %d at (%d, %d) is outside of %v image of scale %d", i, p.X, p.Y, bounds.Size(), options.Scale)
+		}
+		c := color.NRGBA{A: 255}
+		if labels != nil {
+			if labelColor, ok := colors[labels[i]]; ok {
+				c = labelColor
+			}
+		}
+		fillCircle(img, jitter(rng, cell.Min.X, options.Scale, radius), jitter(rng, cell.Min.Y, options.Scale, radius), radius, c)
+	}
+	return img, nil
+}
+
+// jitter returns a random coordinate within the cell starting at from of the
+// given size, which keeps the dot of the radius inside the cell if it fits.
+func jitter(rng *rand.Rand, from, size, radius int) int {
+	free := size - 2*radius
+	if free <= 0 {
+		return from + size/2
+	}
+	return from + radius + rng.Intn(free)
+}
@@ -0,0 +1,73 @@
+package render_test
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+	"github.com/voievodin/self-organizing-map/som/quality"
+	"github.com/voievodin/self-organizing-map/som/render"
+)
+
+func TestOverlay(t *testing.T) {
+	base := quality.Render([][]float64{{0, 1}, {2, 3}}, quality.RenderOptions{Scale: 16, ColorMap: quality.Grays})
+	projections := []som.GridPoint{{X: 0, Y: 0}, {X: 1, Y: 1}, {X: 1, Y: 1}}
+	labels := []string{"setosa", "virginica", "virginica"}
+	colors := map[string]color.NRGBA{"setosa": {255, 0, 0, 255}, "virginica": {0, 0, 255, 255}}
+
+	pix := string(base.Pix)
+	img, err := render.Overlay(base, projections, labels, render.OverlayOptions{Scale: 16, Colors: colors})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if img.Bounds() != base.Bounds() {
+		t.Fatalf("Expected the overlay of the base size, got %v", img.Bounds())
+	}
+	if n := countColor(img, image.Rect(0, 0, 16, 16), colors["setosa"]); n == 0 {
+		t.Fatal("Expected the setosa sample to be drawn in the square of its BMU")
+	}
+	if n := countColor(img, image.Rect(16, 16, 32, 32), colors["virginica"]); n == 0 {
+		t.Fatal("Expected the virginica samples to be drawn in the square of their BMU")
+	}
+	if n := countColor(img, image.Rect(0, 16, 16, 32), colors["virginica"]) + countColor(img, image.Rect(16, 0, 32, 16), colors["setosa"]); n != 0 {
+		t.Fatal("Expected the samples to stay in the squares of their BMUs")
+	}
+	if string(base.Pix) != pix {
+		t.Fatal("Expected the base image not to be modified")
+	}
+
+	again, _ := render.Overlay(base, projections, labels, render.OverlayOptions{Scale: 16, Colors: colors})
+	if string(again.Pix) != string(img.Pix) {
+		t.Fatal("Expected the same overlay for the same samples")
+	}
+}
+
+func TestOverlayRejectsInvalidInput(t *testing.T) {
+	base := image.NewNRGBA(image.Rect(0, 0, 8, 8))
+	projections := []som.GridPoint{{X: 0, Y: 0}}
+
+	if _, err := render.Overlay(base, projections, []string{"a", "b"}, render.OverlayOptions{Scale: 4}); err == nil {
+		t.Fatal("Expected the labels mismatch to be rejected")
+	}
+	if _, err := render.Overlay(base, projections, nil, render.OverlayOptions{}); !errors.Is(err, som.ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig for zero scale, got %v", err)
+	}
+	if _, err := render.Overlay(base, []som.GridPoint{{X: 2, Y: 0}}, nil, render.OverlayOptions{Scale: 4}); err == nil {
+		t.Fatal("Expected the projection outside of the image to be rejected")
+	}
+}
+
+func countColor(img *image.NRGBA, rect image.Rectangle, c color.NRGBA) int {
+	n := 0
+	for x := rect.Min.X; x < rect.Max.X; x++ {
+		for y := rect.Min.Y; y < rect.Max.Y; y++ {
+			if img.NRGBAAt(x, y) == c {
+				n++
+			}
+		}
+	}
+	return n
+}
@@ -0,0 +1,59 @@
+// Package render draws data on top of the per neuron images rendered
+// by the quality package, e.g. the samples projected on the U-matrix.
+package render
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"sort"
+)
+
+// Palette is the categorical palette labels are colored with by LabelColors,
+// its colors are distinguishable from each other and from the color maps
+// of the quality package.
+var Palette = []color.NRGBA{
+	{0x1f, 0x77, 0xb4, 255}, {0xff, 0x7f, 0x0e, 255}, {0x2c, 0xa0, 0x2c, 255},
+	{0xd6, 0x27, 0x28, 255}, {0x94, 0x67, 0xbd, 255}, {0x8c, 0x56, 0x4b, 255},
+	{0xe3, 0x77, 0xc2, 255}, {0x7f, 0x7f, 0x7f, 255}, {0xbc, 0xbd, 0x22, 255},
+	{0x17, 0xbe, 0xcf, 255},
+}
+
+// LabelColors assigns the colors of Palette to the distinct labels
+// in their sorted order, so the same labels get the same colors across images.
+// The colors repeat if there are more labels than colors.
+func LabelColors(labels []string) map[string]color.NRGBA {
+	distinct := make(map[string]bool)
+	for _, label := range labels {
+		distinct[label] = true
+	}
+	sorted := make([]string, 0, len(distinct))
+	for label := range distinct {
+		sorted = append(sorted, label)
+	}
+	sort.Strings(sorted)
+
+	colors := make(map[string]color.NRGBA, len(sorted))
+	for i, label := range sorted {
+		colors[label] = Palette[i%len(Palette)]
+	}
+	return colors
+}
+
+// copyImage returns a copy of the image to draw on.
+func copyImage(base image.Image) *image.NRGBA {
+	img := image.NewNRGBA(base.Bounds())
+	draw.Draw(img, img.Bounds(), base, base.Bounds().Min, draw.Src)
+	return img
+}
+
+// fillCircle fills the circle of radius r centered at (cx, cy).
+func fillCircle(img *image.NRGBA, cx, cy, r int, c color.NRGBA) {
+	for x := cx - r; x <= cx+r; x++ {
+		for y := cy - r; y <= cy+r; y++ {
+			if (x-cx)*(x-cx)+(y-cy)*(y-cy) <= r*r && (image.Point{x, y}).In(img.Rect) {
+				img.SetNRGBA(x, y, c)
+			}
+		}
+	}
+}
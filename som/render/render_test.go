@@ -0,0 +1,18 @@
+package render_test
+
+import (
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som/render"
+)
+
+func TestLabelColors(t *testing.T) {
+	colors := render.LabelColors([]string{"b", "a", "b", "c"})
+
+	if len(colors) != 3 {
+		t.Fatalf("Expected 3 colors, got %v", colors)
+	}
+	if colors["a"] != render.Palette[0] || colors["b"] != render.Palette[1] || colors["c"] != render.Palette[2] {
+		t.Fatalf("Expected the palette colors in the sorted order of labels, got %v", colors)
+	}
+}
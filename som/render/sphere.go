@@ -0,0 +1,46 @@
+package render
+
+import (
+	"image"
+	"math"
+
+	"github.com/voievodin/self-organizing-map/som"
+	"github.com/voievodin/self-organizing-map/som/quality"
+)
+
+// Equirectangular renders the values of the nodes of the sphere, e.g.
+// UMatrix()[0] of the map of the lattice, see som.NewLattice, or a row
+// of quality.ComponentPlane, in the equirectangular projection: the image is
+// width*width/2 pixels, the longitude grows from -180° at the left to 180° at
+// the right and the latitude from -90° at the bottom to 90° at the top, where
+// the z axis points. Each pixel has the color of the value of the node nearest
+// to its point of the sphere by the color map, nil means quality.Viridis.
+// NaN values are transparent.
+func Equirectangular(sphere *som.SphereLattice, values []float64, width int, colors quality.ColorMap) *image.NRGBA {
+	if width < 2 {
+		width = 2
+	}
+	height := width / 2
+	if colors == nil {
+		colors = quality.Viridis
+	}
+	min, max := quality.ValueRange([][]float64{values}, false)
+
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for py := 0; py < height; py++ {
+		lat := math.Pi/2 - (float64(py)+0.5)/float64(height)*math.Pi
+		for px := 0; px < width; px++ {
+			lon := -math.Pi + (float64(px)+0.5)/float64(width)*2*math.Pi
+			v := values[sphere.Nearest([3]float64{math.Cos(lat) * math.Cos(lon), math.Cos(lat) * math.Sin(lon), math.Sin(lat)})]
+			if math.IsNaN(v) {
+				continue
+			}
+			t := 0.5
+			if max > min {
+				t = (v - min) / (max - min)
+			}
+			img.SetNRGBA(px, py, colors.At(t))
+		}
+	}
+	return img
+}
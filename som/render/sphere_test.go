@@ -0,0 +1,33 @@
+package render_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+	"github.com/voievodin/self-organizing-map/som/quality"
+	"github.com/voievodin/self-organizing-map/som/render"
+)
+
+func TestEquirectangular(t *testing.T) {
+	sphere := som.NewSphereLattice(2)
+	// the values are the heights of the nodes, the northern pole is the maximum
+	values := make([]float64, sphere.Len())
+	for i, n := range sphere.Nodes {
+		values[i] = n[2]
+	}
+	values[sphere.Nearest([3]float64{1, 0, 0})] = math.NaN()
+
+	img := render.Equirectangular(sphere, values, 64, quality.Grays)
+
+	if img.Bounds().Dx() != 64 || img.Bounds().Dy() != 32 {
+		t.Fatalf("Expected 64x32 image, got %v", img.Bounds())
+	}
+	if top, bottom := img.NRGBAAt(10, 0), img.NRGBAAt(10, 31); top.R != 255 || bottom.R != 0 {
+		t.Fatalf("Expected the northern pole white and the southern one black, got %v and %v", top, bottom)
+	}
+	// longitude 0 is in the middle of the image at the equator
+	if c := img.NRGBAAt(32, 16); c.A != 0 {
+		t.Fatalf("Expected NaN to be transparent, got %v", c)
+	}
+}
@@ -0,0 +1,147 @@
+package som
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ErrNoSnapshot is returned when a named snapshot doesn't exist.
+var ErrNoSnapshot = errors.New("no such snapshot")
+
+// TakeSnapshot takes the snapshot of the map codebook named, e.g.,
+// "before Black Friday", so learning can be rolled back to it when it goes
+// wrong, see Rollback. A snapshot of the same name is replaced. If SnapshotDir
+// is set, the snapshot is also saved there by SaveBinary, so it survives restarts.
+// Returns ErrNotTrained while the map is warming up.
+func (t *OnlineTrainer) TakeSnapshot(name string) error {
+	if name == "" {
+		return fmt.Errorf("%w: snapshot name is empty", ErrInvalidConfig)
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	model, err := t.SOM.Model()
+	if err != nil {
+		return err
+	}
+	if t.SnapshotDir != "" {
+		if err := saveSnapshot(t.snapshotPath(name), model); err != nil {
+			return err
+		}
+	}
+	if t.snapshots == nil {
+		t.snapshots = make(map[string]*Model)
+	}
+	t.snapshots[name] = model
+	t.SOM.log(LogInfo, "snapshot taken", "name", name, "iteration", t.it)
+	return nil
+}
+
+// Rollback replaces the map codebook with the named snapshot, which is loaded
+// from SnapshotDir if it's not taken since the trainer was created, and
+// resets the learning components of the map, e.g. the guard.
+// The schedules of the learning parameters go on, the mask is kept.
+// Returns ErrNoSnapshot if there's no such snapshot.
+func (t *OnlineTrainer) Rollback(name string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	snapshot, ok := t.snapshots[name]
+	if !ok && t.SnapshotDir != "" {
+		f, err := os.Open(t.snapshotPath(name))
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("%w: %q", ErrNoSnapshot, name)
+		}
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		loaded, err := LoadBinary(f)
+		if err != nil {
+			return fmt.Errorf("snapshot %q: %w", name, err)
+		}
+		if snapshot, err = loaded.Model(); err != nil {
+			return err
+		}
+		ok = true
+	}
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrNoSnapshot, name)
+	}
+	if err := t.SOM.LoadCodebook(snapshot.Codebook()); err != nil {
+		return fmt.Errorf("snapshot %q: %w", name, err)
+	}
+	t.started = true
+	t.warmup = nil
+	if err := t.SOM.startLearning(t.it); err != nil {
+		return err
+	}
+	if t.SOM.Snapshots != nil {
+		t.SOM.Snapshots.publish(t.SOM)
+	}
+	t.SOM.log(LogWarn, "rolled back to snapshot", "name", name, "iteration", t.it)
+	return nil
+}
+
+// Snapshots returns the names of the snapshots in memory and
+// in SnapshotDir in ascending order.
+func (t *OnlineTrainer) Snapshots() ([]string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	names := make(map[string]bool)
+	for name := range t.snapshots {
+		names[name] = true
+	}
+	if t.SnapshotDir != "" {
+		files, err := filepath.Glob(filepath.Join(t.SnapshotDir, "*"+snapshotExt))
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			if name, err := url.PathUnescape(strings.TrimSuffix(filepath.Base(file), snapshotExt)); err == nil {
+				names[name] = true
+			}
+		}
+	}
+	result := make([]string, 0, len(names))
+	for name := range names {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// snapshotExt is the extension of the snapshot files in SnapshotDir.
+const snapshotExt = ".somb"
+
+// snapshotPath returns the file of the named snapshot,
+// the name is escaped, so any name makes a valid file name.
+func (t *OnlineTrainer) snapshotPath(name string) string {
+	return filepath.Join(t.SnapshotDir, url.PathEscape(name)+snapshotExt)
+}
+
+// saveSnapshot writes the model to the file atomically.
+func saveSnapshot(path string, model *Model) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	err = model.SaveBinary(f, Precision{})
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+	}
+	return err
+}
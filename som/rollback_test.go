@@ -0,0 +1,78 @@
+package som_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+func onlineLineTrainer(t *testing.T, dir string) *som.OnlineTrainer {
+	sm := som.New(1, 2)
+	if err := sm.LoadCodebook([][][]float64{{{0}, {10}}}); err != nil {
+		t.Fatal(err)
+	}
+	return &som.OnlineTrainer{SOM: sm, SnapshotDir: dir}
+}
+
+func TestOnlineTrainerRollsBackToSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	trainer := onlineLineTrainer(t, dir)
+	if err := trainer.TakeSnapshot("before Black Friday"); err != nil {
+		t.Fatal(err)
+	}
+	trainer.Learn(som.DataVector{3})
+	checkSlicesEqual(t, []float64{3}, trainer.SOM.Neurons[0][0].Weights)
+
+	if err := trainer.Rollback("before Black Friday"); err != nil {
+		t.Fatal(err)
+	}
+	checkSlicesEqual(t, []float64{0}, trainer.SOM.Neurons[0][0].Weights)
+	assertEq(t, 1, trainer.Iterations())
+
+	if err := trainer.Rollback("after Black Friday"); !errors.Is(err, som.ErrNoSnapshot) {
+		t.Fatalf("Expected ErrNoSnapshot, got %v", err)
+	}
+
+	// a new trainer finds the persisted snapshot
+	restarted := onlineLineTrainer(t, dir)
+	restarted.Learn(som.DataVector{3})
+	names, err := restarted.Snapshots()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 1 || names[0] != "before Black Friday" {
+		t.Fatalf("Unexpected snapshots %q", names)
+	}
+	if err := restarted.Rollback("before Black Friday"); err != nil {
+		t.Fatal(err)
+	}
+	checkSlicesEqual(t, []float64{0}, restarted.SOM.Neurons[0][0].Weights)
+}
+
+func TestOnlineTrainerKeepsSnapshotsInMemory(t *testing.T) {
+	trainer := onlineLineTrainer(t, "")
+	if err := trainer.TakeSnapshot(""); !errors.Is(err, som.ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig, got %v", err)
+	}
+	if err := trainer.TakeSnapshot("a"); err != nil {
+		t.Fatal(err)
+	}
+	trainer.Learn(som.DataVector{9})
+	if err := trainer.TakeSnapshot("b"); err != nil {
+		t.Fatal(err)
+	}
+	names, _ := trainer.Snapshots()
+	if len(names) != 2 || names[0] != "a" || names[1] != "b" {
+		t.Fatalf("Unexpected snapshots %q", names)
+	}
+	if err := trainer.Rollback("a"); err != nil {
+		t.Fatal(err)
+	}
+	checkSlicesEqual(t, []float64{10}, trainer.SOM.Neurons[0][1].Weights)
+
+	untrained := &som.OnlineTrainer{SOM: som.New(1, 2)}
+	if err := untrained.TakeSnapshot("a"); !errors.Is(err, som.ErrNotTrained) {
+		t.Fatalf("Expected ErrNotTrained, got %v", err)
+	}
+}
@@ -0,0 +1,219 @@
+package som
+
+import (
+	"fmt"
+	"math"
+	"sync"
+)
+
+// ScalingMethod is the method RunningScalingDataAdapter scales the features by.
+type ScalingMethod int
+
+const (
+	// MinMaxScaling scales the features into [0, 1] by their running minimum and maximum.
+	MinMaxScaling ScalingMethod = iota
+
+	// StandardScaling standardizes the features by their running mean
+	// and standard deviation, computed by the Welford's algorithm.
+	StandardScaling
+)
+
+// RunningScalingDataAdapter scales the features of the streamed vectors by
+// the statistics it updates as the vectors arrive, so the map can learn
+// online, see OnlineTrainer, without knowing the ranges of the features
+// a priori: each adapted vector updates the statistics first and then is
+// scaled by them. The statistics of the early vectors are rough, so they
+// stop updating after FreezeAfter vectors or once Freeze is called, which
+// keeps the mapping stable while the map converges. The features of zero
+// range or deviation are scaled to 0.5 by MinMaxScaling and to 0 by StandardScaling.
+// Model snapshots the adapter as frozen, so inference never updates the statistics.
+// Note that the original vector is modified. RunningScalingDataAdapter is safe for concurrent use.
+type RunningScalingDataAdapter struct {
+	Method ScalingMethod
+
+	// FreezeAfter is the number of vectors after which the
+	// statistics stop updating, <= 0 means they never do.
+	FreezeAfter int
+
+	mu       sync.Mutex
+	n        int
+	frozen   bool
+	min, max []float64
+	mean, m2 []float64
+}
+
+// Adapt updates the statistics with the vector, unless they are frozen,
+// and scales the vector. Panics with ErrWidthMismatch if the vector
+// length differs from the length of the previous vectors.
+func (adapter *RunningScalingDataAdapter) Adapt(vector []float64) []float64 {
+	adapter.mu.Lock()
+	defer adapter.mu.Unlock()
+	if adapter.n > 0 && len(vector) != len(adapter.min) {
+		panic(fmt.Errorf("%w: vector length is %d, scaled vectors length is %d", ErrWidthMismatch, len(vector), len(adapter.min)))
+	}
+	if !adapter.frozen {
+		adapter.observe(vector)
+	}
+	if adapter.n == 0 {
+		return vector
+	}
+	for k, v := range vector {
+		if offset, scale := adapter.transform(k); scale == 0 {
+			vector[k] = offset
+		} else {
+			vector[k] = (v - offset) * scale
+		}
+	}
+	return vector
+}
+
+// Inverse scales the vector back by the current statistics.
+// Note that the original vector is modified.
+func (adapter *RunningScalingDataAdapter) Inverse(vector []float64) []float64 {
+	adapter.mu.Lock()
+	defer adapter.mu.Unlock()
+	if adapter.n == 0 {
+		return vector
+	}
+	for k, v := range vector {
+		offset, scale := adapter.transform(k)
+		if scale == 0 {
+			vector[k] = adapter.constant(k)
+		} else {
+			vector[k] = v/scale + offset
+		}
+	}
+	return vector
+}
+
+// transform returns the offset and the scale of the k-th feature, the feature
+// value v is scaled to (v - offset) * scale. The zero scale means the constant
+// feature, which is scaled to the offset.
+func (adapter *RunningScalingDataAdapter) transform(k int) (offset, scale float64) {
+	if adapter.Method == StandardScaling {
+		std := math.Sqrt(adapter.m2[k] / float64(adapter.n))
+		if std == 0 {
+			return 0, 0
+		}
+		return adapter.mean[k], 1 / std
+	}
+	if adapter.max[k] == adapter.min[k] {
+		return 0.5, 0
+	}
+	return adapter.min[k], 1 / (adapter.max[k] - adapter.min[k])
+}
+
+// constant returns the value of the constant feature.
+func (adapter *RunningScalingDataAdapter) constant(k int) float64 {
+	if adapter.Method == StandardScaling {
+		return adapter.mean[k]
+	}
+	return adapter.min[k]
+}
+
+func (adapter *RunningScalingDataAdapter) observe(vector []float64) {
+	if adapter.n == 0 {
+		width := len(vector)
+		adapter.min = append([]float64(nil), vector...)
+		adapter.max = append([]float64(nil), vector...)
+		adapter.mean = make([]float64, width)
+		adapter.m2 = make([]float64, width)
+	}
+	adapter.n++
+	for k, v := range vector {
+		adapter.min[k] = math.Min(adapter.min[k], v)
+		adapter.max[k] = math.Max(adapter.max[k], v)
+		delta := v - adapter.mean[k]
+		adapter.mean[k] += delta / float64(adapter.n)
+		adapter.m2[k] += delta * (v - adapter.mean[k])
+	}
+	if adapter.FreezeAfter > 0 && adapter.n >= adapter.FreezeAfter {
+		adapter.frozen = true
+	}
+}
+
+// Describe returns the spec of the adapter with its current statistics.
+func (adapter *RunningScalingDataAdapter) Describe() ComponentSpec {
+	adapter.mu.Lock()
+	defer adapter.mu.Unlock()
+	frozen := 0.0
+	if adapter.frozen {
+		frozen = 1
+	}
+	return ComponentSpec{
+		Type: "running-scaling",
+		Params: Params{
+			"method":       float64(adapter.Method),
+			"freeze_after": float64(adapter.FreezeAfter),
+			"count":        float64(adapter.n),
+			"frozen":       frozen,
+		},
+		Values: map[string][]float64{
+			"min":  append([]float64(nil), adapter.min...),
+			"max":  append([]float64(nil), adapter.max...),
+			"mean": append([]float64(nil), adapter.mean...),
+			"m2":   append([]float64(nil), adapter.m2...),
+		},
+	}
+}
+
+// newRunningScalingDataAdapter recreates the described adapter, see Describe.
+func newRunningScalingDataAdapter(params Params, values map[string][]float64) (DataAdapter, error) {
+	adapter := &RunningScalingDataAdapter{
+		Method:      ScalingMethod(params.Get("method", 0)),
+		FreezeAfter: int(params.Get("freeze_after", 0)),
+		n:           int(params.Get("count", 0)),
+		frozen:      params.Get("frozen", 0) != 0,
+		min:         values["min"],
+		max:         values["max"],
+		mean:        values["mean"],
+		m2:          values["m2"],
+	}
+	if adapter.Method != MinMaxScaling && adapter.Method != StandardScaling {
+		return nil, fmt.Errorf("%w: unknown scaling method %d", ErrInvalidConfig, adapter.Method)
+	}
+	width := len(adapter.min)
+	if adapter.n < 0 || (adapter.n > 0) != (width > 0) ||
+		len(adapter.max) != width || len(adapter.mean) != width || len(adapter.m2) != width {
+		return nil, fmt.Errorf("%w: running scaling statistics don't match %d vectors", ErrInvalidConfig, adapter.n)
+	}
+	return adapter, params.Check("method", "freeze_after", "count", "frozen")
+}
+
+// Freeze stops updating the statistics.
+func (adapter *RunningScalingDataAdapter) Freeze() {
+	adapter.mu.Lock()
+	defer adapter.mu.Unlock()
+	adapter.frozen = true
+}
+
+// IsFrozen returns true if the statistics don't update anymore.
+func (adapter *RunningScalingDataAdapter) IsFrozen() bool {
+	adapter.mu.Lock()
+	defer adapter.mu.Unlock()
+	return adapter.frozen
+}
+
+// Count returns the number of vectors the statistics are computed from.
+func (adapter *RunningScalingDataAdapter) Count() int {
+	adapter.mu.Lock()
+	defer adapter.mu.Unlock()
+	return adapter.n
+}
+
+// Frozen returns the frozen copy of this adapter, which scales
+// the vectors by the current statistics.
+func (adapter *RunningScalingDataAdapter) Frozen() *RunningScalingDataAdapter {
+	adapter.mu.Lock()
+	defer adapter.mu.Unlock()
+	return &RunningScalingDataAdapter{
+		Method:      adapter.Method,
+		FreezeAfter: adapter.FreezeAfter,
+		n:           adapter.n,
+		frozen:      true,
+		min:         append([]float64(nil), adapter.min...),
+		max:         append([]float64(nil), adapter.max...),
+		mean:        append([]float64(nil), adapter.mean...),
+		m2:          append([]float64(nil), adapter.m2...),
+	}
+}
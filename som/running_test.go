@@ -0,0 +1,78 @@
+package som_test
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+func TestRunningMinMaxScaling(t *testing.T) {
+	adapter := &som.RunningScalingDataAdapter{}
+
+	checkSlicesEqual(t, []float64{0.5, 0.5}, adapter.Adapt([]float64{10, 5}))
+	checkSlicesEqual(t, []float64{1, 0}, adapter.Adapt([]float64{20, 1}))
+	checkSlicesEqual(t, []float64{0.5, 0.75}, adapter.Adapt([]float64{15, 4}))
+	checkSlicesEqual(t, []float64{15, 4}, adapter.Inverse([]float64{0.5, 0.75}))
+	if adapter.Count() != 3 {
+		t.Fatalf("Expected 3 observed vectors, got %d", adapter.Count())
+	}
+}
+
+func TestRunningStandardScaling(t *testing.T) {
+	adapter := &som.RunningScalingDataAdapter{Method: som.StandardScaling}
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 10000; i++ {
+		adapter.Adapt([]float64{100 + 5*rng.NormFloat64()})
+	}
+	adapter.Freeze()
+
+	scaled := adapter.Adapt([]float64{110})
+	if math.Abs(scaled[0]-2) > 0.1 {
+		t.Fatalf("Expected 2 standard deviations above the mean, got %v", scaled[0])
+	}
+	if v := adapter.Inverse(scaled)[0]; math.Abs(v-110) > 1e-9 {
+		t.Fatalf("Expected the inverse to restore 110, got %v", v)
+	}
+}
+
+func TestRunningScalingFreezes(t *testing.T) {
+	adapter := &som.RunningScalingDataAdapter{FreezeAfter: 2}
+	adapter.Adapt([]float64{0})
+	adapter.Adapt([]float64{10})
+
+	if !adapter.IsFrozen() {
+		t.Fatal("Expected the statistics to freeze after 2 vectors")
+	}
+	checkSlicesEqual(t, []float64{2}, adapter.Adapt([]float64{20}))
+	if adapter.Count() != 2 {
+		t.Fatalf("Expected frozen statistics not to update, got %d vectors", adapter.Count())
+	}
+}
+
+func TestRunningScalingIsFrozenInModel(t *testing.T) {
+	adapter := &som.RunningScalingDataAdapter{}
+	trainer := onlineLineTrainer(t, "")
+	trainer.SOM.InDataAdapter = adapter
+	for _, v := range []float64{0, 10, 5} {
+		if err := trainer.Learn(som.DataVector{v}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	model, err := trainer.SOM.Model()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	model.BMU(som.DataVector{1000})
+	if adapter.Count() != 3 {
+		t.Fatalf("Expected inference not to update the statistics, got %d vectors", adapter.Count())
+	}
+
+	// the adapter panics, which fails the iteration
+	if err := trainer.Learn(som.DataVector{1, 2}); !errors.Is(err, som.ErrTrainingPanic) {
+		t.Fatalf("Expected ErrTrainingPanic, got %v", err)
+	}
+}
@@ -0,0 +1,454 @@
+package som
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+)
+
+// ErrBadModel is returned when a saved model can't be loaded.
+var ErrBadModel = errors.New("malformed model")
+
+// Precision controls how weights are written by SaveJSON, SaveBinary
+// and the codebook exporters. The zero value means full precision.
+type Precision struct {
+	// Digits is the number of significant digits of weights written
+	// by text formats (JSON, CSV). Values <= 0 mean the shortest
+	// representation which reads back exactly.
+	Digits int
+
+	// Half packs weights of the binary format as IEEE 754 half precision
+	// floats, which takes 4 times less space, but keeps only about
+	// 3 significant digits and the range of ±65504.
+	Half bool
+}
+
+func (p Precision) format(v float64) string {
+	if p.Digits <= 0 {
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	return strconv.FormatFloat(v, 'g', p.Digits, 64)
+}
+
+type jsonModel struct {
+	X       int               `json:"x"`
+	Y       int               `json:"y"`
+	Weights [][][]json.Number `json:"weights"`
+	Mask    [][]bool          `json:"mask,omitempty"`
+	savedComponents
+}
+
+// savedComponents are the components which define how the saved map maps
+// the vectors, described by the names of their registered factories,
+// see Describer. The nil ones are the defaults set by New.
+type savedComponents struct {
+	Topology *ComponentSpec `json:"topology,omitempty"`
+	Distance *ComponentSpec `json:"distance,omitempty"`
+	Adapter  *ComponentSpec `json:"adapter,omitempty"`
+}
+
+// describeComponents describes the topology, the distance function and the
+// input adapter of this SOM. Returns ErrInvalidConfig if a component doesn't
+// implement Describer or describes itself without the type, as it can't be
+// saved, unless SkipUndescribed is set, then such a component is skipped
+// with a warning.
+func (som *SOM) describeComponents() (components savedComponents, err error) {
+	if components.Topology, err = som.describeComponent(topologyKind, som.Topology, "planar"); err != nil {
+		return components, err
+	}
+	if components.Distance, err = som.describeComponent(distanceKind, som.Distance, "euclidean"); err != nil {
+		return components, err
+	}
+	components.Adapter, err = som.describeComponent(adapterKind, som.InDataAdapter, "no-op")
+	return components, err
+}
+
+// describeComponent returns the spec of the component, nil if it's the default one
+// or it can't be described and SkipUndescribed is set.
+func (som *SOM) describeComponent(kind string, component interface{}, def string) (*ComponentSpec, error) {
+	if component == nil {
+		return nil, nil
+	}
+	var spec ComponentSpec
+	describer, ok := component.(Describer)
+	if ok {
+		spec = describer.Describe()
+	}
+	if !ok || spec.Type == "" {
+		if !som.SkipUndescribed {
+			return nil, fmt.Errorf("%w: %s %T can't be saved, it can't be described", ErrInvalidConfig, kind, component)
+		}
+		som.log(LogWarn, "component is not saved, it can't be described", "kind", kind, "type", fmt.Sprintf("%T", component))
+		return nil, nil
+	}
+	if spec.Type == def && len(spec.Params) == 0 && len(spec.Values) == 0 {
+		return nil, nil
+	}
+	return &spec, nil
+}
+
+// empty returns true if all the components are the defaults.
+func (c savedComponents) empty() bool {
+	return c.Topology == nil && c.Distance == nil && c.Adapter == nil
+}
+
+// apply recreates the saved components of the loaded map.
+func (c savedComponents) apply(som *SOM) (err error) {
+	if c.Topology != nil {
+		if som.Topology, err = NewTopology(c.Topology.Type, c.Topology.Params, c.Topology.Values); err != nil {
+			return fmt.Errorf("%w: %v", ErrBadModel, err)
+		}
+	}
+	if c.Distance != nil {
+		if som.Distance, err = NewDistance(c.Distance.Type, c.Distance.Params); err != nil {
+			return fmt.Errorf("%w: %v", ErrBadModel, err)
+		}
+	}
+	if c.Adapter != nil {
+		if som.InDataAdapter, err = NewAdapter(c.Adapter.Type, c.Adapter.Params, c.Adapter.Values); err != nil {
+			return fmt.Errorf("%w: %v", ErrBadModel, err)
+		}
+	}
+	return nil
+}
+
+// SaveJSON writes neurons weights and the mask of this SOM in JSON format,
+// so the map can be restored by LoadJSON. The topology, the distance function
+// and the input adapter are saved by the names they are registered with, see
+// Describer. Returns ErrInvalidConfig if one of them can't be described,
+// unless SkipUndescribed is set, see SOM.SkipUndescribed.
+func (som *SOM) SaveJSON(w io.Writer, precision Precision) error {
+	if err := som.checkTrained(); err != nil {
+		return err
+	}
+	components, err := som.describeComponents()
+	if err != nil {
+		return err
+	}
+	model := jsonModel{X: len(som.Neurons), Y: len(som.Neurons[0]), Mask: som.Mask, savedComponents: components}
+	model.Weights = make([][][]json.Number, model.X)
+	for i := range model.Weights {
+		model.Weights[i] = make([][]json.Number, model.Y)
+		for j := range model.Weights[i] {
+			weights := som.Neurons[i][j].Weights
+			model.Weights[i][j] = make([]json.Number, len(weights))
+			for k, v := range weights {
+				if math.IsNaN(v) || math.IsInf(v, 0) {
+					return fmt.Errorf("%w: neuron (%d, %d) weight %d is %f", ErrNonFinite, i, j, k, v)
+				}
+				model.Weights[i][j][k] = json.Number(precision.format(v))
+			}
+		}
+	}
+	return json.NewEncoder(w).Encode(model)
+}
+
+// LoadJSON reads a map saved by SaveJSON. The loaded map has the saved
+// topology, distance function and input adapter, and the other components
+// as created by New, except Initializer which is set to the loaded weights,
+// so learning continues from them. Returns ErrBadModel if a saved component
+// is not registered, e.g. a custom one registered by another program.
+func LoadJSON(r io.Reader) (*SOM, error) {
+	var model struct {
+		X       int           `json:"x"`
+		Y       int           `json:"y"`
+		Weights [][][]float64 `json:"weights"`
+		Mask    [][]bool      `json:"mask"`
+		savedComponents
+	}
+	if err := json.NewDecoder(r).Decode(&model); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBadModel, err)
+	}
+	if model.X <= 0 || model.Y <= 0 || len(model.Weights) != model.X {
+		return nil, fmt.Errorf("%w: weights don't match %dx%d map", ErrBadModel, model.X, model.Y)
+	}
+	width := -1
+	for i := range model.Weights {
+		if len(model.Weights[i]) != model.Y {
+			return nil, fmt.Errorf("%w: weights don't match %dx%d map", ErrBadModel, model.X, model.Y)
+		}
+		for j := range model.Weights[i] {
+			if width == -1 {
+				width = len(model.Weights[i][j])
+			}
+			if len(model.Weights[i][j]) != width || width == 0 {
+				return nil, fmt.Errorf("%w: neuron (%d, %d) has %d weights", ErrBadModel, i, j, len(model.Weights[i][j]))
+			}
+		}
+	}
+	if model.Mask != nil && !fitsGrid(model.Mask, model.X, model.Y) {
+		return nil, fmt.Errorf("%w: mask doesn't match %dx%d map", ErrBadModel, model.X, model.Y)
+	}
+	som := loadedSOM(model.Weights, model.Mask)
+	if err := model.savedComponents.apply(som); err != nil {
+		return nil, err
+	}
+	return som, nil
+}
+
+// binaryModelMagic starts the files written by SaveBinary.
+var binaryModelMagic = [4]byte{'S', 'O', 'M', 'B'}
+
+const (
+	binaryModelVersion = 1
+
+	// binaryModelComponentsVersion is the version of the files
+	// of the maps whose components are not the defaults.
+	binaryModelComponentsVersion = 2
+
+	// maxBinaryModelComponents limits the length of the components JSON.
+	maxBinaryModelComponents = 1 << 24
+
+	binaryFlagHalf = 1 << 0
+	binaryFlagMask = 1 << 1
+
+	// maxBinaryModelValues limits the number of weights
+	// of a loaded model, so a corrupted header doesn't
+	// cause a huge allocation.
+	maxBinaryModelValues = 1 << 30
+)
+
+// SaveBinary writes neurons weights and the mask of this SOM
+// in a compact little-endian binary format, so the map can be restored by LoadBinary.
+// The format is:
+//
+//	magic "SOMB", version (uint8), flags (uint8), x, y, width (uint32),
+//	weights (float64 or float16 if Precision.Half is set) neuron by neuron,
+//	mask (one byte per neuron) if the map is masked,
+//	components JSON length (uint32) and the JSON, if the version is 2.
+//
+// The version is 1 unless the topology, the distance function or the input
+// adapter is not the default one, then they are written as the components JSON,
+// see SaveJSON, which also describes the errors. Precision.Digits is ignored.
+func (som *SOM) SaveBinary(w io.Writer, precision Precision) error {
+	if err := som.checkTrained(); err != nil {
+		return err
+	}
+	components, err := som.describeComponents()
+	if err != nil {
+		return err
+	}
+	version := byte(binaryModelVersion)
+	var componentsJSON []byte
+	if !components.empty() {
+		version = binaryModelComponentsVersion
+		if componentsJSON, err = json.Marshal(components); err != nil {
+			return err
+		}
+	}
+	x, y, width := len(som.Neurons), len(som.Neurons[0]), len(som.Neurons[0][0].Weights)
+	var flags byte
+	if precision.Half {
+		flags |= binaryFlagHalf
+	}
+	if som.Mask != nil {
+		flags |= binaryFlagMask
+	}
+
+	bw := bufio.NewWriter(w)
+	bw.Write(binaryModelMagic[:])
+	bw.WriteByte(version)
+	bw.WriteByte(flags)
+	var buf [8]byte
+	for _, v := range []int{x, y, width} {
+		binary.LittleEndian.PutUint32(buf[:], uint32(v))
+		bw.Write(buf[:4])
+	}
+	for i := 0; i < x; i++ {
+		for j := 0; j < y; j++ {
+			for _, v := range som.Neurons[i][j].Weights {
+				if precision.Half {
+					binary.LittleEndian.PutUint16(buf[:], float64ToFloat16(v))
+					bw.Write(buf[:2])
+				} else {
+					binary.LittleEndian.PutUint64(buf[:], math.Float64bits(v))
+					bw.Write(buf[:])
+				}
+			}
+		}
+	}
+	if som.Mask != nil {
+		for i := 0; i < x; i++ {
+			for j := 0; j < y; j++ {
+				if som.Mask[i][j] {
+					bw.WriteByte(1)
+				} else {
+					bw.WriteByte(0)
+				}
+			}
+		}
+	}
+	if componentsJSON != nil {
+		binary.LittleEndian.PutUint32(buf[:], uint32(len(componentsJSON)))
+		bw.Write(buf[:4])
+		bw.Write(componentsJSON)
+	}
+	return bw.Flush()
+}
+
+// LoadBinary reads a map saved by SaveBinary of either version, see LoadJSON.
+func LoadBinary(r io.Reader) (*SOM, error) {
+	br := bufio.NewReader(r)
+	header := make([]byte, 18)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBadModel, err)
+	}
+	if [4]byte(header[:4]) != binaryModelMagic {
+		return nil, fmt.Errorf("%w: bad magic %q", ErrBadModel, header[:4])
+	}
+	version := header[4]
+	if version != binaryModelVersion && version != binaryModelComponentsVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrBadModel, header[4])
+	}
+	flags := header[5]
+	x := int(binary.LittleEndian.Uint32(header[6:]))
+	y := int(binary.LittleEndian.Uint32(header[10:]))
+	width := int(binary.LittleEndian.Uint32(header[14:]))
+	if x <= 0 || y <= 0 || width <= 0 || int64(x)*int64(y)*int64(width) > maxBinaryModelValues {
+		return nil, fmt.Errorf("%w: bad dimensions %dx%dx%d", ErrBadModel, x, y, width)
+	}
+
+	valueSize := 8
+	if flags&binaryFlagHalf != 0 {
+		valueSize = 2
+	}
+	// the weights are allocated as they are read, so a header
+	// of a truncated or forged file doesn't cause a huge allocation
+	var buf [8]byte
+	capacity := width
+	if capacity > 1024 {
+		capacity = 1024
+	}
+	weights := make([][][]float64, 0)
+	for i := 0; i < x; i++ {
+		weights = append(weights, make([][]float64, 0))
+		for j := 0; j < y; j++ {
+			neuron := make([]float64, 0, capacity)
+			for k := 0; k < width; k++ {
+				if _, err := io.ReadFull(br, buf[:valueSize]); err != nil {
+					return nil, fmt.Errorf("%w: reading neuron (%d, %d): %v", ErrBadModel, i, j, err)
+				}
+				if valueSize == 2 {
+					neuron = append(neuron, float16ToFloat64(binary.LittleEndian.Uint16(buf[:])))
+				} else {
+					neuron = append(neuron, math.Float64frombits(binary.LittleEndian.Uint64(buf[:])))
+				}
+			}
+			weights[i] = append(weights[i], neuron)
+		}
+	}
+
+	var mask [][]bool
+	if flags&binaryFlagMask != 0 {
+		mask = make([][]bool, x)
+		row := make([]byte, y)
+		for i := range mask {
+			if _, err := io.ReadFull(br, row); err != nil {
+				return nil, fmt.Errorf("%w: reading mask: %v", ErrBadModel, err)
+			}
+			mask[i] = make([]bool, y)
+			for j, b := range row {
+				mask[i][j] = b != 0
+			}
+		}
+	}
+
+	som := loadedSOM(weights, mask)
+	if version == binaryModelComponentsVersion {
+		components, err := readBinaryComponents(br)
+		if err != nil {
+			return nil, err
+		}
+		if err := components.apply(som); err != nil {
+			return nil, err
+		}
+	}
+	return som, nil
+}
+
+// readBinaryComponents reads the components JSON written by SaveBinary.
+func readBinaryComponents(r io.Reader) (components savedComponents, err error) {
+	var buf [4]byte
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		return components, fmt.Errorf("%w: reading components: %v", ErrBadModel, err)
+	}
+	length := binary.LittleEndian.Uint32(buf[:])
+	if length > maxBinaryModelComponents {
+		return components, fmt.Errorf("%w: components length %d is too big", ErrBadModel, length)
+	}
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return components, fmt.Errorf("%w: reading components: %v", ErrBadModel, err)
+	}
+	if err := json.Unmarshal(data, &components); err != nil {
+		return components, fmt.Errorf("%w: components: %v", ErrBadModel, err)
+	}
+	return components, nil
+}
+
+func loadedSOM(weights [][][]float64, mask [][]bool) *SOM {
+	som := New(len(weights), len(weights[0]))
+	for i := range weights {
+		for j := range weights[i] {
+			som.Neurons[i][j].Weights = weights[i][j]
+		}
+	}
+	som.Initializer = &ProvidedWeightsInitializer{Weights: weights}
+	som.Mask = mask
+	return som
+}
+
+func fitsGrid(grid [][]bool, x, y int) bool {
+	if len(grid) != x {
+		return false
+	}
+	for _, row := range grid {
+		if len(row) != y {
+			return false
+		}
+	}
+	return true
+}
+
+// float64ToFloat16 converts the value to IEEE 754 half precision,
+// rounding to the nearest even, out of range values become infinities.
+func float64ToFloat16(v float64) uint16 {
+	f := math.Float32bits(float32(v))
+	sign := uint16(f>>16) & 0x8000
+	exp := int(f>>23&0xFF) - 127 + 15
+	mant := f & 0x7FFFFF
+
+	switch {
+	case f>>23&0xFF == 0xFF:
+		if mant != 0 {
+			return sign | 0x7E00
+		}
+		return sign | 0x7C00
+	case exp >= 0x1F:
+		return sign | 0x7C00
+	case exp <= 0:
+		if exp < -10 {
+			return sign
+		}
+		mant |= 0x800000
+		shift := uint(14 - exp)
+		half := mant >> shift
+		rem, halfway := mant&(1<<shift-1), uint32(1)<<(shift-1)
+		if rem > halfway || (rem == halfway && half&1 == 1) {
+			half++
+		}
+		return sign | uint16(half)
+	default:
+		half := uint32(exp)<<10 | mant>>13
+		rem := mant & 0x1FFF
+		if rem > 0x1000 || (rem == 0x1000 && half&1 == 1) {
+			half++
+		}
+		return sign | uint16(half)
+	}
+}
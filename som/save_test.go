@@ -0,0 +1,293 @@
+package som_test
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+func savableSOM() *som.SOM {
+	sm := som.New(2, 2)
+	sm.Initializer = &som.ProvidedWeightsInitializer{
+		Weights: [][][]float64{
+			{{0.1, 1.0 / 3}, {-2, 65000}},
+			{{1e-7, 0}, {0.5, -0.25}},
+		},
+	}
+	sm.Learn(&som.DataSet{Vectors: []som.DataVector{{}}}, 0)
+	sm.Mask = [][]bool{{false, true}, {false, false}}
+	return sm
+}
+
+func TestSaveLoadJSONRoundTrip(t *testing.T) {
+	sm := savableSOM()
+
+	buf := &bytes.Buffer{}
+	if err := sm.SaveJSON(buf, som.Precision{}); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := som.LoadJSON(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := range sm.Neurons {
+		for j := range sm.Neurons[i] {
+			checkSlicesEqual(t, loaded.Neurons[i][j].Weights, sm.Neurons[i][j].Weights)
+		}
+	}
+	if !loaded.IsMasked(0, 1) || loaded.IsMasked(1, 1) {
+		t.Fatal("Expected mask to be loaded")
+	}
+}
+
+func TestSaveJSONReducedPrecision(t *testing.T) {
+	full, reduced := &bytes.Buffer{}, &bytes.Buffer{}
+	savableSOM().SaveJSON(full, som.Precision{})
+	savableSOM().SaveJSON(reduced, som.Precision{Digits: 3})
+
+	if !strings.Contains(reduced.String(), "0.333]") {
+		t.Fatalf("Expected weights with 3 significant digits, got %s", reduced.String())
+	}
+	if reduced.Len() >= full.Len() {
+		t.Fatalf("Expected reduced output to be smaller, %d >= %d", reduced.Len(), full.Len())
+	}
+}
+
+func TestSaveLoadBinaryRoundTrip(t *testing.T) {
+	for _, half := range []bool{false, true} {
+		sm := savableSOM()
+
+		buf := &bytes.Buffer{}
+		if err := sm.SaveBinary(buf, som.Precision{Half: half}); err != nil {
+			t.Fatal(err)
+		}
+		expectedLen := 18 + 2*2*2*8 + 4
+		if half {
+			expectedLen = 18 + 2*2*2*2 + 4
+		}
+		assertEq(t, buf.Len(), expectedLen)
+
+		loaded, err := som.LoadBinary(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range sm.Neurons {
+			for j := range sm.Neurons[i] {
+				for k, v := range sm.Neurons[i][j].Weights {
+					tolerance := 0.0
+					if half {
+						// relative precision of normal values, absolute one of subnormals
+						tolerance = math.Max(math.Abs(v)/1024, math.Ldexp(1, -24))
+					}
+					if actual := loaded.Neurons[i][j].Weights[k]; math.Abs(actual-v) > tolerance {
+						t.Fatalf("Expected weight %f, got %f (half=%v)", v, actual, half)
+					}
+				}
+			}
+		}
+		if !loaded.IsMasked(0, 1) {
+			t.Fatal("Expected mask to be loaded")
+		}
+	}
+}
+
+func TestSaveLoadKeepsComponents(t *testing.T) {
+	for _, format := range []string{"json", "binary"} {
+		sm := savableSOM()
+		sm.Topology = &som.CylinderTopology{Wrapped: som.AxisY}
+		sm.Distance = &som.ManhattanDistanceFunc{}
+		sm.InDataAdapter = som.NewScalingDataAdapter([]float64{0, 0}, []float64{2, 4})
+
+		buf := &bytes.Buffer{}
+		save, load := sm.SaveJSON, som.LoadJSON
+		if format == "binary" {
+			save, load = sm.SaveBinary, som.LoadBinary
+		}
+		if err := save(buf, som.Precision{}); err != nil {
+			t.Fatal(err)
+		}
+		loaded, err := load(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assertEq(t, *loaded.Topology.(*som.CylinderTopology), som.CylinderTopology{Wrapped: som.AxisY})
+		if _, ok := loaded.Distance.(*som.ManhattanDistanceFunc); !ok {
+			t.Fatalf("Expected manhattan distance, got %T (%s)", loaded.Distance, format)
+		}
+		vector := loaded.InDataAdapter.Adapt([]float64{1, 1})
+		assertEq(t, vector[0], 0.5)
+		assertEq(t, vector[1], 0.25)
+	}
+}
+
+func TestSaveRejectsUndescribedComponents(t *testing.T) {
+	sm := savableSOM()
+	sm.InDataAdapter = som.DataAdapterFunc(func(vector []float64) []float64 { return vector })
+
+	if err := sm.SaveJSON(&bytes.Buffer{}, som.Precision{}); !errors.Is(err, som.ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig, got %v", err)
+	}
+	if err := sm.SaveBinary(&bytes.Buffer{}, som.Precision{}); !errors.Is(err, som.ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig, got %v", err)
+	}
+	model, err := sm.Model()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := model.SaveBinary(&bytes.Buffer{}, som.Precision{}); !errors.Is(err, som.ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig, got %v", err)
+	}
+}
+
+func TestSaveSkipsUndescribedComponents(t *testing.T) {
+	sm := savableSOM()
+	sm.InDataAdapter = som.DataAdapterFunc(func(vector []float64) []float64 { return vector })
+	sm.SkipUndescribed = true
+	logs := &bytes.Buffer{}
+	sm.Logger = &som.StdLogger{Logger: log.New(logs, "", 0)}
+
+	buf := &bytes.Buffer{}
+	if err := sm.SaveJSON(buf, som.Precision{}); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := som.LoadJSON(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := loaded.InDataAdapter.(*som.NoOpAdapter); !ok {
+		t.Fatalf("Expected the default adapter, got %T", loaded.InDataAdapter)
+	}
+	if !strings.HasPrefix(logs.String(), "WARN component is not saved") {
+		t.Fatalf("Expected a warning, got %q", logs.String())
+	}
+
+	model, err := sm.Model()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := model.SaveBinary(&bytes.Buffer{}, som.Precision{}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSaveLoadFittedAdapters(t *testing.T) {
+	nan := math.NaN()
+	ds := &som.DataSet{Vectors: []som.DataVector{{1, 10}, {2, 30}, {4, nan}, {8, 20}}}
+	complete := &som.DataSet{Vectors: []som.DataVector{{1, 10}, {2, 30}, {4, 25}, {8, 20}}}
+	tests := []struct {
+		adapter som.DataAdapter
+		vector  []float64
+	}{
+		{som.NewRankDataAdapter(ds, 0), []float64{3, 15}},
+		{som.NewWhiteningDataAdapter(complete, som.ZCAWhitening, 1e-5), []float64{3, 15}},
+		{som.NewMeanImputer(ds), []float64{3, nan}},
+		{&som.KNNImputer{K: 2, Set: ds}, []float64{3, nan}},
+	}
+	for _, test := range tests {
+		adapter := test.adapter
+		sm := savableSOM()
+		sm.InDataAdapter = adapter
+
+		buf := &bytes.Buffer{}
+		if err := sm.SaveBinary(buf, som.Precision{}); err != nil {
+			t.Fatalf("%T: %v", adapter, err)
+		}
+		loaded, err := som.LoadBinary(buf)
+		if err != nil {
+			t.Fatalf("%T: %v", adapter, err)
+		}
+		expected := adapter.Adapt(append([]float64(nil), test.vector...))
+		checkSlicesEqual(t, expected, loaded.InDataAdapter.Adapt(append([]float64(nil), test.vector...)))
+	}
+}
+
+func TestLoadRejectsUnknownComponents(t *testing.T) {
+	model := `{"x": 1, "y": 1, "weights": [[[1]]], "distance": {"type": "no-such-distance"}}`
+
+	if _, err := som.LoadJSON(strings.NewReader(model)); !errors.Is(err, som.ErrBadModel) {
+		t.Fatalf("Expected ErrBadModel, got %v", err)
+	}
+}
+
+func TestLoadRejectsMalformedModels(t *testing.T) {
+	buf := &bytes.Buffer{}
+	savableSOM().SaveBinary(buf, som.Precision{})
+	truncated := buf.Bytes()[:buf.Len()-10]
+
+	if _, err := som.LoadBinary(bytes.NewReader(truncated)); !errors.Is(err, som.ErrBadModel) {
+		t.Fatalf("Expected ErrBadModel for truncated model, got %v", err)
+	}
+	if _, err := som.LoadBinary(strings.NewReader("not a model at all")); !errors.Is(err, som.ErrBadModel) {
+		t.Fatalf("Expected ErrBadModel for bad magic, got %v", err)
+	}
+	if _, err := som.LoadJSON(strings.NewReader(`{"x":2,"y":1,"weights":[[[1]]]}`)); !errors.Is(err, som.ErrBadModel) {
+		t.Fatalf("Expected ErrBadModel for mismatching weights, got %v", err)
+	}
+}
+
+// checkLoadedSOM checks that the map loaded from untrusted input is usable.
+func checkLoadedSOM(t *testing.T, sm *som.SOM) {
+	model, err := sm.Model()
+	if err != nil {
+		t.Fatalf("Loaded map is not trained: %v", err)
+	}
+	if _, err := model.BMU(make(som.DataVector, model.Width())); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func FuzzLoadBinary(f *testing.F) {
+	sm := savableSOM()
+	sm.Learn(&som.DataSet{Vectors: []som.DataVector{{0, 0}}}, 1)
+	for _, precision := range []som.Precision{{}, {Half: true}} {
+		buf := &bytes.Buffer{}
+		if err := sm.SaveBinary(buf, precision); err != nil {
+			f.Fatal(err)
+		}
+		f.Add(buf.Bytes())
+	}
+	sm.Mask = [][]bool{{false, true}, {false, false}}
+	buf := &bytes.Buffer{}
+	sm.SaveBinary(buf, som.Precision{})
+	f.Add(buf.Bytes())
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		sm, err := som.LoadBinary(bytes.NewReader(data))
+		if err != nil {
+			if !errors.Is(err, som.ErrBadModel) {
+				t.Fatalf("Expected ErrBadModel, got %v", err)
+			}
+			return
+		}
+		checkLoadedSOM(t, sm)
+	})
+}
+
+func FuzzLoadJSON(f *testing.F) {
+	sm := savableSOM()
+	sm.Learn(&som.DataSet{Vectors: []som.DataVector{{0, 0}}}, 1)
+	buf := &bytes.Buffer{}
+	if err := sm.SaveJSON(buf, som.Precision{}); err != nil {
+		f.Fatal(err)
+	}
+	f.Add(buf.Bytes())
+	f.Add([]byte(`{"x":1,"y":2,"weights":[[[0],[1]]],"mask":[[true,false]]}`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		sm, err := som.LoadJSON(bytes.NewReader(data))
+		if err != nil {
+			if !errors.Is(err, som.ErrBadModel) {
+				t.Fatalf("Expected ErrBadModel, got %v", err)
+			}
+			return
+		}
+		checkLoadedSOM(t, sm)
+	})
+}
@@ -0,0 +1,78 @@
+package som
+
+import (
+	"encoding/csv"
+	"io"
+	"math"
+	"strconv"
+)
+
+// schedulePreviewPoints is the max number of iterations sampled by PreviewSchedules.
+const schedulePreviewPoints = 1000
+
+// Schedules are the learning rate and the neighbourhood radius
+// curves of a configuration, see PreviewSchedules.
+type Schedules struct {
+	// Iterations are the sampled iterations within [0, iterations number).
+	Iterations []int
+
+	// Rates[i] is the learning rate (the restraint coefficient) at Iterations[i].
+	Rates []float64
+
+	// Radii[i] is the neighbourhood radius at Iterations[i], all NaN
+	// if the influence function doesn't implement RadiusReporter.
+	Radii []float64
+}
+
+// PreviewSchedules computes the learning rate and the radius curves the
+// restraint and the influence functions produce over the given number of
+// iterations, so configurations can be sanity-checked without training.
+// Long runs are sampled by at most 1000 evenly spaced iterations,
+// the first and the last iterations are always included.
+// Adaptive functions are previewed as if no feedback was observed.
+func PreviewSchedules(restraint RestraintFunc, influence InfluenceFunc, iterations int) *Schedules {
+	n := iterations
+	if n > schedulePreviewPoints {
+		n = schedulePreviewPoints
+	}
+	reporter, reportsRadius := influence.(RadiusReporter)
+	s := &Schedules{
+		Iterations: make([]int, n),
+		Rates:      make([]float64, n),
+		Radii:      make([]float64, n),
+	}
+	for i := 0; i < n; i++ {
+		it := i
+		if n > 1 && n < iterations {
+			it = int(math.Round(float64(i) * float64(iterations-1) / float64(n-1)))
+		}
+		s.Iterations[i] = it
+		s.Rates[i] = restraint.Apply(it, iterations)
+		s.Radii[i] = math.NaN()
+		if reportsRadius {
+			s.Radii[i] = reporter.EffectiveRadius(it, iterations)
+		}
+	}
+	return s
+}
+
+// WriteCSV writes the curves in CSV format with iteration, rate
+// and radius columns, preceded by a header row, e.g. to plot them.
+func (s *Schedules) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"iteration", "rate", "radius"}); err != nil {
+		return err
+	}
+	for i, it := range s.Iterations {
+		record := []string{
+			strconv.Itoa(it),
+			strconv.FormatFloat(s.Rates[i], 'g', -1, 64),
+			strconv.FormatFloat(s.Radii[i], 'g', -1, 64),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,39 @@
+package som_test
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+func TestPreviewSchedules(t *testing.T) {
+	restraint := &som.ExpRestraintFunc{InitialRate: 1, N: 100}
+	influence := &som.GaussianExpDecayInfluenceFunc{InitialWidth: 4, MinWidth: 1}
+	s := som.PreviewSchedules(restraint, influence, 10)
+	assertEq(t, len(s.Iterations), 10)
+	assertEq(t, s.Iterations[9], 9)
+	assertEq(t, s.Rates[3], restraint.Apply(3, 10))
+	assertEq(t, s.Radii[0], influence.EffectiveRadius(0, 10))
+
+	s = som.PreviewSchedules(restraint, influence, 100000)
+	assertEq(t, len(s.Iterations), 1000)
+	assertEq(t, s.Iterations[0], 0)
+	assertEq(t, s.Iterations[999], 99999)
+
+	s = som.PreviewSchedules(restraint, &constantInfluenceFunc{}, 2)
+	if !math.IsNaN(s.Radii[1]) {
+		t.Fatalf("Expected NaN radius, got %f", s.Radii[1])
+	}
+}
+
+func TestSchedulesWriteCSV(t *testing.T) {
+	s := som.PreviewSchedules(&som.NoRestraintFunc{}, &som.BMUOnlyInfluencedFunc{}, 2)
+	buf := &bytes.Buffer{}
+	if err := s.WriteCSV(buf); err != nil {
+		t.Fatal(err)
+	}
+	assertEq(t, buf.String(), strings.Join([]string{"iteration,rate,radius", "0,1,0", "1,1,0", ""}, "\n"))
+}
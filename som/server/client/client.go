@@ -0,0 +1,92 @@
+// Package client is a typed client of the inference server, see package server.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/voievodin/self-organizing-map/som"
+	"github.com/voievodin/self-organizing-map/som/server"
+)
+
+// StatusError is returned when the server responds with a non-OK status.
+type StatusError struct {
+	Code    int
+	Message string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("server responded %d %s: %s", e.Code, http.StatusText(e.Code), e.Message)
+}
+
+// Client calls the server at the URL, e.g. "http://localhost:8080".
+type Client struct {
+	URL string
+
+	// HTTPClient sends the requests, nil means http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// New creates a client of the server at the URL.
+func New(url string) *Client {
+	return &Client{URL: strings.TrimSuffix(url, "/")}
+}
+
+// Map returns the BMU of the vector, see server.MapResponse.
+func (c *Client) Map(ctx context.Context, vector som.DataVector) (*server.MapResponse, error) {
+	body, err := json.Marshal(&server.MapRequest{Vector: vector})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.do(ctx, http.MethodPost, "/map", body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	result := &server.MapResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return nil, fmt.Errorf("decoding map response: %w", err)
+	}
+	return result, nil
+}
+
+// Metrics returns the metrics of the server in the Prometheus text format.
+func (c *Client) Metrics(ctx context.Context) (string, error) {
+	resp, err := c.do(ctx, http.MethodGet, "/metrics", nil)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	metrics, err := io.ReadAll(resp.Body)
+	return string(metrics), err
+}
+
+// do sends the request and returns the response if its status is OK.
+func (c *Client) do(ctx context.Context, method, path string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.URL+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, &StatusError{Code: resp.StatusCode, Message: strings.TrimSpace(string(message))}
+	}
+	return resp, nil
+}
@@ -0,0 +1,69 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+	"github.com/voievodin/self-organizing-map/som/server"
+	"github.com/voievodin/self-organizing-map/som/server/client"
+)
+
+func startServer(t *testing.T) *httptest.Server {
+	sm := som.New(1, 3)
+	if err := sm.LoadCodebook([][][]float64{{{0}, {1}, {2}}}); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(t.TempDir(), "model.somb")
+	f, err := os.Create(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := sm.SaveBinary(f, som.Precision{}); err != nil {
+		t.Fatal(err)
+	}
+	s := &server.Server{Source: &server.FileSource{Path: file}}
+	if _, err := s.Reload(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(s)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestClientMapsVectors(t *testing.T) {
+	c := client.New(startServer(t).URL + "/")
+	result, err := c.Map(context.Background(), som.DataVector{1.75})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.X != 0 || result.Y != 2 || result.Distance != 0.25 || result.Version == "" {
+		t.Fatalf("Unexpected result %+v", result)
+	}
+	metrics, err := c.Metrics(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(metrics, "som_requests_total 1") {
+		t.Fatalf("Unexpected metrics:\n%s", metrics)
+	}
+}
+
+func TestClientReportsStatusErrors(t *testing.T) {
+	c := client.New(startServer(t).URL)
+	_, err := c.Map(context.Background(), som.DataVector{1, 2})
+	var statusErr *client.StatusError
+	if !errors.As(err, &statusErr) || statusErr.Code != http.StatusBadRequest {
+		t.Fatalf("Expected 400 status error, got %v", err)
+	}
+	if !strings.Contains(statusErr.Message, "vector length is 2") {
+		t.Fatalf("Unexpected message %q", statusErr.Message)
+	}
+}
@@ -0,0 +1,320 @@
+// Package server serves inference of a trained map over HTTP.
+//
+// The server maps vectors to their BMUs:
+//
+//	POST /map {"vector": [0.1, 0.7]}
+//
+// responds with
+//
+//	{"version": "3", "x": 2, "y": 5, "distance": 0.042}
+//
+// where distance is the quantization distance of the vector,
+// its anomaly score. The version of the model is also reported by
+// the X-Model-Version header, and GET /metrics exposes the counters
+// of the server in the Prometheus text format. GET /openapi.json
+// describes the endpoints, see OpenAPI, package client consumes them.
+//
+// The model is loaded from a Source, Watch polls the source and swaps
+// in new versions atomically: requests in flight finish with the model
+// they started with, and new requests get the new one.
+//
+// For safe rollout of a retrained map the server can evaluate
+// a candidate model next to the primary one: each request is mapped
+// by both, a share of the requests is answered by the candidate,
+// and the response carries the result of the other model too:
+//
+//	{"version": "3", "x": 2, "y": 5, "distance": 0.042, "variant": "primary",
+//	 "other": {"version": "4", "x": 2, "y": 4, "distance": 0.037, "variant": "candidate"}}
+//
+// TrainingServer serves continuous learning of a map instead.
+package server
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+// OpenAPI is the OpenAPI 3 description of the server endpoints.
+//
+//go:embed openapi.json
+var OpenAPI []byte
+
+// ErrNoModel is returned when no model is loaded yet.
+var ErrNoModel = errors.New("no model loaded")
+
+// DefaultMaxBodyBytes limits the size of request bodies
+// of the servers which don't set MaxBodyBytes.
+const DefaultMaxBodyBytes = 1 << 20
+
+// MapRequest is the body of POST /map.
+type MapRequest struct {
+	Vector som.DataVector `json:"vector"`
+}
+
+// The variants of the models served in A/B evaluation mode.
+const (
+	VariantPrimary   = "primary"
+	VariantCandidate = "candidate"
+)
+
+// MapResponse is the response of POST /map.
+type MapResponse struct {
+	Version  string  `json:"version"`
+	X        int     `json:"x"`
+	Y        int     `json:"y"`
+	Distance float64 `json:"distance"`
+
+	// Variant is the variant of the model which produced the response and
+	// Other is the response of the other model, both are set only
+	// in A/B evaluation mode, see Server.Candidate.
+	Variant string       `json:"variant,omitempty"`
+	Other   *MapResponse `json:"other,omitempty"`
+}
+
+// Comparison is the outcome of a request mapped by both models
+// in A/B evaluation mode, Served is the variant of the response.
+type Comparison struct {
+	Vector             som.DataVector
+	Primary, Candidate *MapResponse
+	Served             string
+}
+
+// Server is an http.Handler serving the model of the source.
+// Call Reload or Watch to load the model before serving requests.
+type Server struct {
+	Source Source
+
+	// Candidate is the source of the model evaluated against the primary one,
+	// nil disables A/B evaluation. Requests are served by the primary model
+	// only until the candidate is loaded.
+	Candidate Source
+
+	// CandidateShare is the fraction of requests within [0, 1]
+	// answered by the candidate model, the rest is answered by the primary one.
+	CandidateShare float64
+
+	// OnCompare is called with the outcome of each request mapped by
+	// both models, e.g. to log it, may be nil. It must be safe for concurrent use.
+	OnCompare func(c *Comparison)
+
+	// OnReloadError is called with errors of reloads by Watch,
+	// which keeps serving the previous model, may be nil.
+	OnReloadError func(err error)
+
+	// MaxBodyBytes limits the size of request bodies, the requests
+	// exceeding it are answered with 413, DefaultMaxBodyBytes if <= 0.
+	MaxBodyBytes int64
+
+	current   atomic.Value // *loaded
+	candidate atomic.Value // *loaded
+	requests  atomic.Int64
+	served    [2]atomic.Int64 // by primary and candidate
+	failures  atomic.Int64
+	reloads   atomic.Int64
+}
+
+type loaded struct {
+	model   *som.Model
+	version string
+}
+
+// Model returns the model being served and its version,
+// or ErrNoModel if nothing is loaded yet.
+func (s *Server) Model() (*som.Model, string, error) {
+	return loadedModel(&s.current)
+}
+
+// CandidateModel returns the candidate model and its version,
+// or ErrNoModel if it's not loaded, see Candidate.
+func (s *Server) CandidateModel() (*som.Model, string, error) {
+	return loadedModel(&s.candidate)
+}
+
+func loadedModel(slot *atomic.Value) (*som.Model, string, error) {
+	current, _ := slot.Load().(*loaded)
+	if current == nil {
+		return nil, "", ErrNoModel
+	}
+	return current.model, current.version, nil
+}
+
+// Reload loads the models from the sources, if their versions differ
+// from the ones being served, and swaps them in.
+// Returns true if any model is swapped.
+func (s *Server) Reload(ctx context.Context) (bool, error) {
+	swapped, err := s.reload(ctx, s.Source, &s.current)
+	if err != nil || s.Candidate == nil {
+		return swapped, err
+	}
+	candidateSwapped, err := s.reload(ctx, s.Candidate, &s.candidate)
+	return swapped || candidateSwapped, err
+}
+
+func (s *Server) reload(ctx context.Context, source Source, slot *atomic.Value) (bool, error) {
+	version, err := source.Version(ctx)
+	if err != nil {
+		return false, err
+	}
+	if _, current, err := loadedModel(slot); err == nil && current == version {
+		return false, nil
+	}
+	model, version, err := source.Load(ctx)
+	if err != nil {
+		return false, err
+	}
+	slot.Store(&loaded{model: model, version: version})
+	s.reloads.Add(1)
+	return true, nil
+}
+
+// Watch reloads the model every interval until the context is done,
+// see Reload. The reload errors are reported to OnReloadError.
+func (s *Server) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.Reload(ctx); err != nil && s.OnReloadError != nil {
+			s.OnReloadError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/map":
+		s.serveMap(w, r)
+	case "/metrics":
+		s.serveMetrics(w, r)
+	case "/openapi.json":
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(OpenAPI)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) serveMap(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	s.requests.Add(1)
+	// the model is taken once, so a reload doesn't affect the request
+	model, version, err := s.Model()
+	if err != nil {
+		s.fail(w, err, http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("X-Model-Version", version)
+
+	var req MapRequest
+	if code, err := decodeBody(w, r, s.MaxBodyBytes, &req); err != nil {
+		s.fail(w, err, code)
+		return
+	}
+	primary, err := mapVector(model, version, req.Vector)
+	if err != nil {
+		s.fail(w, err, http.StatusBadRequest)
+		return
+	}
+	// a candidate which is not loaded or doesn't fit
+	// the input must not break the requests
+	var candidate *MapResponse
+	if s.Candidate != nil {
+		if model, version, err := s.CandidateModel(); err == nil {
+			candidate, _ = mapVector(model, version, req.Vector)
+		}
+	}
+	if candidate == nil {
+		s.served[0].Add(1)
+		writeJSON(w, primary)
+		return
+	}
+
+	primary.Variant, candidate.Variant = VariantPrimary, VariantCandidate
+	served, other := primary, candidate
+	if rand.Float64() < s.CandidateShare {
+		served, other = candidate, primary
+		s.served[1].Add(1)
+	} else {
+		s.served[0].Add(1)
+	}
+	if s.OnCompare != nil {
+		s.OnCompare(&Comparison{Vector: req.Vector, Primary: primary, Candidate: candidate, Served: served.Variant})
+	}
+	w.Header().Set("X-Model-Version", served.Version)
+	response := *served
+	response.Other = other
+	writeJSON(w, &response)
+}
+
+func mapVector(model *som.Model, version string, vector som.DataVector) (*MapResponse, error) {
+	bmu, err := model.BMU(vector)
+	if err != nil {
+		return nil, err
+	}
+	distances := model.Distances(vector, nil)
+	return &MapResponse{
+		Version:  version,
+		X:        bmu.X,
+		Y:        bmu.Y,
+		Distance: distances[bmu.X][bmu.Y],
+	}, nil
+}
+
+func (s *Server) serveMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	if _, version, err := s.Model(); err == nil {
+		fmt.Fprintf(w, "som_model_info{version=%q} 1\n", version)
+	}
+	if _, version, err := s.CandidateModel(); err == nil {
+		fmt.Fprintf(w, "som_candidate_model_info{version=%q} 1\n", version)
+	}
+	fmt.Fprintf(w, "som_model_reloads_total %d\n", s.reloads.Load())
+	fmt.Fprintf(w, "som_requests_total %d\n", s.requests.Load())
+	fmt.Fprintf(w, "som_served_requests_total{variant=%q} %d\n", VariantPrimary, s.served[0].Load())
+	fmt.Fprintf(w, "som_served_requests_total{variant=%q} %d\n", VariantCandidate, s.served[1].Load())
+	fmt.Fprintf(w, "som_request_failures_total %d\n", s.failures.Load())
+}
+
+func (s *Server) fail(w http.ResponseWriter, err error, code int) {
+	s.failures.Add(1)
+	http.Error(w, err.Error(), code)
+}
+
+// decodeBody decodes the JSON body of the request limited to max bytes,
+// see DefaultMaxBodyBytes, and returns the status of the failure.
+func decodeBody(w http.ResponseWriter, r *http.Request, max int64, v interface{}) (int, error) {
+	if max <= 0 {
+		max = DefaultMaxBodyBytes
+	}
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, max)).Decode(v)
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		return http.StatusRequestEntityTooLarge, err
+	case err != nil:
+		return http.StatusBadRequest, err
+	}
+	return 0, nil
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
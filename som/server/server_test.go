@@ -0,0 +1,308 @@
+package server_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/voievodin/self-organizing-map/som"
+	"github.com/voievodin/self-organizing-map/som/internal/somtest"
+	"github.com/voievodin/self-organizing-map/som/modelstore"
+	"github.com/voievodin/self-organizing-map/som/server"
+)
+
+func saveModel(t *testing.T, file string, model *som.Model) {
+	f, err := os.Create(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := model.SaveJSON(f, som.Precision{}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func mapVector(t *testing.T, url, body string) (*http.Response, *server.MapResponse) {
+	resp, err := http.Post(url+"/map", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return resp, nil
+	}
+	result := &server.MapResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		t.Fatal(err)
+	}
+	return resp, result
+}
+
+func TestServerReloadsChangedFile(t *testing.T) {
+	ctx := context.Background()
+	file := filepath.Join(t.TempDir(), "model.json")
+	saveModel(t, file, somtest.LineModel(t, 0, 1, 2))
+	s := &server.Server{Source: &server.FileSource{Path: file}}
+	if swapped, err := s.Reload(ctx); err != nil || !swapped {
+		t.Fatalf("Expected the initial load, got %v, %v", swapped, err)
+	}
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	resp, first := mapVector(t, ts.URL, `{"vector": [1.25]}`)
+	if first == nil {
+		t.Fatalf("Unexpected status %s", resp.Status)
+	}
+	if first.X != 0 || first.Y != 1 || first.Distance != 0.25 {
+		t.Fatalf("Unexpected response %+v", first)
+	}
+	if v := resp.Header.Get("X-Model-Version"); v != first.Version {
+		t.Fatalf("Header version %q differs from response version %q", v, first.Version)
+	}
+
+	if swapped, err := s.Reload(ctx); err != nil || swapped {
+		t.Fatalf("Expected no swap of the same file, got %v, %v", swapped, err)
+	}
+	saveModel(t, file, somtest.LineModel(t, 2, 1, 0))
+	if swapped, err := s.Reload(ctx); err != nil || !swapped {
+		t.Fatalf("Expected a swap of the changed file, got %v, %v", swapped, err)
+	}
+	_, second := mapVector(t, ts.URL, `{"vector": [1.75]}`)
+	if second.Y != 0 || second.Version == first.Version {
+		t.Fatalf("Unexpected response of the reloaded model %+v", second)
+	}
+
+	resp, err := http.Get(ts.URL + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	metrics, _ := io.ReadAll(resp.Body)
+	for _, line := range []string{
+		`som_model_info{version="` + second.Version + `"} 1`,
+		"som_model_reloads_total 2",
+		"som_requests_total 2",
+	} {
+		if !strings.Contains(string(metrics), line) {
+			t.Fatalf("Metrics miss %q:\n%s", line, metrics)
+		}
+	}
+}
+
+func TestServerReloadsSignedFile(t *testing.T) {
+	ctx := context.Background()
+	key := []byte("secret")
+	signed := func(file string, model *som.Model, key []byte) {
+		payload := &bytes.Buffer{}
+		if err := model.SaveBinary(payload, som.Precision{}); err != nil {
+			t.Fatal(err)
+		}
+		f, err := os.Create(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if err := som.WriteSigned(f, key, payload.Bytes()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	file := filepath.Join(t.TempDir(), "model.soms")
+	signed(file, somtest.LineModel(t, 0, 1, 2), key)
+	s := &server.Server{Source: &server.FileSource{Path: file, Key: key}}
+	if _, err := s.Reload(ctx); err != nil {
+		t.Fatal(err)
+	}
+	_, version, err := s.Model()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the tampered models are not swapped in
+	signed(file, somtest.LineModel(t, 2, 1, 0), []byte("other"))
+	if _, err := s.Reload(ctx); !errors.Is(err, som.ErrBadSignature) {
+		t.Fatalf("Expected ErrBadSignature for the model signed with another key, got %v", err)
+	}
+	saveModel(t, file, somtest.LineModel(t, 2, 1, 0))
+	if _, err := s.Reload(ctx); !errors.Is(err, som.ErrBadSignature) {
+		t.Fatalf("Expected ErrBadSignature for the unsigned model, got %v", err)
+	}
+	if model, current, _ := s.Model(); current != version || model.Weights(0, 0)[0] != 0 {
+		t.Fatalf("Expected the signed model to be served, got version %s", current)
+	}
+
+	signed(file, somtest.LineModel(t, 2, 1, 0), key)
+	if swapped, err := s.Reload(ctx); err != nil || !swapped {
+		t.Fatalf("Expected a swap of the signed model, got %v, %v", swapped, err)
+	}
+}
+
+func TestFileSourceVersionFollowsRewrites(t *testing.T) {
+	ctx := context.Background()
+	file := filepath.Join(t.TempDir(), "model.json")
+	saveModel(t, file, somtest.LineModel(t, 0, 1, 2))
+	source := &server.FileSource{Path: file}
+	first, err := source.Version(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the rewritten file has the same size and, on a coarse clock, the same time
+	info, err := os.Stat(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saveModel(t, file, somtest.LineModel(t, 2, 1, 0))
+	if err := os.Chtimes(file, info.ModTime(), info.ModTime()); err != nil {
+		t.Fatal(err)
+	}
+	second, err := source.Version(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second == first {
+		t.Fatal("Expected the rewritten file to have a new version")
+	}
+
+	// an old file is not hashed again
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(file, old, old); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := source.Version(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(file, 0); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chmod(file, 0o644)
+	if _, err := os.ReadFile(file); err == nil {
+		t.Skip("the file can be read regardless of its permissions")
+	}
+	if version, err := source.Version(ctx); err != nil || version != second {
+		t.Fatalf("Expected the cached version %s, got %s, %v", second, version, err)
+	}
+}
+
+func TestServerFollowsRegistryTag(t *testing.T) {
+	ctx := context.Background()
+	registry := &modelstore.Registry{Store: &modelstore.DirStore{Dir: t.TempDir()}}
+	if _, err := registry.Push(ctx, "digits", somtest.LineModel(t, 0, 1), "prod"); err != nil {
+		t.Fatal(err)
+	}
+	s := &server.Server{Source: &server.RegistrySource{Registry: registry, Name: "digits", Ref: "prod"}}
+	if _, err := s.Reload(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := registry.Push(ctx, "digits", somtest.LineModel(t, 1, 0), "prod"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Reload(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, version, _ := s.Model(); version != "2" {
+		t.Fatalf("Serving version %s, expected 2", version)
+	}
+}
+
+func TestServerRejectsBadRequests(t *testing.T) {
+	s := &server.Server{Source: &server.FileSource{Path: filepath.Join(t.TempDir(), "missing.json")}}
+	if _, _, err := s.Model(); !errors.Is(err, server.ErrNoModel) {
+		t.Fatalf("Expected ErrNoModel, got %v", err)
+	}
+	if _, err := s.Reload(context.Background()); err == nil {
+		t.Fatal("Expected error for the missing file")
+	}
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	if resp, _ := mapVector(t, ts.URL, `{"vector": [1]}`); resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("Expected 503 without a model, got %s", resp.Status)
+	}
+
+	file := filepath.Join(t.TempDir(), "model.json")
+	saveModel(t, file, somtest.LineModel(t, 0, 1))
+	s.Source = &server.FileSource{Path: file}
+	if _, err := s.Reload(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if resp, _ := mapVector(t, ts.URL, `{"vector": [1, 2]}`); resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Expected 400 for the width mismatch, got %s", resp.Status)
+	}
+	ls := &server.Server{Source: s.Source, MaxBodyBytes: 16}
+	if _, err := ls.Reload(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	limited := httptest.NewServer(ls)
+	defer limited.Close()
+	if resp, _ := mapVector(t, limited.URL, `{"vector": [1, 2, 3, 4, 5, 6]}`); resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Fatalf("Expected 413 for the large body, got %s", resp.Status)
+	}
+}
+
+func TestServerEvaluatesCandidate(t *testing.T) {
+	dir := t.TempDir()
+	primaryFile, candidateFile := filepath.Join(dir, "primary.json"), filepath.Join(dir, "candidate.json")
+	saveModel(t, primaryFile, somtest.LineModel(t, 0, 1, 2))
+	saveModel(t, candidateFile, somtest.LineModel(t, 2, 1, 0))
+
+	var comparisons []*server.Comparison
+	s := &server.Server{
+		Source:    &server.FileSource{Path: primaryFile},
+		Candidate: &server.FileSource{Path: candidateFile},
+		OnCompare: func(c *server.Comparison) { comparisons = append(comparisons, c) },
+	}
+	if _, err := s.Reload(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	for _, share := range []float64{0, 1} {
+		s.CandidateShare = share
+		_, result := mapVector(t, ts.URL, `{"vector": [0.25]}`)
+		served, other := result, result.Other
+		if share == 1 {
+			served, other = other, served
+		}
+		if served.Variant != server.VariantPrimary || served.Y != 0 {
+			t.Fatalf("Unexpected primary result %+v with share %v", served, share)
+		}
+		if other.Variant != server.VariantCandidate || other.Y != 2 || other.Version == served.Version {
+			t.Fatalf("Unexpected candidate result %+v with share %v", other, share)
+		}
+	}
+	if len(comparisons) != 2 || comparisons[0].Served != server.VariantPrimary || comparisons[1].Served != server.VariantCandidate {
+		t.Fatalf("Unexpected comparisons %+v", comparisons)
+	}
+
+}
+
+func TestServerDescribesEndpoints(t *testing.T) {
+	ts := httptest.NewServer(&server.Server{})
+	defer ts.Close()
+	resp, err := http.Get(ts.URL + "/openapi.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	spec := struct {
+		OpenAPI string                     `json:"openapi"`
+		Paths   map[string]json.RawMessage `json:"paths"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&spec); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"/map", "/metrics", "/openapi.json"} {
+		if _, ok := spec.Paths[path]; !ok {
+			t.Fatalf("OpenAPI %s doesn't describe %s", spec.OpenAPI, path)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/voievodin/self-organizing-map/som"
+	"github.com/voievodin/self-organizing-map/som/modelstore"
+)
+
+// Source is where the server loads models from.
+type Source interface {
+	// Version returns the version of the model currently at the source,
+	// it's cheap compared to Load, so the source can be polled.
+	Version(ctx context.Context) (string, error)
+
+	// Load returns the model currently at the source with its version.
+	Load(ctx context.Context) (*som.Model, string, error)
+}
+
+// FileSource is a model file saved by SaveJSON or SaveBinary, the format
+// is detected by the content. The version of the model is the hash
+// of the file, so any change of the file is a new version. The file is
+// hashed only when its size or modification time changes, so polling
+// the version of an unchanged file only stats it.
+type FileSource struct {
+	Path string
+
+	// Key, if not empty, is the key the model file must be signed with,
+	// see som.WriteSigned, otherwise the file is not loaded and Load returns
+	// som.ErrBadSignature, so the server keeps serving the previous model.
+	Key []byte
+
+	mu      sync.Mutex
+	stat    fileStat
+	version string
+}
+
+// fileStat is the state of the file which tells whether it has changed.
+type fileStat struct {
+	size    int64
+	modTime time.Time
+
+	// checked is the time the file is hashed at
+	checked time.Time
+}
+
+// racyWindow is how recently the file may be modified before it's hashed,
+// for its modification time to be trusted. File systems record modification
+// times with a coarse clock, so the file rewritten within the same tick
+// after it's hashed keeps the time and, if its size is the same, the change
+// is only found by hashing it again.
+const racyWindow = time.Second
+
+func (s *FileSource) Version(ctx context.Context) (string, error) {
+	info, err := os.Stat(s.Path)
+	if err != nil {
+		return "", err
+	}
+	s.mu.Lock()
+	cached, version := s.stat, s.version
+	s.mu.Unlock()
+	if version != "" && info.Size() == cached.size && info.ModTime().Equal(cached.modTime) &&
+		cached.checked.Sub(info.ModTime()) > racyWindow {
+		return version, nil
+	}
+	_, version, err = s.read()
+	return version, err
+}
+
+func (s *FileSource) Load(ctx context.Context) (*som.Model, string, error) {
+	data, version, err := s.read()
+	if err != nil {
+		return nil, "", err
+	}
+	if len(s.Key) > 0 {
+		if data, err = som.ReadSigned(bytes.NewReader(data), s.Key); err != nil {
+			return nil, "", err
+		}
+	}
+	var sm *som.SOM
+	if bytes.HasPrefix(data, []byte("SOMB")) {
+		sm, err = som.LoadBinary(bytes.NewReader(data))
+	} else {
+		sm, err = som.LoadJSON(bytes.NewReader(data))
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	model, err := sm.Model()
+	return model, version, err
+}
+
+// read reads the file and returns it with its version,
+// which is cached along with the state of the file.
+func (s *FileSource) read() ([]byte, string, error) {
+	checked := time.Now()
+	info, err := os.Stat(s.Path)
+	if err != nil {
+		return nil, "", err
+	}
+	data, err := os.ReadFile(s.Path)
+	if err != nil {
+		return nil, "", err
+	}
+	version := fileVersion(data)
+	s.mu.Lock()
+	s.stat = fileStat{size: info.Size(), modTime: info.ModTime(), checked: checked}
+	s.version = version
+	s.mu.Unlock()
+	return data, version, nil
+}
+
+func fileVersion(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:6])
+}
+
+// RegistrySource is a model of the registry referenced by a tag,
+// a version number or modelstore.Latest, e.g. the model tagged "prod".
+// The version of the model is its registry version. The signatures of
+// the models are verified by the registry, if it has a key, see
+// modelstore.Registry.Key.
+type RegistrySource struct {
+	Registry *modelstore.Registry
+	Name     string
+	Ref      string
+}
+
+func (s *RegistrySource) Version(ctx context.Context) (string, error) {
+	version, err := s.Registry.Resolve(ctx, s.Name, s.Ref)
+	if err != nil {
+		return "", err
+	}
+	return strconv.Itoa(version), nil
+}
+
+func (s *RegistrySource) Load(ctx context.Context) (*som.Model, string, error) {
+	model, version, err := s.Registry.Pull(ctx, s.Name, s.Ref)
+	if err != nil {
+		return nil, "", err
+	}
+	return model, strconv.Itoa(version), nil
+}
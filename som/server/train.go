@@ -0,0 +1,341 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"image/png"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/voievodin/self-organizing-map/som"
+	"github.com/voievodin/self-organizing-map/som/quality"
+)
+
+// IngestRequest is the body of POST /vectors.
+type IngestRequest struct {
+	Vectors []som.DataVector `json:"vectors"`
+}
+
+// SnapshotRequest is the body of POST /snapshots and POST /rollback.
+type SnapshotRequest struct {
+	Name string `json:"name"`
+}
+
+// IngestResponse is the response of POST /vectors, Accepted is the
+// number of the learned (or buffered) vectors, Rejected are the errors of the rest.
+type IngestResponse struct {
+	Accepted   int      `json:"accepted"`
+	Rejected   []string `json:"rejected,omitempty"`
+	Iterations int      `json:"iterations"`
+}
+
+// TrainingServer is an http.Handler teaching the map of the online
+// trainer from the vectors posted to it, a continuous learning
+// deployment, e.g. for telemetry:
+//
+//	POST /vectors {"vectors": [[0.1, 0.7], [0.3, 0.2]]}
+//
+// learns the vectors and responds with IngestResponse, with status 503 if
+// the buffer is closed, then the vectors which are not pushed are rejected.
+// GET / is the live dashboard of learning, GET /umatrix.png is the U-matrix
+// of the map, colored by the color map of the optional colormap parameter,
+// one of quality.ColorMaps, with the color bar if legend=true is passed,
+// and GET /metrics exposes the counters in the Prometheus text format.
+//
+//	POST /snapshots {"name": "before Black Friday"}
+//	POST /rollback {"name": "before Black Friday"}
+//
+// take a named snapshot of the map and roll learning back to it,
+// GET /snapshots lists the names, see OnlineTrainer.TakeSnapshot.
+// Checkpoint saves the map, CheckpointEvery does it periodically.
+type TrainingServer struct {
+	Trainer *som.OnlineTrainer
+
+	// Buffer, if not nil, decouples the requests from learning: the posted
+	// vectors are pushed to the buffer, which must be consumed by the
+	// trainer, see OnlineTrainer.Consume, and Iterations of the response
+	// may not include them yet.
+	Buffer *som.IngestBuffer
+
+	// MaxBodyBytes limits the size of request bodies, the requests
+	// exceeding it are answered with 413, DefaultMaxBodyBytes if <= 0.
+	MaxBodyBytes int64
+
+	received    atomic.Int64
+	rejected    atomic.Int64
+	checkpoints atomic.Int64
+	checkpoint  atomic.Value // time.Time of the last checkpoint
+}
+
+func (s *TrainingServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/vectors":
+		s.serveIngest(w, r)
+	case "/umatrix.png":
+		s.serveUMatrix(w, r)
+	case "/metrics":
+		s.serveMetrics(w, r)
+	case "/snapshots":
+		s.serveSnapshots(w, r)
+	case "/rollback":
+		s.serveRollback(w, r)
+	case "/":
+		s.serveDashboard(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *TrainingServer) serveIngest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req IngestRequest
+	if code, err := decodeBody(w, r, s.MaxBodyBytes, &req); err != nil {
+		http.Error(w, err.Error(), code)
+		return
+	}
+	resp := &IngestResponse{}
+	status := http.StatusOK
+	for i, vector := range req.Vectors {
+		if s.Buffer != nil {
+			if err := s.Buffer.Push(vector); err != nil {
+				// the pushed vectors stay buffered, so the client resends the rest only
+				for k := i; k < len(req.Vectors); k++ {
+					resp.Rejected = append(resp.Rejected, fmt.Sprintf("vector %d: %v", k, err))
+				}
+				status = http.StatusServiceUnavailable
+				break
+			}
+			resp.Accepted++
+			continue
+		}
+		if err := s.Trainer.Learn(vector); err != nil {
+			resp.Rejected = append(resp.Rejected, fmt.Sprintf("vector %d: %v", i, err))
+			continue
+		}
+		resp.Accepted++
+	}
+	s.received.Add(int64(resp.Accepted))
+	s.rejected.Add(int64(len(resp.Rejected)))
+	resp.Iterations = s.Trainer.Iterations()
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (s *TrainingServer) serveSnapshots(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		names, err := s.Trainer.Snapshots()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, names)
+	case http.MethodPost:
+		var req SnapshotRequest
+		if code, err := decodeBody(w, r, s.MaxBodyBytes, &req); err != nil {
+			http.Error(w, err.Error(), code)
+			return
+		}
+		if err := s.Trainer.TakeSnapshot(req.Name); err != nil {
+			http.Error(w, err.Error(), snapshotStatus(err))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *TrainingServer) serveRollback(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req SnapshotRequest
+	if code, err := decodeBody(w, r, s.MaxBodyBytes, &req); err != nil {
+		http.Error(w, err.Error(), code)
+		return
+	}
+	if err := s.Trainer.Rollback(req.Name); err != nil {
+		http.Error(w, err.Error(), snapshotStatus(err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func snapshotStatus(err error) int {
+	switch {
+	case errors.Is(err, som.ErrNoSnapshot):
+		return http.StatusNotFound
+	case errors.Is(err, som.ErrInvalidConfig):
+		return http.StatusBadRequest
+	case errors.Is(err, som.ErrNotTrained):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func (s *TrainingServer) serveUMatrix(w http.ResponseWriter, r *http.Request) {
+	model, err := s.Trainer.Model()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	options := quality.RenderOptions{Scale: dashboardScale, Legend: r.URL.Query().Get("legend") == "true"}
+	if name := r.URL.Query().Get("colormap"); name != "" {
+		var ok bool
+		if options.ColorMap, ok = quality.ColorMaps[name]; !ok {
+			http.Error(w, fmt.Sprintf("unknown color map %q, expected one of %v", name, quality.ColorMapNames()), http.StatusBadRequest)
+			return
+		}
+	}
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "no-store")
+	png.Encode(w, quality.Render(model.UMatrix(), options))
+}
+
+func (s *TrainingServer) serveMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	fmt.Fprintf(w, "som_training_iterations_total %d\n", s.Trainer.Iterations())
+	fmt.Fprintf(w, "som_training_vectors_received_total %d\n", s.received.Load())
+	fmt.Fprintf(w, "som_training_vectors_rejected_total %d\n", s.rejected.Load())
+	fmt.Fprintf(w, "som_training_checkpoints_total %d\n", s.checkpoints.Load())
+	if drift := s.Trainer.Drift; drift != nil {
+		fmt.Fprintf(w, "som_drift_divergence %g\n", drift.Divergence())
+		fmt.Fprintf(w, "som_drift_alarms_total %d\n", drift.Alarms())
+	}
+	if s.Buffer != nil {
+		stats := s.Buffer.Stats()
+		fmt.Fprintf(w, "som_ingest_buffered %d\n", stats.Buffered)
+		fmt.Fprintf(w, "som_ingest_pushed_total %d\n", stats.Pushed)
+		fmt.Fprintf(w, "som_ingest_dropped_total %d\n", stats.Dropped)
+	}
+}
+
+// dashboardScale is the size of a neuron on the dashboard U-matrix, in pixels.
+const dashboardScale = 16
+
+func (s *TrainingServer) serveDashboard(w http.ResponseWriter, r *http.Request) {
+	_, err := s.Trainer.Model()
+	data := struct {
+		Iterations         int
+		Received, Rejected int64
+		Checkpoints        int64
+		LastCheckpoint     time.Time
+		WarmingUp          bool
+		Buffer             *som.IngestStats
+		Drift              *som.DriftDetector
+	}{
+		Iterations:  s.Trainer.Iterations(),
+		Received:    s.received.Load(),
+		Rejected:    s.rejected.Load(),
+		Checkpoints: s.checkpoints.Load(),
+		WarmingUp:   err != nil,
+		Drift:       s.Trainer.Drift,
+	}
+	data.LastCheckpoint, _ = s.checkpoint.Load().(time.Time)
+	if s.Buffer != nil {
+		stats := s.Buffer.Stats()
+		data.Buffer = &stats
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	dashboardTemplate.Execute(w, data)
+}
+
+// Checkpoint saves a consistent snapshot of the map to the file, as JSON
+// if the path has .json extension and in binary format otherwise.
+// The file is replaced atomically, so readers never see partial checkpoints.
+func (s *TrainingServer) Checkpoint(path string) error {
+	model, err := s.Trainer.Model()
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	// CreateTemp makes private files, checkpoints are read by other services
+	err = tmp.Chmod(0o644)
+	if err == nil && filepath.Ext(path) == ".json" {
+		err = model.SaveJSON(tmp, som.Precision{})
+	} else if err == nil {
+		err = model.SaveBinary(tmp, som.Precision{})
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+	s.checkpoints.Add(1)
+	s.checkpoint.Store(time.Now())
+	return nil
+}
+
+// CheckpointEvery saves the map to the file every interval and once
+// more when the context is done, see Checkpoint. The errors are reported
+// to onError, may be nil, checkpoints of the maps which are warming up are skipped.
+func (s *TrainingServer) CheckpointEvery(ctx context.Context, path string, interval time.Duration, onError func(err error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for done := false; !done; {
+		select {
+		case <-ctx.Done():
+			done = true
+		case <-ticker.C:
+		}
+		if _, err := s.Trainer.Model(); err != nil {
+			continue
+		}
+		if err := s.Checkpoint(path); err != nil && onError != nil {
+			onError(err)
+		}
+	}
+}
+
+var dashboardTemplate = template.Must(template.New("dashboard").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta http-equiv="refresh" content="5">
+<title>Self-organizing map learning</title>
+<style>
+body { font-family: sans-serif; }
+td { padding: 2px 8px; }
+img { image-rendering: pixelated; }
+</style>
+</head>
+<body>
+<h1>Self-organizing map learning</h1>
+<table>
+<tr><td>iterations</td><td>{{.Iterations}}</td></tr>
+<tr><td>vectors received</td><td>{{.Received}}</td></tr>
+<tr><td>vectors rejected</td><td>{{.Rejected}}</td></tr>
+{{with .Buffer}}<tr><td>vectors buffered</td><td>{{.Buffered}}</td></tr>
+<tr><td>vectors dropped</td><td>{{.Dropped}} of {{.Pushed}}</td></tr>
+{{end}}{{with .Drift}}<tr><td>drift divergence</td><td>{{printf "%.3f" .Divergence}}, {{.Alarms}} alarms</td></tr>
+{{end}}<tr><td>checkpoints</td><td>{{.Checkpoints}}{{if not .LastCheckpoint.IsZero}}, last at {{.LastCheckpoint.Format "2006-01-02 15:04:05"}}{{end}}</td></tr>
+</table>
+{{if .WarmingUp}}<p>Warming up, the map is not initialized yet.</p>{{else}}<h2>U-matrix</h2>
+<img src="/umatrix.png" alt="U-matrix">{{end}}
+</body>
+</html>
+`))
@@ -0,0 +1,221 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/voievodin/self-organizing-map/som"
+	"github.com/voievodin/self-organizing-map/som/server"
+)
+
+func get(t *testing.T, url string) (*http.Response, string) {
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return resp, string(body)
+}
+
+func TestTrainingServerLearnsPostedVectors(t *testing.T) {
+	sm := som.New(2, 2)
+	sm.Initializer = &som.RandWeightsInitializer{}
+	s := &server.TrainingServer{Trainer: &som.OnlineTrainer{SOM: sm, Warmup: 2}}
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	if _, body := get(t, ts.URL+"/"); !strings.Contains(body, "Warming up") {
+		t.Fatalf("Expected the dashboard to report warming up, got\n%s", body)
+	}
+	resp, err := http.Post(ts.URL+"/vectors", "application/json",
+		strings.NewReader(`{"vectors": [[0, 0], [1, 1], [0.5, 0.5], [1]]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Unexpected status %s", resp.Status)
+	}
+	if _, body := get(t, ts.URL+"/metrics"); !strings.Contains(body, "som_training_iterations_total 3") ||
+		!strings.Contains(body, "som_training_vectors_rejected_total 1") {
+		t.Fatalf("Unexpected metrics\n%s", body)
+	}
+	if resp, _ := get(t, ts.URL+"/umatrix.png"); resp.Header.Get("Content-Type") != "image/png" {
+		t.Fatalf("Expected the U-matrix image, got %s", resp.Status)
+	}
+	if resp, _ := get(t, ts.URL+"/umatrix.png?colormap=viridis&legend=true"); resp.Header.Get("Content-Type") != "image/png" {
+		t.Fatalf("Expected the viridis U-matrix image, got %s", resp.Status)
+	}
+	if resp, _ := get(t, ts.URL+"/umatrix.png?colormap=rainbow"); resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Expected unknown color map to be rejected, got %s", resp.Status)
+	}
+	if _, body := get(t, ts.URL+"/"); !strings.Contains(body, `<img src="/umatrix.png"`) {
+		t.Fatalf("Expected the dashboard to show the U-matrix, got\n%s", body)
+	}
+
+	file := filepath.Join(t.TempDir(), "model.somb")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s.CheckpointEvery(ctx, file, time.Hour, func(err error) { t.Fatal(err) })
+	f, err := os.Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	saved, err := som.LoadBinary(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if x, y := saved.Dims(); x != 2 || y != 2 {
+		t.Fatalf("Unexpected dims of the checkpoint %dx%d", x, y)
+	}
+}
+
+func TestTrainingServerLimitsIngestedBodies(t *testing.T) {
+	sm := som.New(1, 2)
+	if err := sm.LoadCodebook([][][]float64{{{0}, {1}}}); err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(&server.TrainingServer{Trainer: &som.OnlineTrainer{SOM: sm}, MaxBodyBytes: 16})
+	defer ts.Close()
+
+	resp, err := http.Post(ts.URL+"/vectors", "application/json", strings.NewReader(`{"vectors": [[0], [1], [0.5], [0.25]]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Fatalf("Expected 413 for the large body, got %s", resp.Status)
+	}
+	if _, body := get(t, ts.URL+"/metrics"); !strings.Contains(body, "som_training_iterations_total 0") {
+		t.Fatalf("Expected the large body not to be learned\n%s", body)
+	}
+}
+
+func TestTrainingServerBuffersVectors(t *testing.T) {
+	sm := som.New(1, 2)
+	if err := sm.LoadCodebook([][][]float64{{{0}, {1}}}); err != nil {
+		t.Fatal(err)
+	}
+	buffer := som.NewIngestBuffer(2, som.OverflowDropOldest, nil)
+	trainer := &som.OnlineTrainer{SOM: sm, Drift: &som.DriftDetector{Reference: [][]int{{1, 0}}, Window: 2}}
+	s := &server.TrainingServer{Trainer: trainer, Buffer: buffer}
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	resp, err := http.Post(ts.URL+"/vectors", "application/json", strings.NewReader(`{"vectors": [[0], [1], [2]]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if _, body := get(t, ts.URL+"/metrics"); !strings.Contains(body, "som_ingest_dropped_total 1") ||
+		!strings.Contains(body, "som_training_iterations_total 0") {
+		t.Fatalf("Unexpected metrics\n%s", body)
+	}
+	if _, body := get(t, ts.URL+"/"); !strings.Contains(body, "1 of 3") {
+		t.Fatalf("Expected the dashboard to show the drops, got\n%s", body)
+	}
+
+	buffer.Close()
+	s.Trainer.Consume(buffer, nil)
+	if _, body := get(t, ts.URL+"/metrics"); !strings.Contains(body, "som_training_iterations_total 2") ||
+		!strings.Contains(body, "som_drift_divergence 1\n") || !strings.Contains(body, "som_drift_alarms_total 1") {
+		t.Fatalf("Unexpected metrics\n%s", body)
+	}
+}
+
+func TestTrainingServerReportsVectorsPushedBeforeBufferCloses(t *testing.T) {
+	sm := som.New(1, 2)
+	if err := sm.LoadCodebook([][][]float64{{{0}, {1}}}); err != nil {
+		t.Fatal(err)
+	}
+	buffer := som.NewIngestBuffer(1, som.OverflowBlock, nil)
+	ts := httptest.NewServer(&server.TrainingServer{Trainer: &som.OnlineTrainer{SOM: sm}, Buffer: buffer})
+	defer ts.Close()
+
+	type result struct {
+		status int
+		resp   server.IngestResponse
+		err    error
+	}
+	done := make(chan result)
+	go func() {
+		resp, err := http.Post(ts.URL+"/vectors", "application/json", strings.NewReader(`{"vectors": [[0], [1], [2]]}`))
+		if err != nil {
+			done <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		r := result{status: resp.StatusCode}
+		r.err = json.NewDecoder(resp.Body).Decode(&r.resp)
+		done <- r
+	}()
+	// the first vector fills the buffer, the next one blocks until it's closed
+	for buffer.Stats().Pushed == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	buffer.Close()
+
+	r := <-done
+	if r.err != nil {
+		t.Fatal(r.err)
+	}
+	if r.status != http.StatusServiceUnavailable {
+		t.Fatalf("Expected 503, got %d", r.status)
+	}
+	if r.resp.Accepted != 1 || len(r.resp.Rejected) != 2 || !strings.HasPrefix(r.resp.Rejected[0], "vector 1:") {
+		t.Fatalf("Expected the first vector accepted and the rest rejected, got %+v", r.resp)
+	}
+}
+
+func TestTrainingServerRollsBack(t *testing.T) {
+	sm := som.New(1, 2)
+	if err := sm.LoadCodebook([][][]float64{{{0}, {10}}}); err != nil {
+		t.Fatal(err)
+	}
+	trainer := &som.OnlineTrainer{SOM: sm}
+	ts := httptest.NewServer(&server.TrainingServer{Trainer: trainer})
+	defer ts.Close()
+	post := func(path, body string) int {
+		resp, err := http.Post(ts.URL+path, "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if code := post("/snapshots", `{"name": "stable"}`); code != http.StatusNoContent {
+		t.Fatalf("Unexpected status %d of taking the snapshot", code)
+	}
+	post("/vectors", `{"vectors": [[4]]}`)
+	if code := post("/rollback", `{"name": "stable"}`); code != http.StatusNoContent {
+		t.Fatalf("Unexpected status %d of the rollback", code)
+	}
+	model, _ := trainer.Model()
+	if w := model.Weights(0, 0)[0]; w != 0 {
+		t.Fatalf("Expected the rolled back weight 0, got %v", w)
+	}
+	if code := post("/rollback", `{"name": "missing"}`); code != http.StatusNotFound {
+		t.Fatalf("Expected 404 for the missing snapshot, got %d", code)
+	}
+	long := `{"name": "` + strings.Repeat("x", server.DefaultMaxBodyBytes) + `"}`
+	if code := post("/snapshots", long); code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("Expected 413 for the large snapshot request, got %d", code)
+	}
+	if code := post("/rollback", long); code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("Expected 413 for the large rollback request, got %d", code)
+	}
+	if _, body := get(t, ts.URL+"/snapshots"); strings.TrimSpace(body) != `["stable"]` {
+		t.Fatalf("Unexpected snapshots %s", body)
+	}
+}
@@ -0,0 +1,103 @@
+package som
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// ErrBadSignature is returned when a signed artifact
+// is tampered, truncated or signed with a different key.
+var ErrBadSignature = errors.New("bad signature")
+
+// signedMagic starts the artifacts written by WriteSigned.
+var signedMagic = [4]byte{'S', 'O', 'M', 'S'}
+
+const signedVersion = 1
+
+// WriteSigned writes the payload signed with HMAC-SHA256 using the given key.
+// The format is:
+//
+//	magic "SOMS", version (uint8), HMAC of the payload (32 bytes),
+//	payload length (uint64, little-endian), payload.
+//
+// An empty key is rejected with ErrInvalidConfig, since it protects nothing.
+func WriteSigned(w io.Writer, key, payload []byte) error {
+	if len(key) == 0 {
+		return fmt.Errorf("%w: signing key is empty", ErrInvalidConfig)
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(payload)
+
+	header := make([]byte, 0, 5+sha256.Size+8)
+	header = append(header, signedMagic[:]...)
+	header = append(header, signedVersion)
+	header = mac.Sum(header)
+	header = binary.LittleEndian.AppendUint64(header, uint64(len(payload)))
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	_, err := w.Write(payload)
+	return err
+}
+
+// ReadSigned reads an artifact written by WriteSigned and returns its payload,
+// if its signature is verified with the given key, ErrBadSignature is returned otherwise.
+// An empty key is rejected with ErrInvalidConfig, see WriteSigned.
+func ReadSigned(r io.Reader, key []byte) ([]byte, error) {
+	if len(key) == 0 {
+		return nil, fmt.Errorf("%w: signing key is empty", ErrInvalidConfig)
+	}
+	header := make([]byte, 5+sha256.Size+8)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, fmt.Errorf("%w: reading header: %v", ErrBadSignature, err)
+	}
+	if [4]byte(header[:4]) != signedMagic || header[4] != signedVersion {
+		return nil, fmt.Errorf("%w: not a signed artifact", ErrBadSignature)
+	}
+	expectedMAC := header[5 : 5+sha256.Size]
+	length := binary.LittleEndian.Uint64(header[5+sha256.Size:])
+	if length > math.MaxInt64 {
+		return nil, fmt.Errorf("%w: bad payload length %d", ErrBadSignature, length)
+	}
+
+	// the payload is copied rather than allocated upfront,
+	// so a corrupted length doesn't cause a huge allocation
+	payload := &bytes.Buffer{}
+	if n, err := io.Copy(payload, io.LimitReader(r, int64(length))); err != nil {
+		return nil, err
+	} else if uint64(n) != length {
+		return nil, fmt.Errorf("%w: truncated, read %d of %d bytes", ErrBadSignature, n, length)
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write(payload.Bytes())
+	if !hmac.Equal(mac.Sum(nil), expectedMAC) {
+		return nil, fmt.Errorf("%w: signature mismatch", ErrBadSignature)
+	}
+	return payload.Bytes(), nil
+}
+
+// SaveBinarySigned saves this SOM like SaveBinary does, signing it with the given key.
+func (som *SOM) SaveBinarySigned(w io.Writer, precision Precision, key []byte) error {
+	payload := &bytes.Buffer{}
+	if err := som.SaveBinary(payload, precision); err != nil {
+		return err
+	}
+	return WriteSigned(w, key, payload.Bytes())
+}
+
+// LoadBinarySigned loads a map saved by SaveBinarySigned,
+// the map is rejected with ErrBadSignature if its signature is not verified with the given key.
+func LoadBinarySigned(r io.Reader, key []byte) (*SOM, error) {
+	payload, err := ReadSigned(r, key)
+	if err != nil {
+		return nil, err
+	}
+	return LoadBinary(bytes.NewReader(payload))
+}
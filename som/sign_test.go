@@ -0,0 +1,64 @@
+package som_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+func TestSignedModelRoundTrip(t *testing.T) {
+	key := []byte("secret")
+	buf := &bytes.Buffer{}
+	if err := savableSOM().SaveBinarySigned(buf, som.Precision{}, key); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := som.LoadBinarySigned(buf, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkSlicesEqual(t, loaded.Neurons[1][1].Weights, []float64{0.5, -0.25})
+}
+
+func TestSignedModelRejectsTamperedArtifacts(t *testing.T) {
+	key := []byte("secret")
+	buf := &bytes.Buffer{}
+	savableSOM().SaveBinarySigned(buf, som.Precision{}, key)
+	signed := buf.Bytes()
+
+	tampered := append([]byte{}, signed...)
+	tampered[len(tampered)-20] ^= 1
+
+	cases := []struct {
+		name string
+		data []byte
+		key  []byte
+	}{
+		{"tampered", tampered, key},
+		{"truncated", signed[:len(signed)-1], key},
+		{"wrong key", signed, []byte("other")},
+		{"unsigned", signed[45:], key},
+	}
+	for _, aCase := range cases {
+		if _, err := som.LoadBinarySigned(bytes.NewReader(aCase.data), aCase.key); !errors.Is(err, som.ErrBadSignature) {
+			t.Fatalf("%s: expected ErrBadSignature, got %v", aCase.name, err)
+		}
+	}
+}
+
+func TestSignedArtifactsRejectEmptyKey(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := som.WriteSigned(buf, nil, []byte("payload")); !errors.Is(err, som.ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig writing with the empty key, got %v", err)
+	}
+	assertEq(t, buf.Len(), 0)
+
+	if err := som.WriteSigned(buf, []byte("secret"), []byte("payload")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := som.ReadSigned(buf, []byte{}); !errors.Is(err, som.ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig reading with the empty key, got %v", err)
+	}
+}
@@ -0,0 +1,41 @@
+package som
+
+import "sync/atomic"
+
+// SnapshotPublisher makes the map readable while it learns: every Every
+// iterations (epochs for LearnBatch) and at the end of learning it publishes
+// an immutable Model of the map, which other goroutines read by Latest, e.g.
+// to serve or to render the codebook, instead of reading half-updated weights.
+// The snapshots are published at iteration boundaries and swapped atomically,
+// so readers never block learning and always see a consistent codebook.
+// Set it to SOM.Snapshots to enable publishing.
+type SnapshotPublisher struct {
+	// Every is the number of iterations between snapshots, <= 0 means 1000.
+	// Each snapshot copies the codebook, so frequent snapshots slow learning down.
+	Every int
+
+	latest atomic.Value
+}
+
+// Latest returns the last published snapshot, nil if there's none yet.
+// Latest is safe for concurrent use.
+func (p *SnapshotPublisher) Latest() *Model {
+	model, _ := p.latest.Load().(*Model)
+	return model
+}
+
+// due returns true if a snapshot is due after the given number of completed iterations.
+func (p *SnapshotPublisher) due(completed int) bool {
+	every := p.Every
+	if every <= 0 {
+		every = 1000
+	}
+	return completed%every == 0
+}
+
+// publish publishes the snapshot of the map.
+func (p *SnapshotPublisher) publish(som *SOM) {
+	if model, err := som.Model(); err == nil {
+		p.latest.Store(model)
+	}
+}
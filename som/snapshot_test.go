@@ -0,0 +1,57 @@
+package som_test
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+func TestSnapshotsAreReadableWhileLearning(t *testing.T) {
+	sm := som.New(5, 5)
+	sm.Initializer = &som.RandWeightsInitializer{Rand: rand.New(rand.NewSource(1))}
+	sm.Selector = &som.RandSelector{Rand: rand.New(rand.NewSource(2))}
+	sm.Influence = &som.GaussianExpDecayInfluenceFunc{InitialWidth: 2}
+	sm.Snapshots = &som.SnapshotPublisher{Every: 10}
+	if sm.Snapshots.Latest() != nil {
+		t.Fatal("Expected no snapshot before learning")
+	}
+
+	done := make(chan struct{})
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	var first *som.Model
+	var firstWeights [][][]float64
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			if model := sm.Snapshots.Latest(); model != nil {
+				if first == nil {
+					first, firstWeights = model, model.Codebook()
+				}
+				model.UMatrix()
+			}
+		}
+	}()
+	if err := sm.Learn(epochsDataSet(), 2000); err != nil {
+		t.Fatal(err)
+	}
+	close(done)
+	wg.Wait()
+
+	if first != nil {
+		for x, column := range first.Codebook() {
+			for y := range column {
+				checkSlicesEqual(t, column[y], firstWeights[x][y])
+			}
+		}
+	}
+	latest := sm.Snapshots.Latest()
+	checkSlicesEqual(t, latest.Weights(2, 2), sm.Neurons[2][2].Weights)
+}
@@ -7,14 +7,22 @@ package som
 
 import (
 	"errors"
+	"fmt"
 	"math"
 	"math/rand"
+	"time"
+
+	"github.com/voievodin/self-organizing-map/som/vec"
 )
 
 var (
 	// ErrNoDataLeft is returned by selector when there is
 	// nothing to select from the corresponding data set.
 	ErrNoDataLeft = errors.New("no data left")
+
+	// ErrInvalidConfig is returned when SOM or its components
+	// are configured with invalid parameters.
+	ErrInvalidConfig = errors.New("invalid config")
 )
 
 // RestraintFunc calculates learning restraint coefficient
@@ -33,6 +41,29 @@ type InfluenceFunc interface {
 	Apply(bmu *Neuron, currentIt, iterationsNumber, x, y int) float64
 }
 
+// RadiusReporter is implemented by influence functions which
+// have a notion of neighbourhood radius.
+type RadiusReporter interface {
+	// EffectiveRadius returns the neighbourhood radius at the given iteration.
+	// currentIt => [0, iterationsNumber)
+	EffectiveRadius(currentIt, iterationsNumber int) float64
+}
+
+// DistanceInfluenceFunc is implemented by influence functions which depend
+// only on the distance between the BMU and the neuron, so they suit the maps
+// of any topology, including MetricTopology.
+type DistanceInfluenceFunc interface {
+	// ApplyDistance returns the coefficient of the neuron at the distance d from the BMU.
+	// currentIt => [0, iterationsNumber)
+	ApplyDistance(d float64, currentIt, iterationsNumber int) float64
+}
+
+// ParamsValidator is implemented by components which can check
+// their parameters, returned errors wrap ErrInvalidConfig.
+type ParamsValidator interface {
+	Validate() error
+}
+
 // DistanceFunc calculates Distance between two points
 // represented as float vectors.
 type DistanceFunc interface {
@@ -50,6 +81,13 @@ type Selector interface {
 	Next() (DataVector, error)
 }
 
+// IndexReporter is implemented by selectors which know the index
+// of the last selected vector in the data set, it's used to describe
+// learning failures, see TrainingError.
+type IndexReporter interface {
+	LastIndex() int
+}
+
 // NeuronsInitializer initializes neurons, for example sets
 // initial values of weights. Called within Learn func before anything else.
 type NeuronsInitializer interface {
@@ -68,6 +106,15 @@ type DataAdapter interface {
 	Adapt(vector []float64) []float64
 }
 
+// InvertibleDataAdapter is a DataAdapter which can also map
+// adapted vectors back, e.g. to interpret neurons weights.
+type InvertibleDataAdapter interface {
+	DataAdapter
+
+	// Inverse does the reverse of Adapt.
+	Inverse(vector []float64) []float64
+}
+
 // DataAdapterFunc is an adapter that allows to use
 // regular functions as DataAdapters.
 type DataAdapterFunc func(vector []float64) []float64
@@ -78,9 +125,19 @@ func (f DataAdapterFunc) Adapt(vector []float64) []float64 { return f(vector) }
 // One neuron manages number of weights equal to the number of input vector elements(data set width).
 // Each neuron is indexed and has its unique place in a map.
 type Neuron struct {
-	Weights  []float64
+	Weights []float64
+
+	// Distance is the distance to the vector passed to the last Test call.
+	//
+	// Deprecated: the field is shared mutable state and is only set by Test,
+	// use SOM.TestDistances or SOM.ComputeDistances with a caller-owned DistanceField instead.
 	Distance float64
-	X, Y     int
+
+	X, Y int
+
+	// Frozen neurons keep their weights while learning,
+	// though they still can be BMU, see AnchorInitializer.
+	Frozen bool
 }
 
 // New creates new 2 dimensional X*Y size SOM.
@@ -102,12 +159,19 @@ func New(X, Y int) *SOM {
 		Distance:      &EuclideanDistanceFunc{},
 		Monitor:       &NoOpProgressMonitor{},
 		InDataAdapter: &NoOpAdapter{},
+		Events:        &NoOpEventListener{},
+		Topology:      &PlanarTopology{},
+		TieBreaker:    &RandTieBreaker{},
+		Logger:        &NoOpLogger{},
 	}
 }
 
 // SOM is a map itself.
 // Currently it carries double dimension array of neurons,
 // provides ability to teach the map and then use results.
+// SOM is mutable and is not safe for concurrent use, it holds both the
+// learning components and the inference state, Model is the immutable
+// map it produces for inference, see SOM.Model.
 type SOM struct {
 	Neurons [][]*Neuron
 
@@ -118,59 +182,386 @@ type SOM struct {
 	Distance      DistanceFunc
 	Monitor       ProgressMonitor
 	InDataAdapter DataAdapter
+
+	// Events receives events emitted while learning, e.g. IterationEvent,
+	// use EventBus to pass events to several listeners.
+	Events EventListener
+
+	// Topology defines how the edges of the map are connected,
+	// influence functions receive the BMU image which is the closest to
+	// the neuron in this topology, see Topology.Closest. The neurons of
+	// MetricTopology are influenced by the distance from the BMU instead,
+	// see DistanceInfluenceFunc.
+	Topology Topology
+
+	// TieBreaker chooses BMU when several neurons are equally
+	// distant from the input vector, random one by default.
+	TieBreaker TieBreaker
+
+	// Guard, if set, checks neurons weights for NaN and Inf values while learning.
+	Guard *NumericGuard
+
+	// Mask makes the map non-rectangular, Mask[x][y] == true excludes
+	// the neuron at (x, y) from the map: it is never BMU, its weights
+	// are never fixed, and its distance to any vector is +Inf.
+	// Renderers should skip masked neurons, see IsMasked.
+	// A nil Mask means all the neurons are in the map.
+	Mask [][]bool
+
+	// Logger receives messages about learning progress and failures.
+	Logger Logger
+
+	// Incremental, if set, makes Learn recompute only the distances
+	// to the changed neurons, see IncrementalDistances.
+	Incremental *IncrementalDistances
+
+	// Profile, if set, records the time learning spends in each phase.
+	Profile *PhaseProfile
+
+	// KernelCache caches neighbourhood kernels of LearnBatch,
+	// nil means that each LearnBatch call has its own cache.
+	KernelCache *KernelCache
+
+	// BatchShards is the number of shards the data set is split into
+	// by LearnBatch, the shards are mapped by parallel workers,
+	// <= 1 means that the data set is mapped sequentially.
+	BatchShards int
+
+	// Watchdog, if set, reports non-fatal anomalies found while learning.
+	Watchdog *Watchdog
+
+	// Snapshots, if set, publishes snapshots of the map while it learns,
+	// so they can be read concurrently, see SnapshotPublisher.
+	Snapshots *SnapshotPublisher
+
+	// Journal, if set, logs the updates of the codebook while the map
+	// learns by Learn or online, so they can be audited, see Journal.
+	Journal *Journal
+
+	// Renormalize, if true, rescales the weights of each neuron to unit length
+	// after learning updates them, so the codebook stays on the unit sphere,
+	// which CosineDistanceFunc assumes: without it the updates shrink the
+	// weights towards the origin and the map slowly degenerates.
+	// The vectors are expected to be normalized too, see UnitNormAdapter.
+	Renormalize bool
+
+	// SkipUndescribed, if true, makes SaveJSON and SaveBinary skip the topology,
+	// the distance function or the input adapter which doesn't implement
+	// Describer, logging a warning, so the loaded map uses the default one
+	// and may map the vectors differently. Otherwise saving such a map fails.
+	SkipUndescribed bool
+
+	// state is updated by Learn, see TrainingState
+	state TrainingState
+
+	// distances is a buffer reused by Learn
+	distances DistanceField
 }
 
 // Learn does learning of this SOM from the given data set,
 // making as many iterations as iterationsNumber value is.
-func (som *SOM) Learn(set *DataSet, iterationsNumber int) {
+// Learning stops earlier if the selector has no data left.
+// Returns an error if the selector fails or if Guard aborts the learning,
+// and ErrInvalidConfig if a component implementing ParamsValidator fails
+// validation. Vectors which don't fit the neurons weights and panics of the components
+// are reported as *TrainingError wrapping ErrWidthMismatch and
+// ErrTrainingPanic respectively.
+func (som *SOM) Learn(set *DataSet, iterationsNumber int) error {
+	som.log(LogInfo, "learning started", "iterations", iterationsNumber, "vectors", set.Len())
+	started := time.Now()
+	it, err := som.learn(set, iterationsNumber, iterationsNumber, nil, nil)
+	return som.learned(it, started, err)
+}
+
+// validateComponents validates the components implementing ParamsValidator,
+// so the components set directly are checked like the ones created by the
+// registry are, checks that Mask fits the map and leaves some neurons in it,
+// and that the influence function suits MetricTopology.
+// The errors are wrapped in ErrInvalidConfig.
+func (som *SOM) validateComponents() error {
+	if err := som.validateMask(); err != nil {
+		return err
+	}
+	components := []interface{}{som.Initializer, som.Selector, som.Restraint, som.Influence,
+		som.Distance, som.InDataAdapter, som.Topology, som.TieBreaker}
+	for _, component := range components {
+		validator, ok := component.(ParamsValidator)
+		if !ok {
+			continue
+		}
+		err := validator.Validate()
+		switch {
+		case err == nil:
+		case errors.Is(err, ErrInvalidConfig):
+			return fmt.Errorf("%T: %w", component, err)
+		default:
+			return fmt.Errorf("%w: %T: %v", ErrInvalidConfig, component, err)
+		}
+	}
+	if _, ok := som.Topology.(MetricTopology); ok {
+		if _, ok := som.Influence.(DistanceInfluenceFunc); !ok {
+			return fmt.Errorf("%w: %T needs an influence function implementing DistanceInfluenceFunc, got %T", ErrInvalidConfig, som.Topology, som.Influence)
+		}
+	}
+	if sized, ok := som.Topology.(sizeValidator); ok {
+		xLen, yLen := som.Dims()
+		return sized.validateSize(xLen, yLen)
+	}
+	return nil
+}
+
+// validateMask returns ErrInvalidConfig if Mask doesn't fit the map
+// or masks all the neurons.
+func (som *SOM) validateMask() error {
+	if som.Mask == nil {
+		return nil
+	}
+	xLen, yLen := som.Dims()
+	if !fitsGrid(som.Mask, xLen, yLen) {
+		return fmt.Errorf("%w: mask doesn't fit %dx%d map", ErrInvalidConfig, xLen, yLen)
+	}
+	for i := range som.Mask {
+		for _, masked := range som.Mask[i] {
+			if !masked {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("%w: all the neurons are masked", ErrInvalidConfig)
+}
+
+// learned records the result of learning, which took it iterations.
+func (som *SOM) learned(it int, started time.Time, err error) error {
+	if err != nil {
+		som.log(LogError, "learning failed", "iteration", it, "error", err)
+		return err
+	}
+	som.state.Iterations += it
+	som.state.Width = len(som.Neurons[0][0].Weights)
+	som.state.TrainedAt = time.Now()
+	if som.Snapshots != nil {
+		som.Snapshots.publish(som)
+	}
+	som.log(LogInfo, "learning finished", "iterations", it, "duration", time.Since(started))
+	if som.Profile != nil {
+		som.log(LogDebug, "learning phases",
+			"distance", som.Profile.Duration(PhaseDistance),
+			"bmu", som.Profile.Duration(PhaseBMU),
+			"update", som.Profile.Duration(PhaseUpdate),
+			"monitor", som.Profile.Duration(PhaseMonitor))
+	}
+	return nil
+}
+
+// validateInit checks that the initializer fits the map and the data set,
+// if it implements initValidator.
+func (som *SOM) validateInit(set *DataSet) error {
+	if validator, ok := som.Initializer.(initValidator); ok {
+		xLen, yLen := som.Dims()
+		return validator.validateInit(set, xLen, yLen)
+	}
+	return nil
+}
+
+// learn does Learn and returns the number of completed iterations.
+// The iterations are divided into epochs of epochLen iterations, the selector
+// is initialized before each epoch and afterEpoch, if not nil,
+// is called with the 1-based number of each completed epoch.
+// The budget, if not nil, re-estimates the number of iterations while learning.
+// The selected vectors are adapted by copies, so the data set is not modified
+// when it is passed many times. Returns ErrInvalidConfig if epochLen is not
+// positive or a component fails validation, see validateComponents
+// and validateInit.
+func (som *SOM) learn(set *DataSet, iterationsNumber, epochLen int, afterEpoch func(epoch int) error, budget *timeBudget) (it int, err error) {
+	if iterationsNumber > 0 && epochLen <= 0 {
+		return 0, fmt.Errorf("%w: epoch length must be positive, got %d", ErrInvalidConfig, epochLen)
+	}
+	if err := som.validateComponents(); err != nil {
+		return 0, err
+	}
+	if err := som.validateInit(set); err != nil {
+		return 0, err
+	}
+	var vector, adapted DataVector
+	som.Profile.start()
+	defer func() {
+		som.Profile.stop(it)
+		if r := recover(); r != nil {
+			err = som.panicError(it, vector, r)
+		}
+	}()
+
 	som.Initializer.Init(set, som.Neurons)
-	som.Selector.Init(set)
-	for it := 0; it < iterationsNumber; it++ {
-		vector, err := som.Selector.Next()
-		if err != nil {
+	if err := som.startLearning(0); err != nil {
+		return it, err
+	}
+	for ; it < iterationsNumber; it++ {
+		if budget != nil {
+			if iterationsNumber = budget.estimate(it, iterationsNumber); it >= iterationsNumber {
+				break
+			}
+		}
+		if it%epochLen == 0 {
+			som.Selector.Init(set)
+		}
+		vector, err = som.Selector.Next()
+		if err == ErrNoDataLeft {
 			break
 		}
-		vector = som.InDataAdapter.Adapt(vector)
+		if err != nil {
+			return it, err
+		}
+		adapted = som.InDataAdapter.Adapt(append(adapted[:0], vector...))
+		if _, err := som.iterate(it, it, iterationsNumber, adapted, true); err != nil {
+			return it, err
+		}
 
-		som.computeDistance(vector)
-		bmu := som.findBMU()
-		som.fixWeights(it, iterationsNumber, bmu, vector)
+		if afterEpoch != nil && (it+1)%epochLen == 0 {
+			if err := afterEpoch((it + 1) / epochLen); err != nil {
+				return it + 1, err
+			}
+		}
+	}
+	return it, nil
+}
 
-		som.Monitor.ItCompleted(it+1, iterationsNumber, som)
+// startLearning resets the state of the learning components and journals
+// the codebook, called once the neurons are initialized, before the first
+// iteration, it is the number of the iterations completed before.
+func (som *SOM) startLearning(it int) error {
+	if som.Guard != nil {
+		som.Guard.start(som.Neurons)
 	}
+	if som.Incremental != nil {
+		som.Incremental.reset(som.Neurons, 0)
+	}
+	if som.Watchdog != nil {
+		som.Watchdog.start(som.Neurons)
+	}
+	if som.Journal != nil {
+		return som.Journal.start(som, it)
+	}
+	return nil
+}
+
+// iterate does the iteration it of learning the adapted vector,
+// t of T is the position of the iteration in the learning schedule,
+// which is it of the iterations number for Learn, see OnlineTrainer.
+// Selected reports whether the vector is selected from the data set by
+// Selector, only then its distances are cached by Incremental, since
+// the cache is keyed by the index of the vector in the data set.
+// Returns the BMU of the vector.
+func (som *SOM) iterate(it, t, T int, vector DataVector, selected bool) (*Neuron, error) {
+	if width := len(som.Neurons[0][0].Weights); len(vector) != width {
+		mismatch := fmt.Errorf("%w: vector length is %d, weights length is %d", ErrWidthMismatch, len(vector), width)
+		return nil, som.trainingError(it, vector, mismatch)
+	}
+
+	mark := som.Profile.enter(PhaseDistance)
+	if som.Incremental != nil && selected {
+		som.distances = som.Incremental.distances(som, it, vector)
+	} else {
+		som.distances = som.computeDistances(vector, som.distances)
+	}
+	som.Profile.leave(PhaseDistance, mark)
+
+	mark = som.Profile.enter(PhaseBMU)
+	bmu := som.bmu(som.distances)
+	if observer, ok := som.TieBreaker.(WinObserver); ok {
+		observer.Won(bmu, it)
+	}
+	if observer, ok := som.Influence.(QuantizationObserver); ok {
+		observer.ObserveQuantization(it, som.distances[bmu.X][bmu.Y])
+	}
+	som.Profile.leave(PhaseBMU, mark)
+
+	mark = som.Profile.enter(PhaseUpdate)
+	weightsDelta := som.fixWeights(it, t, T, bmu, vector)
+	// the guard checks the weights first, so the journal
+	// records the restored codebook instead of the updates
+	rolledBack := false
+	if som.Guard != nil {
+		var err error
+		if rolledBack, err = som.Guard.check(it+1, som); err != nil {
+			return nil, err
+		}
+	}
+	if som.Journal != nil && !rolledBack {
+		if err := som.Journal.commit(it, bmu, vector, som.Renormalize); err != nil {
+			return nil, som.trainingError(it, vector, err)
+		}
+	}
+	som.Profile.leave(PhaseUpdate, mark)
+
+	mark = som.Profile.enter(PhaseMonitor)
+	if som.listening() {
+		som.Events.OnEvent(som.iterationEvent(t, T, bmu, weightsDelta))
+	}
+	if som.Watchdog != nil {
+		som.Watchdog.check(som, it, T, bmu.X, bmu.Y)
+	}
+	if som.Snapshots != nil && som.Snapshots.due(it+1) {
+		som.Snapshots.publish(som)
+	}
+	som.Monitor.ItCompleted(t+1, T, som)
+	som.Profile.leave(PhaseMonitor, mark)
+	return bmu, nil
 }
 
 // LearnEntire does learning of this SOM from the given
 // data set, making as many iterations as data set length is.
-func (som *SOM) LearnEntire(dataSet *DataSet) {
-	som.Learn(dataSet, dataSet.Len())
+func (som *SOM) LearnEntire(dataSet *DataSet) error {
+	return som.Learn(dataSet, dataSet.Len())
 }
 
 // Test finds BMU (Neuron) and returns it.
 // Note that this func DOES CHANGE the values of neuron.Distance props,
 // so they become equal to the distance between the given vector
-// and corresponding neurons.
+// and corresponding neurons. Prefer TestDistances, which doesn't.
+// It panics with ErrNotTrained or ErrWidthMismatch, see FindBMU.
 func (som *SOM) Test(vector DataVector) *Neuron {
-	som.computeDistance(som.InDataAdapter.Adapt(vector))
-	return som.findBMU()
+	field := som.borrowField()
+	defer releaseField(field)
+	distances := som.computeDistances(som.mustAdaptInput(vector), *field)
+	for i := 0; i < len(som.Neurons); i++ {
+		for j := 0; j < len(som.Neurons[i]); j++ {
+			som.Neurons[i][j].Distance = distances[i][j]
+		}
+	}
+	return som.bmu(distances)
+}
+
+// TestDistances finds BMU (Neuron) for the given vector, like Test does,
+// but computes distances into the given field instead of neuron.Distance props.
+// The field is reused if it matches the map size, otherwise a new one is allocated,
+// the field holding computed distances is returned along with the BMU.
+// It panics like Test does, FindBMU returns the errors instead.
+func (som *SOM) TestDistances(vector DataVector, field DistanceField) (*Neuron, DistanceField) {
+	field = som.computeDistances(som.mustAdaptInput(vector), field)
+	return som.bmu(field), field
+}
+
+// ComputeDistances computes distance from the given vector to each neuron
+// into the given field, which is reused if it matches the map size,
+// otherwise a new one is allocated. Returns the field holding computed distances.
+// Note that this func:
+//   - DOES NOT CHANGE the values of neuron.Distance props;
+//   - ADAPTS input vector using som.InDataAdapter;
+//   - panics like Test does, DistancesTo returns the errors instead.
+func (som *SOM) ComputeDistances(vector DataVector, field DistanceField) DistanceField {
+	return som.computeDistances(som.mustAdaptInput(vector), field)
 }
 
 // ComputeDistanceMatrix computes distance from the given vector
 // to each neuron and returns a matrix of such values.
 // The value at position (x, y) is a distance to the neuron at position (x, y).
+// The matrix is allocated by each call, use ComputeDistances to reuse it.
 // Note that this func:
 //   - DOES NOT CHANGE the values of neuron.Distance props;
-//   - ADAPTS input vector using som.InDataAdapter.
+//   - ADAPTS input vector using som.InDataAdapter;
+//   - panics like Test does, DistancesTo returns the errors instead.
 func (som *SOM) ComputeDistanceMatrix(vector DataVector) [][]float64 {
-	vector = som.InDataAdapter.Adapt(vector)
-	distances := make([][]float64, len(som.Neurons))
-	for i := 0; i < len(som.Neurons); i++ {
-		distances[i] = make([]float64, len(som.Neurons[i]))
-		for j := 0; j < len(som.Neurons[i]); j++ {
-			distances[i][j] = som.Distance.Apply(vector, som.Neurons[i][j].Weights)
-		}
-	}
-	return distances
+	return som.ComputeDistances(vector, nil)
 }
 
 // SeparateWeights creates and returns N matrices of neurons weights.
@@ -187,71 +578,132 @@ func (som *SOM) ComputeDistanceMatrix(vector DataVector) [][]float64 {
 //	result[0]:   result[1]:
 //	   [ 1 3 ]         [ 2 4 ]
 //	   [ 5 7 ]         [ 6 8 ]
+//
+// Use SeparateWeightsInto to reuse the matrices.
 func (som *SOM) SeparateWeights() [][][]float64 {
-	separations := make([][][]float64, len(som.Neurons[0][0].Weights))
-	for si := 0; si < len(separations); si++ {
-		separations[si] = make([][]float64, len(som.Neurons))
-		for i := 0; i < len(separations[si]); i++ {
-			separations[si][i] = make([]float64, len(som.Neurons[i]))
-			for j := 0; j < len(separations[si][i]); j++ {
-				separations[si][i][j] = som.Neurons[i][j].Weights[si]
-			}
-		}
-	}
-	return separations
+	return som.SeparateWeightsInto(nil)
 }
 
-func (som *SOM) computeDistance(vector DataVector) {
-	for i := 0; i < len(som.Neurons); i++ {
-		for j := 0; j < len(som.Neurons[i]); j++ {
-			som.Neurons[i][j].Distance = som.Distance.Apply(vector, som.Neurons[i][j].Weights)
-		}
+func (som *SOM) computeDistances(vector DataVector, field DistanceField) DistanceField {
+	if !field.fits(som.Neurons) {
+		field = NewDistanceField(som.Neurons)
 	}
-}
-
-func (som *SOM) findBMU() *Neuron {
-	bmu := som.Neurons[0][0]
-	minDistance := bmu.Distance
-	candidatesCount := 1
 	for i := 0; i < len(som.Neurons); i++ {
 		for j := 0; j < len(som.Neurons[i]); j++ {
-			candidate := som.Neurons[i][j]
-			if minDistance > candidate.Distance {
-				bmu = candidate
-				minDistance = bmu.Distance
-				candidatesCount = 1
-			} else if minDistance == candidate.Distance {
-				candidatesCount++
+			if som.IsMasked(i, j) {
+				field[i][j] = math.Inf(1)
+			} else {
+				field[i][j] = som.Distance.Apply(vector, som.Neurons[i][j].Weights)
 			}
 		}
 	}
+	return field
+}
+
+// IsMasked returns true if the neuron at (x, y) is excluded from the map by Mask.
+func (som *SOM) IsMasked(x, y int) bool {
+	return som.Mask != nil && som.Mask[x][y]
+}
 
-	if candidatesCount == 1 {
-		return bmu
+func (som *SOM) bmu(field DistanceField) *Neuron {
+	min, x, y, count := field.minimum()
+	if count <= 1 {
+		return som.Neurons[x][y]
 	}
 
-	candidates := make([]*Neuron, 0, 2)
-	for i := 0; i < len(som.Neurons); i++ {
-		for j := 0; j < len(som.Neurons[i]); j++ {
-			if minDistance == som.Neurons[i][j].Distance {
+	candidates := make([]*Neuron, 0, count)
+	for i := 0; i < len(field); i++ {
+		for j := 0; j < len(field[i]); j++ {
+			if field[i][j] == min {
 				candidates = append(candidates, som.Neurons[i][j])
 			}
 		}
 	}
-
-	return candidates[rand.Intn(len(candidates))]
+	if som.TieBreaker == nil {
+		return (&RandTieBreaker{}).Break(candidates)
+	}
+	return som.TieBreaker.Break(candidates)
 }
 
-func (som *SOM) fixWeights(t, T int, bmu *Neuron, input DataVector) {
+// fixWeights moves neurons weights towards the input vector
+// and returns the sum of absolute weights changes.
+func (som *SOM) fixWeights(it, t, T int, bmu *Neuron, input DataVector) float64 {
+	delta := 0.0
+	image := *bmu
+	xLen, yLen := len(som.Neurons), len(som.Neurons[0])
+	metric, isMetric := som.Topology.(MetricTopology)
 	for i := 0; i < len(som.Neurons); i++ {
 		for j := 0; j < len(som.Neurons[i]); j++ {
 			neuron := som.Neurons[i][j]
-			for k := 0; k < len(neuron.Weights); k++ {
-				cof := som.Restraint.Apply(t, T) * som.Influence.Apply(bmu, t, T, i, j)
-				neuron.Weights[k] += cof * (input[k] - neuron.Weights[k])
+			if neuron.Frozen || som.IsMasked(i, j) {
+				continue
+			}
+			var influence float64
+			if isMetric {
+				d := metric.Distance(bmu.X, bmu.Y, i, j, xLen, yLen)
+				influence = som.Influence.(DistanceInfluenceFunc).ApplyDistance(d, t, T)
+			} else {
+				image.X, image.Y = som.Topology.Closest(bmu.X, bmu.Y, i, j, xLen, yLen)
+				influence = som.Influence.Apply(&image, t, T, i, j)
 			}
+			delta += som.moveWeights(i, j, it, som.Restraint.Apply(t, T)*influence, input)
 		}
 	}
+	return delta
+}
+
+// moveWeights moves the weights of the neuron at (i, j) toward the target
+// by the coefficient at the iteration it, rescales them to unit length if
+// Renormalize is set, and records the update in the incremental distances and
+// the journal. Returns the sum of the absolute changes of the weights.
+func (som *SOM) moveWeights(i, j, it int, cof float64, target DataVector) float64 {
+	weights := som.Neurons[i][j].Weights
+	delta := 0.0
+	for k := range weights {
+		change := cof * (target[k] - weights[k])
+		weights[k] += change
+		delta += math.Abs(change)
+	}
+	if som.Renormalize && cof != 0 {
+		delta += normalize(weights)
+	}
+	if som.Incremental != nil {
+		som.Incremental.changed(i, j, it, delta)
+	}
+	if som.Journal != nil {
+		som.Journal.update(i, j, cof, delta)
+	}
+	return delta
+}
+
+// listening returns false when nobody listens to the events,
+// so there is no need to compute them.
+func (som *SOM) listening() bool {
+	_, noOp := som.Events.(*NoOpEventListener)
+	return som.Events != nil && !noOp
+}
+
+func (som *SOM) iterationEvent(t, T int, bmu *Neuron, weightsDelta float64) *IterationEvent {
+	radius := math.NaN()
+	if reporter, ok := som.Influence.(RadiusReporter); ok {
+		radius = reporter.EffectiveRadius(t, T)
+	}
+	weightsNum := 0
+	for i := range som.Neurons {
+		for j := range som.Neurons[i] {
+			weightsNum += len(som.Neurons[i][j].Weights)
+		}
+	}
+	return &IterationEvent{
+		It:              t + 1,
+		ItNum:           T,
+		LearningRate:    som.Restraint.Apply(t, T),
+		Radius:          radius,
+		BMUX:            bmu.X,
+		BMUY:            bmu.Y,
+		BMUDistance:     som.distances[bmu.X][bmu.Y],
+		MeanWeightDelta: weightsDelta / float64(weightsNum),
+	}
 }
 
 type EuclideanDistanceFunc struct{}
@@ -264,6 +716,56 @@ func (ed *EuclideanDistanceFunc) Apply(xVector, yVector []float64) float64 {
 	return math.Sqrt(sum)
 }
 
+func (ed *EuclideanDistanceFunc) Describe() ComponentSpec { return ComponentSpec{Type: "euclidean"} }
+
+// CosineDistanceFunc is 1 minus the cosine similarity of the vectors, which
+// compares their directions regardless of their lengths, e.g. of text
+// embeddings. It is 1 if either vector is zero. Maps learning by the cosine
+// distance should keep their weights normalized, see SOM.Renormalize.
+type CosineDistanceFunc struct{}
+
+func (cd *CosineDistanceFunc) Apply(xVector, yVector []float64) float64 {
+	var dot, xx, yy float64
+	for i := 0; i < len(xVector); i++ {
+		dot += xVector[i] * yVector[i]
+		xx += xVector[i] * xVector[i]
+		yy += yVector[i] * yVector[i]
+	}
+	if xx == 0 || yy == 0 {
+		return 1
+	}
+	return 1 - dot/math.Sqrt(xx*yy)
+}
+
+func (cd *CosineDistanceFunc) Describe() ComponentSpec { return ComponentSpec{Type: "cosine"} }
+
+// normalize rescales the vector to unit length, unless it's zero,
+// and returns the sum of absolute changes of its values.
+func normalize(vector []float64) float64 {
+	norm := vec.Norm(vector)
+	if norm == 0 || norm == 1 {
+		return 0
+	}
+	delta := 0.0
+	for k, v := range vector {
+		vector[k] = v / norm
+		delta += math.Abs(vector[k] - v)
+	}
+	return delta
+}
+
+// UnitNormAdapter rescales vectors to unit length, zero vectors are
+// kept as they are, e.g. for learning by CosineDistanceFunc.
+// Note that the original vector is modified.
+type UnitNormAdapter struct{}
+
+func (adapter *UnitNormAdapter) Adapt(vector []float64) []float64 {
+	normalize(vector)
+	return vector
+}
+
+func (adapter *UnitNormAdapter) Describe() ComponentSpec { return ComponentSpec{Type: "unit-norm"} }
+
 // See https://en.wikipedia.org/wiki/Taxicab_geometry.
 type ManhattanDistanceFunc struct{}
 
@@ -275,6 +777,8 @@ func (md *ManhattanDistanceFunc) Apply(xVector, yVector []float64) float64 {
 	return sum
 }
 
+func (md *ManhattanDistanceFunc) Describe() ComponentSpec { return ComponentSpec{Type: "manhattan"} }
+
 // See https://en.wikipedia.org/wiki/Chebyshev_distance.
 type ChebyshevDistanceFunc struct{}
 
@@ -289,10 +793,14 @@ func (cd *ChebyshevDistanceFunc) Apply(xVector, yVector []float64) float64 {
 	return max
 }
 
+func (cd *ChebyshevDistanceFunc) Describe() ComponentSpec { return ComponentSpec{Type: "chebyshev"} }
+
 // BMUOnlyInfluencedFunc is implementation of InfluenceFunc which
 // allows modification of BMU neuron only.
 type BMUOnlyInfluencedFunc struct{}
 
+func (calc *BMUOnlyInfluencedFunc) EffectiveRadius(currentIt, iterationsNumber int) float64 { return 0 }
+
 func (calc *BMUOnlyInfluencedFunc) Apply(bmu *Neuron, currentIt, iterationsNumber, i, j int) float64 {
 	if bmu.X == i && bmu.Y == j {
 		return 1
@@ -301,6 +809,13 @@ func (calc *BMUOnlyInfluencedFunc) Apply(bmu *Neuron, currentIt, iterationsNumbe
 	}
 }
 
+func (calc *BMUOnlyInfluencedFunc) ApplyDistance(d float64, currentIt, iterationsNumber int) float64 {
+	if d == 0 {
+		return 1
+	}
+	return 0
+}
+
 // NoRestraintFunc is RestraintFunc implementation which always returns 1,
 // thus doesn't effect weights modification at all.
 type NoRestraintFunc struct{}
@@ -314,21 +829,30 @@ type SequentialSelector struct {
 
 func (sel *SequentialSelector) Init(set *DataSet) {
 	sel.set = set
+	sel.idx = 0
 }
 
 func (sel *SequentialSelector) Next() (DataVector, error) {
 	if sel.idx >= sel.set.Len() {
 		return nil, ErrNoDataLeft
 	}
-	vector := sel.set.Vectors[sel.idx]
+	vector := sel.set.At(sel.idx)
 	sel.idx++
 	return vector, nil
 }
 
+func (sel *SequentialSelector) LastIndex() int {
+	return sel.idx - 1
+}
+
 // RandSelector randomly selects a data vector from the corresponding data set,
 // the selection is infinite, thus Next() never returns error. If data set size is X
 // then X calls to Next() will return X different random vectors from the data set.
 type RandSelector struct {
+	// Rand is the source of randomness, nil means the global one.
+	// Setting a seeded source makes the selection reproducible.
+	Rand *rand.Rand
+
 	dataSet *DataSet
 	perm    []int
 	idx     int
@@ -336,19 +860,24 @@ type RandSelector struct {
 
 func (sel *RandSelector) Init(dataSet *DataSet) {
 	sel.dataSet = dataSet
-	sel.perm = rand.Perm(dataSet.Len())
+	sel.perm = randPerm(sel.Rand, dataSet.Len())
+	sel.idx = 0
 }
 
 func (sel *RandSelector) Next() (DataVector, error) {
 	if sel.idx == len(sel.perm) {
 		sel.idx = 0
-		sel.perm = rand.Perm(sel.dataSet.Len())
+		sel.perm = randPerm(sel.Rand, sel.dataSet.Len())
 	}
-	vector := sel.dataSet.Vectors[sel.perm[sel.idx]]
+	vector := sel.dataSet.At(sel.perm[sel.idx])
 	sel.idx++
 	return vector, nil
 }
 
+func (sel *RandSelector) LastIndex() int {
+	return sel.perm[sel.idx-1]
+}
+
 // ZeroValueWeightsInitializer adjusts weight arrays length based on data set width.
 type ZeroValueWeightsInitializer struct{}
 
@@ -362,7 +891,10 @@ func (initializer *ZeroValueWeightsInitializer) Init(set *DataSet, neurons [][]*
 }
 
 // RandWeightsInitializer sets weights values to small [0.0,1.0) random values.
-type RandWeightsInitializer struct{}
+type RandWeightsInitializer struct {
+	// Rand is the source of randomness, nil means the global one.
+	Rand *rand.Rand
+}
 
 func (initializer *RandWeightsInitializer) Init(set *DataSet, neurons [][]*Neuron) {
 	zeroInitializer := &ZeroValueWeightsInitializer{}
@@ -372,14 +904,17 @@ func (initializer *RandWeightsInitializer) Init(set *DataSet, neurons [][]*Neuro
 		for j := 0; j < len(neurons[i]); j++ {
 			neuron := neurons[i][j]
 			for k := 0; k < len(neuron.Weights); k++ {
-				neuron.Weights[k] = rand.Float64()
+				neuron.Weights[k] = randFloat64(initializer.Rand)
 			}
 		}
 	}
 }
 
 // RandDataSetVectorsWeightsInitializer sets weights values to random vectors from data set.
-type RandDataSetVectorsWeightsInitializer struct{}
+type RandDataSetVectorsWeightsInitializer struct {
+	// Rand is the source of randomness, nil means the global one.
+	Rand *rand.Rand
+}
 
 func (initializer *RandDataSetVectorsWeightsInitializer) Init(dataSet *DataSet, neurons [][]*Neuron) {
 	zeroInitializer := &ZeroValueWeightsInitializer{}
@@ -392,7 +927,7 @@ func (initializer *RandDataSetVectorsWeightsInitializer) Init(dataSet *DataSet,
 		dataSet.Reduce(matrixSize)
 	}
 
-	selector := &RandSelector{}
+	selector := &RandSelector{Rand: initializer.Rand}
 	selector.Init(dataSet)
 
 	for i := 0; i < len(neurons); i++ {
@@ -421,65 +956,159 @@ func (initializer *ProvidedWeightsInitializer) Init(set *DataSet, neurons [][]*N
 	}
 }
 
-// RadiusReducingConstantInfluenceFunc influences only neurons in a given radius around BMU.
-// Radius is reduced at each iteration, so the influence area becomes smaller,
-// but not smaller than r/2, so R >= influence area > R/2.
+// DefaultMinRadius is the floor of the radius of RadiusReducingConstantInfluenceFunc
+// when its MinRadius is not set, so the immediate neighbours of the BMU keep
+// learning along with it however long the map learns.
+const DefaultMinRadius = 1.0
+
+// RadiusReducingConstantInfluenceFunc influences only neurons in a given radius around BMU,
+// all of them equally. The radius is reduced at each iteration as
+// q(t) = Radius / (1 + currentIt/iterationsNumber), so it decreases from Radius
+// at the first iteration towards Radius/2, and further if the learning continues
+// past iterationsNumber, e.g. online, but it never gets smaller than the floor,
+// which is MinRadius or DefaultMinRadius if MinRadius is 0, capped by Radius.
+// So zero Radius influences the BMU only.
 type RadiusReducingConstantInfluenceFunc struct {
 	Radius float64
+
+	// MinRadius is the floor of the radius, 0 means DefaultMinRadius,
+	// the floor is capped by Radius.
+	MinRadius float64
 }
 
-func (influence *RadiusReducingConstantInfluenceFunc) Apply(bmu *Neuron, currentIt, iterationsNumber, x, y int) float64 {
-	t := float64(currentIt)
-	T := float64(iterationsNumber)
-	qt := influence.Radius / (1 + t/T)
+func (influence *RadiusReducingConstantInfluenceFunc) Validate() error {
+	return validateWidths("radius", influence.Radius, influence.MinRadius)
+}
 
-	d := math.Sqrt(math.Pow(float64(bmu.X-x), 2) + math.Pow(float64(bmu.Y-y), 2))
+func (influence *RadiusReducingConstantInfluenceFunc) EffectiveRadius(currentIt, iterationsNumber int) float64 {
+	floor := influence.MinRadius
+	if floor == 0 {
+		floor = DefaultMinRadius
+	}
+	return math.Max(influence.Radius/(1+progress(currentIt, iterationsNumber)), math.Min(floor, influence.Radius))
+}
 
-	if d > qt {
+func (influence *RadiusReducingConstantInfluenceFunc) Apply(bmu *Neuron, currentIt, iterationsNumber, x, y int) float64 {
+	return influence.ApplyDistance(gridDistance(bmu, x, y), currentIt, iterationsNumber)
+}
+
+func (influence *RadiusReducingConstantInfluenceFunc) ApplyDistance(d float64, currentIt, iterationsNumber int) float64 {
+	if d > influence.EffectiveRadius(currentIt, iterationsNumber) {
 		return 0
-	} else {
-		return 1
 	}
+	return 1
 }
 
-// Calculates influence coefficient g(t) using gaussian function
+// GaussianExpDecayInfluenceFunc calculates influence coefficient g(t) using gaussian function
 // with exp decay function to reduce neighbourhood width.
 // The calculation is done in the following way:
 // g(t) = exp( - d*d/(2*q(t)*q(t)) )
-// q(t) = InitialWidth * exp( -currentIt/iterationsNumber )
+// q(t) = max( InitialWidth * exp( -currentIt/iterationsNumber ), MinWidth )
 // d - distance from the BMU to the neuron at position (x, y)
+// The BMU itself is always fully influenced, when the width is 0
+// nothing but the BMU is influenced (the limit of the gaussian).
 type GaussianExpDecayInfluenceFunc struct {
 	// InitialWidth is the initial width of the neighbourhood.
 	InitialWidth float64
+
+	// MinWidth is the floor of the neighbourhood width, 0 means no floor.
+	MinWidth float64
+}
+
+func (f *GaussianExpDecayInfluenceFunc) Validate() error {
+	return validateWidths("width", f.InitialWidth, f.MinWidth)
+}
+
+// EffectiveRadius returns the neighbourhood width q(t).
+func (f *GaussianExpDecayInfluenceFunc) EffectiveRadius(currentIt, iterationsNumber int) float64 {
+	return math.Max(f.InitialWidth*math.Exp(-progress(currentIt, iterationsNumber)), f.MinWidth)
 }
 
 func (f *GaussianExpDecayInfluenceFunc) Apply(bmu *Neuron, currentIt, iterationsNumber, x, y int) float64 {
-	xx := float64(bmu.X - x)
-	yy := float64(bmu.Y - y)
-	d := math.Sqrt(xx*xx + yy*yy)
-	q := f.InitialWidth * math.Exp(-float64(currentIt)/float64(iterationsNumber))
-	return math.Exp(-(d * d) / (2 * q * q))
+	return f.ApplyDistance(gridDistance(bmu, x, y), currentIt, iterationsNumber)
+}
+
+func (f *GaussianExpDecayInfluenceFunc) ApplyDistance(d float64, currentIt, iterationsNumber int) float64 {
+	return gaussian(d, f.EffectiveRadius(currentIt, iterationsNumber))
 }
 
 // GaussianInfluenceFunc calculates influence coefficient g(t) using gaussian function
 // with custom neighbourhood function.
 // g(t) = exp( -d**2/ (2*q(t)**2) )
-// where q(T) - is neighbourhood function
-// where d is euclidean distance from the BMU to [i][j] neuron
+// where q(T) - is neighbourhood function, floored by MinWidth,
+// where d is euclidean distance from the BMU to [i][j] neuron.
+// Like with GaussianExpDecayInfluenceFunc, the BMU is always fully influenced
+// and zero width influences the BMU only.
 type GaussianInfluenceFunc struct {
 	// Q - neighbourhood function.
 	// currentIt => [currentIt, iterationsNumber)
 	Q func(currentIt, iterationsNumber int) float64
+
+	// MinWidth is the floor of the neighbourhood width, 0 means no floor.
+	MinWidth float64
+}
+
+func (f *GaussianInfluenceFunc) Validate() error {
+	if f.Q == nil {
+		return fmt.Errorf("%w: neighbourhood function is not set", ErrInvalidConfig)
+	}
+	return validateWidths("width", 0, f.MinWidth)
+}
+
+// EffectiveRadius returns the neighbourhood width q(t).
+func (f *GaussianInfluenceFunc) EffectiveRadius(currentIt, iterationsNumber int) float64 {
+	q := f.Q(currentIt, iterationsNumber)
+	if math.IsNaN(q) || q < f.MinWidth {
+		return f.MinWidth
+	}
+	return q
 }
 
 func (f *GaussianInfluenceFunc) Apply(bmu *Neuron, currentIt, iterationsNumber, x, y int) float64 {
+	return f.ApplyDistance(gridDistance(bmu, x, y), currentIt, iterationsNumber)
+}
+
+func (f *GaussianInfluenceFunc) ApplyDistance(d float64, currentIt, iterationsNumber int) float64 {
+	return gaussian(d, f.EffectiveRadius(currentIt, iterationsNumber))
+}
+
+// progress returns currentIt/iterationsNumber, or 0 if there are no iterations.
+func progress(currentIt, iterationsNumber int) float64 {
+	if iterationsNumber <= 0 {
+		return 0
+	}
+	return float64(currentIt) / float64(iterationsNumber)
+}
+
+// gridDistance returns euclidean distance from the bmu to the (x, y) cell.
+func gridDistance(bmu *Neuron, x, y int) float64 {
 	xx := float64(bmu.X - x)
 	yy := float64(bmu.Y - y)
-	d := math.Sqrt(xx*xx + yy*yy)
-	q := f.Q(currentIt, iterationsNumber)
+	return math.Sqrt(xx*xx + yy*yy)
+}
+
+// gaussian returns exp(-d*d/(2*q*q)), defined as 1 for d == 0
+// and as 0 for d > 0 when q is 0, so the result is never NaN.
+func gaussian(d, q float64) float64 {
+	if d == 0 {
+		return 1
+	}
+	if q <= 0 {
+		return 0
+	}
 	return math.Exp(-(d * d) / (2 * q * q))
 }
 
+func validateWidths(name string, width, min float64) error {
+	if math.IsNaN(width) || width < 0 || math.IsInf(width, 0) {
+		return fmt.Errorf("%w: %s must be a non-negative number, got %v", ErrInvalidConfig, name, width)
+	}
+	if math.IsNaN(min) || min < 0 || math.IsInf(min, 0) {
+		return fmt.Errorf("%w: min %s must be a non-negative number, got %v", ErrInvalidConfig, name, min)
+	}
+	return nil
+}
+
 // SimpleRestraintFunc calculates coefficient as => A / (B + t).
 type SimpleRestraintFunc struct {
 	A, B float64
@@ -519,6 +1148,8 @@ func (adapter *NoOpAdapter) Adapt(vector []float64) []float64 {
 	return vector
 }
 
+func (adapter *NoOpAdapter) Describe() ComponentSpec { return ComponentSpec{Type: "no-op"} }
+
 func NewScalingDataAdapter(min, max []float64) *ScalingDataAdapter {
 	maxMinDiff := make([]float64, len(min))
 	for i := range min {
@@ -528,7 +1159,9 @@ func NewScalingDataAdapter(min, max []float64) *ScalingDataAdapter {
 }
 
 // ScalingDataAdapter scales input vector values to be in range [0, 1].
-// Note that the original vector is modified.
+// Note that the original vector is modified, so when the same vectors
+// are selected repeatedly, prefer attaching the adapter to the data set
+// (see DataSet.SetAdapter) over using it as SOM.InDataAdapter.
 type ScalingDataAdapter struct {
 	Min, MaxMinDiff []float64
 }
@@ -540,3 +1173,44 @@ func (adapter *ScalingDataAdapter) Adapt(vector []float64) []float64 {
 	}
 	return vector
 }
+
+// Inverse scales vector values back from [0, 1] to the original range.
+// Note that the original vector is modified.
+func (adapter *ScalingDataAdapter) Inverse(vector []float64) []float64 {
+	for i := range vector {
+		vector[i] *= adapter.MaxMinDiff[i]
+		vector[i] += adapter.Min[i]
+	}
+	return vector
+}
+
+func (adapter *ScalingDataAdapter) Describe() ComponentSpec {
+	return ComponentSpec{Type: "scaling", Values: map[string][]float64{
+		"min":          append([]float64(nil), adapter.Min...),
+		"max_min_diff": append([]float64(nil), adapter.MaxMinDiff...),
+	}}
+}
+
+// randPerm is rand.Perm of the given source, or of the global one if it's nil.
+func randPerm(r *rand.Rand, n int) []int {
+	if r == nil {
+		return rand.Perm(n)
+	}
+	return r.Perm(n)
+}
+
+// randIntn is rand.Intn of the given source, or of the global one if it's nil.
+func randIntn(r *rand.Rand, n int) int {
+	if r == nil {
+		return rand.Intn(n)
+	}
+	return r.Intn(n)
+}
+
+// randFloat64 is rand.Float64 of the given source, or of the global one if it's nil.
+func randFloat64(r *rand.Rand) float64 {
+	if r == nil {
+		return rand.Float64()
+	}
+	return r.Float64()
+}
@@ -8,6 +8,7 @@ import (
 	"os"
 	"reflect"
 	"testing"
+	"testing/quick"
 	"time"
 
 	"github.com/voievodin/self-organizing-map/som"
@@ -263,6 +264,67 @@ func TestChebyshevDistanceFunc(t *testing.T) {
 	}
 }
 
+func TestCosineDistanceFunc(t *testing.T) {
+	f := som.CosineDistanceFunc{}
+
+	if d := f.Apply([]float64{1, 0}, []float64{5, 0}); d != 0 {
+		t.Fatalf("Expected zero distance between vectors of the same direction, got %v", d)
+	}
+	if d := f.Apply([]float64{1, 0}, []float64{0, 2}); d != 1 {
+		t.Fatalf("Expected distance 1 between orthogonal vectors, got %v", d)
+	}
+	if d := f.Apply([]float64{1, 1}, []float64{-1, -1}); math.Abs(d-2) > 1e-12 {
+		t.Fatalf("Expected distance 2 between opposite vectors, got %v", d)
+	}
+	if d := f.Apply([]float64{0, 0}, []float64{1, 1}); d != 1 {
+		t.Fatalf("Expected distance 1 to zero vector, got %v", d)
+	}
+}
+
+func TestUnitNormAdapter(t *testing.T) {
+	adapter := &som.UnitNormAdapter{}
+
+	checkSlicesEqual(t, []float64{0.6, 0.8}, adapter.Adapt([]float64{3, 4}))
+	checkSlicesEqual(t, []float64{0, 0}, adapter.Adapt([]float64{0, 0}))
+}
+
+func TestRenormalizeKeepsWeightsUnitLength(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	ds := &som.DataSet{}
+	for i := 0; i < 100; i++ {
+		ds.Add(som.DataVector{rng.NormFloat64(), rng.NormFloat64(), rng.NormFloat64()})
+	}
+	ds.SetAdapter(&som.UnitNormAdapter{})
+
+	for name, learn := range map[string]func(sm *som.SOM) error{
+		"online": func(sm *som.SOM) error { return sm.Learn(ds, 500) },
+		"batch":  func(sm *som.SOM) error { return sm.LearnBatch(ds, 5) },
+	} {
+		sm := som.New(4, 4)
+		sm.Initializer = &som.RandWeightsInitializer{Rand: rng}
+		sm.Selector = &som.RandSelector{Rand: rng}
+		sm.Restraint = &som.ExpRestraintFunc{InitialRate: 0.5}
+		sm.Influence = &som.GaussianExpDecayInfluenceFunc{InitialWidth: 2, MinWidth: 0.5}
+		sm.Distance = &som.CosineDistanceFunc{}
+		sm.Renormalize = true
+		if err := learn(sm); err != nil {
+			t.Fatal(err)
+		}
+
+		for x := range sm.Neurons {
+			for y, neuron := range sm.Neurons[x] {
+				norm := 0.0
+				for _, w := range neuron.Weights {
+					norm += w * w
+				}
+				if math.Abs(math.Sqrt(norm)-1) > 1e-9 {
+					t.Fatalf("%s: expected neuron (%d, %d) of unit length, got %v", name, x, y, math.Sqrt(norm))
+				}
+			}
+		}
+	}
+}
+
 func TestProvidedWeightsInitializerProperlyInitializesWeightsFor1DMap(t *testing.T) {
 	sm := som.New(3, 1)
 	sm.Initializer = &som.ProvidedWeightsInitializer{
@@ -370,3 +432,55 @@ func checkSlicesEqual(t *testing.T, a, b []float64) {
 		}
 	}
 }
+
+func TestTestDistancesDoesNotChangeNeurons(t *testing.T) {
+	sm := som.New(2, 2)
+	sm.Initializer = &som.ProvidedWeightsInitializer{
+		Weights: [][][]float64{
+			{{0}, {1}},
+			{{2}, {3}},
+		},
+	}
+	sm.Learn(&som.DataSet{Vectors: []som.DataVector{{}}}, 0)
+
+	field := som.NewDistanceField(sm.Neurons)
+	bmu, returned := sm.TestDistances(som.DataVector{2}, field)
+
+	if bmu != sm.Neurons[1][0] {
+		t.Fatalf("Expected BMU to be (1, 0), got (%d, %d)", bmu.X, bmu.Y)
+	}
+	if &returned[0][0] != &field[0][0] {
+		t.Fatal("Expected the given field to be reused")
+	}
+	checkSlicesEqual(t, returned[0], []float64{2, 1})
+	checkSlicesEqual(t, returned[1], []float64{0, 1})
+	for i := range sm.Neurons {
+		for j := range sm.Neurons[i] {
+			if sm.Neurons[i][j].Distance != 0 {
+				t.Fatalf("Expected neuron (%d, %d) distance to stay 0", i, j)
+			}
+		}
+	}
+}
+
+func TestScalingDataAdapterInverseRestoresVectors(t *testing.T) {
+	property := func(min, span, vector [3]float64) bool {
+		max := make([]float64, 3)
+		for k := range max {
+			min[k] = math.Mod(min[k], 1e6)
+			max[k] = min[k] + math.Mod(math.Abs(span[k]), 1e6) + 1e-3
+			vector[k] = math.Mod(vector[k], 1e6)
+		}
+		adapter := som.NewScalingDataAdapter(min[:], max)
+		restored := adapter.Inverse(adapter.Adapt(append([]float64(nil), vector[:]...)))
+		for k := range restored {
+			if math.Abs(restored[k]-vector[k]) > 1e-9*(1+math.Abs(vector[k])+math.Abs(min[k])) {
+				return false
+			}
+		}
+		return true
+	}
+	if err := quick.Check(property, nil); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,172 @@
+package som
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ReadSOMPAK reads data set in SOM_PAK data file format, where the first
+// line holds the width of the vectors and each next line is
+//
+//	<value> <value> ... [<label>]
+//
+// Missing values are marked as x and read as NaN, '#' starts a comment.
+// Fields after the values form the label of the vector, it is empty
+// if there are none. Returns the data set and the labels of its vectors.
+// Malformed lines are skipped and reported by *LoadReport returned
+// along with the data set of the accepted lines, see LoadReport.
+func ReadSOMPAK(r io.Reader) (*DataSet, []string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	header, lineNum, err := scanSOMPAKHeader(scanner)
+	if err != nil {
+		return nil, nil, fmt.Errorf("sompak: %w", err)
+	}
+	width, err := strconv.Atoi(header[0])
+	if err != nil || width <= 0 {
+		return nil, nil, fmt.Errorf("sompak: %w: bad width %q", ErrInvalidConfig, header[0])
+	}
+
+	var (
+		ds     = &DataSet{}
+		labels []string
+		report = &LoadReport{}
+	)
+	for scanner.Scan() {
+		lineNum++
+		fields := somPAKFields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		// the width is checked against the fields before the vector
+		// is allocated, so a forged header doesn't cause a huge allocation
+		if len(fields) < width {
+			report.Rows++
+			report.reject(&RowError{Row: lineNum, Err: ErrWidthMismatch, Expected: width, Actual: len(fields)})
+			continue
+		}
+		vector, err := parseSOMPAKValues(fields[:width], true)
+		if err != nil {
+			report.Rows++
+			report.reject(&RowError{Row: lineNum, Err: err})
+			continue
+		}
+		if report.addRow(ds, lineNum, vector); ds.Len() > len(labels) {
+			labels = append(labels, strings.Join(fields[width:], " "))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	return ds, labels, report.result()
+}
+
+// LoadSOMPAK reads a map from SOM_PAK codebook file, where the first line is
+//
+//	<width> <topology> <xdim> <ydim> <neighbourhood>
+//
+// and each next line holds the weights of a neuron, optionally followed
+// by a label which is ignored. Neurons are listed row by row, the neuron
+// at (x, y) is on line 2 + y*xdim + x. Only the rect topology is supported,
+// the neighbourhood, bubble or gaussian, describes how the codebook was
+// trained and isn't restored.
+// ErrBadModel is returned if the codebook is malformed.
+func LoadSOMPAK(r io.Reader) (*SOM, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	header, lineNum, err := scanSOMPAKHeader(scanner)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBadModel, err)
+	}
+	if len(header) < 5 {
+		return nil, fmt.Errorf("%w: header %q must have width, topology, dimensions and neighbourhood", ErrBadModel, strings.Join(header, " "))
+	}
+	width, errW := strconv.Atoi(header[0])
+	x, errX := strconv.Atoi(header[2])
+	y, errY := strconv.Atoi(header[3])
+	if errW != nil || errX != nil || errY != nil || x <= 0 || y <= 0 || width <= 0 ||
+		int64(x)*int64(y)*int64(width) > maxBinaryModelValues {
+		return nil, fmt.Errorf("%w: bad dimensions %sx%sx%s", ErrBadModel, header[2], header[3], header[0])
+	}
+	if header[1] != "rect" {
+		return nil, fmt.Errorf("%w: unsupported topology %q", ErrBadModel, header[1])
+	}
+	if header[4] != "bubble" && header[4] != "gaussian" {
+		return nil, fmt.Errorf("%w: unsupported neighbourhood %q", ErrBadModel, header[4])
+	}
+
+	// the weights are allocated as they are read, so a header
+	// of a truncated or forged file doesn't cause a huge allocation
+	weights := make([][][]float64, 0)
+	for n := 0; n < x*y; {
+		if !scanner.Scan() {
+			if err := scanner.Err(); err != nil {
+				return nil, fmt.Errorf("%w: reading neuron (%d, %d): %v", ErrBadModel, n%x, n/x, err)
+			}
+			return nil, fmt.Errorf("%w: %d of %d neurons", ErrBadModel, n, x*y)
+		}
+		lineNum++
+		fields := somPAKFields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		i, j := n%x, n/x
+		if len(fields) < width {
+			return nil, fmt.Errorf("%w: line %d: neuron (%d, %d) has %d weights, expected %d", ErrBadModel, lineNum, i, j, len(fields), width)
+		}
+		neuron, err := parseSOMPAKValues(fields[:width], false)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: neuron (%d, %d): %v", ErrBadModel, lineNum, i, j, err)
+		}
+		if j == 0 {
+			weights = append(weights, make([][]float64, 0))
+		}
+		weights[i] = append(weights[i], neuron)
+		n++
+	}
+	return loadedSOM(weights, nil), nil
+}
+
+// scanSOMPAKHeader returns the fields of the first non-empty line
+// and its number.
+func scanSOMPAKHeader(scanner *bufio.Scanner) ([]string, int, error) {
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		if fields := somPAKFields(scanner.Text()); len(fields) != 0 {
+			return fields, lineNum, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, lineNum, err
+	}
+	return nil, lineNum, fmt.Errorf("missing header")
+}
+
+func somPAKFields(line string) []string {
+	if i := strings.IndexByte(line, '#'); i >= 0 {
+		line = line[:i]
+	}
+	return strings.Fields(line)
+}
+
+func parseSOMPAKValues(fields []string, missing bool) (DataVector, error) {
+	vector := make(DataVector, len(fields))
+	for i, field := range fields {
+		if missing && field == "x" {
+			vector[i] = math.NaN()
+			continue
+		}
+		v, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return nil, fmt.Errorf("column %d: bad value %q", i+1, field)
+		}
+		vector[i] = v
+	}
+	return vector, nil
+}
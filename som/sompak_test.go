@@ -0,0 +1,126 @@
+package som_test
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+func TestReadSOMPAKReadsLabelsAndMissingValues(t *testing.T) {
+	input := `3
+# comment line
+1 2 3 red
+4 x 6
+7 8 9 dark blue # trailing comment
+1 2
+1 y 3
+`
+	ds, labels, err := som.ReadSOMPAK(strings.NewReader(input))
+
+	var report *som.LoadReport
+	if !errors.As(err, &report) {
+		t.Fatalf("Expected LoadReport, got %v", err)
+	}
+	assertEq(t, report.Rows, 5)
+	assertEq(t, report.Rejected, 2)
+	assertEq(t, report.Errors[0].Row, 6)
+	assertEq(t, report.Errors[0].Expected, 3)
+	assertEq(t, report.Errors[0].Actual, 2)
+	assertEq(t, report.Errors[1].Row, 7)
+
+	assertEq(t, ds.Len(), 3)
+	checkSlicesEqual(t, ds.Vectors[0], []float64{1, 2, 3})
+	if !math.IsNaN(ds.Vectors[1][1]) {
+		t.Fatalf("Expected x to be read as NaN, got %f", ds.Vectors[1][1])
+	}
+	assertEq(t, strings.Join(labels, ","), "red,,dark blue")
+}
+
+func TestReadSOMPAKRejectsBadWidth(t *testing.T) {
+	for _, input := range []string{"", "0\n1\n", "three\n1 2 3\n"} {
+		if _, _, err := som.ReadSOMPAK(strings.NewReader(input)); err == nil {
+			t.Fatalf("Expected %q to be rejected", input)
+		}
+	}
+}
+
+func TestLoadSOMPAKListsNeuronsRowByRow(t *testing.T) {
+	input := `2 rect 3 2 gaussian
+0 0
+1 0 label
+2 0
+
+0 1
+1 1
+2 1
+`
+	sm, err := som.LoadSOMPAK(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEq(t, len(sm.Neurons), 3)
+	assertEq(t, len(sm.Neurons[0]), 2)
+	for x := range sm.Neurons {
+		for y, neuron := range sm.Neurons[x] {
+			checkSlicesEqual(t, neuron.Weights, []float64{float64(x), float64(y)})
+		}
+	}
+	checkLoadedSOM(t, sm)
+}
+
+func TestLoadSOMPAKRejectsMalformedCodebooks(t *testing.T) {
+	for _, input := range []string{
+		"",
+		"2 rect 2 1\n0 0\n1 1\n",
+		"2 hexa 2 1 bubble\n0 0\n1 1\n",
+		"2 rect 2 1 cutgauss\n0 0\n1 1\n",
+		"2 rect 2 1 bubble\n0 0\n",
+		"2 rect 2 1 bubble\n0 0\n1\n",
+		"2 rect 2 1 bubble\n0 0\n1 x\n",
+		"2 rect 100000 100000 bubble\n0 0\n",
+	} {
+		if _, err := som.LoadSOMPAK(strings.NewReader(input)); !errors.Is(err, som.ErrBadModel) {
+			t.Fatalf("Expected ErrBadModel for %q, got %v", input, err)
+		}
+	}
+}
+
+func FuzzReadSOMPAK(f *testing.F) {
+	f.Add("3\n# comment line\n1 2 3 red\n4 x 6\n7 8 9 dark blue # trailing\n1 2\n")
+	f.Add("2 rect 2 1 bubble\n0 0\n1 1\n")
+
+	f.Fuzz(func(t *testing.T, input string) {
+		ds, labels, err := som.ReadSOMPAK(strings.NewReader(input))
+		var report *som.LoadReport
+		if err != nil && !errors.As(err, &report) {
+			return
+		}
+		if len(labels) != ds.Len() {
+			t.Fatalf("%d labels for %d vectors", len(labels), ds.Len())
+		}
+		for i, vector := range ds.Vectors {
+			if len(vector) != len(ds.Vectors[0]) {
+				t.Fatalf("Vector %d has unexpected width %d", i, len(vector))
+			}
+		}
+	})
+}
+
+func FuzzLoadSOMPAK(f *testing.F) {
+	f.Add("2 rect 3 2 gaussian\n0 0\n1 0 label\n2 0\n0 1\n1 1\n2 1\n")
+	f.Add("2 rect 2 1 bubble # comment\n\n0 0\n1 1\n")
+
+	f.Fuzz(func(t *testing.T, input string) {
+		sm, err := som.LoadSOMPAK(strings.NewReader(input))
+		if err != nil {
+			if !errors.Is(err, som.ErrBadModel) {
+				t.Fatalf("Expected ErrBadModel, got %v", err)
+			}
+			return
+		}
+		checkLoadedSOM(t, sm)
+	})
+}
@@ -0,0 +1,161 @@
+package som
+
+import (
+	"fmt"
+	"math"
+)
+
+// SphereLattice is the geodesic grid on the unit sphere: the icosahedron whose
+// faces are subdivided into 4 triangles n times, see NewSphereLattice, with the
+// new nodes projected on the sphere. It has 10*4^n+2 nodes, e.g. 12, 42, 162,
+// 642, 2562, the 12 nodes of the icosahedron have 5 neighbours and the others 6.
+// The distance between the nodes is the great circle distance, so the map of
+// the lattice, see NewLattice, has no borders at all and suits the data with
+// no natural boundary, e.g. directions or positions on the globe.
+type SphereLattice struct {
+	// Nodes are the unit vectors of the positions of the nodes.
+	Nodes [][3]float64
+
+	neighbours [][]int
+
+	// spacing is the mean angle between the neighbours.
+	spacing float64
+
+	subdivisions int
+}
+
+// NewSphereLattice creates the lattice of the icosahedron subdivided
+// the given number of times, negative numbers mean 0.
+func NewSphereLattice(subdivisions int) *SphereLattice {
+	if subdivisions < 0 {
+		subdivisions = 0
+	}
+	phi := (1 + math.Sqrt(5)) / 2
+	nodes := [][3]float64{
+		{-1, phi, 0}, {1, phi, 0}, {-1, -phi, 0}, {1, -phi, 0},
+		{0, -1, phi}, {0, 1, phi}, {0, -1, -phi}, {0, 1, -phi},
+		{phi, 0, -1}, {phi, 0, 1}, {-phi, 0, -1}, {-phi, 0, 1},
+	}
+	for i := range nodes {
+		nodes[i] = unitVector(nodes[i])
+	}
+	faces := [][3]int{
+		{0, 11, 5}, {0, 5, 1}, {0, 1, 7}, {0, 7, 10}, {0, 10, 11},
+		{1, 5, 9}, {5, 11, 4}, {11, 10, 2}, {10, 7, 6}, {7, 1, 8},
+		{3, 9, 4}, {3, 4, 2}, {3, 2, 6}, {3, 6, 8}, {3, 8, 9},
+		{4, 9, 5}, {2, 4, 11}, {6, 2, 10}, {8, 6, 7}, {9, 8, 1},
+	}
+
+	for s := 0; s < subdivisions; s++ {
+		midpoints := make(map[[2]int]int)
+		midpoint := func(a, b int) int {
+			key := [2]int{a, b}
+			if a > b {
+				key = [2]int{b, a}
+			}
+			if m, ok := midpoints[key]; ok {
+				return m
+			}
+			nodes = append(nodes, unitVector([3]float64{
+				nodes[a][0] + nodes[b][0],
+				nodes[a][1] + nodes[b][1],
+				nodes[a][2] + nodes[b][2],
+			}))
+			midpoints[key] = len(nodes) - 1
+			return len(nodes) - 1
+		}
+		subdivided := make([][3]int, 0, 4*len(faces))
+		for _, f := range faces {
+			ab, bc, ca := midpoint(f[0], f[1]), midpoint(f[1], f[2]), midpoint(f[2], f[0])
+			subdivided = append(subdivided, [3]int{f[0], ab, ca}, [3]int{f[1], bc, ab}, [3]int{f[2], ca, bc}, [3]int{ab, bc, ca})
+		}
+		faces = subdivided
+	}
+
+	lattice := &SphereLattice{Nodes: nodes, neighbours: make([][]int, len(nodes)), subdivisions: subdivisions}
+	edges := make(map[[2]int]bool)
+	angles := 0.0
+	for _, f := range faces {
+		for e := 0; e < 3; e++ {
+			a, b := f[e], f[(e+1)%3]
+			if a > b {
+				a, b = b, a
+			}
+			if edges[[2]int{a, b}] {
+				continue
+			}
+			edges[[2]int{a, b}] = true
+			lattice.neighbours[a] = append(lattice.neighbours[a], b)
+			lattice.neighbours[b] = append(lattice.neighbours[b], a)
+			angles += greatCircle(nodes[a], nodes[b])
+		}
+	}
+	lattice.spacing = angles / float64(len(edges))
+	return lattice
+}
+
+func (l *SphereLattice) Len() int {
+	return len(l.Nodes)
+}
+
+// Distance returns the great circle distance between the nodes
+// in the units of the mean distance between the neighbours.
+func (l *SphereLattice) Distance(i, j int) float64 {
+	return greatCircle(l.Nodes[i], l.Nodes[j]) / l.spacing
+}
+
+func (l *SphereLattice) Neighbours(i int) []int {
+	return l.neighbours[i]
+}
+
+// Describe returns the sphere topology spec with the param subdivisions,
+// so the maps of the lattice can be saved.
+func (l *SphereLattice) Describe() ComponentSpec {
+	return ComponentSpec{Type: "sphere", Params: Params{"subdivisions": float64(l.subdivisions)}}
+}
+
+// newSphereTopology creates LatticeTopology of the sphere described by SphereLattice.Describe.
+func newSphereTopology(params Params, values map[string][]float64) (Topology, error) {
+	if err := params.Check("subdivisions"); err != nil {
+		return nil, err
+	}
+	subdivisions := params.Get("subdivisions", 0)
+	if subdivisions != math.Trunc(subdivisions) || subdivisions < 0 || subdivisions > maxSphereSubdivisions {
+		return nil, fmt.Errorf("%w: sphere subdivisions must be an integer in [0, %d], got %v", ErrInvalidConfig, maxSphereSubdivisions, subdivisions)
+	}
+	return &LatticeTopology{Lattice: NewSphereLattice(int(subdivisions))}, nil
+}
+
+// maxSphereSubdivisions limits the subdivisions of the sphere recreated from
+// its description, so a corrupted saved map doesn't cause a huge allocation.
+const maxSphereSubdivisions = 8
+
+// Nearest returns the node closest to the point on the sphere, which is
+// the unit vector, e.g. for rendering the values of the nodes.
+func (l *SphereLattice) Nearest(p [3]float64) int {
+	nearest, max := 0, math.Inf(-1)
+	for i, n := range l.Nodes {
+		if dot := n[0]*p[0] + n[1]*p[1] + n[2]*p[2]; dot > max {
+			nearest, max = i, dot
+		}
+	}
+	return nearest
+}
+
+// greatCircle returns the angle between the unit vectors, which is accurate
+// for close vectors unlike the arc cosine of their dot product.
+func greatCircle(a, b [3]float64) float64 {
+	cross := [3]float64{
+		a[1]*b[2] - a[2]*b[1],
+		a[2]*b[0] - a[0]*b[2],
+		a[0]*b[1] - a[1]*b[0],
+	}
+	sin := math.Sqrt(cross[0]*cross[0] + cross[1]*cross[1] + cross[2]*cross[2])
+	cos := a[0]*b[0] + a[1]*b[1] + a[2]*b[2]
+	return math.Atan2(sin, cos)
+}
+
+func unitVector(v [3]float64) [3]float64 {
+	norm := math.Sqrt(v[0]*v[0] + v[1]*v[1] + v[2]*v[2])
+	return [3]float64{v[0] / norm, v[1] / norm, v[2] / norm}
+}
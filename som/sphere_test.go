@@ -0,0 +1,52 @@
+package som_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+func TestSphereLatticeSubdivisions(t *testing.T) {
+	for subdivisions, nodes := range []int{12, 42, 162, 642} {
+		lattice := som.NewSphereLattice(subdivisions)
+		if lattice.Len() != nodes {
+			t.Fatalf("Expected %d nodes for %d subdivisions, got %d", nodes, subdivisions, lattice.Len())
+		}
+		fives := 0
+		for i := 0; i < lattice.Len(); i++ {
+			switch n := len(lattice.Neighbours(i)); n {
+			case 5:
+				fives++
+			case 6:
+			default:
+				t.Fatalf("Node %d has %d neighbours", i, n)
+			}
+			for _, j := range lattice.Neighbours(i) {
+				if d := lattice.Distance(i, j); d < 0.7 || d > 1.3 {
+					t.Fatalf("Expected the neighbours at about unit distance, got %v between %d and %d", d, i, j)
+				}
+			}
+		}
+		if fives != 12 {
+			t.Fatalf("Expected 12 nodes with 5 neighbours, got %d", fives)
+		}
+	}
+}
+
+func TestSphereLatticeDistanceIsSymmetricGeodesic(t *testing.T) {
+	lattice := som.NewSphereLattice(2)
+	// the opposite nodes are the farthest, half of the circumference away
+	for i, n := range lattice.Nodes {
+		opposite := lattice.Nearest([3]float64{-n[0], -n[1], -n[2]})
+		far := lattice.Distance(i, opposite)
+		for j := range lattice.Nodes {
+			if d := lattice.Distance(i, j); d > far+1e-9 || math.Abs(d-lattice.Distance(j, i)) > 1e-12 {
+				t.Fatalf("Unexpected distance %v between %d and %d, the farthest is %v", d, i, j, far)
+			}
+		}
+	}
+	if lattice.Nearest(lattice.Nodes[7]) != 7 {
+		t.Fatal("Expected the node to be the nearest to itself")
+	}
+}
@@ -0,0 +1,191 @@
+// Package sqlsource provides som.VectorSource backed by a database/sql query,
+// so maps can be trained directly from database tables.
+package sqlsource
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+// DefaultBatchSize is the number of rows fetched at once by Source
+// if its BatchSize is not set.
+const DefaultBatchSize = 1024
+
+// Source streams rows returned by a query as data vectors,
+// one vector per row, each column becomes a vector element.
+// Column values are coerced to float64: numbers are converted,
+// booleans become 0 or 1, time values become unix seconds,
+// strings and bytes are parsed, NULLs become NaN.
+//
+// Source is both som.VectorSource and som.Selector, when used as a selector
+// each Init call re-executes the query, so the rows are streamed from
+// the database rather than from the data set given to SOM.Learn,
+// which is still used by the neurons initializer (e.g. a sample of the table).
+// As Selector.Init can't fail, query errors are returned by Next,
+// and so by SOM.Learn, they are also available via Err.
+type Source struct {
+	// BatchSize is the number of rows fetched from the database at once.
+	BatchSize int
+
+	db    *sql.DB
+	query string
+	args  []interface{}
+
+	rows    *sql.Rows
+	columns []string
+	batch   []som.DataVector
+	idx     int
+	done    bool
+	err     error
+}
+
+// New creates a new source for the given query, the query is executed lazily.
+func New(db *sql.DB, query string, args ...interface{}) *Source {
+	return &Source{db: db, query: query, args: args}
+}
+
+// Init restarts the query, the data set is ignored.
+func (src *Source) Init(set *som.DataSet) {
+	src.Close()
+	src.done = false
+	src.err = nil
+	src.batch = nil
+	src.idx = 0
+}
+
+// Next returns the next row as a data vector,
+// or som.ErrNoDataLeft if all the rows are read.
+func (src *Source) Next() (som.DataVector, error) {
+	if src.idx >= len(src.batch) {
+		if src.done {
+			if src.err != nil {
+				return nil, src.err
+			}
+			return nil, som.ErrNoDataLeft
+		}
+		if err := src.fetch(); err != nil {
+			src.err = err
+			src.done = true
+			src.Close()
+			return nil, err
+		}
+		if len(src.batch) == 0 {
+			return nil, som.ErrNoDataLeft
+		}
+	}
+
+	vector := src.batch[src.idx]
+	src.idx++
+	return vector, nil
+}
+
+// Columns returns names of the query columns,
+// available once the first row is read.
+func (src *Source) Columns() []string {
+	return append([]string(nil), src.columns...)
+}
+
+// Err returns the error which stopped the streaming, if any.
+func (src *Source) Err() error {
+	return src.err
+}
+
+// Close releases the rows of the currently executed query.
+func (src *Source) Close() error {
+	if src.rows == nil {
+		return nil
+	}
+	err := src.rows.Close()
+	src.rows = nil
+	return err
+}
+
+func (src *Source) fetch() error {
+	if src.rows == nil {
+		rows, err := src.db.Query(src.query, src.args...)
+		if err != nil {
+			return err
+		}
+		columns, err := rows.Columns()
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		src.rows = rows
+		src.columns = columns
+	}
+
+	batchSize := src.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+
+	values := make([]interface{}, len(src.columns))
+	dest := make([]interface{}, len(src.columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+
+	src.batch = src.batch[:0]
+	src.idx = 0
+	for len(src.batch) < batchSize && src.rows.Next() {
+		if err := src.rows.Scan(dest...); err != nil {
+			return err
+		}
+		vector := make(som.DataVector, len(values))
+		for i, value := range values {
+			v, err := coerce(value)
+			if err != nil {
+				return fmt.Errorf("sqlsource: column %q: %w", src.columns[i], err)
+			}
+			vector[i] = v
+		}
+		src.batch = append(src.batch, vector)
+	}
+
+	if len(src.batch) < batchSize {
+		src.done = true
+		if err := src.rows.Err(); err != nil {
+			return err
+		}
+		return src.Close()
+	}
+	return nil
+}
+
+func coerce(value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case nil:
+		return math.NaN(), nil
+	case float64:
+		return v, nil
+	case float32:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case int32:
+		return float64(v), nil
+	case int:
+		return float64(v), nil
+	case uint64:
+		return float64(v), nil
+	case bool:
+		if v {
+			return 1, nil
+		}
+		return 0, nil
+	case time.Time:
+		return float64(v.UnixNano()) / float64(time.Second), nil
+	case []byte:
+		return strconv.ParseFloat(string(v), 64)
+	case string:
+		return strconv.ParseFloat(v, 64)
+	default:
+		return 0, fmt.Errorf("unsupported value type %T", value)
+	}
+}
@@ -0,0 +1,125 @@
+package sqlsource_test
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+	"github.com/voievodin/self-organizing-map/som/sqlsource"
+)
+
+func TestSourceStreamsCoercedRowsInBatches(t *testing.T) {
+	db := openTable(t, []string{"a", "b", "c"}, [][]driver.Value{
+		{int64(1), 0.5, "2.5"},
+		{true, nil, []byte("3")},
+		{int64(-4), 1.0, "0"},
+	})
+
+	src := sqlsource.New(db, "select a, b, c from t")
+	src.BatchSize = 2
+
+	ds, err := som.ReadDataSet(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ds.Len() != 3 {
+		t.Fatalf("Expected 3 vectors, got %d", ds.Len())
+	}
+	if ds.Vectors[0][0] != 1 || ds.Vectors[0][1] != 0.5 || ds.Vectors[0][2] != 2.5 {
+		t.Fatalf("Unexpected first vector %v", ds.Vectors[0])
+	}
+	if ds.Vectors[1][0] != 1 || !math.IsNaN(ds.Vectors[1][1]) || ds.Vectors[1][2] != 3 {
+		t.Fatalf("Unexpected second vector %v", ds.Vectors[1])
+	}
+	if strings.Join(src.Columns(), ",") != "a,b,c" {
+		t.Fatalf("Unexpected columns %v", src.Columns())
+	}
+}
+
+func TestSourceIsUsableAsSelector(t *testing.T) {
+	db := openTable(t, []string{"v"}, [][]driver.Value{{0.25}, {0.75}})
+
+	somap := som.New(1, 1)
+	somap.Selector = sqlsource.New(db, "select v from t")
+	somap.Restraint = &som.SimpleRestraintFunc{A: 1, B: 1}
+	somap.Learn(&som.DataSet{Vectors: []som.DataVector{{0}}}, 10)
+
+	// 0 -> 0.25 (rate 1) -> 0.5 (rate 1/2)
+	if w := somap.Neurons[0][0].Weights[0]; w != 0.5 {
+		t.Fatalf("Expected weight 0.5, got %f", w)
+	}
+}
+
+func TestSourceReportsCoercionError(t *testing.T) {
+	db := openTable(t, []string{"name"}, [][]driver.Value{{"abc"}})
+
+	src := sqlsource.New(db, "select name from t")
+	if _, err := src.Next(); err == nil || !strings.Contains(err.Error(), `"name"`) {
+		t.Fatalf("Expected coercion error mentioning the column, got %v", err)
+	}
+	if src.Err() == nil {
+		t.Fatal("Expected Err to return the coercion error")
+	}
+}
+
+// A minimal database/sql driver serving a single in-memory table for any query.
+
+type table struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+var tables = map[string]*table{}
+
+func openTable(t *testing.T, columns []string, rows [][]driver.Value) *sql.DB {
+	tables[t.Name()] = &table{columns: columns, rows: rows}
+	db, err := sql.Open("sqlsource-test", t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func init() {
+	sql.Register("sqlsource-test", testDriver{})
+}
+
+type testDriver struct{}
+
+func (testDriver) Open(name string) (driver.Conn, error) { return &testConn{table: tables[name]}, nil }
+
+type testConn struct{ table *table }
+
+func (c *testConn) Prepare(query string) (driver.Stmt, error) { return &testStmt{table: c.table}, nil }
+func (c *testConn) Close() error                              { return nil }
+func (c *testConn) Begin() (driver.Tx, error)                 { return nil, driver.ErrSkip }
+
+type testStmt struct{ table *table }
+
+func (s *testStmt) Close() error                                    { return nil }
+func (s *testStmt) NumInput() int                                   { return -1 }
+func (s *testStmt) Exec(args []driver.Value) (driver.Result, error) { return nil, driver.ErrSkip }
+func (s *testStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return &testRows{table: s.table}, nil
+}
+
+type testRows struct {
+	table *table
+	idx   int
+}
+
+func (r *testRows) Columns() []string { return r.table.columns }
+func (r *testRows) Close() error      { return nil }
+func (r *testRows) Next(dest []driver.Value) error {
+	if r.idx >= len(r.table.rows) {
+		return io.EOF
+	}
+	copy(dest, r.table.rows[r.idx])
+	r.idx++
+	return nil
+}
@@ -0,0 +1,111 @@
+package som
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// TrainingState describes the training history of a map.
+type TrainingState struct {
+	// Iterations is the total number of iterations completed
+	// by successful Learn calls.
+	Iterations int
+
+	// Width is the length of neurons weights, 0 if the map is not trained.
+	Width int
+
+	// TrainedAt is the time of the last successful Learn call,
+	// zero for maps which are loaded or have provided weights.
+	TrainedAt time.Time
+}
+
+// IsTrained returns true if neurons weights of this map are initialized,
+// i.e. all the neurons have non-empty weights of the same length,
+// which is the case after Learn or loading.
+func (som *SOM) IsTrained() bool {
+	return som.checkTrained() == nil
+}
+
+// TrainingState returns the training state of this map.
+func (som *SOM) TrainingState() TrainingState {
+	state := som.state
+	if state.Width == 0 && som.IsTrained() {
+		state.Width = len(som.Neurons[0][0].Weights)
+	}
+	return state
+}
+
+// FindBMU finds BMU (Neuron) for the given vector like TestDistances does,
+// but instead of panicking returns ErrNotTrained if neurons weights are not
+// initialized and ErrWidthMismatch if the adapted vector doesn't fit the weights.
+// Returns ErrInvalidConfig if no neuron is at a finite distance from the vector,
+// e.g. all the neurons are masked.
+func (som *SOM) FindBMU(vector DataVector) (*Neuron, error) {
+	adapted, err := som.adaptInput(vector)
+	if err != nil {
+		return nil, err
+	}
+	field := som.borrowField()
+	defer releaseField(field)
+	distances := som.computeDistances(adapted, *field)
+	if min, _, _, _ := distances.minimum(); math.IsInf(min, 1) {
+		return nil, fmt.Errorf("%w: no neuron is at a finite distance from the vector", ErrInvalidConfig)
+	}
+	return som.bmu(distances), nil
+}
+
+// DistancesTo computes distances from the vector to the neurons like
+// ComputeDistances does, but instead of panicking returns the errors of FindBMU.
+func (som *SOM) DistancesTo(vector DataVector, field DistanceField) (DistanceField, error) {
+	adapted, err := som.adaptInput(vector)
+	if err != nil {
+		return field, err
+	}
+	return som.computeDistances(adapted, field), nil
+}
+
+// adaptInput adapts the vector for inference by InDataAdapter. Returns
+// ErrNotTrained if neurons weights are not initialized and ErrWidthMismatch
+// if the adapted vector doesn't fit them, or the adapter panics on the vector,
+// e.g. because its length doesn't match the fitted adapter.
+func (som *SOM) adaptInput(vector DataVector) (adapted DataVector, err error) {
+	if err := som.checkTrained(); err != nil {
+		return nil, err
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			adapted, err = nil, fmt.Errorf("%w: can't adapt vector of length %d: %v", ErrWidthMismatch, len(vector), r)
+		}
+	}()
+	adapted = som.InDataAdapter.Adapt(vector)
+	if width := len(som.Neurons[0][0].Weights); len(adapted) != width {
+		return nil, fmt.Errorf("%w: adapted vector length is %d, weights length is %d", ErrWidthMismatch, len(adapted), width)
+	}
+	return adapted, nil
+}
+
+// mustAdaptInput is adaptInput for the methods which can't return errors,
+// it panics with the error, so the cause is clear, unlike an index panic.
+func (som *SOM) mustAdaptInput(vector DataVector) DataVector {
+	adapted, err := som.adaptInput(vector)
+	if err != nil {
+		panic(err)
+	}
+	return adapted
+}
+
+func (som *SOM) checkTrained() error {
+	if len(som.Neurons) == 0 || len(som.Neurons[0]) == 0 || som.Neurons[0][0] == nil {
+		return fmt.Errorf("%w: map has no neurons", ErrNotTrained)
+	}
+	width := len(som.Neurons[0][0].Weights)
+	for i := range som.Neurons {
+		for j, neuron := range som.Neurons[i] {
+			if neuron == nil || len(neuron.Weights) == 0 || len(neuron.Weights) != width {
+				return fmt.Errorf("%w: neuron (%d, %d) weights are not initialized", ErrNotTrained, i, j)
+			}
+		}
+	}
+	return nil
+}
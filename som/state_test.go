@@ -0,0 +1,101 @@
+package som_test
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+func TestTrainingState(t *testing.T) {
+	sm := som.New(2, 2)
+	if sm.IsTrained() {
+		t.Fatal("Expected new map to be untrained")
+	}
+	assertEq(t, sm.TrainingState().Width, 0)
+
+	dataSet := &som.DataSet{Vectors: []som.DataVector{{1, 2, 3}, {3, 2, 1}}}
+	sm.LearnEntire(dataSet)
+	sm.Learn(dataSet, 1)
+
+	if !sm.IsTrained() {
+		t.Fatal("Expected map to be trained")
+	}
+	state := sm.TrainingState()
+	assertEq(t, state.Iterations, 3)
+	assertEq(t, state.Width, 3)
+	if state.TrainedAt.IsZero() {
+		t.Fatal("Expected training time to be set")
+	}
+}
+
+func TestUntrainedMapReturnsErrNotTrained(t *testing.T) {
+	sm := som.New(2, 2)
+
+	if _, err := sm.FindBMU(som.DataVector{1}); !errors.Is(err, som.ErrNotTrained) {
+		t.Fatalf("Expected ErrNotTrained, got %v", err)
+	}
+	if err := sm.SaveBinary(&bytes.Buffer{}, som.Precision{}); !errors.Is(err, som.ErrNotTrained) {
+		t.Fatalf("Expected ErrNotTrained, got %v", err)
+	}
+	if err := sm.ExportCodebookCSV(&bytes.Buffer{}, nil); !errors.Is(err, som.ErrNotTrained) {
+		t.Fatalf("Expected ErrNotTrained, got %v", err)
+	}
+	if _, err := sm.DistancesTo(som.DataVector{1}, nil); !errors.Is(err, som.ErrNotTrained) {
+		t.Fatalf("Expected ErrNotTrained, got %v", err)
+	}
+	if _, err := sm.FindBMUWithin(som.DataVector{1}, som.Rect{Max: som.GridPoint{X: 2, Y: 2}}); !errors.Is(err, som.ErrNotTrained) {
+		t.Fatalf("Expected ErrNotTrained, got %v", err)
+	}
+	if qe := sm.QuantizationError(&som.DataSet{Vectors: []som.DataVector{{1}}}); !math.IsNaN(qe) {
+		t.Fatalf("Expected NaN quantization error, got %v", qe)
+	}
+}
+
+func TestUntrainedMapPanicsWithErrNotTrained(t *testing.T) {
+	defer func() {
+		err, _ := recover().(error)
+		if !errors.Is(err, som.ErrNotTrained) {
+			t.Fatalf("Expected ErrNotTrained panic, got %v", err)
+		}
+	}()
+	som.New(3, 3).Test(som.DataVector{1, 2})
+}
+
+func TestFindBMUChecksAdaptedWidth(t *testing.T) {
+	sm := som.New(1, 2)
+	sm.Initializer = &som.ProvidedWeightsInitializer{Weights: [][][]float64{{{0}, {2}}}}
+	sm.Learn(&som.DataSet{Vectors: []som.DataVector{{}}}, 0)
+	// the adapter sums the features, so vectors of any length fit the weights
+	sm.InDataAdapter = som.DataAdapterFunc(func(vector []float64) []float64 {
+		sum := 0.0
+		for _, v := range vector {
+			sum += v
+		}
+		return []float64{sum}
+	})
+
+	bmu, err := sm.FindBMU(som.DataVector{0.5, 0.5, 0.7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEq(t, bmu.Y, 1)
+}
+
+func TestFindBMU(t *testing.T) {
+	sm := som.New(1, 2)
+	sm.Initializer = &som.ProvidedWeightsInitializer{Weights: [][][]float64{{{0, 0}, {1, 1}}}}
+	sm.Learn(&som.DataSet{Vectors: []som.DataVector{{}}}, 0)
+
+	bmu, err := sm.FindBMU(som.DataVector{0.9, 0.8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEq(t, bmu.Y, 1)
+
+	if _, err := sm.FindBMU(som.DataVector{1}); !errors.Is(err, som.ErrWidthMismatch) {
+		t.Fatalf("Expected ErrWidthMismatch, got %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package som
+
+import "math/rand"
+
+// TieBreaker chooses BMU among several neurons which
+// are equally distant from the input vector.
+type TieBreaker interface {
+	// Break returns one of the candidates, which are
+	// in row-major order, there are at least 2 of them.
+	Break(candidates []*Neuron) *Neuron
+}
+
+// WinObserver is implemented by tie breakers which need
+// to know the BMU of each learning iteration.
+type WinObserver interface {
+	// Won is called by Learn once BMU is found.
+	// currentIt => [0, iterationsNumber)
+	Won(bmu *Neuron, currentIt int)
+}
+
+// RandTieBreaker chooses a random candidate, it is the default tie breaker.
+type RandTieBreaker struct {
+	// Rand is the source of randomness, nil means the global one.
+	Rand *rand.Rand
+}
+
+func (tb *RandTieBreaker) Break(candidates []*Neuron) *Neuron {
+	return candidates[randIntn(tb.Rand, len(candidates))]
+}
+
+// LowestIndexTieBreaker deterministically chooses the candidate
+// with the lowest row-major index, which makes runs reproducible.
+type LowestIndexTieBreaker struct{}
+
+func (tb *LowestIndexTieBreaker) Break(candidates []*Neuron) *Neuron {
+	return candidates[0]
+}
+
+// LeastRecentlyWonTieBreaker chooses the candidate which won the longest time ago
+// (never won neurons first, then by the lowest index), spreading wins
+// over equally good neurons in the spirit of conscience learning.
+type LeastRecentlyWonTieBreaker struct {
+	lastWon map[*Neuron]int
+}
+
+func (tb *LeastRecentlyWonTieBreaker) Break(candidates []*Neuron) *Neuron {
+	chosen := candidates[0]
+	chosenIt, chosenWon := tb.lastWon[chosen]
+	for _, candidate := range candidates[1:] {
+		it, won := tb.lastWon[candidate]
+		if !won && chosenWon || won && chosenWon && it < chosenIt {
+			chosen, chosenIt, chosenWon = candidate, it, won
+		}
+	}
+	return chosen
+}
+
+func (tb *LeastRecentlyWonTieBreaker) Won(bmu *Neuron, currentIt int) {
+	if tb.lastWon == nil || currentIt == 0 {
+		tb.lastWon = make(map[*Neuron]int)
+	}
+	tb.lastWon[bmu] = currentIt
+}
@@ -0,0 +1,44 @@
+package som_test
+
+import (
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+func TestTieBreakers(t *testing.T) {
+	cases := []struct {
+		tieBreaker som.TieBreaker
+		expected   []int
+	}{
+		{&som.LowestIndexTieBreaker{}, []int{0, 0, 0, 0, 0}},
+		{&som.LeastRecentlyWonTieBreaker{}, []int{0, 1, 2, 0, 1}},
+	}
+
+	for _, aCase := range cases {
+		sm := som.New(1, 3)
+		sm.TieBreaker = aCase.tieBreaker
+		// weights never change, so all the neurons are always equally distant
+		sm.Restraint = &som.SimpleRestraintFunc{A: 0, B: 1}
+		sm.Selector = &som.RandSelector{}
+
+		var bmus []int
+		sm.Events = som.EventListenerFunc(func(event som.Event) {
+			bmus = append(bmus, event.(*som.IterationEvent).BMUY)
+		})
+		sm.Learn(&som.DataSet{Vectors: []som.DataVector{{1}}}, 5)
+
+		checkIntsEqual(t, bmus, aCase.expected)
+	}
+}
+
+func checkIntsEqual(t *testing.T, a, b []int) {
+	if len(a) != len(b) {
+		t.Fatalf("Slices have different length %d != %d", len(a), len(b))
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("Slices are not equal %v != %v", a, b)
+		}
+	}
+}
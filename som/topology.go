@@ -0,0 +1,109 @@
+package som
+
+import "math"
+
+// Topology defines how the edges of the map are connected,
+// and so the grid distance between neurons, which influence functions rely on.
+type Topology interface {
+	// Closest returns the position of the (x, y) cell image which is the closest
+	// to the (toX, toY) cell on the map of xLen*yLen size. For the maps with
+	// connected edges the image may lie outside of the grid, e.g. on 10x10 torus
+	// the image of (0, 0) which is the closest to (9, 9) is (10, 10).
+	Closest(x, y, toX, toY, xLen, yLen int) (int, int)
+}
+
+// MetricTopology is implemented by topologies whose neurons are not the cells
+// of a grid, e.g. LatticeTopology, so the distance between the neurons isn't
+// the euclidean distance between their cells and the neighbours of a neuron
+// are not the adjacent cells. The neuron positions are still the cells of the
+// xLen*yLen map. The influence functions of such maps must implement
+// DistanceInfluenceFunc, since the BMU position means nothing to them.
+type MetricTopology interface {
+	Topology
+
+	// Distance returns the distance between the neurons at (x1, y1) and (x2, y2)
+	// on the map of xLen*yLen size, in the units of the typical distance
+	// between neighbours, so the radii of influence functions mean about
+	// the same as on the grid maps.
+	Distance(x1, y1, x2, y2, xLen, yLen int) float64
+
+	// Neighbours returns the positions of the neurons adjacent
+	// to the neuron at (x, y) on the map of xLen*yLen size.
+	Neighbours(x, y, xLen, yLen int) [][2]int
+}
+
+// Axis is an axis of the map grid.
+type Axis int
+
+const (
+	AxisX Axis = iota
+	AxisY
+)
+
+// GridDistance returns euclidean distance between the
+// (x1, y1) and (x2, y2) cells in the given topology,
+// or the distance of MetricTopology between the neurons.
+func GridDistance(topology Topology, x1, y1, x2, y2, xLen, yLen int) float64 {
+	if metric, ok := topology.(MetricTopology); ok {
+		return metric.Distance(x1, y1, x2, y2, xLen, yLen)
+	}
+	x1, y1 = topology.Closest(x1, y1, x2, y2, xLen, yLen)
+	xx := float64(x1 - x2)
+	yy := float64(y1 - y2)
+	return math.Sqrt(xx*xx + yy*yy)
+}
+
+// PlanarTopology is a default topology, the map is a flat rectangle.
+type PlanarTopology struct{}
+
+func (t *PlanarTopology) Closest(x, y, toX, toY, xLen, yLen int) (int, int) {
+	return x, y
+}
+
+func (t *PlanarTopology) Describe() ComponentSpec { return ComponentSpec{Type: "planar"} }
+
+// TorusTopology connects both opposite edges of the map,
+// so there are no border neurons at all.
+type TorusTopology struct{}
+
+func (t *TorusTopology) Closest(x, y, toX, toY, xLen, yLen int) (int, int) {
+	return closestWrapped(x, toX, xLen), closestWrapped(y, toY, yLen)
+}
+
+func (t *TorusTopology) Describe() ComponentSpec { return ComponentSpec{Type: "torus"} }
+
+// CylinderTopology connects the opposite edges across the Wrapped axis only,
+// e.g. for AxisX the neurons (0, y) and (xLen-1, y) are neighbours.
+// Suits the data having one periodic feature, like hour-of-day or wind direction.
+type CylinderTopology struct {
+	Wrapped Axis
+}
+
+func (t *CylinderTopology) Closest(x, y, toX, toY, xLen, yLen int) (int, int) {
+	if t.Wrapped == AxisX {
+		return closestWrapped(x, toX, xLen), y
+	}
+	return x, closestWrapped(y, toY, yLen)
+}
+
+func (t *CylinderTopology) Describe() ComponentSpec {
+	return ComponentSpec{Type: "cylinder", Params: Params{"axis": float64(t.Wrapped)}}
+}
+
+// closestWrapped returns v, v-n or v+n, whichever is the closest to the given coordinate.
+func closestWrapped(v, to, n int) int {
+	closest := v
+	for _, candidate := range []int{v - n, v + n} {
+		if absInt(candidate-to) < absInt(closest-to) {
+			closest = candidate
+		}
+	}
+	return closest
+}
+
+func absInt(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
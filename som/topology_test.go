@@ -0,0 +1,60 @@
+package som_test
+
+import (
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+func TestGridDistanceInTopologies(t *testing.T) {
+	cases := []struct {
+		topology     som.Topology
+		toX30, toY04 float64
+	}{
+		{&som.PlanarTopology{}, 3, 4},
+		{&som.TorusTopology{}, 1, 1},
+		{&som.CylinderTopology{Wrapped: som.AxisX}, 1, 4},
+		{&som.CylinderTopology{Wrapped: som.AxisY}, 3, 1},
+	}
+
+	for _, aCase := range cases {
+		// from (0, 0) to (3, 0) and (0, 4) on a 4x5 map
+		toX30 := som.GridDistance(aCase.topology, 0, 0, 3, 0, 4, 5)
+		toY04 := som.GridDistance(aCase.topology, 0, 0, 0, 4, 4, 5)
+		if toX30 != aCase.toX30 || toY04 != aCase.toY04 {
+			t.Fatalf(
+				"Expected distances %f, %f in %T, got %f, %f",
+				aCase.toX30, aCase.toY04, aCase.topology, toX30, toY04,
+			)
+		}
+	}
+}
+
+func TestCylinderTopologyConnectsWrappedEdges(t *testing.T) {
+	learn := func(topology som.Topology) *som.SOM {
+		sm := som.New(3, 6)
+		sm.Topology = topology
+		sm.Influence = &som.RadiusReducingConstantInfluenceFunc{Radius: 1}
+		weights := make([][][]float64, 3)
+		for i := range weights {
+			weights[i] = make([][]float64, 6)
+			for j := range weights[i] {
+				weights[i][j] = []float64{0}
+			}
+		}
+		weights[1][0][0] = 1
+		sm.Initializer = &som.ProvidedWeightsInitializer{Weights: weights}
+		sm.LearnEntire(&som.DataSet{Vectors: []som.DataVector{{1}}})
+		return sm
+	}
+
+	planar := learn(&som.PlanarTopology{})
+	assertEq(t, planar.Neurons[1][5].Weights[0], 0.0)
+
+	cylinder := learn(&som.CylinderTopology{Wrapped: som.AxisY})
+	assertEq(t, cylinder.Neurons[1][5].Weights[0], 1.0)
+	assertEq(t, cylinder.Neurons[1][1].Weights[0], 1.0)
+	// x axis is not wrapped, (0, 0) is a neighbour anyway, (2, 5) is not
+	assertEq(t, cylinder.Neurons[0][0].Weights[0], 1.0)
+	assertEq(t, cylinder.Neurons[2][5].Weights[0], 0.0)
+}
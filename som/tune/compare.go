@@ -0,0 +1,215 @@
+// Package tune supports hyper-parameter experiments with self-organizing maps.
+package tune
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"image/png"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/voievodin/self-organizing-map/som"
+	"github.com/voievodin/self-organizing-map/som/quality"
+)
+
+// Names of the metrics set by NewRunResult.
+const (
+	MetricQuantizationError = "quantization_error"
+	MetricTopographicError  = "topographic_error"
+)
+
+// RunResult describes a training run.
+type RunResult struct {
+	Name string
+
+	// Params are the hyper-parameters of the run, e.g. "radius": "3".
+	Params map[string]string
+
+	// Metrics are the quality metrics of the trained map.
+	Metrics map[string]float64
+
+	// UMatrix of the trained map, used for thumbnails, may be nil.
+	UMatrix [][]float64
+}
+
+// NewRunResult creates the result of the run which trained the map,
+// computing quantization and topographic errors on the data set and the U-matrix.
+func NewRunResult(name string, params map[string]string, sm *som.SOM, ds *som.DataSet) RunResult {
+	return RunResult{
+		Name:   name,
+		Params: params,
+		Metrics: map[string]float64{
+			MetricQuantizationError: sm.QuantizationError(ds),
+			MetricTopographicError:  sm.TopographicError(ds),
+		},
+		UMatrix: sm.UMatrix(),
+	}
+}
+
+// Comparison is a side-by-side comparison of training runs.
+type Comparison struct {
+	Runs []RunResult
+
+	// Params and Metrics are the sorted names of all the parameters
+	// and metrics met in the runs, a run may miss some of them.
+	Params, Metrics []string
+}
+
+// CompareRuns creates a comparison of the runs, in the given order.
+func CompareRuns(results ...RunResult) *Comparison {
+	params, metrics := make(map[string]bool), make(map[string]bool)
+	for _, r := range results {
+		for name := range r.Params {
+			params[name] = true
+		}
+		for name := range r.Metrics {
+			metrics[name] = true
+		}
+	}
+	return &Comparison{Runs: results, Params: sortedKeys(params), Metrics: sortedKeys(metrics)}
+}
+
+// Best returns the index of the run with the lowest value of the metric,
+// -1 if no run has it.
+func (c *Comparison) Best(metric string) int {
+	best := -1
+	for i, r := range c.Runs {
+		v, ok := r.Metrics[metric]
+		if ok && !math.IsNaN(v) && (best == -1 || v < c.Runs[best].Metrics[metric]) {
+			best = i
+		}
+	}
+	return best
+}
+
+// WriteTable writes the comparison as a plain text table,
+// a column per run and a row per parameter and metric.
+func (c *Comparison) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprint(tw, "run")
+	for _, r := range c.Runs {
+		fmt.Fprintf(tw, "\t%s", r.Name)
+	}
+	fmt.Fprintln(tw)
+	for _, row := range c.rows() {
+		fmt.Fprint(tw, row.Name)
+		for _, cell := range row.Cells {
+			fmt.Fprintf(tw, "\t%s", cell.Value)
+		}
+		fmt.Fprintln(tw)
+	}
+	return tw.Flush()
+}
+
+// WriteHTML writes the comparison as an HTML page with U-matrix
+// thumbnails of the runs, the best metric values are highlighted.
+func (c *Comparison) WriteHTML(w io.Writer) error {
+	type column struct {
+		Name      string
+		Thumbnail template.URL
+	}
+	columns := make([]column, len(c.Runs))
+	for i, r := range c.Runs {
+		columns[i].Name = r.Name
+		if r.UMatrix != nil {
+			thumbnail, err := thumbnailURL(r.UMatrix)
+			if err != nil {
+				return err
+			}
+			columns[i].Thumbnail = thumbnail
+		}
+	}
+	return comparisonTemplate.Execute(w, struct {
+		Columns []column
+		Rows    []comparisonRow
+	}{columns, c.rows()})
+}
+
+type comparisonCell struct {
+	Value string
+	Best  bool
+}
+
+type comparisonRow struct {
+	Name  string
+	Cells []comparisonCell
+}
+
+func (c *Comparison) rows() []comparisonRow {
+	var rows []comparisonRow
+	for _, name := range c.Params {
+		row := comparisonRow{Name: name}
+		for _, r := range c.Runs {
+			v, ok := r.Params[name]
+			if !ok {
+				v = "-"
+			}
+			row.Cells = append(row.Cells, comparisonCell{Value: v})
+		}
+		rows = append(rows, row)
+	}
+	for _, name := range c.Metrics {
+		row := comparisonRow{Name: name}
+		best := c.Best(name)
+		for i, r := range c.Runs {
+			cell := comparisonCell{Value: "-", Best: i == best}
+			if v, ok := r.Metrics[name]; ok {
+				cell.Value = strconv.FormatFloat(v, 'g', 4, 64)
+			}
+			row.Cells = append(row.Cells, cell)
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// thumbnailScale is the size of a U-matrix cell in thumbnails, in pixels.
+const thumbnailScale = 4
+
+// thumbnailURL renders the U-matrix as a grayscale PNG data URL,
+// darker cells are closer to their neighbours, NaN cells are transparent.
+func thumbnailURL(umatrix [][]float64) (template.URL, error) {
+	img := quality.Render(umatrix, quality.RenderOptions{Scale: thumbnailScale, ColorMap: quality.Grays})
+
+	buf := &bytes.Buffer{}
+	if err := png.Encode(buf, img); err != nil {
+		return "", err
+	}
+	return template.URL("data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes())), nil
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+var comparisonTemplate = template.Must(template.New("comparison").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Runs comparison</title>
+<style>
+table { border-collapse: collapse; font-family: sans-serif; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: right; }
+td.best { font-weight: bold; background: #dfd; }
+img { image-rendering: pixelated; }
+</style>
+</head>
+<body>
+<table>
+<tr><th>run</th>{{range .Columns}}<th>{{.Name}}</th>{{end}}</tr>
+<tr><th>U-matrix</th>{{range .Columns}}<td>{{if .Thumbnail}}<img src="{{.Thumbnail}}" alt="U-matrix of {{.Name}}">{{end}}</td>{{end}}</tr>
+{{range .Rows}}<tr><th>{{.Name}}</th>{{range .Cells}}<td{{if .Best}} class="best"{{end}}>{{.Value}}</td>{{end}}</tr>
+{{end}}</table>
+</body>
+</html>
+`))
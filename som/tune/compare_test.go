@@ -0,0 +1,70 @@
+package tune_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+	"github.com/voievodin/self-organizing-map/som/tune"
+)
+
+func TestCompareRuns(t *testing.T) {
+	small := tune.RunResult{
+		Name:    "small",
+		Params:  map[string]string{"size": "2x2"},
+		Metrics: map[string]float64{"qe": 0.5},
+	}
+	large := tune.RunResult{
+		Name:    "large",
+		Params:  map[string]string{"size": "10x10", "radius": "3"},
+		Metrics: map[string]float64{"qe": 0.25},
+		UMatrix: [][]float64{{0, 1}, {2, 3}},
+	}
+
+	comparison := tune.CompareRuns(small, large)
+
+	if strings.Join(comparison.Params, ",") != "radius,size" {
+		t.Fatalf("Unexpected params %v", comparison.Params)
+	}
+	if comparison.Best("qe") != 1 {
+		t.Fatalf("Expected the large run to be the best, got %d", comparison.Best("qe"))
+	}
+
+	table := &bytes.Buffer{}
+	if err := comparison.WriteTable(table); err != nil {
+		t.Fatal(err)
+	}
+	expected := "run     small  large\n" +
+		"radius  -      3\n" +
+		"size    2x2    10x10\n" +
+		"qe      0.5    0.25\n"
+	if table.String() != expected {
+		t.Fatalf("Expected table\n%s\ngot\n%s", expected, table.String())
+	}
+
+	html := &bytes.Buffer{}
+	if err := comparison.WriteHTML(html); err != nil {
+		t.Fatal(err)
+	}
+	for _, part := range []string{`<td class="best">0.25</td>`, `<img src="data:image/png;base64,`, `<th>small</th>`} {
+		if !strings.Contains(html.String(), part) {
+			t.Fatalf("Expected HTML to contain %q, got\n%s", part, html.String())
+		}
+	}
+}
+
+func TestNewRunResult(t *testing.T) {
+	sm := som.New(1, 2)
+	sm.LoadCodebook([][][]float64{{{0}, {1}}})
+	ds := &som.DataSet{Vectors: []som.DataVector{{0.25}, {1}}}
+
+	result := tune.NewRunResult("run", nil, sm, ds)
+
+	if result.Metrics[tune.MetricQuantizationError] != 0.125 {
+		t.Fatalf("Unexpected metrics %v", result.Metrics)
+	}
+	if len(result.UMatrix) != 1 || len(result.UMatrix[0]) != 2 {
+		t.Fatalf("Unexpected U-matrix %v", result.UMatrix)
+	}
+}
@@ -0,0 +1,128 @@
+package tune
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+// Size is the size of a map grid.
+type Size struct {
+	X, Y int
+}
+
+func (s Size) String() string {
+	return fmt.Sprintf("%dx%d", s.X, s.Y)
+}
+
+// GridSearch trains a map for each combination of the sizes and the radii
+// and compares the runs. The maps are initialized by random vectors of the
+// data set and learn the vectors in random order with the exponentially
+// decaying rate from 0.5 and the gaussian neighbourhood decaying
+// from the radius to 0.5, see som.NewInfluence("gaussian-exp-decay").
+type GridSearch struct {
+	Sizes []Size
+	Radii []float64
+
+	// Iterations is the number of learning iterations of each map.
+	Iterations int
+
+	// Seed seeds the random components of each map,
+	// so runs with the same parameters produce the same maps.
+	Seed int64
+}
+
+// Run trains the maps on the data set and returns the comparison
+// of the runs and the trained maps, maps[i] is the map of the run i.
+func (g *GridSearch) Run(ds *som.DataSet) (*Comparison, []*som.SOM, error) {
+	if len(g.Sizes) == 0 || len(g.Radii) == 0 || g.Iterations <= 0 {
+		return nil, nil, fmt.Errorf("%w: grid search needs sizes, radii and iterations", som.ErrInvalidConfig)
+	}
+	var results []RunResult
+	var maps []*som.SOM
+	for _, size := range g.Sizes {
+		for _, radius := range g.Radii {
+			sm, err := g.train(ds, size, radius)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%s radius %g: %w", size, radius, err)
+			}
+			r := strconv.FormatFloat(radius, 'g', -1, 64)
+			params := map[string]string{"size": size.String(), "radius": r}
+			results = append(results, NewRunResult(size.String()+"-r"+r, params, sm, ds))
+			maps = append(maps, sm)
+		}
+	}
+	return CompareRuns(results...), maps, nil
+}
+
+func (g *GridSearch) train(ds *som.DataSet, size Size, radius float64) (*som.SOM, error) {
+	rng := rand.New(rand.NewSource(g.Seed))
+	sm := som.New(size.X, size.Y)
+	var err error
+	if sm.Initializer, err = som.NewInitializer("rand-vectors", nil, rng); err != nil {
+		return nil, err
+	}
+	if sm.Restraint, err = som.NewRestraint("exp", som.Params{"initial_rate": 0.5}); err != nil {
+		return nil, err
+	}
+	if sm.Influence, err = som.NewInfluence("gaussian-exp-decay", som.Params{"initial_width": radius, "min_width": 0.5}); err != nil {
+		return nil, err
+	}
+	sm.Selector = &som.RandSelector{Rand: rng}
+	sm.TieBreaker = &som.RandTieBreaker{Rand: rng}
+	return sm, sm.Learn(ds, g.Iterations)
+}
+
+// Ranked returns the indices of the runs ordered by the ascending
+// value of the metric, the runs without it (or with NaN) go last.
+func (c *Comparison) Ranked(metric string) []int {
+	ranked := make([]int, len(c.Runs))
+	for i := range ranked {
+		ranked[i] = i
+	}
+	value := func(i int) float64 {
+		v, ok := c.Runs[i].Metrics[metric]
+		if !ok || math.IsNaN(v) {
+			return math.Inf(1)
+		}
+		return v
+	}
+	sort.SliceStable(ranked, func(i, j int) bool { return value(ranked[i]) < value(ranked[j]) })
+	return ranked
+}
+
+// WriteCSV writes the runs ranked by the metric as CSV, a record per run
+// with its rank, name, parameters and metrics, see Ranked.
+func (c *Comparison) WriteCSV(w io.Writer, metric string) error {
+	cw := csv.NewWriter(w)
+	header := append(append([]string{"rank", "run"}, c.Params...), c.Metrics...)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for rank, i := range c.Ranked(metric) {
+		r := c.Runs[i]
+		record := []string{strconv.Itoa(rank + 1), r.Name}
+		for _, name := range c.Params {
+			record = append(record, r.Params[name])
+		}
+		for _, name := range c.Metrics {
+			v, ok := r.Metrics[name]
+			if !ok {
+				record = append(record, "")
+				continue
+			}
+			record = append(record, strconv.FormatFloat(v, 'g', -1, 64))
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
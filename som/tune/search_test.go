@@ -0,0 +1,77 @@
+package tune_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+	"github.com/voievodin/self-organizing-map/som/tune"
+)
+
+func TestGridSearch(t *testing.T) {
+	ds := &som.DataSet{}
+	for i := 0; i < 40; i++ {
+		ds.AddRaw(float64(i%5)/4, float64(i%8)/7)
+	}
+	search := &tune.GridSearch{
+		Sizes:      []tune.Size{{1, 2}, {4, 4}},
+		Radii:      []float64{1, 2},
+		Iterations: 400,
+		Seed:       1,
+	}
+	comparison, maps, err := search.Run(ds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(comparison.Runs) != 4 || len(maps) != 4 {
+		t.Fatalf("Expected 4 runs, got %d runs and %d maps", len(comparison.Runs), len(maps))
+	}
+	if name := comparison.Runs[3].Name; name != "4x4-r2" {
+		t.Fatalf("Unexpected name of the last run %s", name)
+	}
+	if x, y := maps[3].Dims(); x != 4 || y != 4 {
+		t.Fatalf("Unexpected dims of the last map %dx%d", x, y)
+	}
+
+	// the larger map quantizes the data better
+	ranked := comparison.Ranked(tune.MetricQuantizationError)
+	if comparison.Runs[ranked[0]].Params["size"] != "4x4" || comparison.Runs[ranked[3]].Params["size"] != "1x2" {
+		t.Fatalf("Unexpected ranking %v", ranked)
+	}
+
+	again, _, err := search.Run(ds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Runs[0].Metrics[tune.MetricQuantizationError] != comparison.Runs[0].Metrics[tune.MetricQuantizationError] {
+		t.Fatal("Expected the seeded runs to be reproducible")
+	}
+
+	if _, _, err := (&tune.GridSearch{Sizes: search.Sizes}).Run(ds); !errors.Is(err, som.ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig, got %v", err)
+	}
+}
+
+func TestComparisonWriteCSV(t *testing.T) {
+	comparison := tune.CompareRuns(
+		tune.RunResult{Name: "a", Params: map[string]string{"size": "2x2"}, Metrics: map[string]float64{"qe": 0.5}},
+		tune.RunResult{Name: "b", Params: map[string]string{"size": "3x3"}},
+		tune.RunResult{Name: "c", Params: map[string]string{"size": "4x4"}, Metrics: map[string]float64{"qe": 0.25}},
+	)
+	out := &bytes.Buffer{}
+	if err := comparison.WriteCSV(out, "qe"); err != nil {
+		t.Fatal(err)
+	}
+	expected := strings.Join([]string{
+		"rank,run,size,qe",
+		"1,c,4x4,0.25",
+		"2,a,2x2,0.5",
+		"3,b,3x3,",
+		"",
+	}, "\n")
+	if out.String() != expected {
+		t.Fatalf("Expected CSV\n%s\ngot\n%s", expected, out.String())
+	}
+}
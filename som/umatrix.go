@@ -0,0 +1,70 @@
+package som
+
+import "math"
+
+// UMatrix computes the unified distance matrix of this map, the value
+// at (x, y) is the average distance between the weights of the neuron at (x, y)
+// and its direct (non-diagonal) neighbours, including the neighbours
+// across the edges connected by Topology, or the neighbours of MetricTopology.
+// High values separate clusters. Masked neurons are not neighbours of any neuron and have NaN values.
+func (som *SOM) UMatrix() [][]float64 {
+	xLen, yLen := len(som.Neurons), len(som.Neurons[0])
+	umatrix := make([][]float64, xLen)
+	for x := range umatrix {
+		umatrix[x] = make([]float64, yLen)
+		for y := range umatrix[x] {
+			if som.IsMasked(x, y) {
+				umatrix[x][y] = math.NaN()
+				continue
+			}
+			sum, n := 0.0, 0
+			for _, p := range som.directNeighbours(x, y) {
+				if som.IsMasked(p[0], p[1]) {
+					continue
+				}
+				sum += som.Distance.Apply(som.Neurons[x][y].Weights, som.Neurons[p[0]][p[1]].Weights)
+				n++
+			}
+			if n != 0 {
+				umatrix[x][y] = sum / float64(n)
+			}
+		}
+	}
+	return umatrix
+}
+
+// directNeighbours returns the positions of the direct (non-diagonal)
+// neighbours of the neuron at (x, y), or its neighbours of MetricTopology.
+func (som *SOM) directNeighbours(x, y int) [][2]int {
+	xLen, yLen := len(som.Neurons), len(som.Neurons[0])
+	if metric, ok := som.Topology.(MetricTopology); ok {
+		return metric.Neighbours(x, y, xLen, yLen)
+	}
+	neighbours := make([][2]int, 0, 4)
+	for _, d := range [][2]int{{-1, 0}, {1, 0}, {0, -1}, {0, 1}} {
+		if nx, ny, ok := som.neighbour(x, y, d[0], d[1]); ok {
+			neighbours = append(neighbours, [2]int{nx, ny})
+		}
+	}
+	return neighbours
+}
+
+// neighbour returns the position of the neuron at the (dx, dy) offset
+// from the (x, y) one, wrapping the edges connected by Topology.
+func (som *SOM) neighbour(x, y, dx, dy int) (int, int, bool) {
+	xLen, yLen := len(som.Neurons), len(som.Neurons[0])
+	nx, ny := x+dx, y+dy
+	if nx >= 0 && nx < xLen && ny >= 0 && ny < yLen {
+		return nx, ny, true
+	}
+	wx, wy := (nx+xLen)%xLen, (ny+yLen)%yLen
+	if wx == x && wy == y {
+		return 0, 0, false
+	}
+	// the wrapped neuron is the neighbour if its image is at the offset
+	ix, iy := som.Topology.Closest(wx, wy, x, y, xLen, yLen)
+	if ix == nx && iy == ny {
+		return wx, wy, true
+	}
+	return 0, 0, false
+}
@@ -0,0 +1,26 @@
+package som_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+func TestUMatrix(t *testing.T) {
+	sm := som.New(1, 4)
+	sm.LoadCodebook([][][]float64{{{0}, {1}, {3}, {6}}})
+
+	umatrix := sm.UMatrix()
+	checkSlicesEqual(t, umatrix[0], []float64{1, 1.5, 2.5, 3})
+
+	sm.Topology = &som.CylinderTopology{Wrapped: som.AxisY}
+	checkSlicesEqual(t, sm.UMatrix()[0], []float64{3.5, 1.5, 2.5, 4.5})
+
+	sm.Mask = [][]bool{{false, true, false, false}}
+	umatrix = sm.UMatrix()
+	if !math.IsNaN(umatrix[0][1]) {
+		t.Fatalf("Expected masked neuron to be NaN, got %f", umatrix[0][1])
+	}
+	assertEq(t, umatrix[0][2], 3.0)
+}
@@ -0,0 +1,129 @@
+// Package vec implements the arithmetic of vectors, e.g. som.DataVector
+// and neurons weights, which the som package uses in its update loops.
+// The functions are generic over the element type, see Float, so DataVector
+// values and float32 vectors of compact codebooks are passed as is.
+//
+// The in-place functions modify their first argument and return it,
+// the To functions write the result into dst, which is reused if it has
+// enough capacity and allocated otherwise, and return it. All the functions
+// panic if the lengths of the vectors differ, like index expressions do.
+package vec
+
+import "math"
+
+// Float is the element type of vectors.
+type Float interface {
+	~float32 | ~float64
+}
+
+// Add adds b to a in place.
+func Add[T Float](a, b []T) []T {
+	checkLen(a, b)
+	for k, v := range b {
+		a[k] += v
+	}
+	return a
+}
+
+// AddTo writes a + b to dst.
+func AddTo[T Float](dst, a, b []T) []T {
+	checkLen(a, b)
+	dst = resize(dst, len(a))
+	for k := range dst {
+		dst[k] = a[k] + b[k]
+	}
+	return dst
+}
+
+// AddScaled adds c*b to a in place.
+func AddScaled[T Float](a []T, c T, b []T) []T {
+	checkLen(a, b)
+	for k, v := range b {
+		a[k] += c * v
+	}
+	return a
+}
+
+// Sub subtracts b from a in place.
+func Sub[T Float](a, b []T) []T {
+	checkLen(a, b)
+	for k, v := range b {
+		a[k] -= v
+	}
+	return a
+}
+
+// SubTo writes a - b to dst.
+func SubTo[T Float](dst, a, b []T) []T {
+	checkLen(a, b)
+	dst = resize(dst, len(a))
+	for k := range dst {
+		dst[k] = a[k] - b[k]
+	}
+	return dst
+}
+
+// Scale multiplies a by c in place.
+func Scale[T Float](a []T, c T) []T {
+	for k := range a {
+		a[k] *= c
+	}
+	return a
+}
+
+// ScaleTo writes c*a to dst.
+func ScaleTo[T Float](dst []T, c T, a []T) []T {
+	dst = resize(dst, len(a))
+	for k, v := range a {
+		dst[k] = c * v
+	}
+	return dst
+}
+
+// Clamp limits the values of a to [min, max] in place, NaN values stay NaN.
+func Clamp[T Float](a []T, min, max T) []T {
+	for k, v := range a {
+		if v < min {
+			a[k] = min
+		} else if v > max {
+			a[k] = max
+		}
+	}
+	return a
+}
+
+// ClampTo writes a with the values limited to [min, max] to dst.
+func ClampTo[T Float](dst, a []T, min, max T) []T {
+	dst = resize(dst, len(a))
+	copy(dst, a)
+	return Clamp(dst, min, max)
+}
+
+// Dot returns the dot product of a and b.
+func Dot[T Float](a, b []T) T {
+	checkLen(a, b)
+	var sum T
+	for k, v := range a {
+		sum += v * b[k]
+	}
+	return sum
+}
+
+// Norm returns the Euclidean norm of a.
+func Norm[T Float](a []T) T {
+	return T(math.Sqrt(float64(Dot(a, a))))
+}
+
+func checkLen[T Float](a, b []T) {
+	if len(a) != len(b) {
+		panic("vec: vector lengths differ")
+	}
+}
+
+// resize returns dst of length n, reusing its array if it's big enough.
+func resize[T Float](dst []T, n int) []T {
+	if cap(dst) < n {
+		return make([]T, n)
+	}
+	return dst[:n]
+}
@@ -0,0 +1,63 @@
+package vec_test
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+	"github.com/voievodin/self-organizing-map/som/vec"
+)
+
+func TestInPlace(t *testing.T) {
+	a := som.DataVector{1, 2, 3}
+	vec.Add(a, som.DataVector{1, 1, 1})
+	vec.Sub(a, []float64{0, 1, 2})
+	vec.Scale(a, 2)
+	vec.AddScaled(a, 0.5, []float64{2, 2, 2})
+	if expected := (som.DataVector{5, 5, 5}); !reflect.DeepEqual(a, expected) {
+		t.Fatalf("Expected %v, got %v", expected, a)
+	}
+	if clamped := vec.Clamp([]float64{-1, 0.5, 2, math.NaN()}, 0, 1); !reflect.DeepEqual(clamped[:3], []float64{0, 0.5, 1}) || !math.IsNaN(clamped[3]) {
+		t.Fatalf("Unexpected clamped vector %v", clamped)
+	}
+}
+
+func TestOutOfPlace(t *testing.T) {
+	a, b := []float64{1, 2}, []float64{3, 5}
+	dst := make([]float64, 0, 2)
+	sum := vec.AddTo(dst, a, b)
+	if !reflect.DeepEqual(sum, []float64{4, 7}) || &sum[0] != &dst[:1][0] {
+		t.Fatalf("Expected the sum written to dst, got %v", sum)
+	}
+	if diff := vec.SubTo(nil, b, a); !reflect.DeepEqual(diff, []float64{2, 3}) {
+		t.Fatalf("Unexpected difference %v", diff)
+	}
+	if scaled := vec.ScaleTo(nil, 3, a); !reflect.DeepEqual(scaled, []float64{3, 6}) {
+		t.Fatalf("Unexpected scaled vector %v", scaled)
+	}
+	if clamped := vec.ClampTo(nil, b, 0, 4); !reflect.DeepEqual(clamped, []float64{3, 4}) {
+		t.Fatalf("Unexpected clamped vector %v", clamped)
+	}
+	if !reflect.DeepEqual(a, []float64{1, 2}) || !reflect.DeepEqual(b, []float64{3, 5}) {
+		t.Fatalf("Expected the arguments not modified, got %v and %v", a, b)
+	}
+}
+
+func TestDotAndNorm(t *testing.T) {
+	if dot := vec.Dot([]float64{1, 2}, []float64{3, 4}); dot != 11 {
+		t.Fatalf("Expected dot product 11, got %v", dot)
+	}
+	if norm := vec.Norm([]float64{3, 4}); norm != 5 {
+		t.Fatalf("Expected norm 5, got %v", norm)
+	}
+}
+
+func TestLengthMismatchPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Expected panic")
+		}
+	}()
+	vec.Add([]float64{1}, []float64{1, 2})
+}
@@ -0,0 +1,171 @@
+package som
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// ErrUnsupportedType is returned when a value can't be mapped to data vectors.
+var ErrUnsupportedType = errors.New("unsupported type")
+
+// StructMapper maps numeric fields of structs to data vectors and back,
+// the k-th vector value is the value of the k-th field of Fields.
+// Integer, float and bool (1 or 0) fields are supported.
+type StructMapper struct {
+	Type   reflect.Type
+	Fields []string
+
+	indices []int
+}
+
+// NewStructMapper creates a mapper for the struct type of the sample value,
+// which may be a struct or a pointer to struct. If fields are not given,
+// all the exported numeric fields are mapped, except the ones tagged
+// with `som:"-"`, otherwise the given fields are mapped in the given order.
+func NewStructMapper(sample interface{}, fields ...string) (*StructMapper, error) {
+	typ := reflect.TypeOf(sample)
+	if typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: %v is not a struct", ErrUnsupportedType, typ)
+	}
+
+	mapper := &StructMapper{Type: typ}
+	if len(fields) == 0 {
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			if field.IsExported() && isNumericKind(field.Type.Kind()) && field.Tag.Get("som") != "-" {
+				mapper.Fields = append(mapper.Fields, field.Name)
+				mapper.indices = append(mapper.indices, i)
+			}
+		}
+		if len(mapper.Fields) == 0 {
+			return nil, fmt.Errorf("%w: %v has no numeric fields", ErrUnsupportedType, typ)
+		}
+		return mapper, nil
+	}
+
+	for _, name := range fields {
+		field, ok := typ.FieldByName(name)
+		if !ok || len(field.Index) != 1 || !field.IsExported() {
+			return nil, fmt.Errorf("%w: %v has no exported field %q", ErrUnsupportedType, typ, name)
+		}
+		if !isNumericKind(field.Type.Kind()) {
+			return nil, fmt.Errorf("%w: field %q of %v is %v", ErrUnsupportedType, name, typ, field.Type)
+		}
+		mapper.Fields = append(mapper.Fields, name)
+		mapper.indices = append(mapper.indices, field.Index[0])
+	}
+	return mapper, nil
+}
+
+// VectorizeStructs maps the structs of the slice to a data set, see NewStructMapper.
+// The slice elements may be structs or pointers to structs. The returned mapper
+// maps vectors back to structs, e.g. to interpret the codebook.
+func VectorizeStructs(slice interface{}, fields ...string) (*DataSet, *StructMapper, error) {
+	value := reflect.ValueOf(slice)
+	if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+		return nil, nil, fmt.Errorf("%w: %T is not a slice", ErrUnsupportedType, slice)
+	}
+	mapper, err := NewStructMapper(reflect.Zero(value.Type().Elem()).Interface(), fields...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ds := &DataSet{Vectors: make([]DataVector, value.Len())}
+	for i := range ds.Vectors {
+		if ds.Vectors[i], err = mapper.vector(value.Index(i)); err != nil {
+			return nil, nil, fmt.Errorf("element %d: %w", i, err)
+		}
+	}
+	ds.SetColumns(mapper.Columns())
+	return ds, mapper, nil
+}
+
+// Columns returns the columns named after the mapped fields,
+// units are taken from the `unit` tags of the fields.
+func (m *StructMapper) Columns() []Column {
+	columns := make([]Column, len(m.Fields))
+	for k, idx := range m.indices {
+		field := m.Type.Field(idx)
+		columns[k] = Column{Name: field.Name, Unit: field.Tag.Get("unit")}
+	}
+	return columns
+}
+
+// Vector maps the struct, or pointer to struct, to a data vector.
+func (m *StructMapper) Vector(v interface{}) (DataVector, error) {
+	return m.vector(reflect.ValueOf(v))
+}
+
+func (m *StructMapper) vector(value reflect.Value) (DataVector, error) {
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return nil, fmt.Errorf("%w: nil %v", ErrUnsupportedType, value.Type())
+		}
+		value = value.Elem()
+	}
+	if value.Type() != m.Type {
+		return nil, fmt.Errorf("%w: %v is not %v", ErrUnsupportedType, value.Type(), m.Type)
+	}
+
+	vector := make(DataVector, len(m.indices))
+	for k, idx := range m.indices {
+		field := value.Field(idx)
+		switch field.Kind() {
+		case reflect.Float32, reflect.Float64:
+			vector[k] = field.Float()
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			vector[k] = float64(field.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			vector[k] = float64(field.Uint())
+		case reflect.Bool:
+			if field.Bool() {
+				vector[k] = 1
+			}
+		}
+	}
+	return vector, nil
+}
+
+// Struct sets the mapped fields of the struct dst points to from the vector.
+// Integer fields are rounded to the nearest value, bool ones are true if the value is >= 0.5.
+func (m *StructMapper) Struct(vector []float64, dst interface{}) error {
+	value := reflect.ValueOf(dst)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Type() != m.Type {
+		return fmt.Errorf("%w: %T is not a pointer to %v", ErrUnsupportedType, dst, m.Type)
+	}
+	if len(vector) != len(m.indices) {
+		return fmt.Errorf("%w: vector length is %d, %d fields are mapped", ErrWidthMismatch, len(vector), len(m.indices))
+	}
+
+	value = value.Elem()
+	for k, idx := range m.indices {
+		field := value.Field(idx)
+		switch field.Kind() {
+		case reflect.Float32, reflect.Float64:
+			field.SetFloat(vector[k])
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			field.SetInt(int64(math.Round(vector[k])))
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			field.SetUint(uint64(math.Max(math.Round(vector[k]), 0)))
+		case reflect.Bool:
+			field.SetBool(vector[k] >= 0.5)
+		}
+	}
+	return nil
+}
+
+func isNumericKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Bool:
+		return true
+	}
+	return false
+}
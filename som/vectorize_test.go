@@ -0,0 +1,57 @@
+package som_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+type measurement struct {
+	Height  float64
+	Age     int
+	Label   string
+	Active  bool
+	Ignored float64 `som:"-"`
+	weight  float64
+}
+
+func TestVectorizeStructs(t *testing.T) {
+	items := []*measurement{
+		{Height: 1.8, Age: 30, Label: "a", Active: true, Ignored: 7},
+		{Height: 1.6, Age: 25, Label: "b"},
+	}
+
+	ds, mapper, err := som.VectorizeStructs(items)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertEq(t, len(mapper.Fields), 3)
+	checkSlicesEqual(t, ds.Vectors[0], []float64{1.8, 30, 1})
+	checkSlicesEqual(t, ds.Vectors[1], []float64{1.6, 25, 0})
+
+	var m measurement
+	if err := mapper.Struct([]float64{1.7, 27.6, 0.7}, &m); err != nil {
+		t.Fatal(err)
+	}
+	assertEq(t, m, measurement{Height: 1.7, Age: 28, Active: true})
+}
+
+func TestVectorizeStructsSelectedFields(t *testing.T) {
+	ds, mapper, err := som.VectorizeStructs([]measurement{{Height: 2, Age: 40}}, "Age", "Height")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkSlicesEqual(t, ds.Vectors[0], []float64{40, 2})
+
+	if _, err := mapper.Vector(struct{ Age int }{}); !errors.Is(err, som.ErrUnsupportedType) {
+		t.Fatalf("Expected ErrUnsupportedType for other type, got %v", err)
+	}
+	if _, _, err := som.VectorizeStructs([]measurement{}, "Label"); !errors.Is(err, som.ErrUnsupportedType) {
+		t.Fatalf("Expected ErrUnsupportedType for string field, got %v", err)
+	}
+	if _, _, err := som.VectorizeStructs([]int{1}); !errors.Is(err, som.ErrUnsupportedType) {
+		t.Fatalf("Expected ErrUnsupportedType for non-struct elements, got %v", err)
+	}
+}
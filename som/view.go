@@ -0,0 +1,125 @@
+package som
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// DataView is an immutable snapshot of a data set, created by DataSet.Freeze.
+// A view refers to the vectors of the snapshot by indices, so Select, Split
+// and Shuffled create new views without copying the vectors.
+// Views are safe for concurrent use, as long as the vectors
+// themselves are not modified in place.
+type DataView struct {
+	vectors []DataVector
+	indices []int
+	columns []Column
+}
+
+// Freeze returns an immutable snapshot of this data set.
+// If the data set has an adapter, the vectors and the columns of the snapshot are adapted,
+// so the snapshot doesn't depend on the adapter cache of this data set.
+// The vectors are shared with this data set, which is still free to
+// Add, Shuffle, Sort or Reduce its vectors after the snapshot is taken.
+func (ds *DataSet) Freeze() *DataView {
+	vectors := make([]DataVector, ds.Len())
+	if ds.adapter == nil {
+		copy(vectors, ds.Vectors)
+	} else {
+		for i := range vectors {
+			vectors[i] = ds.At(i)
+		}
+	}
+	return &DataView{vectors: vectors, columns: ds.AdaptedColumns()}
+}
+
+// Len returns the number of vectors in this view.
+func (v *DataView) Len() int {
+	if v.indices == nil {
+		return len(v.vectors)
+	}
+	return len(v.indices)
+}
+
+// Width returns the length of a single vector from this view.
+func (v *DataView) Width() int {
+	if v.Len() == 0 {
+		panic("data view contains no elements")
+	}
+	return len(v.At(0))
+}
+
+// Columns returns feature metadata of this view, nil if it's not set.
+func (v *DataView) Columns() []Column {
+	if v.columns == nil {
+		return nil
+	}
+	return append([]Column(nil), v.columns...)
+}
+
+// At returns the vector at the given index of this view.
+func (v *DataView) At(i int) DataVector {
+	if v.indices == nil {
+		return v.vectors[i]
+	}
+	return v.vectors[v.indices[i]]
+}
+
+// Select returns a view of the vectors at the given indices of this view.
+func (v *DataView) Select(indices ...int) *DataView {
+	selected := make([]int, len(indices))
+	for k, i := range indices {
+		if i < 0 || i >= v.Len() {
+			panic(fmt.Sprintf("data view index %d out of range [0, %d)", i, v.Len()))
+		}
+		selected[k] = v.index(i)
+	}
+	return &DataView{vectors: v.vectors, indices: selected, columns: v.columns}
+}
+
+// Split splits this view into two views, the first one contains
+// the first ratio part of the vectors, the second one contains the rest.
+// The ratio must be in range [0, 1]. Use Shuffled first
+// for random train/test splits.
+func (v *DataView) Split(ratio float64) (*DataView, *DataView) {
+	if ratio < 0 || ratio > 1 {
+		panic(fmt.Sprintf("split ratio %f is not in range [0, 1]", ratio))
+	}
+	n := int(float64(v.Len())*ratio + 0.5)
+	return v.slice(0, n), v.slice(n, v.Len())
+}
+
+// Shuffled returns a view of the vectors of this view in random order.
+func (v *DataView) Shuffled() *DataView {
+	shuffled := make([]int, v.Len())
+	for i, j := range rand.Perm(v.Len()) {
+		shuffled[i] = v.index(j)
+	}
+	return &DataView{vectors: v.vectors, indices: shuffled, columns: v.columns}
+}
+
+// DataSet returns a new data set containing the vectors of this view
+// and their columns, e.g. to train a map on it. The vectors are shared, not copied,
+// the data set itself may be freely mutated.
+func (v *DataView) DataSet() *DataSet {
+	vectors := make([]DataVector, v.Len())
+	for i := range vectors {
+		vectors[i] = v.At(i)
+	}
+	return &DataSet{Vectors: vectors, columns: v.columns}
+}
+
+func (v *DataView) index(i int) int {
+	if v.indices == nil {
+		return i
+	}
+	return v.indices[i]
+}
+
+func (v *DataView) slice(from, to int) *DataView {
+	indices := make([]int, to-from)
+	for i := range indices {
+		indices[i] = v.index(from + i)
+	}
+	return &DataView{vectors: v.vectors, indices: indices, columns: v.columns}
+}
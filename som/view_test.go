@@ -0,0 +1,104 @@
+package som_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+func TestFrozenViewIsNotAffectedByDataSetMutations(t *testing.T) {
+	dataSet := &som.DataSet{}
+	dataSet.AddRaw(3)
+	dataSet.AddRaw(1)
+	dataSet.AddRaw(2)
+
+	view := dataSet.Freeze()
+	dataSet.Sort()
+	dataSet.Reduce(1)
+	dataSet.AddRaw(4)
+
+	assertEq(t, view.Len(), 3)
+	checkSlicesEqual(t, view.At(0), []float64{3})
+	checkSlicesEqual(t, view.At(1), []float64{1})
+	checkSlicesEqual(t, view.At(2), []float64{2})
+}
+
+func TestSortDoesNotModifyPreviousVectorsSlice(t *testing.T) {
+	dataSet := &som.DataSet{}
+	dataSet.AddRaw(2)
+	dataSet.AddRaw(1)
+	vectors := dataSet.Vectors
+
+	dataSet.Sort()
+
+	checkSlicesEqual(t, vectors[0], []float64{2})
+	checkSlicesEqual(t, dataSet.Vectors[0], []float64{1})
+}
+
+func TestFreezeAdaptsVectors(t *testing.T) {
+	dataSet := &som.DataSet{}
+	dataSet.AddRaw(0, 10)
+	dataSet.AddRaw(10, 20)
+	dataSet.SetAdapter(som.NewScalingDataAdapter([]float64{0, 10}, []float64{10, 20}))
+
+	view := dataSet.Freeze()
+
+	checkSlicesEqual(t, view.At(1), []float64{1, 1})
+	checkSlicesEqual(t, dataSet.Vectors[1], []float64{10, 20})
+}
+
+func TestDataViewSelectAndSplit(t *testing.T) {
+	dataSet := &som.DataSet{}
+	for i := 0; i < 5; i++ {
+		dataSet.AddRaw(float64(i))
+	}
+
+	selected := dataSet.Freeze().Select(4, 2, 0, 1)
+	train, test := selected.Split(0.5)
+
+	assertEq(t, train.Len(), 2)
+	assertEq(t, test.Len(), 2)
+	checkSlicesEqual(t, train.At(0), []float64{4})
+	checkSlicesEqual(t, train.At(1), []float64{2})
+	checkSlicesEqual(t, test.Select(1).At(0), []float64{1})
+	assertEq(t, test.DataSet().Len(), 2)
+}
+
+func TestDataViewShuffledKeepsVectors(t *testing.T) {
+	dataSet := &som.DataSet{}
+	for i := 0; i < 10; i++ {
+		dataSet.AddRaw(float64(i))
+	}
+	view := dataSet.Freeze()
+
+	shuffled := view.Shuffled()
+
+	seen := make(map[float64]bool)
+	for i := 0; i < shuffled.Len(); i++ {
+		seen[shuffled.At(i)[0]] = true
+	}
+	assertEq(t, len(seen), 10)
+}
+
+func TestDataViewConcurrentReads(t *testing.T) {
+	dataSet := &som.DataSet{}
+	for i := 0; i < 100; i++ {
+		dataSet.AddRaw(float64(i), float64(i))
+	}
+	view := dataSet.Freeze()
+
+	wg := sync.WaitGroup{}
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < view.Len(); i++ {
+				_ = view.Shuffled().At(i)
+			}
+		}()
+	}
+	dataSet.Shuffle()
+	dataSet.Sort()
+	wg.Wait()
+}
@@ -0,0 +1,147 @@
+//go:build js && wasm
+
+// Command wasm exports inference of trained maps to JavaScript, so they can
+// power in-browser visualizations. Build it by
+//
+//	GOOS=js GOARCH=wasm go build -o som.wasm ./som/wasm
+//
+// and run it by wasm_exec.js of the Go distribution. It defines the global
+// som object:
+//
+//	const model = som.load(bytes)           // Uint8Array or string saved by SaveJSON or SaveBinary
+//	model.dims                              // [x, y]
+//	model.map([0.1, 0.7])                   // {x, y} of the BMU
+//	model.umatrix()                         // [[...], ...], see som.Model.UMatrix
+//	model.activation([[0.1, 0.7], ...])     // {hits, entropy, normalizedEntropy, dead, neurons}, see quality.Activation
+//
+// Failures are returned as {error: message} instead of results.
+package main
+
+import (
+	"bytes"
+	"syscall/js"
+
+	"github.com/voievodin/self-organizing-map/som"
+	"github.com/voievodin/self-organizing-map/som/quality"
+)
+
+func main() {
+	js.Global().Set("som", js.ValueOf(map[string]interface{}{
+		"load": js.FuncOf(load),
+	}))
+	select {}
+}
+
+// load loads the model from its first argument and returns its bindings.
+func load(this js.Value, args []js.Value) interface{} {
+	if len(args) != 1 {
+		return jsError("load expects the saved model")
+	}
+	var data []byte
+	if args[0].Type() == js.TypeString {
+		data = []byte(args[0].String())
+	} else {
+		data = make([]byte, args[0].Get("length").Int())
+		js.CopyBytesToGo(data, args[0])
+	}
+	var sm *som.SOM
+	var err error
+	if bytes.HasPrefix(data, []byte("SOMB")) {
+		sm, err = som.LoadBinary(bytes.NewReader(data))
+	} else {
+		sm, err = som.LoadJSON(bytes.NewReader(data))
+	}
+	if err != nil {
+		return jsError(err.Error())
+	}
+	model, err := sm.Model()
+	if err != nil {
+		return jsError(err.Error())
+	}
+	xLen, yLen := model.Dims()
+	return js.ValueOf(map[string]interface{}{
+		"dims":       []interface{}{xLen, yLen},
+		"map":        js.FuncOf(func(this js.Value, args []js.Value) interface{} { return mapVector(model, args) }),
+		"umatrix":    js.FuncOf(func(this js.Value, args []js.Value) interface{} { return matrix(model.UMatrix()) }),
+		"activation": js.FuncOf(func(this js.Value, args []js.Value) interface{} { return activation(model, args) }),
+	})
+}
+
+func mapVector(model *som.Model, args []js.Value) interface{} {
+	if len(args) != 1 {
+		return jsError("map expects the vector")
+	}
+	bmu, err := model.BMU(vector(args[0]))
+	if err != nil {
+		return jsError(err.Error())
+	}
+	return map[string]interface{}{"x": bmu.X, "y": bmu.Y}
+}
+
+func activation(model *som.Model, args []js.Value) interface{} {
+	if len(args) != 1 {
+		return jsError("activation expects the vectors")
+	}
+	vectors := make([]som.DataVector, args[0].Length())
+	for i := range vectors {
+		vectors[i] = vector(args[0].Index(i))
+	}
+	points, err := model.MapBatch(vectors)
+	if err != nil {
+		return jsError(err.Error())
+	}
+	xLen, yLen := model.Dims()
+	hits := make([][]int, xLen)
+	for x := range hits {
+		hits[x] = make([]int, yLen)
+		for y := range hits[x] {
+			if model.IsMasked(x, y) {
+				hits[x][y] = -1
+			}
+		}
+	}
+	for _, p := range points {
+		hits[p.X][p.Y]++
+	}
+	summary := quality.Activation(hits)
+	rows := make([]interface{}, xLen)
+	for x := range hits {
+		row := make([]interface{}, yLen)
+		for y, h := range hits[x] {
+			row[y] = h
+		}
+		rows[x] = row
+	}
+	return map[string]interface{}{
+		"hits":              rows,
+		"entropy":           summary.Entropy,
+		"normalizedEntropy": summary.NormalizedEntropy,
+		"dead":              summary.Dead,
+		"neurons":           summary.Neurons,
+	}
+}
+
+// vector converts the array or the typed array to the vector.
+func vector(v js.Value) som.DataVector {
+	vector := make(som.DataVector, v.Length())
+	for k := range vector {
+		vector[k] = v.Index(k).Float()
+	}
+	return vector
+}
+
+func matrix(values [][]float64) []interface{} {
+	rows := make([]interface{}, len(values))
+	for x := range values {
+		row := make([]interface{}, len(values[x]))
+		for y, v := range values[x] {
+			row[y] = v
+		}
+		rows[x] = row
+	}
+	return rows
+}
+
+func jsError(message string) interface{} {
+	return map[string]interface{}{"error": message}
+}
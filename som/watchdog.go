@@ -0,0 +1,84 @@
+package som
+
+import "fmt"
+
+// Warning codes emitted by Watchdog, see Warning.
+const (
+	// WarnIdleNeuron: a neuron hasn't won for Watchdog.IdleIterations.
+	WarnIdleNeuron = "idle-neuron"
+
+	// WarnRateTooLow: the learning rate has fallen below Watchdog.MinRate
+	// before Watchdog.MinRateProgress of learning.
+	WarnRateTooLow = "rate-too-low"
+)
+
+// WarningEvent is emitted by Learn when Watchdog finds a non-fatal anomaly,
+// so problems surface early instead of after hours of computation.
+type WarningEvent struct {
+	// It is the iteration within bounds [1, ItNum] which revealed the anomaly.
+	It, ItNum int
+
+	Warning
+}
+
+func (e *WarningEvent) EventName() string { return "warning" }
+
+// Watchdog watches Learn for non-fatal anomalies, emits them as WarningEvents
+// and logs them at LogWarn level. Each anomaly is reported once per run.
+// Set it to SOM.Watchdog to enable the checks, zero values disable them.
+type Watchdog struct {
+	// IdleIterations reports neurons which haven't won
+	// in the last IdleIterations iterations, e.g. 10000.
+	IdleIterations int
+
+	// MinRate reports the learning rate (the restraint coefficient)
+	// falling below MinRate, e.g. 1e-9, before MinRateProgress
+	// (the share of completed iterations) of learning, e.g. 0.5.
+	MinRate, MinRateProgress float64
+
+	lastWon    [][]int
+	idle       [][]bool
+	rateWarned bool
+}
+
+// start resets the watchdog, called when learning starts.
+func (w *Watchdog) start(neurons [][]*Neuron) {
+	w.lastWon = make([][]int, len(neurons))
+	w.idle = make([][]bool, len(neurons))
+	for i := range neurons {
+		w.lastWon[i] = make([]int, len(neurons[i]))
+		w.idle[i] = make([]bool, len(neurons[i]))
+	}
+	w.rateWarned = false
+}
+
+// check inspects the completed iteration it (0 based) whose BMU is at (x, y).
+func (w *Watchdog) check(som *SOM, it, itNum, x, y int) {
+	w.lastWon[x][y] = it + 1
+	if w.MinRate > 0 && !w.rateWarned {
+		progress := float64(it+1) / float64(itNum)
+		if rate := som.Restraint.Apply(it, itNum); rate < w.MinRate && progress < w.MinRateProgress {
+			w.rateWarned = true
+			som.warn(it+1, itNum, WarnRateTooLow, "learning rate %g is below %g at %.0f%% progress", rate, w.MinRate, 100*progress)
+		}
+	}
+	if w.IdleIterations > 0 && (it+1)%w.IdleIterations == 0 {
+		for i := range w.lastWon {
+			for j, won := range w.lastWon[i] {
+				if !w.idle[i][j] && !som.IsMasked(i, j) && it+1-won >= w.IdleIterations {
+					w.idle[i][j] = true
+					som.warn(it+1, itNum, WarnIdleNeuron, "neuron (%d, %d) never won in last %d iterations", i, j, w.IdleIterations)
+				}
+			}
+		}
+	}
+}
+
+// warn logs the warning and emits it as WarningEvent.
+func (som *SOM) warn(it, itNum int, code, format string, args ...interface{}) {
+	warning := Warning{Code: code, Message: fmt.Sprintf(format, args...)}
+	som.log(LogWarn, "learning anomaly", "iteration", it, "code", code, "message", warning.Message)
+	if som.listening() {
+		som.Events.OnEvent(&WarningEvent{It: it, ItNum: itNum, Warning: warning})
+	}
+}
@@ -0,0 +1,36 @@
+package som_test
+
+import (
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+func TestWatchdogEmitsWarnings(t *testing.T) {
+	sm := som.New(1, 3)
+	sm.Initializer = &som.ProvidedWeightsInitializer{Weights: [][][]float64{{{0}, {10}, {100}}}}
+	sm.Restraint = &som.ExpRestraintFunc{InitialRate: 1, N: 1}
+	sm.TieBreaker = &som.LowestIndexTieBreaker{}
+	sm.Watchdog = &som.Watchdog{IdleIterations: 4, MinRate: 1e-3, MinRateProgress: 0.5}
+
+	var warnings []*som.WarningEvent
+	sm.Events = som.EventListenerFunc(func(event som.Event) {
+		if w, ok := event.(*som.WarningEvent); ok {
+			warnings = append(warnings, w)
+		}
+	})
+	// the vectors are close to the first two neurons only
+	ds := &som.DataSet{Vectors: []som.DataVector{{1}, {9}}}
+	if _, err := sm.LearnEpochs(ds, 10, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(warnings) != 2 {
+		t.Fatalf("Expected 2 warnings, got %v", warnings)
+	}
+	assertEq(t, warnings[0].Code, som.WarnIdleNeuron)
+	assertEq(t, warnings[0].It, 4)
+	assertEq(t, warnings[0].Message, "neuron (0, 2) never won in last 4 iterations")
+	assertEq(t, warnings[0].EventName(), "warning")
+	assertEq(t, warnings[1].Code, som.WarnRateTooLow)
+}
@@ -0,0 +1,83 @@
+package som
+
+import (
+	"container/heap"
+	"math"
+)
+
+// Watershed segments this map into clusters by the watershed of its U-matrix,
+// so the number of clusters is found by the map rather than chosen upfront.
+// Each basin of the U-matrix (a local minimum, possibly a plateau of equal
+// values) seeds a cluster, which is then flooded in ascending order of
+// the U-matrix values, so neighbouring clusters meet at the ridges of high
+// distances. Neighbours are direct (non-diagonal), including the neighbours
+// across the edges connected by Topology.
+// The result is indexed like Neurons, clusters[x][y] is the label of the neuron
+// within [0, clusters number), labels are assigned to the basins in the order of
+// neuron indexes, masked neurons have the label -1. See ClusterBoundaries.
+func (som *SOM) Watershed() [][]int {
+	umatrix := som.UMatrix()
+	xLen, yLen := som.Dims()
+	clusters := make([][]int, xLen)
+	for x := range clusters {
+		clusters[x] = make([]int, yLen)
+		for y := range clusters[x] {
+			clusters[x][y] = -1
+		}
+	}
+	offsets := [][2]int{{-1, 0}, {1, 0}, {0, -1}, {0, 1}}
+
+	// minimal neurons have no lower neighbours, neighbouring
+	// minimal neurons of the same value form a single basin
+	minimal := func(x, y int) bool {
+		for _, offset := range offsets {
+			nx, ny, ok := som.neighbour(x, y, offset[0], offset[1])
+			if ok && !som.IsMasked(nx, ny) && umatrix[nx][ny] < umatrix[x][y] {
+				return false
+			}
+		}
+		return true
+	}
+	queue := &pathQueue{}
+	labels := 0
+	for i := 0; i < som.Len(); i++ {
+		x, y := som.Position(i)
+		if som.IsMasked(x, y) || clusters[x][y] != -1 || !minimal(x, y) {
+			continue
+		}
+		clusters[x][y] = labels
+		basin := []int{i}
+		for len(basin) > 0 {
+			bx, by := som.Position(basin[len(basin)-1])
+			basin = basin[:len(basin)-1]
+			heap.Push(queue, pathItem{index: som.Index(bx, by), cost: umatrix[bx][by]})
+			for _, offset := range offsets {
+				nx, ny, ok := som.neighbour(bx, by, offset[0], offset[1])
+				if ok && !som.IsMasked(nx, ny) && clusters[nx][ny] == -1 && umatrix[nx][ny] == umatrix[x][y] && minimal(nx, ny) {
+					clusters[nx][ny] = labels
+					basin = append(basin, som.Index(nx, ny))
+				}
+			}
+		}
+		labels++
+	}
+
+	for queue.Len() > 0 {
+		item := heap.Pop(queue).(pathItem)
+		x, y := som.Position(item.index)
+		for _, offset := range offsets {
+			nx, ny, ok := som.neighbour(x, y, offset[0], offset[1])
+			if !ok || som.IsMasked(nx, ny) || clusters[nx][ny] != -1 {
+				continue
+			}
+			clusters[nx][ny] = clusters[x][y]
+			heap.Push(queue, pathItem{index: som.Index(nx, ny), cost: math.Max(umatrix[nx][ny], item.cost)})
+		}
+	}
+	return clusters
+}
+
+// Watershed segments the map into clusters, see SOM.Watershed.
+func (m *Model) Watershed() [][]int {
+	return m.som.Watershed()
+}
@@ -0,0 +1,41 @@
+package som_test
+
+import (
+	"testing"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+func TestWatershedFindsClustersSeparatedByRidge(t *testing.T) {
+	// two groups of close neurons separated by the middle column
+	sm := som.New(3, 5)
+	codebook := make([][][]float64, 3)
+	for x := range codebook {
+		for _, w := range []float64{0, 1, 50, 100, 101} {
+			codebook[x] = append(codebook[x], []float64{w})
+		}
+	}
+	if err := sm.LoadCodebook(codebook); err != nil {
+		t.Fatal(err)
+	}
+	clusters := sm.Watershed()
+	for x := range clusters {
+		assertEq(t, clusters[x][0], 0)
+		assertEq(t, clusters[x][1], 0)
+		assertEq(t, clusters[x][3], 1)
+		assertEq(t, clusters[x][4], 1)
+	}
+	if len(som.ClusterBoundaries(clusters)) != 1 {
+		t.Fatalf("Expected a single boundary, got %v", som.ClusterBoundaries(clusters))
+	}
+}
+
+func TestWatershedSkipsMaskedNeurons(t *testing.T) {
+	sm := som.New(1, 3)
+	if err := sm.LoadCodebook([][][]float64{{{0}, {1}, {2}}}); err != nil {
+		t.Fatal(err)
+	}
+	sm.Mask = [][]bool{{false, true, false}}
+	clusters := sm.Watershed()
+	checkIntsEqual(t, clusters[0], []int{0, -1, 1})
+}
@@ -0,0 +1,261 @@
+package som
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/voievodin/self-organizing-map/som/vec"
+)
+
+// WhiteningMethod selects the whitening transform, see WhiteningDataAdapter.
+type WhiteningMethod int
+
+const (
+	// PCAWhitening rotates vectors onto the principal components
+	// and scales them to unit variance, the features lose their meaning.
+	PCAWhitening WhiteningMethod = iota
+
+	// ZCAWhitening is PCA whitening rotated back to the original axes,
+	// so the whitened features stay as close as possible to the original ones.
+	ZCAWhitening
+)
+
+// WhiteningDataAdapter decorrelates features and scales them to unit variance,
+// so strongly correlated features don't dominate euclidean BMU selection.
+// Vectors are transformed as W * (vector - Mean).
+// Note that the original vector is modified.
+type WhiteningDataAdapter struct {
+	Mean []float64
+
+	// W is the whitening matrix and WInv is its inverse.
+	W, WInv [][]float64
+
+	method WhiteningMethod
+}
+
+// NewWhiteningDataAdapter fits whitening transform to the data set.
+// Epsilon is added to the covariance eigenvalues, which regularizes
+// the transform of (nearly) constant directions, e.g. 1e-5.
+func NewWhiteningDataAdapter(ds *DataSet, method WhiteningMethod, epsilon float64) *WhiteningDataAdapter {
+	width := ds.Width()
+	mean, cov := covariance(ds.Vectors, width)
+
+	values, vectors := symmetricEigen(cov)
+	// W = S^-1/2 * E^T for PCA, E * S^-1/2 * E^T for ZCA
+	w, wInv := newMatrix(width, width), newMatrix(width, width)
+	for i := 0; i < width; i++ {
+		scale := math.Sqrt(math.Max(values[i], 0) + epsilon)
+		for j := 0; j < width; j++ {
+			w[i][j] = vectors[j][i] / scale
+			wInv[j][i] = vectors[j][i] * scale
+		}
+	}
+	if method == ZCAWhitening {
+		w, wInv = mulMatrix(vectors, w), mulMatrix(wInv, transpose(vectors))
+	}
+	return &WhiteningDataAdapter{Mean: mean, W: w, WInv: wInv, method: method}
+}
+
+func (adapter *WhiteningDataAdapter) Adapt(vector []float64) []float64 {
+	centered := vec.SubTo(nil, vector, adapter.Mean)
+	mulVector(adapter.W, centered, vector)
+	return vector
+}
+
+// Inverse maps whitened vector back to the original space.
+// Note that the original vector is modified.
+func (adapter *WhiteningDataAdapter) Inverse(vector []float64) []float64 {
+	whitened := append([]float64(nil), vector...)
+	mulVector(adapter.WInv, whitened, vector)
+	return vec.Add(vector, adapter.Mean)
+}
+
+// Describe returns the spec of the adapter, the matrices are written row by row.
+func (adapter *WhiteningDataAdapter) Describe() ComponentSpec {
+	return ComponentSpec{
+		Type:   "whitening",
+		Params: Params{"method": float64(adapter.method)},
+		Values: map[string][]float64{
+			"mean":  append([]float64(nil), adapter.Mean...),
+			"w":     flattenMatrix(adapter.W),
+			"w_inv": flattenMatrix(adapter.WInv),
+		},
+	}
+}
+
+// newWhiteningDataAdapter recreates the described adapter, see Describe.
+func newWhiteningDataAdapter(params Params, values map[string][]float64) (DataAdapter, error) {
+	method := WhiteningMethod(params.Get("method", 0))
+	if method != PCAWhitening && method != ZCAWhitening {
+		return nil, fmt.Errorf("%w: unknown whitening method %d", ErrInvalidConfig, method)
+	}
+	mean := values["mean"]
+	width := len(mean)
+	if width == 0 || len(values["w"]) != width*width || len(values["w_inv"]) != width*width {
+		return nil, fmt.Errorf("%w: whitening needs mean and %dx%d matrices", ErrInvalidConfig, width, width)
+	}
+	return &WhiteningDataAdapter{
+		Mean:   mean,
+		W:      unflattenMatrix(values["w"], width),
+		WInv:   unflattenMatrix(values["w_inv"], width),
+		method: method,
+	}, params.Check("method")
+}
+
+func flattenMatrix(m [][]float64) []float64 {
+	var flat []float64
+	for _, row := range m {
+		flat = append(flat, row...)
+	}
+	return flat
+}
+
+func unflattenMatrix(flat []float64, cols int) [][]float64 {
+	m := make([][]float64, len(flat)/cols)
+	for i := range m {
+		m[i] = append([]float64(nil), flat[i*cols:(i+1)*cols]...)
+	}
+	return m
+}
+
+// covariance computes the mean and the population
+// covariance matrix of the vectors of the given width.
+func covariance(vectors []DataVector, width int) ([]float64, [][]float64) {
+	mean := make([]float64, width)
+	for _, vector := range vectors {
+		vec.Add(mean, vector)
+	}
+	for k := range mean {
+		mean[k] /= float64(len(vectors))
+	}
+
+	cov := newMatrix(width, width)
+	for _, vector := range vectors {
+		for i := 0; i < width; i++ {
+			for j := i; j < width; j++ {
+				cov[i][j] += (vector[i] - mean[i]) * (vector[j] - mean[j])
+			}
+		}
+	}
+	for i := 0; i < width; i++ {
+		for j := i; j < width; j++ {
+			cov[i][j] /= float64(len(vectors))
+			cov[j][i] = cov[i][j]
+		}
+	}
+	return mean, cov
+}
+
+func newMatrix(rows, cols int) [][]float64 {
+	m := make([][]float64, rows)
+	for i := range m {
+		m[i] = make([]float64, cols)
+	}
+	return m
+}
+
+func mulMatrix(a, b [][]float64) [][]float64 {
+	c := newMatrix(len(a), len(b[0]))
+	for i := range a {
+		for k := range b {
+			for j := range b[k] {
+				c[i][j] += a[i][k] * b[k][j]
+			}
+		}
+	}
+	return c
+}
+
+func transpose(a [][]float64) [][]float64 {
+	t := newMatrix(len(a[0]), len(a))
+	for i := range a {
+		for j := range a[i] {
+			t[j][i] = a[i][j]
+		}
+	}
+	return t
+}
+
+// mulVector computes m * v into result, which must not share memory with v.
+func mulVector(m [][]float64, v, result []float64) {
+	for i := range m {
+		sum := 0.0
+		for j, x := range v {
+			sum += m[i][j] * x
+		}
+		result[i] = sum
+	}
+}
+
+// symmetricEigen computes eigenvalues and eigenvectors of the symmetric matrix
+// by the cyclic Jacobi method, the i-th column of vectors is the eigenvector
+// of the i-th eigenvalue, eigenvalues are in descending order.
+func symmetricEigen(m [][]float64) (values []float64, vectors [][]float64) {
+	n := len(m)
+	a := newMatrix(n, n)
+	vectors = newMatrix(n, n)
+	for i := range m {
+		copy(a[i], m[i])
+		vectors[i][i] = 1
+	}
+
+	for sweep := 0; sweep < 100; sweep++ {
+		off := 0.0
+		for i := 0; i < n; i++ {
+			for j := i + 1; j < n; j++ {
+				off += a[i][j] * a[i][j]
+			}
+		}
+		if off < 1e-22 {
+			break
+		}
+		for p := 0; p < n; p++ {
+			for q := p + 1; q < n; q++ {
+				if a[p][q] == 0 {
+					continue
+				}
+				theta := (a[q][q] - a[p][p]) / (2 * a[p][q])
+				t := math.Copysign(1, theta) / (math.Abs(theta) + math.Sqrt(theta*theta+1))
+				c := 1 / math.Sqrt(t*t+1)
+				s := t * c
+				for k := 0; k < n; k++ {
+					akp, akq := a[k][p], a[k][q]
+					a[k][p], a[k][q] = c*akp-s*akq, s*akp+c*akq
+				}
+				for k := 0; k < n; k++ {
+					apk, aqk := a[p][k], a[q][k]
+					a[p][k], a[q][k] = c*apk-s*aqk, s*apk+c*aqk
+				}
+				for k := 0; k < n; k++ {
+					vkp, vkq := vectors[k][p], vectors[k][q]
+					vectors[k][p], vectors[k][q] = c*vkp-s*vkq, s*vkp+c*vkq
+				}
+			}
+		}
+	}
+
+	values = make([]float64, n)
+	order := make([]int, n)
+	for i := range values {
+		values[i] = a[i][i]
+		order[i] = i
+	}
+	// selection sort keeps it simple for small matrices
+	for i := 0; i < n; i++ {
+		maxIdx := i
+		for j := i + 1; j < n; j++ {
+			if values[order[j]] > values[order[maxIdx]] {
+				maxIdx = j
+			}
+		}
+		order[i], order[maxIdx] = order[maxIdx], order[i]
+	}
+	sortedValues, sortedVectors := make([]float64, n), newMatrix(n, n)
+	for i, idx := range order {
+		sortedValues[i] = values[idx]
+		for k := 0; k < n; k++ {
+			sortedVectors[k][i] = vectors[k][idx]
+		}
+	}
+	return sortedValues, sortedVectors
+}
@@ -0,0 +1,102 @@
+package som_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"testing/quick"
+
+	"github.com/voievodin/self-organizing-map/som"
+)
+
+func correlatedDataSet() *som.DataSet {
+	rnd := rand.New(rand.NewSource(1))
+	ds := &som.DataSet{}
+	for i := 0; i < 500; i++ {
+		a, b, c := rnd.NormFloat64(), rnd.NormFloat64(), rnd.NormFloat64()
+		ds.AddRaw(10+3*a, 2*a+0.5*b, -b+0.3*c)
+	}
+	return ds
+}
+
+func TestWhiteningDataAdapterDecorrelates(t *testing.T) {
+	for _, method := range []som.WhiteningMethod{som.PCAWhitening, som.ZCAWhitening} {
+		ds := correlatedDataSet()
+		adapter := som.NewWhiteningDataAdapter(ds, method, 0)
+
+		whitened := ds.Copy()
+		for _, vector := range whitened.Vectors {
+			adapter.Adapt(vector)
+		}
+
+		for i := 0; i < 3; i++ {
+			for j := 0; j < 3; j++ {
+				expected := 0.0
+				if i == j {
+					expected = 1
+				}
+				if cov := covariance(whitened, i, j); math.Abs(cov-expected) > 1e-6 {
+					t.Fatalf("method %d: expected cov[%d][%d] = %f, got %f", method, i, j, expected, cov)
+				}
+			}
+		}
+
+		restored := adapter.Inverse(append([]float64(nil), whitened.Vectors[7]...))
+		for k := range restored {
+			if math.Abs(restored[k]-ds.Vectors[7][k]) > 1e-9 {
+				t.Fatalf("method %d: expected inverse %v, got %v", method, ds.Vectors[7], restored)
+			}
+		}
+	}
+}
+
+func TestZCAWhiteningKeepsFeatureAxes(t *testing.T) {
+	ds := &som.DataSet{Vectors: []som.DataVector{{0, 0}, {2, 0}, {0, 4}, {2, 4}}}
+	adapter := som.NewWhiteningDataAdapter(ds, som.ZCAWhitening, 0)
+
+	// uncorrelated features are only scaled
+	checkSlicesEqual(t, adapter.Adapt([]float64{2, 4}), []float64{1, 1})
+}
+
+func covariance(ds *som.DataSet, i, j int) float64 {
+	meanI, meanJ := 0.0, 0.0
+	for _, v := range ds.Vectors {
+		meanI += v[i]
+		meanJ += v[j]
+	}
+	meanI /= float64(ds.Len())
+	meanJ /= float64(ds.Len())
+	cov := 0.0
+	for _, v := range ds.Vectors {
+		cov += (v[i] - meanI) * (v[j] - meanJ)
+	}
+	return cov / float64(ds.Len())
+}
+
+func TestWhiteningDataAdapterInverseRestoresVectors(t *testing.T) {
+	property := func(seed int64, zca bool) bool {
+		rng := rand.New(rand.NewSource(seed))
+		ds := &som.DataSet{}
+		for i := 0; i < 20; i++ {
+			x := rng.NormFloat64()
+			ds.Vectors = append(ds.Vectors, som.DataVector{x, 2*x + rng.NormFloat64(), rng.Float64() * 100})
+		}
+		method := som.PCAWhitening
+		if zca {
+			method = som.ZCAWhitening
+		}
+		adapter := som.NewWhiteningDataAdapter(ds, method, 1e-5)
+		for _, v := range ds.Vectors {
+			restored := adapter.Inverse(adapter.Adapt(append([]float64(nil), v...)))
+			for k := range v {
+				if math.Abs(restored[k]-v[k]) > 1e-6*(1+math.Abs(v[k])) {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	if err := quick.Check(property, nil); err != nil {
+		t.Fatal(err)
+	}
+}